@@ -0,0 +1,7 @@
+// Package bench contains benchmarks measuring the throughput and allocations of common
+// pipelines, used to evaluate changes to the core runtime.
+//
+// Run the suite with:
+//
+//	go test ./bench -bench . -benchmem
+package bench
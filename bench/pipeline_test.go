@@ -0,0 +1,202 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/flows"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+	"github.com/svenvdam/linea/util"
+)
+
+// items returns a slice of b.N integers to stream.
+func items(b *testing.B) []int {
+	res := make([]int, b.N)
+	for i := range res {
+		res[i] = i
+	}
+	return res
+}
+
+// count creates a Sink counting the items it receives, without retaining them.
+func count[I any]() *core.Sink[I, int] {
+	return sinks.Reduce(0, func(_ context.Context, acc int, _ I) int {
+		return acc + 1
+	})
+}
+
+// run runs the stream to completion and fails the benchmark on error.
+func run[R any](b *testing.B, stream *core.Stream[R]) {
+	runWith(b, context.Background(), stream)
+}
+
+// runWith runs the stream to completion with the given context and fails the benchmark on error.
+func runWith[R any](b *testing.B, ctx context.Context, stream *core.Stream[R]) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	res := <-stream.Run(ctx)
+	stream.AwaitDone()
+	if res.Err != nil {
+		b.Fatal(res.Err)
+	}
+}
+
+func BenchmarkSourceToSink(b *testing.B) {
+	run(b, compose.SourceToSink(sources.Slice(items(b)), count[int]()))
+}
+
+func BenchmarkMapFilter(b *testing.B) {
+	run(b, compose.SourceThroughFlowToSink2(
+		sources.Slice(items(b)),
+		flows.Map(func(_ context.Context, i int) int { return i * 2 }),
+		flows.Filter(func(_ context.Context, i int) bool { return i%3 != 0 }),
+		count[int](),
+	))
+}
+
+// BenchmarkMapFilterUnfused runs the pipeline of BenchmarkMapFilter with each flow in its own
+// goroutine, as the baseline for the gain of fusing them.
+func BenchmarkMapFilterUnfused(b *testing.B) {
+	run(b, compose.SourceThroughFlowToSink2(
+		sources.Slice(items(b)),
+		flows.Map(func(_ context.Context, i int) int { return i * 2 }, core.WithAsyncBoundary()),
+		flows.Filter(func(_ context.Context, i int) bool { return i%3 != 0 }, core.WithAsyncBoundary()),
+		count[int](),
+	))
+}
+
+func BenchmarkNamedStages(b *testing.B) {
+	run(b, compose.SourceThroughFlowToSink2(
+		sources.Slice(items(b)),
+		flows.ForEach(func(_ context.Context, i int) {}, core.WithFlowName("first")),
+		flows.ForEach(func(_ context.Context, i int) {}, core.WithFlowName("second")),
+		count[int](),
+	))
+}
+
+// countErrors creates a Sink counting the errors it receives.
+func countErrors[I any]() *core.Sink[I, int] {
+	return core.NewSink(
+		0,
+		func(_ context.Context, _ I, acc core.Item[int]) (core.Item[int], core.StreamAction) {
+			return acc, core.ActionProceed
+		},
+		func(_ context.Context, _ error, acc core.Item[int]) (core.Item[int], core.StreamAction) {
+			return core.Item[int]{Value: acc.Value + 1}, core.ActionProceed
+		},
+		nil,
+	)
+}
+
+// failing creates a named synchronous flow emitting the error returned by errFor for every
+// item and continuing, so that the errors are attributed to it and reach the sink.
+func failing(errFor func(int) error) *core.Flow[int, int] {
+	return core.NewSyncFlow(
+		func(_ context.Context, i int, emit func(core.Item[int])) core.StreamAction {
+			emit(core.Item[int]{Err: errFor(i)})
+			return core.ActionProceed
+		},
+		nil,
+		core.WithFlowName("fail"),
+	)
+}
+
+func BenchmarkErrors(b *testing.B) {
+	errTest := errors.New("test error")
+	run(b, compose.SourceThroughFlowToSink(
+		sources.Slice(items(b)),
+		failing(func(int) error { return errTest }),
+		countErrors[int](),
+	))
+}
+
+// BenchmarkErrorsAlternating runs the pipeline of BenchmarkErrors with a named stage emitting
+// a different error than for the previous item, so every error is wrapped in a new StageError,
+// as the baseline for the gain of reusing them.
+func BenchmarkErrorsAlternating(b *testing.B) {
+	errs := []error{errors.New("first error"), errors.New("second error")}
+	run(b, compose.SourceThroughFlowToSink(
+		sources.Slice(items(b)),
+		failing(func(i int) error { return errs[i%2] }),
+		countErrors[int](),
+	))
+}
+
+func BenchmarkBatch(b *testing.B) {
+	run(b, compose.SourceThroughFlowToSink(
+		sources.Slice(items(b)),
+		flows.Batch[int](64),
+		count[[]int](),
+	))
+}
+
+func BenchmarkMapPar(b *testing.B) {
+	run(b, compose.SourceThroughFlowToSink(
+		sources.Slice(items(b)),
+		flows.MapPar(func(_ context.Context, i int) int { return i * 2 }, 4),
+		count[int](),
+	))
+}
+
+// mapParPerItem is MapPar starting a goroutine for every item instead of reusing workers.
+func mapParPerItem[I, O any](fn func(context.Context, I) O, parallelism int) *core.Flow[I, O] {
+	sem := make(chan struct{}, parallelism)
+	wg := sync.WaitGroup{}
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[O]) core.StreamAction {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() {
+					wg.Done()
+					<-sem
+				}()
+				util.Send(ctx, core.Item[O]{Value: fn(ctx, elem)}, out)
+			}()
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[O]) {
+			wg.Wait()
+		},
+	)
+}
+
+// BenchmarkMapParPerItem runs the pipeline of BenchmarkMapPar starting a goroutine per item,
+// as the baseline for the gain of reusing workers.
+func BenchmarkMapParPerItem(b *testing.B) {
+	run(b, compose.SourceThroughFlowToSink(
+		sources.Slice(items(b)),
+		mapParPerItem(func(_ context.Context, i int) int { return i * 2 }, 4),
+		count[int](),
+	))
+}
+
+func BenchmarkFlatMap(b *testing.B) {
+	run(b, compose.SourceThroughFlowToSink(
+		sources.Slice(items(b)),
+		flows.FlatMap(func(_ context.Context, i int) []int { return []int{i, i} }),
+		count[int](),
+	))
+}
+
+func BenchmarkChunkedSourceToSink(b *testing.B) {
+	ctx := core.ContextWithAttributes(context.Background(), core.ChunkSize(64))
+	runWith(b, ctx, compose.SourceToSink(sources.Slice(items(b)), count[int]()))
+}
+
+func BenchmarkChunkedMapFilter(b *testing.B) {
+	ctx := core.ContextWithAttributes(context.Background(), core.ChunkSize(64))
+	runWith(b, ctx, compose.SourceThroughFlowToSink2(
+		sources.Slice(items(b)),
+		flows.Map(func(_ context.Context, i int) int { return i * 2 }),
+		flows.Filter(func(_ context.Context, i int) bool { return i%3 != 0 }),
+		count[int](),
+	))
+}
@@ -26,6 +26,18 @@
 //	    flows.Map(strconv.Itoa),
 //	)
 //
+// Non-linear topologies, such as broadcasting to multiple flows and merging their
+// results, can be built with a GraphBuilder:
+//
+//	g := compose.NewGraphBuilder()
+//	branches := compose.GraphBroadcast(g, compose.GraphSource(g, sourceOfInts), 2)
+//	merged := compose.GraphMerge(
+//	    g,
+//	    compose.GraphFlow(g, branches[0], flows.Map(double)),
+//	    compose.GraphFlow(g, branches[1], flows.Map(square)),
+//	)
+//	stream, err := compose.BuildGraph(g, merged, sinks.Slice[int]())
+//
 // The functions in this package are designed to be composable, allowing for
 // flexible construction of processing pipelines while maintaining type safety
 // and readability.
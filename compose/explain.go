@@ -0,0 +1,23 @@
+package compose
+
+import "fmt"
+
+// Explain renders a human-readable, indented description of a pipeline, listing its stages
+// with their names, item types and configuration such as buffer sizes and parallelism. It
+// accepts any Source, Flow, Sink or Stream, and is meant for logging a pipeline built from
+// configuration when a service starts.
+//
+// Example output:
+//
+//	Stream[[]string] with 3 stages:
+//	  source "orders" (int) bufSize=16
+//	  flow "enrich" (int -> string) parallelism=4
+//	  sink (string -> []string)
+//
+// Parameters:
+//   - pipeline: The Source, Flow, Sink or Stream to describe
+//
+// Returns the description of the pipeline
+func Explain(pipeline fmt.Stringer) string {
+	return pipeline.String()
+}
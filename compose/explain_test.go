@@ -0,0 +1,74 @@
+package compose
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/flows"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name     string
+		pipeline fmt.Stringer
+		expected string
+	}{
+		{
+			name: "stream",
+			pipeline: SourceThroughFlowToSink2(
+				sources.Slice([]int{1, 2, 3}, core.WithSourceName("numbers"), core.WithSourceBufSize(16)),
+				flows.MapPar(func(_ context.Context, i int) string { return strconv.Itoa(i) }, 4, core.WithFlowName("format")),
+				flows.Filter(func(_ context.Context, s string) bool { return s != "" }),
+				sinks.Slice[string](),
+			),
+			expected: "Stream[[]string] with 4 stages:\n" +
+				"  source \"numbers\" (int) bufSize=16\n" +
+				"  flow \"format\" (int -> string) parallelism=4\n" +
+				"  flow (string -> string)\n" +
+				"  sink (string -> []string)",
+		},
+		{
+			name: "segment attributes",
+			pipeline: core.FlowWithAttributes(
+				MergeFlows(
+					flows.Map(func(_ context.Context, i int) int { return i * 2 }),
+					flows.Map(func(_ context.Context, i int) int { return i + 1 }, core.WithFlowBuffer(8, core.OverflowDropHead)),
+				),
+				core.SetAttribute(core.Name("transform"), core.ErrorDrainKey, core.ErrorDrainDiscard),
+			),
+			expected: "Flow[int, int] with 2 stages:\n" +
+				"  flow \"transform\" (int -> int) errorDrain=discard\n" +
+				"  flow \"transform\" (int -> int) bufSize=8 errorDrain=discard overflow=dropHead",
+		},
+		{
+			name: "unprintable attributes are left out",
+			pipeline: flows.Map(
+				func(_ context.Context, i int) int { return i },
+				core.WithFlowAttributes(core.DivertErrors(core.NewMergeHub[error](1))),
+			),
+			expected: "Flow[int, int] with 1 stage:\n" +
+				"  flow (int -> int) errorMode=divert",
+		},
+		{
+			name:     "junction",
+			pipeline: SourceToSink(core.MergeSources(sources.Slice([]int{1}), sources.Slice([]int{2})), sinks.Slice[int]()),
+			expected: "Stream[[]int] with 4 stages:\n" +
+				"  source (int)\n" +
+				"  source (int)\n" +
+				"  junction \"merge\"\n" +
+				"  sink (int -> []int)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Explain(tt.pipeline))
+		})
+	}
+}
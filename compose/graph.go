@@ -0,0 +1,219 @@
+package compose
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// ErrInvalidGraph is returned by BuildGraph when the graph is not wired correctly.
+var ErrInvalidGraph = errors.New("invalid graph")
+
+// GraphBuilder builds non-linear stream topologies such as fan-out and fan-in shapes.
+// Stages are added as nodes, and edges are wired explicitly by passing the output
+// Port of one node as the input of another. Every output port must be consumed exactly
+// once, which is validated when the graph is built.
+//
+// Example of a diamond shape:
+//
+//	g := compose.NewGraphBuilder()
+//	src := compose.GraphSource(g, sources.Slice([]int{1, 2, 3}))
+//	branches := compose.GraphBroadcast(g, src, 2)
+//	doubled := compose.GraphFlow(g, branches[0], flows.Map(double))
+//	squared := compose.GraphFlow(g, branches[1], flows.Map(square))
+//	merged := compose.GraphMerge(g, doubled, squared)
+//	stream, err := compose.BuildGraph(g, merged, sinks.Slice[int]())
+type GraphBuilder struct {
+	ports []*portState
+	errs  []error
+	built bool
+}
+
+// portState tracks how often an output port has been wired to a downstream node.
+type portState struct {
+	id       int
+	consumed int
+}
+
+// Port is a typed output of a node in a GraphBuilder. It can be used as the input
+// of exactly one other node.
+//
+// Type Parameters:
+//   - T: Type of items emitted through the port
+type Port[T any] struct {
+	graph  *GraphBuilder
+	state  *portState
+	source *core.Source[T]
+}
+
+// NewGraphBuilder creates an empty GraphBuilder.
+func NewGraphBuilder() *GraphBuilder {
+	return &GraphBuilder{}
+}
+
+// newPort registers a new output port in the graph.
+func newPort[T any](g *GraphBuilder, source *core.Source[T]) *Port[T] {
+	state := &portState{id: len(g.ports)}
+	g.ports = append(g.ports, state)
+	return &Port[T]{
+		graph:  g,
+		state:  state,
+		source: source,
+	}
+}
+
+// use marks a port as consumed by a node and records an error if the port
+// is nil, belongs to another graph or has already been consumed.
+func use[T any](g *GraphBuilder, port *Port[T]) bool {
+	switch {
+	case g.built:
+		g.errs = append(g.errs, errors.New("graph has already been built"))
+		return false
+	case port == nil:
+		g.errs = append(g.errs, errors.New("port is nil"))
+		return false
+	case port.graph != g:
+		g.errs = append(g.errs, fmt.Errorf("port %d belongs to another graph", port.state.id))
+		return false
+	}
+
+	port.state.consumed++
+	if port.state.consumed > 1 {
+		g.errs = append(g.errs, fmt.Errorf("port %d is consumed more than once", port.state.id))
+		return false
+	}
+
+	// The node producing the port was wired incorrectly, its error is already recorded
+	return port.source != nil
+}
+
+// GraphSource adds a source node to the graph.
+//
+// Type Parameters:
+//   - T: Type of items produced by the source
+//
+// Parameters:
+//   - g: The graph to add the node to
+//   - source: The source producing items of type T
+//
+// Returns the output Port of the source node
+func GraphSource[T any](g *GraphBuilder, source *core.Source[T]) *Port[T] {
+	return newPort(g, source)
+}
+
+// GraphFlow adds a flow node to the graph, connecting its input to the given port.
+//
+// Type Parameters:
+//   - I: Type of items received by the flow
+//   - O: Type of items produced by the flow
+//
+// Parameters:
+//   - g: The graph to add the node to
+//   - in: The port providing the input of the flow
+//   - flow: The flow transforming items from type I to type O
+//
+// Returns the output Port of the flow node
+func GraphFlow[I, O any](g *GraphBuilder, in *Port[I], flow *core.Flow[I, O]) *Port[O] {
+	if !use(g, in) {
+		return newPort[O](g, nil)
+	}
+	return newPort(g, core.AppendFlowToSource(in.source, flow))
+}
+
+// GraphBroadcast adds a fan-out node to the graph, emitting every item of the input
+// port to each of the n output ports.
+//
+// Type Parameters:
+//   - T: Type of items being broadcast
+//
+// Parameters:
+//   - g: The graph to add the node to
+//   - in: The port providing the items to broadcast
+//   - n: The number of output ports, must be at least 1
+//
+// Returns n output Ports that each receive all items of the input port
+func GraphBroadcast[T any](g *GraphBuilder, in *Port[T], n int) []*Port[T] {
+	if n < 1 {
+		g.errs = append(g.errs, fmt.Errorf("broadcast requires at least 1 output, got %d", n))
+		n = 0
+	}
+
+	ports := make([]*Port[T], n)
+	if !use(g, in) || n == 0 {
+		for i := range ports {
+			ports[i] = newPort[T](g, nil)
+		}
+		return ports
+	}
+
+	for i, branch := range core.BroadcastSource(in.source, n) {
+		ports[i] = newPort(g, branch)
+	}
+	return ports
+}
+
+// GraphMerge adds a fan-in node to the graph, emitting the items of all input ports
+// through a single output port. No ordering is guaranteed between items of different inputs.
+//
+// Type Parameters:
+//   - T: Type of items being merged
+//
+// Parameters:
+//   - g: The graph to add the node to
+//   - ins: The ports providing the items to merge, at least one is required
+//
+// Returns the output Port of the merge node
+func GraphMerge[T any](g *GraphBuilder, ins ...*Port[T]) *Port[T] {
+	if len(ins) == 0 {
+		g.errs = append(g.errs, errors.New("merge requires at least 1 input"))
+		return newPort[T](g, nil)
+	}
+
+	sources := make([]*core.Source[T], 0, len(ins))
+	ok := true
+	for _, in := range ins {
+		if !use(g, in) {
+			ok = false
+			continue
+		}
+		sources = append(sources, in.source)
+	}
+	if !ok {
+		return newPort[T](g, nil)
+	}
+	return newPort(g, core.MergeSources(sources...))
+}
+
+// BuildGraph validates the graph and connects the given port to a sink, returning a
+// runnable Stream. Building fails if any port other than the one passed is left
+// unconsumed, if a port is consumed more than once, or if any node was wired incorrectly.
+// A graph can only be built once.
+//
+// Type Parameters:
+//   - T: Type of items consumed by the sink
+//   - R: Type of the final result produced by the sink
+//
+// Parameters:
+//   - g: The graph to build
+//   - in: The port providing the input of the sink
+//   - sink: The sink consuming items of type T and producing a result of type R
+//
+// Returns a Stream that can be executed to produce a result of type R, or an error
+// wrapping ErrInvalidGraph describing all problems found in the graph
+func BuildGraph[T, R any](g *GraphBuilder, in *Port[T], sink *core.Sink[T, R]) (*core.Stream[R], error) {
+	use(g, in)
+	g.built = true
+
+	errs := g.errs
+	for _, port := range g.ports {
+		if port.consumed == 0 {
+			errs = append(errs, fmt.Errorf("port %d is not connected", port.id))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGraph, errors.Join(errs...))
+	}
+
+	return core.ConnectSourceToSink(in.source, sink), nil
+}
@@ -0,0 +1,110 @@
+package compose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/flows"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestGraphDiamond(t *testing.T) {
+	g := NewGraphBuilder()
+	src := GraphSource(g, sources.Slice([]int{1, 2, 3}))
+	branches := GraphBroadcast(g, src, 2)
+	doubled := GraphFlow(g, branches[0], flows.Map(func(_ context.Context, i int) int { return i * 2 }))
+	squared := GraphFlow(g, branches[1], flows.Map(func(_ context.Context, i int) int { return i * i }))
+	merged := GraphMerge(g, doubled, squared)
+
+	stream, err := BuildGraph(g, merged, sinks.Slice[int]())
+	assert.NoError(t, err)
+
+	res := <-stream.Run(context.Background())
+	assert.NoError(t, res.Err)
+	assert.ElementsMatch(t, []int{2, 4, 6, 1, 4, 9}, res.Value)
+}
+
+func TestGraphValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func() error
+	}{
+		{
+			name: "unconnected port",
+			build: func() error {
+				g := NewGraphBuilder()
+				src := GraphSource(g, sources.Slice([]int{1}))
+				branches := GraphBroadcast(g, src, 2)
+				_, err := BuildGraph(g, branches[0], sinks.Slice[int]())
+				return err
+			},
+		},
+		{
+			name: "port consumed twice",
+			build: func() error {
+				g := NewGraphBuilder()
+				src := GraphSource(g, sources.Slice([]int{1}))
+				a := GraphFlow(g, src, flows.Map(func(_ context.Context, i int) int { return i }))
+				b := GraphFlow(g, src, flows.Map(func(_ context.Context, i int) int { return i }))
+				_, err := BuildGraph(g, GraphMerge(g, a, b), sinks.Slice[int]())
+				return err
+			},
+		},
+		{
+			name: "port from another graph",
+			build: func() error {
+				other := NewGraphBuilder()
+				src := GraphSource(other, sources.Slice([]int{1}))
+				g := NewGraphBuilder()
+				_, err := BuildGraph(g, src, sinks.Slice[int]())
+				return err
+			},
+		},
+		{
+			name: "broadcast without outputs",
+			build: func() error {
+				g := NewGraphBuilder()
+				src := GraphSource(g, sources.Slice([]int{1}))
+				GraphBroadcast(g, src, 0)
+				_, err := BuildGraph(g, GraphSource(g, sources.Slice([]int{1})), sinks.Slice[int]())
+				return err
+			},
+		},
+		{
+			name: "merge without inputs",
+			build: func() error {
+				g := NewGraphBuilder()
+				_, err := BuildGraph(g, GraphMerge[int](g), sinks.Slice[int]())
+				return err
+			},
+		},
+		{
+			name: "nil port",
+			build: func() error {
+				g := NewGraphBuilder()
+				_, err := BuildGraph[int](g, nil, sinks.Slice[int]())
+				return err
+			},
+		},
+		{
+			name: "graph built twice",
+			build: func() error {
+				g := NewGraphBuilder()
+				_, err := BuildGraph(g, GraphSource(g, sources.Slice([]int{1})), sinks.Slice[int]())
+				if err != nil {
+					return err
+				}
+				_, err = BuildGraph(g, GraphSource(g, sources.Slice([]int{1})), sinks.Slice[int]())
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.build(), ErrInvalidGraph)
+		})
+	}
+}
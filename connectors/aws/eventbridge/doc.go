@@ -5,6 +5,9 @@
 //
 // Features:
 // - EventBridge event publishing with result handling and original input preservation
+// - Optional hooks around every EventBridge call for audit logs and metrics
+// - Per-entry batch results through PutEventsResult.BatchResult, shared with other connectors
+// - EventBusCheck preflight check verifying access to an event bus before a stream starts
 //
 // This package requires an externally configured AWS client to be passed in, allowing the caller
 // to handle authentication and AWS configuration according to their own requirements.
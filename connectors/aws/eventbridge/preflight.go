@@ -0,0 +1,36 @@
+package eventbridge
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+)
+
+// EventBridgeDescribeClient defines the interface for EventBridge operations needed by EventBusCheck
+type EventBridgeDescribeClient interface {
+	DescribeEventBus(
+		ctx context.Context,
+		params *eventbridge.DescribeEventBusInput,
+		optFns ...func(*eventbridge.Options),
+	) (*eventbridge.DescribeEventBusOutput, error)
+}
+
+// EventBusCheck creates a preflight check verifying that the event bus exists and can be
+// accessed with the client's credentials. Register it with core.WithFlowPreflight to fail
+// fast before a stream starts.
+//
+// Parameters:
+//   - client: AWS EventBridge client or compatible interface
+//   - eventBusName: Name or ARN of the event bus to check, the default event bus is used if empty
+//
+// Returns a function that returns an error if the event bus cannot be accessed
+func EventBusCheck(client EventBridgeDescribeClient, eventBusName string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		input := &eventbridge.DescribeEventBusInput{}
+		if eventBusName != "" {
+			input.Name = &eventBusName
+		}
+		_, err := client.DescribeEventBus(ctx, input)
+		return err
+	}
+}
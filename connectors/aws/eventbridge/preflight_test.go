@@ -0,0 +1,59 @@
+package eventbridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/svenvdam/linea/connectors/aws/eventbridge/mocks"
+	"github.com/svenvdam/linea/connectors/aws/util"
+)
+
+func TestEventBusCheck(t *testing.T) {
+	testErr := errors.New("access denied")
+
+	tests := []struct {
+		name          string
+		eventBusName  string
+		expectedInput *eventbridge.DescribeEventBusInput
+		err           error
+		expectedErr   error
+	}{
+		{
+			name:          "checks the given event bus",
+			eventBusName:  "my-bus",
+			expectedInput: &eventbridge.DescribeEventBusInput{Name: util.AsPtr("my-bus")},
+		},
+		{
+			name:          "checks the default event bus",
+			expectedInput: &eventbridge.DescribeEventBusInput{},
+		},
+		{
+			name:          "fails when event bus cannot be accessed",
+			eventBusName:  "my-bus",
+			expectedInput: &eventbridge.DescribeEventBusInput{Name: util.AsPtr("my-bus")},
+			err:           testErr,
+			expectedErr:   testErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewMockEventBridgeDescribeClient(t)
+			mockClient.EXPECT().
+				DescribeEventBus(mock.Anything, tt.expectedInput, mock.Anything).
+				Return(&eventbridge.DescribeEventBusOutput{}, tt.err).Once()
+
+			err := EventBusCheck(mockClient, tt.eventBusName)(context.Background())
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
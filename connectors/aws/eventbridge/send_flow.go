@@ -2,8 +2,13 @@ package eventbridge
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/svenvdam/linea/connectors"
+	"github.com/svenvdam/linea/connectors/aws/util"
 	"github.com/svenvdam/linea/core"
 	"github.com/svenvdam/linea/flows"
 )
@@ -22,21 +27,92 @@ type PutEventsResult[I any] struct {
 	// The original item that was used to create the EventBridge event
 	Original I
 
+	// The input sent to the EventBridge PutEvents operation
+	Input *eventbridge.PutEventsInput
+
 	// The output from the EventBridge PutEvents operation
 	Output *eventbridge.PutEventsOutput
 }
 
+// retriableErrorCodes are the PutEvents entry error codes for which retrying may succeed.
+var retriableErrorCodes = map[string]bool{
+	"InternalFailure":     true,
+	"ThrottlingException": true,
+}
+
+// BatchResult reports which entries of the PutEvents request succeeded and which failed.
+// EventBridge reports failures per entry, so a successful request can still contain failed
+// entries. Failures due to throttling or internal errors are marked as retriable.
+//
+// Returns a BatchResult holding the request entries
+func (r PutEventsResult[I]) BatchResult() connectors.BatchResult[types.PutEventsRequestEntry] {
+	var result connectors.BatchResult[types.PutEventsRequestEntry]
+	if r.Input == nil {
+		return result
+	}
+
+	for i, entry := range r.Input.Entries {
+		// Result entries are in the same order as the request entries
+		if r.Output == nil || i >= len(r.Output.Entries) {
+			result.Failed = append(result.Failed, connectors.BatchFailure[types.PutEventsRequestEntry]{
+				Entry:  entry,
+				Reason: "no result returned for entry",
+			})
+			continue
+		}
+
+		resultEntry := r.Output.Entries[i]
+		if resultEntry.ErrorCode == nil {
+			result.Succeeded = append(result.Succeeded, entry)
+			continue
+		}
+
+		failure := connectors.BatchFailure[types.PutEventsRequestEntry]{
+			Entry:     entry,
+			Code:      *resultEntry.ErrorCode,
+			Retriable: retriableErrorCodes[*resultEntry.ErrorCode],
+		}
+		if resultEntry.ErrorMessage != nil {
+			failure.Reason = *resultEntry.ErrorMessage
+		}
+		result.Failed = append(result.Failed, failure)
+	}
+	return result
+}
+
 // SendFlowConfig holds configuration for the EventBridge send flow
 type SendFlowConfig struct {
 	// EventBusName is the name of the EventBridge bus to send to
 	// If not specified, the default event bus will be used
 	EventBusName string
+
+	// Hooks are called around every PutEvents call, for example to record audit logs or metrics
+	Hooks util.Hooks
+}
+
+const (
+	maxEventBusNameLength = 1600
+	maxEntriesPerRequest  = 10
+)
+
+// Validate checks the config and returns an error wrapping util.ErrInvalidConfig
+// describing every invalid value.
+func (c SendFlowConfig) Validate() error {
+	var problems []error
+	if len(c.EventBusName) > maxEventBusNameLength {
+		problems = append(problems, fmt.Errorf(
+			"EventBusName must be at most %d characters, got %d", maxEventBusNameLength, len(c.EventBusName)))
+	}
+	return util.ConfigError(problems...)
 }
 
 // SendFlow creates a Flow that sends events to an EventBridge event bus and passes the results downstream.
 // For each input event, it sends it to EventBridge and emits a PutEventsResult containing the
 // original input item and the EventBridge response.
 // If an error occurs during sending, it will be propagated through the flow's error handling mechanism.
+// Inputs with more than 10 entries are rejected without calling EventBridge, as EventBridge accepts
+// at most 10 entries per request. If the config is invalid, the validation error is propagated for
+// every item without calling EventBridge.
 //
 // Type Parameters:
 //   - I: The type of input items that will be converted to EventBridge events
@@ -54,10 +130,22 @@ func SendFlow[I any](
 	eventsBuilder func(I) *eventbridge.PutEventsInput,
 	opts ...core.FlowOption,
 ) *core.Flow[I, PutEventsResult[I]] {
+	if err := config.Validate(); err != nil {
+		return flows.TryMap(func(context.Context, I) (PutEventsResult[I], error) {
+			return PutEventsResult[I]{}, err
+		}, opts...)
+	}
+
 	return flows.TryMap(func(ctx context.Context, elem I) (PutEventsResult[I], error) {
 		// Build the events input from the input element
 		eventsInput := eventsBuilder(elem)
 
+		// Reject requests that EventBridge would refuse
+		if len(eventsInput.Entries) > maxEntriesPerRequest {
+			return PutEventsResult[I]{}, fmt.Errorf(
+				"PutEvents accepts at most %d entries, got %d", maxEntriesPerRequest, len(eventsInput.Entries))
+		}
+
 		// If EventBusName is set in config, ensure it's applied to entries that don't specify one
 		if config.EventBusName != "" {
 			for i := range eventsInput.Entries {
@@ -68,7 +156,18 @@ func SendFlow[I any](
 		}
 
 		// Send the events to EventBridge using the provided context
-		output, err := client.PutEvents(ctx, eventsInput)
+		output, err := util.Call(
+			ctx,
+			config.Hooks,
+			"PutEvents",
+			eventsInput,
+			func(ctx context.Context, input *eventbridge.PutEventsInput) (*eventbridge.PutEventsOutput, error) {
+				return client.PutEvents(ctx, input)
+			},
+			func(output *eventbridge.PutEventsOutput) middleware.Metadata {
+				return output.ResultMetadata
+			},
+		)
 		if err != nil {
 			return PutEventsResult[I]{}, err
 		}
@@ -76,6 +175,7 @@ func SendFlow[I any](
 		// Create the result, including the original input item
 		return PutEventsResult[I]{
 			Original: elem,
+			Input:    eventsInput,
 			Output:   output,
 		}, nil
 	}, opts...)
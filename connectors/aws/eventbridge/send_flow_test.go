@@ -3,6 +3,7 @@ package eventbridge
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
@@ -10,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/connectors"
 	"github.com/svenvdam/linea/connectors/aws/eventbridge/mocks"
 	"github.com/svenvdam/linea/connectors/aws/util"
 	"github.com/svenvdam/linea/sinks"
@@ -61,6 +63,16 @@ func TestSendFlow(t *testing.T) {
 			expectedResults: []PutEventsResult[string]{
 				{
 					Original: "test event",
+					Input: &eventbridge.PutEventsInput{
+						Entries: []types.PutEventsRequestEntry{
+							{
+								EventBusName: util.AsPtr("test-event-bus"),
+								Source:       util.AsPtr("test.source"),
+								DetailType:   util.AsPtr("TestEvent"),
+								Detail:       util.AsPtr(`{"id":"123","value":"test"}`),
+							},
+						},
+					},
 					Output: &eventbridge.PutEventsOutput{
 						FailedEntryCount: 0,
 						Entries: []types.PutEventsResultEntry{
@@ -87,6 +99,16 @@ func TestSendFlow(t *testing.T) {
 			expectedResults: nil,
 			expectedErr:     errors.New("eventbridge error"),
 		},
+		{
+			name: "invalid config fails without sending",
+			config: SendFlowConfig{
+				EventBusName: strings.Repeat("a", 1601),
+			},
+			input:           "test event",
+			setupMocks:      func(t *testing.T, mockClient *mocks.MockEventBridgeSendClient) {},
+			expectedResults: []PutEventsResult[string]{},
+			expectedErr:     SendFlowConfig{EventBusName: strings.Repeat("a", 1601)}.Validate(),
+		},
 	}
 
 	for _, tt := range tests {
@@ -129,3 +151,124 @@ func TestSendFlow(t *testing.T) {
 		})
 	}
 }
+
+func TestSendFlowTooManyEntries(t *testing.T) {
+	// The mock fails the test if PutEvents is called
+	mockClient := mocks.NewMockEventBridgeSendClient(t)
+
+	eventBuilder := func(n int) *eventbridge.PutEventsInput {
+		return &eventbridge.PutEventsInput{
+			Entries: make([]types.PutEventsRequestEntry, n),
+		}
+	}
+
+	stream := compose.SourceThroughFlowToSink(
+		sources.Slice([]int{11}),
+		SendFlow(mockClient, SendFlowConfig{}, eventBuilder),
+		sinks.Slice[PutEventsResult[int]](),
+	)
+
+	result := <-stream.Run(context.Background())
+
+	assert.EqualError(t, result.Err, "PutEvents accepts at most 10 entries, got 11")
+}
+
+func TestSendFlowConfigValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      SendFlowConfig
+		expectedErr string
+	}{
+		{
+			name:   "default event bus",
+			config: SendFlowConfig{},
+		},
+		{
+			name:   "named event bus",
+			config: SendFlowConfig{EventBusName: "test-event-bus"},
+		},
+		{
+			name:        "event bus name too long",
+			config:      SendFlowConfig{EventBusName: strings.Repeat("a", 1601)},
+			expectedErr: "EventBusName must be at most 1600 characters, got 1601",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, util.ErrInvalidConfig)
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestPutEventsResultBatchResult(t *testing.T) {
+	entryA := types.PutEventsRequestEntry{Detail: util.AsPtr("a")}
+	entryB := types.PutEventsRequestEntry{Detail: util.AsPtr("b")}
+	entryC := types.PutEventsRequestEntry{Detail: util.AsPtr("c")}
+
+	tests := []struct {
+		name     string
+		result   PutEventsResult[string]
+		expected connectors.BatchResult[types.PutEventsRequestEntry]
+	}{
+		{
+			name: "all entries succeeded",
+			result: PutEventsResult[string]{
+				Input: &eventbridge.PutEventsInput{Entries: []types.PutEventsRequestEntry{entryA, entryB}},
+				Output: &eventbridge.PutEventsOutput{Entries: []types.PutEventsResultEntry{
+					{EventId: util.AsPtr("1")},
+					{EventId: util.AsPtr("2")},
+				}},
+			},
+			expected: connectors.BatchResult[types.PutEventsRequestEntry]{
+				Succeeded: []types.PutEventsRequestEntry{entryA, entryB},
+			},
+		},
+		{
+			name: "partial failure",
+			result: PutEventsResult[string]{
+				Input: &eventbridge.PutEventsInput{Entries: []types.PutEventsRequestEntry{entryA, entryB, entryC}},
+				Output: &eventbridge.PutEventsOutput{
+					FailedEntryCount: 2,
+					Entries: []types.PutEventsResultEntry{
+						{EventId: util.AsPtr("1")},
+						{ErrorCode: util.AsPtr("ThrottlingException"), ErrorMessage: util.AsPtr("rate exceeded")},
+						{ErrorCode: util.AsPtr("MalformedDetail"), ErrorMessage: util.AsPtr("invalid json")},
+					},
+				},
+			},
+			expected: connectors.BatchResult[types.PutEventsRequestEntry]{
+				Succeeded: []types.PutEventsRequestEntry{entryA},
+				Failed: []connectors.BatchFailure[types.PutEventsRequestEntry]{
+					{Entry: entryB, Code: "ThrottlingException", Reason: "rate exceeded", Retriable: true},
+					{Entry: entryC, Code: "MalformedDetail", Reason: "invalid json"},
+				},
+			},
+		},
+		{
+			name: "missing result entries",
+			result: PutEventsResult[string]{
+				Input:  &eventbridge.PutEventsInput{Entries: []types.PutEventsRequestEntry{entryA}},
+				Output: &eventbridge.PutEventsOutput{},
+			},
+			expected: connectors.BatchResult[types.PutEventsRequestEntry]{
+				Failed: []connectors.BatchFailure[types.PutEventsRequestEntry]{
+					{Entry: entryA, Reason: "no result returned for entry"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.result.BatchResult())
+		})
+	}
+}
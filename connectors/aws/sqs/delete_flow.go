@@ -5,6 +5,8 @@ import (
 	"errors"
 
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/svenvdam/linea/connectors/aws/util"
 	"github.com/svenvdam/linea/core"
 	"github.com/svenvdam/linea/flows"
 )
@@ -31,12 +33,26 @@ type DeleteMessageResult[I any] struct {
 type DeleteFlowConfig struct {
 	// QueueURL is the URL of the SQS queue to delete from
 	QueueURL string
+
+	// Hooks are called around every DeleteMessage call, for example to record audit logs or metrics
+	Hooks util.Hooks
+}
+
+// Validate checks the config and returns an error wrapping util.ErrInvalidConfig
+// describing every invalid value.
+func (c DeleteFlowConfig) Validate() error {
+	var problems []error
+	if c.QueueURL == "" {
+		problems = append(problems, errors.New("QueueURL is required"))
+	}
+	return util.ConfigError(problems...)
 }
 
 // DeleteFlow creates a Flow that deletes messages from an SQS queue and passes the results downstream.
 // For each input item, it extracts the receipt handle using the provided function, deletes the message
 // from SQS, and emits a DeleteMessageResult containing the original input item and the SQS response.
 // If an error occurs during deletion, it will be propagated through the flow's error handling mechanism.
+// If the config is invalid, the validation error is propagated for every item without calling SQS.
 //
 // Type Parameters:
 //   - I: The type of input items that contain or can be used to extract receipt handles
@@ -54,6 +70,12 @@ func DeleteFlow[I any](
 	receiptHandleExtractor func(I) *string,
 	opts ...core.FlowOption,
 ) *core.Flow[I, DeleteMessageResult[I]] {
+	if err := config.Validate(); err != nil {
+		return flows.TryMap(func(context.Context, I) (DeleteMessageResult[I], error) {
+			return DeleteMessageResult[I]{}, err
+		}, opts...)
+	}
+
 	return flows.TryMap(func(ctx context.Context, elem I) (DeleteMessageResult[I], error) {
 		// Extract the receipt handle from the input element
 		receiptHandle := receiptHandleExtractor(elem)
@@ -70,7 +92,18 @@ func DeleteFlow[I any](
 		}
 
 		// Delete the message from SQS using the provided context
-		output, err := client.DeleteMessage(ctx, deleteInput)
+		output, err := util.Call(
+			ctx,
+			config.Hooks,
+			"DeleteMessage",
+			deleteInput,
+			func(ctx context.Context, input *sqs.DeleteMessageInput) (*sqs.DeleteMessageOutput, error) {
+				return client.DeleteMessage(ctx, input)
+			},
+			func(output *sqs.DeleteMessageOutput) middleware.Metadata {
+				return output.ResultMetadata
+			},
+		)
 		if err != nil {
 			return DeleteMessageResult[I]{}, err
 		}
@@ -102,6 +102,18 @@ func TestDeleteFlow(t *testing.T) {
 			expectedResults: nil,
 			expectedErr:     errors.New("receipt handle is nil"),
 		},
+		{
+			name:   "invalid config fails without deleting",
+			config: DeleteFlowConfig{},
+			input: TestMessage{
+				ID:            "msg123",
+				ReceiptHandle: "receipt123",
+				Content:       "test message",
+			},
+			setupMocks:      func(t *testing.T, mockClient *mocks.MockSQSDeleteClient) {},
+			expectedResults: []DeleteMessageResult[TestMessage]{},
+			expectedErr:     DeleteFlowConfig{}.Validate(),
+		},
 	}
 
 	for _, tt := range tests {
@@ -2,13 +2,20 @@
 //
 // It currently offers:
 // - Source for reading messages from SQS queues
+// - OrderedSource for reading messages with concurrent pollers, approximately ordered by SentTimestamp
 // - SendFlow for sending messages to SQS queues while preserving the original input
 // - DeleteFlow for deleting messages from SQS queues using receipt handles extracted from inputs
+// - UnwrapEnvelope for unwrapping SNS and EventBridge envelopes around message bodies
+// - MessageMetadata for carrying message attributes such as correlation ids in a core.Envelope
 //
 // Features:
 // - SQS message reading with configurable batching and polling
+// - At-least-once or at-most-once delivery of received messages
 // - SQS message sending with result handling and original input preservation
 // - SQS message deletion with flexible receipt handle extraction
+// - SNS signature verification for messages delivered through SNS subscriptions
+// - Optional hooks around every SQS call for audit logs and metrics
+// - QueueCheck preflight check verifying access to a queue before a stream starts
 //
 // This package requires an externally configured AWS client to be passed in, allowing the caller
 // to handle authentication and AWS configuration according to their own requirements.
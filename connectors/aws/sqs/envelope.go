@@ -0,0 +1,350 @@
+package sqs
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/flows"
+)
+
+// ErrInvalidSignature is the error wrapped when the signature of an SNS notification cannot be verified.
+var ErrInvalidSignature = errors.New("invalid SNS signature")
+
+// EnvelopeKind identifies the envelope an SQS message body was wrapped in.
+type EnvelopeKind int
+
+const (
+	// EnvelopeNone indicates the message body was not wrapped in a known envelope.
+	EnvelopeNone EnvelopeKind = iota
+
+	// EnvelopeSNS indicates the message body was an SNS notification.
+	EnvelopeSNS
+
+	// EnvelopeEventBridge indicates the message body was an EventBridge event.
+	EnvelopeEventBridge
+)
+
+// String returns the name of the envelope kind.
+func (k EnvelopeKind) String() string {
+	switch k {
+	case EnvelopeNone:
+		return "none"
+	case EnvelopeSNS:
+		return "sns"
+	case EnvelopeEventBridge:
+		return "eventbridge"
+	default:
+		return fmt.Sprintf("EnvelopeKind(%d)", int(k))
+	}
+}
+
+// SNSMessageAttribute is a message attribute of an SNS notification.
+type SNSMessageAttribute struct {
+	Type  string `json:"Type"`
+	Value string `json:"Value"`
+}
+
+// SNSNotification holds the metadata of an SNS notification delivered to SQS.
+type SNSNotification struct {
+	MessageId         string
+	TopicArn          string
+	Subject           string
+	Timestamp         time.Time
+	SignatureVersion  string
+	SigningCertURL    string
+	UnsubscribeURL    string
+	MessageAttributes map[string]SNSMessageAttribute
+}
+
+// EventBridgeEvent holds the metadata of an EventBridge event delivered to SQS.
+type EventBridgeEvent struct {
+	ID         string    `json:"id"`
+	DetailType string    `json:"detail-type"`
+	Source     string    `json:"source"`
+	Account    string    `json:"account"`
+	Time       time.Time `json:"time"`
+	Region     string    `json:"region"`
+	Resources  []string  `json:"resources"`
+}
+
+// UnwrappedMessage is an SQS message whose body was unwrapped from its envelope.
+type UnwrappedMessage struct {
+	// Message is the original message received from SQS
+	Message types.Message
+
+	// Body is the inner message: the SNS message, the EventBridge event detail,
+	// or the message body as-is if it was not wrapped
+	Body string
+
+	// Envelope is the kind of envelope the body was wrapped in
+	Envelope EnvelopeKind
+
+	// SNS holds the metadata of the SNS notification, if the body was wrapped by SNS
+	SNS *SNSNotification
+
+	// EventBridge holds the metadata of the EventBridge event, if the body was wrapped by EventBridge
+	EventBridge *EventBridgeEvent
+}
+
+// EnvelopeConfig holds configuration for the envelope unwrapping flow
+type EnvelopeConfig struct {
+	// VerifySignature enables verification of the signature of SNS notifications
+	// If not specified, signatures are not verified
+	VerifySignature bool
+
+	// FetchCertificate retrieves the certificate SNS signed a notification with, from its SigningCertURL
+	// If not specified, certificates are downloaded with http.DefaultClient and cached
+	FetchCertificate func(ctx context.Context, url string) (*x509.Certificate, error)
+}
+
+// UnwrapEnvelope creates a Flow that detects and unwraps the envelope around SQS message bodies.
+// Messages published to an SNS topic with an SQS subscription, unless raw message delivery is
+// enabled, and events routed by an EventBridge rule to an SQS queue arrive wrapped in a JSON
+// envelope. This flow extracts the inner message and its metadata, and passes messages that are
+// not wrapped through with EnvelopeNone.
+//
+// If config.VerifySignature is set, the signature of every SNS notification is verified against
+// the certificate at its SigningCertURL, which must be an SNS endpoint. Notifications that fail
+// verification are emitted as errors wrapping ErrInvalidSignature. EventBridge events are not signed.
+//
+// Parameters:
+//   - config: Configuration for the envelope unwrapping
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that produces UnwrappedMessage items
+func UnwrapEnvelope(
+	config EnvelopeConfig,
+	opts ...core.FlowOption,
+) *core.Flow[types.Message, UnwrappedMessage] {
+	if config.FetchCertificate == nil {
+		config.FetchCertificate = newCertificateCache(http.DefaultClient).fetch
+	}
+
+	return flows.TryMap(func(ctx context.Context, msg types.Message) (UnwrappedMessage, error) {
+		return unwrapEnvelope(ctx, msg, config)
+	}, opts...)
+}
+
+// envelopeProbe holds the fields used to detect the kind of envelope.
+type envelopeProbe struct {
+	Type       string          `json:"Type"`
+	MessageId  string          `json:"MessageId"`
+	TopicArn   string          `json:"TopicArn"`
+	DetailType string          `json:"detail-type"`
+	Source     string          `json:"source"`
+	Detail     json.RawMessage `json:"detail"`
+}
+
+// snsEnvelope is an SNS notification as delivered to SQS. The fields are kept as
+// strings, as the signature is computed over their original values.
+type snsEnvelope struct {
+	Type              string                         `json:"Type"`
+	MessageId         string                         `json:"MessageId"`
+	TopicArn          string                         `json:"TopicArn"`
+	Subject           *string                        `json:"Subject"`
+	Message           string                         `json:"Message"`
+	Timestamp         string                         `json:"Timestamp"`
+	SignatureVersion  string                         `json:"SignatureVersion"`
+	Signature         string                         `json:"Signature"`
+	SigningCertURL    string                         `json:"SigningCertURL"`
+	UnsubscribeURL    string                         `json:"UnsubscribeURL"`
+	MessageAttributes map[string]SNSMessageAttribute `json:"MessageAttributes"`
+}
+
+// unwrapEnvelope unwraps the body of a single message.
+func unwrapEnvelope(ctx context.Context, msg types.Message, config EnvelopeConfig) (UnwrappedMessage, error) {
+	res := UnwrappedMessage{Message: msg}
+	if msg.Body == nil {
+		return res, nil
+	}
+	res.Body = *msg.Body
+
+	var probe envelopeProbe
+	if err := json.Unmarshal([]byte(*msg.Body), &probe); err != nil {
+		// Not a JSON object, so not wrapped
+		return res, nil
+	}
+
+	switch {
+	case probe.Type == "Notification" && probe.TopicArn != "" && probe.MessageId != "":
+		var env snsEnvelope
+		if err := json.Unmarshal([]byte(*msg.Body), &env); err != nil {
+			return UnwrappedMessage{}, fmt.Errorf("parsing SNS notification: %w", err)
+		}
+		if config.VerifySignature {
+			if err := verifySignature(ctx, env, config.FetchCertificate); err != nil {
+				return UnwrappedMessage{}, err
+			}
+		}
+		timestamp, _ := time.Parse(time.RFC3339, env.Timestamp)
+		res.Body = env.Message
+		res.Envelope = EnvelopeSNS
+		res.SNS = &SNSNotification{
+			MessageId:         env.MessageId,
+			TopicArn:          env.TopicArn,
+			Timestamp:         timestamp,
+			SignatureVersion:  env.SignatureVersion,
+			SigningCertURL:    env.SigningCertURL,
+			UnsubscribeURL:    env.UnsubscribeURL,
+			MessageAttributes: env.MessageAttributes,
+		}
+		if env.Subject != nil {
+			res.SNS.Subject = *env.Subject
+		}
+	case probe.DetailType != "" && probe.Source != "" && probe.Detail != nil:
+		var event EventBridgeEvent
+		if err := json.Unmarshal([]byte(*msg.Body), &event); err != nil {
+			return UnwrappedMessage{}, fmt.Errorf("parsing EventBridge event: %w", err)
+		}
+		res.Body = string(probe.Detail)
+		res.Envelope = EnvelopeEventBridge
+		res.EventBridge = &event
+	}
+	return res, nil
+}
+
+// snsCertHost matches the hosts SNS serves its signing certificates from.
+var snsCertHost = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// verifySignature verifies the signature of an SNS notification.
+func verifySignature(
+	ctx context.Context,
+	env snsEnvelope,
+	fetch func(ctx context.Context, url string) (*x509.Certificate, error),
+) error {
+	var hash crypto.Hash
+	switch env.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return fmt.Errorf("%w: unsupported signature version %q", ErrInvalidSignature, env.SignatureVersion)
+	}
+
+	certURL, err := url.Parse(env.SigningCertURL)
+	if err != nil || certURL.Scheme != "https" || !snsCertHost.MatchString(certURL.Hostname()) {
+		return fmt.Errorf("%w: untrusted signing certificate URL %q", ErrInvalidSignature, env.SigningCertURL)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(env.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: decoding signature: %w", ErrInvalidSignature, err)
+	}
+
+	cert, err := fetch(ctx, env.SigningCertURL)
+	if err != nil {
+		return fmt.Errorf("fetching SNS signing certificate: %w", err)
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: signing certificate has no RSA public key", ErrInvalidSignature)
+	}
+
+	var digest []byte
+	data := []byte(stringToSign(env))
+	if hash == crypto.SHA1 {
+		sum := sha1.Sum(data)
+		digest = sum[:]
+	} else {
+		sum := sha256.Sum256(data)
+		digest = sum[:]
+	}
+	if err := rsa.VerifyPKCS1v15(key, hash, digest, signature); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	return nil
+}
+
+// stringToSign builds the string SNS signs for a notification.
+func stringToSign(env snsEnvelope) string {
+	var b strings.Builder
+	write := func(key, value string) {
+		b.WriteString(key)
+		b.WriteString("\n")
+		b.WriteString(value)
+		b.WriteString("\n")
+	}
+	write("Message", env.Message)
+	write("MessageId", env.MessageId)
+	if env.Subject != nil {
+		write("Subject", *env.Subject)
+	}
+	write("Timestamp", env.Timestamp)
+	write("TopicArn", env.TopicArn)
+	write("Type", env.Type)
+	return b.String()
+}
+
+// certificateCache downloads SNS signing certificates and caches them by URL.
+type certificateCache struct {
+	client *http.Client
+	mu     sync.Mutex
+	certs  map[string]*x509.Certificate
+}
+
+// newCertificateCache creates a certificateCache downloading certificates with client.
+func newCertificateCache(client *http.Client) *certificateCache {
+	return &certificateCache{
+		client: client,
+		certs:  make(map[string]*x509.Certificate),
+	}
+}
+
+// fetch returns the certificate at url, downloading it if it is not cached.
+func (c *certificateCache) fetch(ctx context.Context, url string) (*x509.Certificate, error) {
+	c.mu.Lock()
+	cert, ok := c.certs[url]
+	c.mu.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(body)
+	if block == nil {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.certs[url] = cert
+	c.mu.Unlock()
+	return cert, nil
+}
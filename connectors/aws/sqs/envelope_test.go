@@ -0,0 +1,192 @@
+package sqs
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/connectors/aws/util"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+const testCertURL = "https://sns.eu-west-1.amazonaws.com/SimpleNotificationService-test.pem"
+
+// testSigner creates a key and a self-signed certificate to sign SNS notifications with.
+func testSigner(t *testing.T) (*rsa.PrivateKey, *x509.Certificate) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return key, cert
+}
+
+// snsBody creates the body of an SNS notification, signed with key if it is not nil.
+func snsBody(t *testing.T, key *rsa.PrivateKey, message string, modify func(env *snsEnvelope)) *string {
+	subject := "greeting"
+	env := snsEnvelope{
+		Type:             "Notification",
+		MessageId:        "sns-1",
+		TopicArn:         "arn:aws:sns:eu-west-1:123456789012:topic",
+		Subject:          &subject,
+		Message:          message,
+		Timestamp:        "2024-01-02T03:04:05.678Z",
+		SignatureVersion: "2",
+		SigningCertURL:   testCertURL,
+		MessageAttributes: map[string]SNSMessageAttribute{
+			"tenant": {Type: "String", Value: "acme"},
+		},
+	}
+	if key != nil {
+		digest := sha256.Sum256([]byte(stringToSign(env)))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		env.Signature = base64.StdEncoding.EncodeToString(signature)
+	}
+	if modify != nil {
+		modify(&env)
+	}
+	body, err := json.Marshal(env)
+	require.NoError(t, err)
+	return util.AsPtr(string(body))
+}
+
+func TestUnwrapEnvelope(t *testing.T) {
+	key, cert := testSigner(t)
+	fetch := func(ctx context.Context, url string) (*x509.Certificate, error) {
+		if url != testCertURL {
+			return nil, errors.New("unknown certificate")
+		}
+		return cert, nil
+	}
+
+	eventBridgeBody := `{"version":"0","id":"eb-1","detail-type":"Order Placed","source":"shop",` +
+		`"account":"123456789012","time":"2024-01-02T03:04:05Z","region":"eu-west-1",` +
+		`"resources":["arn:aws:shop"],"detail":{"orderId":42}}`
+
+	tests := []struct {
+		name         string
+		config       EnvelopeConfig
+		body         *string
+		expectedBody string
+		expectedKind EnvelopeKind
+		expectedErr  error
+		check        func(t *testing.T, msg UnwrappedMessage)
+	}{
+		{
+			name:         "passes through raw messages",
+			body:         util.AsPtr("hello"),
+			expectedBody: "hello",
+			expectedKind: EnvelopeNone,
+		},
+		{
+			name:         "passes through other JSON messages",
+			body:         util.AsPtr(`{"Type":"order"}`),
+			expectedBody: `{"Type":"order"}`,
+			expectedKind: EnvelopeNone,
+		},
+		{
+			name:         "unwraps SNS notification",
+			body:         snsBody(t, nil, "hello", nil),
+			expectedBody: "hello",
+			expectedKind: EnvelopeSNS,
+			check: func(t *testing.T, msg UnwrappedMessage) {
+				assert.Equal(t, "sns-1", msg.SNS.MessageId)
+				assert.Equal(t, "greeting", msg.SNS.Subject)
+				assert.Equal(t, "acme", msg.SNS.MessageAttributes["tenant"].Value)
+				assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 678000000, time.UTC), msg.SNS.Timestamp)
+			},
+		},
+		{
+			name:         "unwraps EventBridge event",
+			body:         util.AsPtr(eventBridgeBody),
+			expectedBody: `{"orderId":42}`,
+			expectedKind: EnvelopeEventBridge,
+			check: func(t *testing.T, msg UnwrappedMessage) {
+				assert.Equal(t, "eb-1", msg.EventBridge.ID)
+				assert.Equal(t, "Order Placed", msg.EventBridge.DetailType)
+				assert.Equal(t, []string{"arn:aws:shop"}, msg.EventBridge.Resources)
+			},
+		},
+		{
+			name:         "verifies SNS signature",
+			config:       EnvelopeConfig{VerifySignature: true, FetchCertificate: fetch},
+			body:         snsBody(t, key, "hello", nil),
+			expectedBody: "hello",
+			expectedKind: EnvelopeSNS,
+		},
+		{
+			name:   "rejects tampered SNS notification",
+			config: EnvelopeConfig{VerifySignature: true, FetchCertificate: fetch},
+			body: snsBody(t, key, "hello", func(env *snsEnvelope) {
+				env.Message = "goodbye"
+			}),
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name:   "rejects untrusted certificate URL",
+			config: EnvelopeConfig{VerifySignature: true, FetchCertificate: fetch},
+			body: snsBody(t, key, "hello", func(env *snsEnvelope) {
+				env.SigningCertURL = "https://example.com/cert.pem"
+			}),
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name:         "ignores signature when verification is disabled",
+			body:         snsBody(t, nil, "hello", func(env *snsEnvelope) { env.Signature = "invalid" }),
+			expectedBody: "hello",
+			expectedKind: EnvelopeSNS,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := types.Message{MessageId: util.AsPtr("sqs-1"), Body: tt.body}
+
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice([]types.Message{msg}),
+				UnwrapEnvelope(tt.config),
+				sinks.Slice[UnwrappedMessage](),
+			)
+
+			result := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, result.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, result.Err)
+			require.Len(t, result.Value, 1)
+			unwrapped := result.Value[0]
+			assert.Equal(t, msg, unwrapped.Message)
+			assert.Equal(t, tt.expectedBody, unwrapped.Body)
+			assert.Equal(t, tt.expectedKind, unwrapped.Envelope)
+			if tt.check != nil {
+				tt.check(t, unwrapped)
+			}
+		})
+	}
+}
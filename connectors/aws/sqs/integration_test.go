@@ -109,6 +109,95 @@ func TestSqsStream(t *testing.T) {
 	)
 }
 
+// TestSqsStreamShutdown verifies that a coordinated shutdown while messages are still being
+// processed leaves no message undeleted or double-processed: every message is either
+// forwarded and deleted exactly once, or left untouched in the source queue.
+func TestSqsStreamShutdown(t *testing.T) {
+	ctx := context.Background()
+
+	awsCfg, container, err := test.SetupLocalstack(ctx)
+	require.NoError(t, err)
+	defer func() {
+		_ = container.Terminate(ctx)
+	}()
+
+	sqsClient := sqs.NewFromConfig(*awsCfg)
+
+	// Use a short visibility timeout so messages aborted during shutdown become visible again quickly
+	sourceQueueURL, err := setupQueue(ctx, sqsClient, "shutdown-source-queue", 5)
+	require.NoError(t, err)
+	destQueueURL, err := setupQueue(ctx, sqsClient, "shutdown-dest-queue", 30)
+	require.NoError(t, err)
+
+	testMessages := make([]string, 50)
+	for i := range testMessages {
+		testMessages[i] = fmt.Sprintf("message %d", i)
+	}
+	err = sendTestMessages(ctx, sqsClient, sourceQueueURL, testMessages)
+	require.NoError(t, err)
+
+	// Slow down processing so the shutdown happens while messages are in flight
+	sqsStream := compose.SourceThroughFlowToSink3(
+		Source(sqsClient, SourceConfig{
+			QueueURL:            sourceQueueURL,
+			MaxNumberOfMessages: 10,
+			WaitTimeSeconds:     5,
+			VisibilityTimeout:   5,
+			PollInterval:        100 * time.Millisecond,
+		}),
+		flows.ForEach(func(_ context.Context, _ types.Message) {
+			time.Sleep(50 * time.Millisecond)
+		}),
+		SendFlow(sqsClient, SendFlowConfig{QueueURL: destQueueURL}, func(msg types.Message) *sqs.SendMessageInput {
+			return &sqs.SendMessageInput{MessageBody: msg.Body}
+		}),
+		DeleteFlow(sqsClient, DeleteFlowConfig{QueueURL: sourceQueueURL}, func(result SendMessageResult[types.Message]) *string {
+			return result.Original.ReceiptHandle
+		}),
+		sinks.Noop[DeleteMessageResult[SendMessageResult[types.Message]]](),
+	)
+
+	closed := false
+	coordinator := core.NewShutdownCoordinator()
+	coordinator.OnClose("sqs client", func(context.Context) error {
+		closed = true
+		return nil
+	})
+
+	resultChan := sqsStream.Run(ctx)
+	time.Sleep(time.Second)
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	require.NoError(t, coordinator.Shutdown(shutdownCtx, sqsStream))
+	assert.True(t, closed, "close hook should have been called")
+
+	result := <-resultChan
+	assert.NoError(t, result.Err)
+
+	destMessages, err := receiveAllMessages(ctx, sqsClient, destQueueURL)
+	require.NoError(t, err)
+
+	// Wait for messages that were received but not processed to become visible again
+	time.Sleep(6 * time.Second)
+	remainingMessages, err := receiveAllMessages(ctx, sqsClient, sourceQueueURL)
+	require.NoError(t, err)
+
+	seen := make(map[string]int)
+	for _, msg := range destMessages {
+		seen[*msg.Body]++
+	}
+	for _, msg := range remainingMessages {
+		seen[*msg.Body]++
+	}
+
+	assert.NotEmpty(t, destMessages, "some messages should have been processed before shutdown")
+	assert.Len(t, seen, len(testMessages), "every message should be either processed or left in the source queue")
+	for body, count := range seen {
+		assert.Equal(t, 1, count, "message %q should be accounted for exactly once", body)
+	}
+}
+
 // setupQueue creates an SQS queue and returns its URL
 func setupQueue(
 	ctx context.Context,
@@ -0,0 +1,43 @@
+package sqs
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/svenvdam/linea/core"
+)
+
+// TraceParentAttribute is the message attribute MessageMetadata reads the W3C traceparent from.
+const TraceParentAttribute = "traceparent"
+
+// MessageMetadata returns the metadata of a message, to be carried through a pipeline in a
+// core.Envelope, for example with flows.Envelop. The values are the string and number message
+// attributes of the message, the trace parent is read from the TraceParentAttribute attribute,
+// and the ingest time is the SentTimestamp of the message if it was requested. Message attributes
+// are only returned for the names in SourceConfig.MessageAttributeNames.
+//
+// Parameters:
+//   - ctx: Context of the stage calling the function
+//   - msg: The message received from SQS
+//
+// Returns the metadata of the message
+func MessageMetadata(ctx context.Context, msg types.Message) core.Metadata {
+	var meta core.Metadata
+	for name, attr := range msg.MessageAttributes {
+		if attr.StringValue == nil {
+			continue
+		}
+		if name == TraceParentAttribute {
+			meta.TraceParent = *attr.StringValue
+			continue
+		}
+		if meta.Values == nil {
+			meta.Values = make(map[string]string, len(msg.MessageAttributes))
+		}
+		meta.Values[name] = *attr.StringValue
+	}
+	if sent, ok := SentTimestamp(msg); ok {
+		meta.IngestTime = sent
+	}
+	return meta
+}
@@ -0,0 +1,51 @@
+package sqs
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/connectors/aws/util"
+	"github.com/svenvdam/linea/core"
+)
+
+func TestMessageMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      types.Message
+		expected core.Metadata
+	}{
+		{
+			name: "message without attributes",
+			msg:  types.Message{MessageId: util.AsPtr("msg1")},
+		},
+		{
+			name: "reads message attributes and sent timestamp",
+			msg: types.Message{
+				Attributes: map[string]string{
+					string(types.MessageSystemAttributeNameSentTimestamp): strconv.FormatInt(1700000000000, 10),
+				},
+				MessageAttributes: map[string]types.MessageAttributeValue{
+					"correlationId": {DataType: util.AsPtr("String"), StringValue: util.AsPtr("abc")},
+					"retries":       {DataType: util.AsPtr("Number"), StringValue: util.AsPtr("3")},
+					"payload":       {DataType: util.AsPtr("Binary"), BinaryValue: []byte{1}},
+					"traceparent":   {DataType: util.AsPtr("String"), StringValue: util.AsPtr("00-trace-span-01")},
+				},
+			},
+			expected: core.Metadata{
+				Values:      map[string]string{"correlationId": "abc", "retries": "3"},
+				TraceParent: "00-trace-span-01",
+				IngestTime:  time.UnixMilli(1700000000000),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MessageMetadata(context.Background(), tt.msg))
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package sqs
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/connectors/aws/util"
+	"github.com/svenvdam/linea/core"
+)
+
+// OrderedSource creates a Source that reads messages from an SQS queue with several concurrent
+// pollers, and emits them approximately ordered by their SentTimestamp. Standard queues do not
+// preserve order, and concurrent pollers receive messages in an arbitrary interleaving; this
+// source restores near-chronological order without requiring a FIFO queue.
+//
+// Messages are held for the tolerance window after they are received, and released in order
+// of SentTimestamp, see OrderBySentTimestamp. Messages sent further apart than the window are
+// emitted in order, while a larger window tolerates more delay between pollers at the cost of
+// latency. The SentTimestamp attribute is requested in addition to config.AttributeNames.
+//
+// Parameters:
+//   - client: AWS SQS client or compatible interface
+//   - config: Configuration for each of the pollers
+//   - pollers: Number of concurrent pollers, at least 1
+//   - window: Time messages are held to be ordered with messages received after them
+//   - opts: Optional configuration options applied to each poller
+//
+// Returns a Source that produces SQS messages approximately ordered by SentTimestamp
+func OrderedSource(
+	client SQSReceiveClient,
+	config SourceConfig,
+	pollers int,
+	window time.Duration,
+	opts ...core.SourceOption,
+) *core.Source[types.Message] {
+	var problems []error
+	if pollers < 1 {
+		problems = append(problems, fmt.Errorf("pollers must be at least 1, got %d", pollers))
+	}
+	if window < 0 {
+		problems = append(problems, fmt.Errorf("window must not be negative, got %s", window))
+	}
+	if err := errors.Join(config.Validate(), util.ConfigError(problems...)); err != nil {
+		return errSource[types.Message](err, opts...)
+	}
+
+	if !slices.Contains(config.AttributeNames, types.QueueAttributeNameAll) &&
+		!slices.Contains(config.AttributeNames, types.QueueAttributeName(types.MessageSystemAttributeNameSentTimestamp)) {
+		config.AttributeNames = append(slices.Clone(config.AttributeNames),
+			types.QueueAttributeName(types.MessageSystemAttributeNameSentTimestamp))
+	}
+
+	sources := make([]*core.Source[types.Message], pollers)
+	for i := range sources {
+		sources[i] = Source(client, config, opts...)
+	}
+
+	return compose.SourceThroughFlow(
+		core.MergeSources(sources...),
+		OrderBySentTimestamp(window),
+	)
+}
+
+// SentTimestamp returns the time a message was sent to the queue, as reported by its
+// SentTimestamp attribute. It returns false if the attribute was not requested or is invalid.
+//
+// Parameters:
+//   - msg: The message received from SQS
+//
+// Returns the time the message was sent and whether it is known
+func SentTimestamp(msg types.Message) (time.Time, bool) {
+	value, ok := msg.Attributes[string(types.MessageSystemAttributeNameSentTimestamp)]
+	if !ok {
+		return time.Time{}, false
+	}
+	millis, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(millis), true
+}
+
+// OrderBySentTimestamp creates a Flow that reorders messages by their SentTimestamp within a
+// tolerance window. Each message is held until the window has passed since it was received, and
+// pending messages are released in order of SentTimestamp. Messages without a SentTimestamp are
+// ordered by the time they were received. When the stream is drained, all pending messages are
+// emitted in order.
+//
+// Parameters:
+//   - window: Time messages are held to be ordered with messages received after them
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that emits messages approximately ordered by SentTimestamp
+func OrderBySentTimestamp(
+	window time.Duration,
+	opts ...core.FlowOption,
+) *core.Flow[types.Message, types.Message] {
+	var mu sync.Mutex
+	var pending pendingMessages
+	var wake, stop, stopped chan struct{}
+
+	// release emits the pending messages that are due, or all of them, returning the time the
+	// next message is due
+	release := func(ctx context.Context, out chan<- core.Item[types.Message], all bool) (time.Time, bool) {
+		for {
+			mu.Lock()
+			if len(pending) == 0 {
+				mu.Unlock()
+				return time.Time{}, false
+			}
+			next := pending[0]
+			if !all && next.due.After(time.Now()) {
+				mu.Unlock()
+				return next.due, true
+			}
+			heap.Pop(&pending)
+			mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return time.Time{}, false
+			case out <- core.Item[types.Message]{Value: next.msg}:
+			}
+		}
+	}
+
+	run := func(ctx context.Context, out chan<- core.Item[types.Message], wake, stop <-chan struct{}, stopped chan<- struct{}) {
+		defer close(stopped)
+		timer := time.NewTimer(window)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-wake:
+			case <-timer.C:
+			}
+			if due, ok := release(ctx, out, false); ok {
+				timer.Reset(time.Until(due))
+			}
+		}
+	}
+
+	return core.NewFlow(
+		func(ctx context.Context, elem types.Message, out chan<- core.Item[types.Message]) core.StreamAction {
+			if wake == nil {
+				wake = make(chan struct{}, 1)
+				stop = make(chan struct{})
+				stopped = make(chan struct{})
+				go run(ctx, out, wake, stop, stopped)
+			}
+
+			now := time.Now()
+			sent, ok := SentTimestamp(elem)
+			if !ok {
+				sent = now
+			}
+			mu.Lock()
+			heap.Push(&pending, pendingMessage{msg: elem, sent: sent, due: now.Add(window)})
+			mu.Unlock()
+
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[types.Message]) {
+			if wake != nil {
+				close(stop)
+				<-stopped
+				wake = nil
+			}
+			release(ctx, out, true)
+		},
+		opts...)
+}
+
+// pendingMessage is a message held by OrderBySentTimestamp.
+type pendingMessage struct {
+	msg  types.Message
+	sent time.Time
+	due  time.Time
+}
+
+// pendingMessages is a min-heap of messages ordered by the time they were sent.
+type pendingMessages []pendingMessage
+
+func (p pendingMessages) Len() int           { return len(p) }
+func (p pendingMessages) Less(i, j int) bool { return p[i].sent.Before(p[j].sent) }
+func (p pendingMessages) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+func (p *pendingMessages) Push(x any) {
+	*p = append(*p, x.(pendingMessage))
+}
+
+func (p *pendingMessages) Pop() any {
+	old := *p
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = pendingMessage{}
+	*p = old[:n-1]
+	return item
+}
@@ -0,0 +1,175 @@
+package sqs
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/connectors/aws/sqs/mocks"
+	"github.com/svenvdam/linea/connectors/aws/util"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+	"github.com/svenvdam/linea/test"
+)
+
+// sentMsg creates a test message with the given id and SentTimestamp in milliseconds.
+func sentMsg(id string, sent int64) types.Message {
+	return types.Message{
+		MessageId: util.AsPtr(id),
+		Attributes: map[string]string{
+			string(types.MessageSystemAttributeNameSentTimestamp): strconv.FormatInt(sent, 10),
+		},
+	}
+}
+
+// messageIds returns the ids of the given messages.
+func messageIds(msgs []types.Message) []string {
+	ids := make([]string, len(msgs))
+	for i, msg := range msgs {
+		ids[i] = *msg.MessageId
+	}
+	return ids
+}
+
+func TestOrderBySentTimestamp(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []types.Message
+		window   time.Duration
+		expected []string
+	}{
+		{
+			name:     "orders messages received within window",
+			input:    []types.Message{sentMsg("b", 2000), sentMsg("c", 3000), sentMsg("a", 1000)},
+			window:   time.Second,
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "keeps order of ordered messages",
+			input:    []types.Message{sentMsg("a", 1000), sentMsg("b", 2000)},
+			window:   10 * time.Millisecond,
+			expected: []string{"a", "b"},
+		},
+		{
+			name: "orders messages without timestamp by arrival",
+			input: []types.Message{
+				{MessageId: util.AsPtr("a")},
+				{MessageId: util.AsPtr("b")},
+			},
+			window:   10 * time.Millisecond,
+			expected: []string{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(tt.input),
+				OrderBySentTimestamp(tt.window),
+				sinks.Slice[types.Message](),
+			)
+
+			result := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			assert.NoError(t, result.Err)
+			assert.Equal(t, tt.expected, messageIds(result.Value))
+		})
+	}
+}
+
+func TestSentTimestamp(t *testing.T) {
+	tests := []struct {
+		name       string
+		msg        types.Message
+		expected   time.Time
+		expectedOk bool
+	}{
+		{
+			name:       "parses timestamp",
+			msg:        sentMsg("a", 1700000000123),
+			expected:   time.UnixMilli(1700000000123),
+			expectedOk: true,
+		},
+		{
+			name: "missing attribute",
+			msg:  types.Message{},
+		},
+		{
+			name: "invalid attribute",
+			msg: types.Message{Attributes: map[string]string{
+				string(types.MessageSystemAttributeNameSentTimestamp): "yesterday",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent, ok := SentTimestamp(tt.msg)
+			assert.Equal(t, tt.expectedOk, ok)
+			assert.Equal(t, tt.expected, sent)
+		})
+	}
+}
+
+func TestOrderedSource(t *testing.T) {
+	mockClient := mocks.NewMockSQSReceiveClient(t)
+	requestsSentTimestamp := mock.MatchedBy(func(input *sqs.ReceiveMessageInput) bool {
+		return slices.Contains(input.AttributeNames,
+			types.QueueAttributeName(types.MessageSystemAttributeNameSentTimestamp))
+	})
+
+	// Each poller receives one batch, interleaving the messages sent to the queue
+	mockClient.EXPECT().
+		ReceiveMessage(mock.Anything, requestsSentTimestamp, mock.Anything).
+		Return(&sqs.ReceiveMessageOutput{Messages: []types.Message{sentMsg("b", 2000), sentMsg("d", 4000)}}, nil).
+		Once()
+	mockClient.EXPECT().
+		ReceiveMessage(mock.Anything, requestsSentTimestamp, mock.Anything).
+		Return(&sqs.ReceiveMessageOutput{Messages: []types.Message{sentMsg("a", 1000), sentMsg("c", 3000)}}, nil).
+		Once()
+	mockClient.EXPECT().
+		ReceiveMessage(mock.Anything, requestsSentTimestamp, mock.Anything).
+		Return(&sqs.ReceiveMessageOutput{}, nil).
+		Maybe()
+
+	stream := compose.SourceThroughFlowToSink(
+		OrderedSource(mockClient, SourceConfig{
+			QueueURL:     "https://sqs.example.com/queue",
+			PollInterval: 50 * time.Millisecond,
+		}, 2, 100*time.Millisecond),
+		test.CheckItems(t, func(t *testing.T, elems []types.Message) {
+			assert.Equal(t, []string{"a", "b", "c", "d"}, messageIds(elems))
+		}),
+		sinks.Noop[types.Message](),
+	)
+
+	resultChan := stream.Run(context.Background())
+	time.Sleep(300 * time.Millisecond)
+	stream.Drain()
+	result := <-resultChan
+
+	assert.NoError(t, result.Err)
+}
+
+func TestOrderedSourceInvalidConfig(t *testing.T) {
+	mockClient := mocks.NewMockSQSReceiveClient(t)
+
+	stream := compose.SourceToSink(
+		OrderedSource(mockClient, SourceConfig{QueueURL: "https://sqs.example.com/queue"}, 0, -time.Second),
+		sinks.Noop[types.Message](),
+	)
+
+	result := <-stream.Run(context.Background())
+
+	assert.ErrorIs(t, result.Err, util.ErrInvalidConfig)
+	assert.ErrorContains(t, result.Err, "pollers must be at least 1, got 0")
+	assert.ErrorContains(t, result.Err, "window must not be negative, got -1s")
+}
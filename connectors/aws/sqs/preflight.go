@@ -0,0 +1,36 @@
+package sqs
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// SQSQueueAttributesClient defines the interface for SQS operations needed by QueueCheck
+type SQSQueueAttributesClient interface {
+	GetQueueAttributes(
+		ctx context.Context,
+		params *sqs.GetQueueAttributesInput,
+		optFns ...func(*sqs.Options),
+	) (*sqs.GetQueueAttributesOutput, error)
+}
+
+// QueueCheck creates a preflight check verifying that the queue exists and can be accessed
+// with the client's credentials, by reading the queue's ARN. Register it with
+// core.WithSourcePreflight or core.WithFlowPreflight to fail fast before a stream starts.
+//
+// Parameters:
+//   - client: AWS SQS client or compatible interface
+//   - queueURL: URL of the queue to check
+//
+// Returns a function that returns an error if the queue cannot be accessed
+func QueueCheck(client SQSQueueAttributesClient, queueURL string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+			QueueUrl:       &queueURL,
+			AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameQueueArn},
+		})
+		return err
+	}
+}
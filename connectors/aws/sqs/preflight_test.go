@@ -0,0 +1,54 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/svenvdam/linea/connectors/aws/sqs/mocks"
+	"github.com/svenvdam/linea/connectors/aws/util"
+)
+
+func TestQueueCheck(t *testing.T) {
+	testErr := errors.New("queue does not exist")
+
+	tests := []struct {
+		name        string
+		err         error
+		expectedErr error
+	}{
+		{
+			name: "passes when queue can be accessed",
+		},
+		{
+			name:        "fails when queue cannot be accessed",
+			err:         testErr,
+			expectedErr: testErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewMockSQSQueueAttributesClient(t)
+			expectedInput := &sqs.GetQueueAttributesInput{
+				QueueUrl:       util.AsPtr("https://sqs.example.com/queue"),
+				AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameQueueArn},
+			}
+			mockClient.EXPECT().
+				GetQueueAttributes(mock.Anything, expectedInput, mock.Anything).
+				Return(&sqs.GetQueueAttributesOutput{}, tt.err).Once()
+
+			err := QueueCheck(mockClient, "https://sqs.example.com/queue")(context.Background())
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
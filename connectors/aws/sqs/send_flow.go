@@ -2,8 +2,11 @@ package sqs
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/svenvdam/linea/connectors/aws/util"
 	"github.com/svenvdam/linea/core"
 	"github.com/svenvdam/linea/flows"
 )
@@ -35,12 +38,29 @@ type SendFlowConfig struct {
 	// Valid values: 0 to 900 (15 minutes)
 	// If not specified, the default value for the queue applies
 	DelaySeconds int32
+
+	// Hooks are called around every SendMessage call, for example to record audit logs or metrics
+	Hooks util.Hooks
+}
+
+const maxDelaySeconds = 900
+
+// Validate checks the config and returns an error wrapping util.ErrInvalidConfig
+// describing every invalid value.
+func (c SendFlowConfig) Validate() error {
+	var problems []error
+	if c.DelaySeconds < 0 || c.DelaySeconds > maxDelaySeconds {
+		problems = append(problems, fmt.Errorf(
+			"DelaySeconds must be between 0 and %d, got %d", maxDelaySeconds, c.DelaySeconds))
+	}
+	return util.ConfigError(problems...)
 }
 
 // SendFlow creates a Flow that sends messages to an SQS queue and passes the results downstream.
 // For each input message, it sends it to SQS and emits a SendMessageResult containing the
 // original input item and the SQS response.
 // If an error occurs during sending, it will be propagated through the flow's error handling mechanism.
+// If the config is invalid, the validation error is propagated for every item without calling SQS.
 //
 // Type Parameters:
 //   - I: The type of input items that will be converted to SQS messages
@@ -58,6 +78,12 @@ func SendFlow[I any](
 	messageBuilder func(I) *sqs.SendMessageInput,
 	opts ...core.FlowOption,
 ) *core.Flow[I, SendMessageResult[I]] {
+	if err := config.Validate(); err != nil {
+		return flows.TryMap(func(context.Context, I) (SendMessageResult[I], error) {
+			return SendMessageResult[I]{}, err
+		}, opts...)
+	}
+
 	return flows.TryMap(func(ctx context.Context, elem I) (SendMessageResult[I], error) {
 		// Build the message input from the input element
 		msgInput := messageBuilder(elem)
@@ -73,7 +99,18 @@ func SendFlow[I any](
 		}
 
 		// Send the message to SQS using the provided context
-		output, err := client.SendMessage(ctx, msgInput)
+		output, err := util.Call(
+			ctx,
+			config.Hooks,
+			"SendMessage",
+			msgInput,
+			func(ctx context.Context, input *sqs.SendMessageInput) (*sqs.SendMessageOutput, error) {
+				return client.SendMessage(ctx, input)
+			},
+			func(output *sqs.SendMessageOutput) middleware.Metadata {
+				return output.ResultMetadata
+			},
+		)
 		if err != nil {
 			return SendMessageResult[I]{}, err
 		}
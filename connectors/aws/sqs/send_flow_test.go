@@ -73,6 +73,17 @@ func TestSendFlow(t *testing.T) {
 			expectedResults: nil,
 			expectedErr:     errors.New("sqs error"),
 		},
+		{
+			name: "invalid config fails without sending",
+			config: SendFlowConfig{
+				QueueURL:     "https://sqs.example.com/queue",
+				DelaySeconds: 901,
+			},
+			input:           "test message",
+			setupMocks:      func(t *testing.T, mockClient *mocks.MockSQSSendClient) {},
+			expectedResults: []SendMessageResult[string]{},
+			expectedErr:     SendFlowConfig{DelaySeconds: 901}.Validate(),
+		},
 	}
 
 	for _, tt := range tests {
@@ -109,3 +120,41 @@ func TestSendFlow(t *testing.T) {
 		})
 	}
 }
+
+func TestSendFlowHooks(t *testing.T) {
+	mockClient := mocks.NewMockSQSSendClient(t)
+	output := &sqs.SendMessageOutput{MessageId: util.AsPtr("msg123")}
+	mockClient.EXPECT().
+		SendMessage(mock.Anything, mock.Anything, mock.Anything).
+		Return(output, nil).Once()
+
+	var events []util.CallEvent
+	config := SendFlowConfig{
+		QueueURL: "https://sqs.example.com/queue",
+		Hooks: util.Hooks{
+			OnRequest: func(ctx context.Context, event util.CallEvent) {
+				events = append(events, event)
+			},
+			OnResponse: func(ctx context.Context, event util.CallEvent) {
+				events = append(events, event)
+			},
+		},
+	}
+
+	stream := compose.SourceThroughFlowToSink(
+		sources.Slice([]string{"test message"}),
+		SendFlow(mockClient, config, func(msg string) *sqs.SendMessageInput {
+			return &sqs.SendMessageInput{MessageBody: util.AsPtr(msg)}
+		}),
+		sinks.Noop[SendMessageResult[string]](),
+	)
+
+	result := <-stream.Run(context.Background())
+
+	assert.NoError(t, result.Err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "SendMessage", events[0].Operation)
+	assert.Nil(t, events[0].Output)
+	assert.Equal(t, "SendMessage", events[1].Operation)
+	assert.Equal(t, output, events[1].Output)
+}
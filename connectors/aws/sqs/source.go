@@ -2,11 +2,18 @@ package sqs
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go/middleware"
 	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/connectors"
+	"github.com/svenvdam/linea/connectors/aws/util"
 	"github.com/svenvdam/linea/core"
 	"github.com/svenvdam/linea/flows"
 	"github.com/svenvdam/linea/sources"
@@ -21,6 +28,16 @@ type SQSReceiveClient interface {
 	) (*sqs.ReceiveMessageOutput, error)
 }
 
+// SQSDeleteBatchClient defines the interface for SQS operations needed by the Source to
+// acknowledge messages when using the AtMostOnce delivery mode
+type SQSDeleteBatchClient interface {
+	DeleteMessageBatch(
+		ctx context.Context,
+		params *sqs.DeleteMessageBatchInput,
+		optFns ...func(*sqs.Options),
+	) (*sqs.DeleteMessageBatchOutput, error)
+}
+
 // SourceConfig holds configuration for the SQS source
 type SourceConfig struct {
 	// QueueURL is the URL of the SQS queue to read from
@@ -30,21 +47,110 @@ type SourceConfig struct {
 	// If not specified, defaults to 10
 	MaxNumberOfMessages int32
 
-	// WaitTimeSeconds is the duration (in seconds) to wait for messages (0-20)
+	// WaitTimeSeconds is the duration (in seconds) to wait for messages (1-20)
 	// If not specified, defaults to 20 (long polling)
+	// Set it to ShortPolling to return immediately, as 0 selects the default
 	WaitTimeSeconds int32
 
-	// VisibilityTimeout is the duration (in seconds) that messages are hidden from subsequent retrieve requests
-	// If not specified, defaults to 30 seconds
+	// VisibilityTimeout is the duration (in seconds) that messages are hidden from subsequent retrieve requests (0-43200)
+	// If not specified, the visibility timeout of the queue applies, which is 30 seconds unless configured otherwise
 	VisibilityTimeout int32
 
 	// PollInterval is the duration to wait between polling attempts when no messages are received
 	// If not specified, defaults to 1 second
 	PollInterval time.Duration
+
+	// AttributeNames are the message system attributes returned with each message, such as SentTimestamp
+	// If not specified, no attributes are returned
+	AttributeNames []types.QueueAttributeName
+
+	// MessageAttributeNames are the names of the message attributes returned with each message, or "All"
+	// If not specified, no message attributes are returned
+	MessageAttributeNames []string
+
+	// DeliveryMode determines when received messages are deleted from the queue
+	// If not specified, defaults to connectors.AtLeastOnce, leaving deletion to the pipeline
+	// With connectors.AtMostOnce, messages are deleted before they are emitted, which requires
+	// the client to implement SQSDeleteBatchClient
+	DeliveryMode connectors.DeliveryMode
+
+	// Hooks are called around every ReceiveMessage and DeleteMessageBatch call, for example to record
+	// audit logs or metrics
+	Hooks util.Hooks
+}
+
+// ShortPolling is the WaitTimeSeconds that receives messages with short polling, returning
+// immediately even if no messages are available. It is needed because a WaitTimeSeconds of 0
+// is unspecified and defaults to long polling.
+const ShortPolling int32 = -1
+
+const (
+	defaultMaxNumberOfMessages = 10
+	defaultWaitTimeSeconds     = 20
+	defaultPollInterval        = time.Second
+
+	maxNumberOfMessages  = 10
+	maxWaitTimeSeconds   = 20
+	maxVisibilityTimeout = 43200
+)
+
+// withDefaults returns a copy of the config with defaults applied to unspecified values.
+func (c SourceConfig) withDefaults() SourceConfig {
+	if c.MaxNumberOfMessages == 0 {
+		c.MaxNumberOfMessages = defaultMaxNumberOfMessages
+	}
+	switch c.WaitTimeSeconds {
+	case 0:
+		c.WaitTimeSeconds = defaultWaitTimeSeconds
+	case ShortPolling:
+		c.WaitTimeSeconds = 0
+	}
+	if c.PollInterval == 0 {
+		c.PollInterval = defaultPollInterval
+	}
+	return c
+}
+
+// Validate checks the config after applying defaults for unspecified values.
+// It returns an error wrapping util.ErrInvalidConfig describing every invalid value.
+func (c SourceConfig) Validate() error {
+	c = c.withDefaults()
+
+	var problems []error
+	if c.QueueURL == "" {
+		problems = append(problems, errors.New("QueueURL is required"))
+	}
+	if c.MaxNumberOfMessages < 1 || c.MaxNumberOfMessages > maxNumberOfMessages {
+		problems = append(problems, fmt.Errorf(
+			"MaxNumberOfMessages must be between 1 and %d, got %d", maxNumberOfMessages, c.MaxNumberOfMessages))
+	}
+	if c.WaitTimeSeconds < 0 || c.WaitTimeSeconds > maxWaitTimeSeconds {
+		problems = append(problems, fmt.Errorf(
+			"WaitTimeSeconds must be between 1 and %d or ShortPolling, got %d", maxWaitTimeSeconds, c.WaitTimeSeconds))
+	}
+	if c.VisibilityTimeout < 0 || c.VisibilityTimeout > maxVisibilityTimeout {
+		problems = append(problems, fmt.Errorf(
+			"VisibilityTimeout must be between 0 and %d, got %d", maxVisibilityTimeout, c.VisibilityTimeout))
+	}
+	if c.PollInterval < 0 {
+		problems = append(problems, fmt.Errorf("PollInterval must not be negative, got %s", c.PollInterval))
+	}
+	if c.DeliveryMode != connectors.AtLeastOnce && c.DeliveryMode != connectors.AtMostOnce {
+		problems = append(problems, fmt.Errorf("DeliveryMode %s is not supported", c.DeliveryMode))
+	}
+	return util.ConfigError(problems...)
 }
 
 // Source creates a Source that reads messages from an SQS queue.
 // It continuously polls the queue and emits messages until the context is canceled or an error occurs.
+// Defaults are applied to unspecified config values. If the config is invalid, the source emits
+// the validation error and completes without calling SQS.
+//
+// By default, messages are delivered at least once: they remain in the queue until the pipeline
+// deletes them, for example with DeleteFlow once they have been processed, and are received
+// again after their visibility timeout otherwise. With the connectors.AtMostOnce delivery mode,
+// each batch of messages is deleted before it is emitted, and messages that could not be deleted
+// are not emitted, so that no message is processed more than once.
 //
 // Parameters:
 //   - client: AWS SQS client or compatible interface
@@ -57,6 +163,19 @@ func Source(
 	config SourceConfig,
 	opts ...core.SourceOption,
 ) *core.Source[types.Message] {
+	if err := config.Validate(); err != nil {
+		return errSource[types.Message](err, opts...)
+	}
+	config = config.withDefaults()
+
+	var deleter SQSDeleteBatchClient
+	if config.DeliveryMode == connectors.AtMostOnce {
+		var ok bool
+		if deleter, ok = client.(SQSDeleteBatchClient); !ok {
+			return errSource[types.Message](util.ConfigError(
+				errors.New("DeliveryMode at_most_once requires a client implementing SQSDeleteBatchClient")), opts...)
+		}
+	}
 
 	// Create a polling function that returns:
 	// - a pointer to a slice of messages from the SQS queue (or nil if no messages)
@@ -64,12 +183,25 @@ func Source(
 	// - an error if one occurred during polling
 	pollFunc := func(ctx context.Context) (*[]types.Message, bool, error) {
 		// Poll SQS for messages
-		resp, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
-			QueueUrl:            &config.QueueURL,
-			MaxNumberOfMessages: config.MaxNumberOfMessages,
-			WaitTimeSeconds:     config.WaitTimeSeconds,
-			VisibilityTimeout:   config.VisibilityTimeout,
-		})
+		resp, err := util.Call(
+			ctx,
+			config.Hooks,
+			"ReceiveMessage",
+			&sqs.ReceiveMessageInput{
+				QueueUrl:              &config.QueueURL,
+				MaxNumberOfMessages:   config.MaxNumberOfMessages,
+				WaitTimeSeconds:       config.WaitTimeSeconds,
+				VisibilityTimeout:     config.VisibilityTimeout,
+				AttributeNames:        config.AttributeNames,
+				MessageAttributeNames: config.MessageAttributeNames,
+			},
+			func(ctx context.Context, input *sqs.ReceiveMessageInput) (*sqs.ReceiveMessageOutput, error) {
+				return client.ReceiveMessage(ctx, input)
+			},
+			func(output *sqs.ReceiveMessageOutput) middleware.Metadata {
+				return output.ResultMetadata
+			},
+		)
 
 		// If there was an error, return nil and the error
 		if err != nil {
@@ -83,8 +215,14 @@ func Source(
 
 		// If the number of messages received is equal to the max number of messages,
 		// there are likely more messages to receive, so return true for more
+		more := len(resp.Messages) == int(config.MaxNumberOfMessages)
 		messages := resp.Messages
-		return &messages, len(resp.Messages) == int(config.MaxNumberOfMessages), nil
+		if deleter != nil {
+			if messages, err = deleteReceived(ctx, deleter, config, messages); err != nil {
+				return nil, false, err
+			}
+		}
+		return &messages, more, nil
 	}
 
 	// Use sources.Poll to create a source that emits slices of messages
@@ -97,3 +235,64 @@ func Source(
 		flows.Flatten[types.Message](),
 	)
 }
+
+// deleteReceived deletes received messages from the queue, returning the messages that were deleted.
+func deleteReceived(
+	ctx context.Context,
+	client SQSDeleteBatchClient,
+	config SourceConfig,
+	messages []types.Message,
+) ([]types.Message, error) {
+	entries := make([]types.DeleteMessageBatchRequestEntry, len(messages))
+	for i, msg := range messages {
+		entries[i] = types.DeleteMessageBatchRequestEntry{
+			Id:            util.AsPtr(strconv.Itoa(i)),
+			ReceiptHandle: msg.ReceiptHandle,
+		}
+	}
+
+	resp, err := util.Call(
+		ctx,
+		config.Hooks,
+		"DeleteMessageBatch",
+		&sqs.DeleteMessageBatchInput{
+			QueueUrl: &config.QueueURL,
+			Entries:  entries,
+		},
+		func(ctx context.Context, input *sqs.DeleteMessageBatchInput) (*sqs.DeleteMessageBatchOutput, error) {
+			return client.DeleteMessageBatch(ctx, input)
+		},
+		func(output *sqs.DeleteMessageBatchOutput) middleware.Metadata {
+			return output.ResultMetadata
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Messages that were not deleted will be received again, so they are not emitted
+	deleted := make([]types.Message, 0, len(resp.Successful))
+	for _, entry := range resp.Successful {
+		if entry.Id == nil {
+			continue
+		}
+		i, err := strconv.Atoi(*entry.Id)
+		if err != nil || i < 0 || i >= len(messages) {
+			continue
+		}
+		deleted = append(deleted, messages[i])
+	}
+	return deleted, nil
+}
+
+// errSource creates a Source that emits a single error and completes.
+func errSource[O any](err error, opts ...core.SourceOption) *core.Source[O] {
+	return core.NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan core.Item[O] {
+			out := make(chan core.Item[O], 1)
+			out <- core.Item[O]{Err: err}
+			close(out)
+			return out
+		},
+		opts...)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/connectors"
 	"github.com/svenvdam/linea/connectors/aws/sqs/mocks"
 	"github.com/svenvdam/linea/connectors/aws/util"
 	"github.com/svenvdam/linea/sinks"
@@ -195,6 +196,69 @@ func TestSource(t *testing.T) {
 					Maybe()
 			},
 		},
+		{
+			name: "applies defaults to unspecified config values",
+			config: SourceConfig{
+				QueueURL:     "https://sqs.example.com/queue",
+				PollInterval: 50 * time.Millisecond,
+			},
+			expectedResult: []types.Message{testMsg1},
+			duration:       100 * time.Millisecond,
+			expectedErr:    nil,
+			setupMocks: func(t *testing.T, mockClient *mocks.MockSQSReceiveClient) {
+				expectedInput := &sqs.ReceiveMessageInput{
+					QueueUrl:            util.AsPtr("https://sqs.example.com/queue"),
+					MaxNumberOfMessages: 10,
+					WaitTimeSeconds:     20,
+				}
+
+				mockClient.EXPECT().
+					ReceiveMessage(mock.Anything, expectedInput, mock.Anything).
+					Return(&sqs.ReceiveMessageOutput{
+						Messages: []types.Message{testMsg1},
+					}, nil).
+					Once()
+
+				mockClient.EXPECT().
+					ReceiveMessage(mock.Anything, expectedInput, mock.Anything).
+					Return(&sqs.ReceiveMessageOutput{
+						Messages: []types.Message{},
+					}, nil).
+					Maybe()
+			},
+		},
+		{
+			name: "uses short polling",
+			config: SourceConfig{
+				QueueURL:        "https://sqs.example.com/queue",
+				WaitTimeSeconds: ShortPolling,
+				PollInterval:    50 * time.Millisecond,
+			},
+			expectedResult: []types.Message{testMsg1},
+			duration:       100 * time.Millisecond,
+			expectedErr:    nil,
+			setupMocks: func(t *testing.T, mockClient *mocks.MockSQSReceiveClient) {
+				expectedInput := &sqs.ReceiveMessageInput{
+					QueueUrl:            util.AsPtr("https://sqs.example.com/queue"),
+					MaxNumberOfMessages: 10,
+					WaitTimeSeconds:     0,
+				}
+
+				mockClient.EXPECT().
+					ReceiveMessage(mock.Anything, expectedInput, mock.Anything).
+					Return(&sqs.ReceiveMessageOutput{
+						Messages: []types.Message{testMsg1},
+					}, nil).
+					Once()
+
+				mockClient.EXPECT().
+					ReceiveMessage(mock.Anything, expectedInput, mock.Anything).
+					Return(&sqs.ReceiveMessageOutput{
+						Messages: []types.Message{},
+					}, nil).
+					Maybe()
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -225,3 +289,208 @@ func TestSource(t *testing.T) {
 		})
 	}
 }
+
+func TestSourceConfigValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      SourceConfig
+		expectedErr []string
+	}{
+		{
+			name:   "valid config",
+			config: SourceConfig{QueueURL: "https://sqs.example.com/queue", MaxNumberOfMessages: 5, WaitTimeSeconds: 10},
+		},
+		{
+			name:   "unspecified values are defaulted",
+			config: SourceConfig{QueueURL: "https://sqs.example.com/queue"},
+		},
+		{
+			name:   "short polling",
+			config: SourceConfig{QueueURL: "https://sqs.example.com/queue", WaitTimeSeconds: ShortPolling},
+		},
+		{
+			name:        "missing queue url",
+			config:      SourceConfig{},
+			expectedErr: []string{"QueueURL is required"},
+		},
+		{
+			name: "out of range values",
+			config: SourceConfig{
+				QueueURL:            "https://sqs.example.com/queue",
+				MaxNumberOfMessages: 11,
+				WaitTimeSeconds:     21,
+				VisibilityTimeout:   -1,
+				PollInterval:        -time.Second,
+				DeliveryMode:        connectors.DeliveryMode(5),
+			},
+			expectedErr: []string{
+				"MaxNumberOfMessages must be between 1 and 10, got 11",
+				"WaitTimeSeconds must be between 1 and 20 or ShortPolling, got 21",
+				"VisibilityTimeout must be between 0 and 43200, got -1",
+				"PollInterval must not be negative, got -1s",
+				"DeliveryMode DeliveryMode(5) is not supported",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if len(tt.expectedErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, util.ErrInvalidConfig)
+			for _, msg := range tt.expectedErr {
+				assert.ErrorContains(t, err, msg)
+			}
+		})
+	}
+}
+
+func TestSourceInvalidConfig(t *testing.T) {
+	// The mock fails the test if ReceiveMessage is called
+	mockClient := mocks.NewMockSQSReceiveClient(t)
+
+	stream := compose.SourceToSink(
+		Source(mockClient, SourceConfig{QueueURL: "https://sqs.example.com/queue", MaxNumberOfMessages: 20}),
+		sinks.Noop[types.Message](),
+	)
+
+	result := <-stream.Run(context.Background())
+
+	assert.ErrorIs(t, result.Err, util.ErrInvalidConfig)
+	assert.ErrorContains(t, result.Err, "MaxNumberOfMessages")
+}
+
+// deletingClient is an SQS client that can both receive and batch delete messages.
+type deletingClient struct {
+	*mocks.MockSQSReceiveClient
+	*mocks.MockSQSDeleteBatchClient
+}
+
+func TestSourceDeliveryMode(t *testing.T) {
+	deletesReceipts := func(receipts ...string) interface{} {
+		return mock.MatchedBy(func(input *sqs.DeleteMessageBatchInput) bool {
+			if len(input.Entries) != len(receipts) {
+				return false
+			}
+			for i, entry := range input.Entries {
+				if *entry.ReceiptHandle != receipts[i] {
+					return false
+				}
+			}
+			return true
+		})
+	}
+
+	tests := []struct {
+		name           string
+		mode           connectors.DeliveryMode
+		setupDelete    func(mock *mocks.MockSQSDeleteBatchClient)
+		expectedResult []types.Message
+		expectedErr    error
+	}{
+		{
+			name:           "at least once leaves messages in the queue",
+			mode:           connectors.AtLeastOnce,
+			setupDelete:    func(mock *mocks.MockSQSDeleteBatchClient) {},
+			expectedResult: []types.Message{testMsg1, testMsg2},
+		},
+		{
+			name: "at most once deletes messages before emitting them",
+			mode: connectors.AtMostOnce,
+			setupDelete: func(mockClient *mocks.MockSQSDeleteBatchClient) {
+				mockClient.EXPECT().
+					DeleteMessageBatch(mock.Anything, deletesReceipts("receipt1", "receipt2"), mock.Anything).
+					Return(&sqs.DeleteMessageBatchOutput{
+						Successful: []types.DeleteMessageBatchResultEntry{{Id: util.AsPtr("0")}, {Id: util.AsPtr("1")}},
+					}, nil).
+					Once()
+			},
+			expectedResult: []types.Message{testMsg1, testMsg2},
+		},
+		{
+			name: "at most once does not emit messages that were not deleted",
+			mode: connectors.AtMostOnce,
+			setupDelete: func(mockClient *mocks.MockSQSDeleteBatchClient) {
+				mockClient.EXPECT().
+					DeleteMessageBatch(mock.Anything, mock.Anything, mock.Anything).
+					Return(&sqs.DeleteMessageBatchOutput{
+						Successful: []types.DeleteMessageBatchResultEntry{{Id: util.AsPtr("1")}},
+						Failed:     []types.BatchResultErrorEntry{{Id: util.AsPtr("0"), Code: util.AsPtr("ReceiptHandleIsInvalid")}},
+					}, nil).
+					Once()
+			},
+			expectedResult: []types.Message{testMsg2},
+		},
+		{
+			name: "at most once fails if messages cannot be deleted",
+			mode: connectors.AtMostOnce,
+			setupDelete: func(mockClient *mocks.MockSQSDeleteBatchClient) {
+				mockClient.EXPECT().
+					DeleteMessageBatch(mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, errors.New("access denied")).
+					Once()
+			},
+			expectedResult: []types.Message{},
+			expectedErr:    errors.New("access denied"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := deletingClient{
+				MockSQSReceiveClient:     mocks.NewMockSQSReceiveClient(t),
+				MockSQSDeleteBatchClient: mocks.NewMockSQSDeleteBatchClient(t),
+			}
+			client.MockSQSReceiveClient.EXPECT().
+				ReceiveMessage(mock.Anything, mock.Anything, mock.Anything).
+				Return(&sqs.ReceiveMessageOutput{Messages: []types.Message{testMsg1, testMsg2}}, nil).
+				Once()
+			client.MockSQSReceiveClient.EXPECT().
+				ReceiveMessage(mock.Anything, mock.Anything, mock.Anything).
+				Return(&sqs.ReceiveMessageOutput{}, nil).
+				Maybe()
+			tt.setupDelete(client.MockSQSDeleteBatchClient)
+
+			stream := compose.SourceThroughFlowToSink(
+				Source(client, SourceConfig{
+					QueueURL:     "https://sqs.example.com/queue",
+					PollInterval: 50 * time.Millisecond,
+					DeliveryMode: tt.mode,
+				}),
+				test.CheckItems(t, func(t *testing.T, elems []types.Message) {
+					assert.Equal(t, tt.expectedResult, elems)
+				}),
+				sinks.Noop[types.Message](),
+			)
+
+			resultChan := stream.Run(context.Background())
+			time.Sleep(100 * time.Millisecond)
+			stream.Drain()
+			result := <-resultChan
+
+			assert.Equal(t, tt.expectedErr, result.Err)
+		})
+	}
+}
+
+func TestSourceAtMostOnceRequiresDeleteClient(t *testing.T) {
+	// The mock fails the test if ReceiveMessage is called
+	mockClient := mocks.NewMockSQSReceiveClient(t)
+
+	stream := compose.SourceToSink(
+		Source(mockClient, SourceConfig{
+			QueueURL:     "https://sqs.example.com/queue",
+			DeliveryMode: connectors.AtMostOnce,
+		}),
+		sinks.Noop[types.Message](),
+	)
+
+	result := <-stream.Run(context.Background())
+
+	assert.ErrorIs(t, result.Err, util.ErrInvalidConfig)
+	assert.ErrorContains(t, result.Err, "SQSDeleteBatchClient")
+}
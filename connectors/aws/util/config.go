@@ -0,0 +1,18 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidConfig is the error wrapped by all connector configuration validation errors.
+var ErrInvalidConfig = errors.New("invalid config")
+
+// ConfigError combines the problems found while validating a connector configuration
+// into a single error wrapping ErrInvalidConfig. It returns nil if there are no problems.
+func ConfigError(problems ...error) error {
+	if err := errors.Join(problems...); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// CallEvent describes a single call made by a connector to an AWS API.
+type CallEvent struct {
+	// Operation is the name of the AWS API operation, such as "ReceiveMessage"
+	Operation string
+
+	// Input is the input passed to the operation
+	Input any
+
+	// Output is the output returned by the operation, or nil if the call failed or has not completed
+	Output any
+
+	// Err is the error returned by the operation, or nil if the call succeeded or has not completed
+	Err error
+
+	// RequestID is the AWS request id of the call, if known
+	RequestID string
+
+	// Duration is the time the call took, or zero if the call has not completed
+	Duration time.Duration
+}
+
+// Hooks are optional functions called around every call a connector makes to an AWS API.
+// They allow recording audit logs and custom metrics without wrapping the AWS clients.
+// Hooks are called synchronously from the stage making the call, so they should return quickly.
+// Any hook may be nil.
+type Hooks struct {
+	// OnRequest is called before each call with the operation and its input
+	OnRequest func(ctx context.Context, event CallEvent)
+
+	// OnResponse is called after each successful call with the output, request id and duration
+	OnResponse func(ctx context.Context, event CallEvent)
+
+	// OnError is called after each failed call with the error, request id and duration
+	OnError func(ctx context.Context, event CallEvent)
+}
+
+// requestIDError is implemented by errors returned by AWS clients for failed requests.
+type requestIDError interface {
+	ServiceRequestID() string
+}
+
+// Call calls an AWS API operation, invoking the hooks before and after the call.
+//
+// Type Parameters:
+//   - I: The type of the operation input
+//   - O: The type of the operation output, which is returned by pointer
+//
+// Parameters:
+//   - ctx: Context passed to the call and the hooks
+//   - hooks: The hooks to invoke
+//   - operation: The name of the AWS API operation
+//   - input: The input of the operation
+//   - call: Function performing the call
+//   - metadata: Function returning the result metadata of a non-nil output, used to read the request id
+//
+// Returns the output and error of the call
+func Call[I, O any](
+	ctx context.Context,
+	hooks Hooks,
+	operation string,
+	input I,
+	call func(context.Context, I) (*O, error),
+	metadata func(*O) middleware.Metadata,
+) (*O, error) {
+	if hooks.OnRequest != nil {
+		hooks.OnRequest(ctx, CallEvent{Operation: operation, Input: input})
+	}
+
+	start := time.Now()
+	output, err := call(ctx, input)
+	event := CallEvent{
+		Operation: operation,
+		Input:     input,
+		Duration:  time.Since(start),
+	}
+
+	if err != nil {
+		var reqErr requestIDError
+		if errors.As(err, &reqErr) {
+			event.RequestID = reqErr.ServiceRequestID()
+		}
+		event.Err = err
+		if hooks.OnError != nil {
+			hooks.OnError(ctx, event)
+		}
+		return output, err
+	}
+
+	if output != nil {
+		event.RequestID, _ = awsmiddleware.GetRequestIDMetadata(metadata(output))
+	}
+	event.Output = output
+	if hooks.OnResponse != nil {
+		hooks.OnResponse(ctx, event)
+	}
+	return output, nil
+}
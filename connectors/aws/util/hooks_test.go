@@ -0,0 +1,123 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+type testOutput struct {
+	Value          string
+	ResultMetadata middleware.Metadata
+}
+
+type testRequestError struct {
+	requestID string
+}
+
+func (e *testRequestError) Error() string {
+	return "request failed"
+}
+
+func (e *testRequestError) ServiceRequestID() string {
+	return e.requestID
+}
+
+func TestCall(t *testing.T) {
+	requestErr := &testRequestError{requestID: "req-2"}
+
+	tests := []struct {
+		name              string
+		call              func(context.Context, string) (*testOutput, error)
+		expectedOperation []string
+		expectedRequestID string
+		expectedErr       error
+	}{
+		{
+			name: "calls OnRequest and OnResponse on success",
+			call: func(ctx context.Context, input string) (*testOutput, error) {
+				output := &testOutput{Value: input}
+				awsmiddleware.SetRequestIDMetadata(&output.ResultMetadata, "req-1")
+				return output, nil
+			},
+			expectedOperation: []string{"request", "response"},
+			expectedRequestID: "req-1",
+		},
+		{
+			name: "calls OnRequest and OnError on failure",
+			call: func(ctx context.Context, input string) (*testOutput, error) {
+				return nil, requestErr
+			},
+			expectedOperation: []string{"request", "error"},
+			expectedRequestID: "req-2",
+			expectedErr:       requestErr,
+		},
+		{
+			name: "handles nil output",
+			call: func(ctx context.Context, input string) (*testOutput, error) {
+				return nil, nil
+			},
+			expectedOperation: []string{"request", "response"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			var last CallEvent
+			hooks := Hooks{
+				OnRequest: func(ctx context.Context, event CallEvent) {
+					calls = append(calls, "request")
+					assert.Equal(t, "TestOperation", event.Operation)
+					assert.Equal(t, "input", event.Input)
+				},
+				OnResponse: func(ctx context.Context, event CallEvent) {
+					calls = append(calls, "response")
+					last = event
+				},
+				OnError: func(ctx context.Context, event CallEvent) {
+					calls = append(calls, "error")
+					last = event
+				},
+			}
+
+			metadata := func(output *testOutput) middleware.Metadata {
+				return output.ResultMetadata
+			}
+			output, err := Call(context.Background(), hooks, "TestOperation", "input", tt.call, metadata)
+
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedOperation, calls)
+			assert.Equal(t, tt.expectedRequestID, last.RequestID)
+			assert.Equal(t, tt.expectedErr, last.Err)
+			assert.Equal(t, "input", last.Input)
+			if output != nil {
+				assert.Equal(t, output, last.Output)
+			}
+		})
+	}
+}
+
+func TestCallWithoutHooks(t *testing.T) {
+	testErr := errors.New("test error")
+
+	output, err := Call(
+		context.Background(),
+		Hooks{},
+		"TestOperation",
+		"input",
+		func(ctx context.Context, input string) (*testOutput, error) {
+			return &testOutput{Value: input}, testErr
+		},
+		func(output *testOutput) middleware.Metadata {
+			return output.ResultMetadata
+		},
+	)
+
+	assert.Equal(t, &testOutput{Value: "input"}, output)
+	assert.Equal(t, testErr, err)
+}
@@ -0,0 +1,147 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// BatchFailure describes an entry of a batch operation that failed.
+//
+// Type Parameters:
+//   - T: The type of the batch entries
+type BatchFailure[T any] struct {
+	// Entry is the entry that failed
+	Entry T
+
+	// Code is the error code reported by the external system, if any
+	Code string
+
+	// Reason is a description of why the entry failed
+	Reason string
+
+	// Retriable indicates whether retrying the entry may succeed
+	Retriable bool
+}
+
+// BatchResult is the result of a batch operation that can partially fail, such as sending
+// multiple messages or events in a single request. Batch-capable connectors report their
+// results as a BatchResult, so partial failures can be handled the same way across connectors.
+//
+// Type Parameters:
+//   - T: The type of the batch entries
+type BatchResult[T any] struct {
+	// Succeeded holds the entries that were processed successfully
+	Succeeded []T
+
+	// Failed holds the entries that failed together with the reason of the failure
+	Failed []BatchFailure[T]
+}
+
+// AllSucceeded reports whether all entries of the batch were processed successfully.
+func (r BatchResult[T]) AllSucceeded() bool {
+	return len(r.Failed) == 0
+}
+
+// Retriable returns the entries that failed and may succeed when retried.
+func (r BatchResult[T]) Retriable() []T {
+	var entries []T
+	for _, failure := range r.Failed {
+		if failure.Retriable {
+			entries = append(entries, failure.Entry)
+		}
+	}
+	return entries
+}
+
+// BatchEntryError is the error emitted for a failed entry by FailuresAsErrors.
+type BatchEntryError[T any] struct {
+	BatchFailure[T]
+}
+
+// Error returns a description of the failed entry.
+func (e *BatchEntryError[T]) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("batch entry failed: %s", e.Reason)
+	}
+	return fmt.Sprintf("batch entry failed with %s: %s", e.Code, e.Reason)
+}
+
+// SucceededEntries creates a Flow that emits every entry that succeeded in the incoming
+// batch results individually. Failed entries are discarded.
+//
+// Type Parameters:
+//   - T: The type of the batch entries
+//
+// Parameters:
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that emits succeeded entries
+func SucceededEntries[T any](opts ...core.FlowOption) *core.Flow[BatchResult[T], T] {
+	return core.NewFlow(
+		func(ctx context.Context, elem BatchResult[T], out chan<- core.Item[T]) core.StreamAction {
+			for _, entry := range elem.Succeeded {
+				util.Send(ctx, core.Item[T]{Value: entry}, out)
+			}
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		nil,
+		opts...)
+}
+
+// FailedEntries creates a Flow that emits every failure of the incoming batch results
+// individually, for example to route them to a dead letter queue or retry them.
+// Succeeded entries are discarded.
+//
+// Type Parameters:
+//   - T: The type of the batch entries
+//
+// Parameters:
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that emits failed entries
+func FailedEntries[T any](opts ...core.FlowOption) *core.Flow[BatchResult[T], BatchFailure[T]] {
+	return core.NewFlow(
+		func(ctx context.Context, elem BatchResult[T], out chan<- core.Item[BatchFailure[T]]) core.StreamAction {
+			for _, failure := range elem.Failed {
+				util.Send(ctx, core.Item[BatchFailure[T]]{Value: failure}, out)
+			}
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		nil,
+		opts...)
+}
+
+// FailuresAsErrors creates a Flow that emits every entry that succeeded in the incoming
+// batch results, and a BatchEntryError for every entry that failed. This routes failures
+// through the regular error handling of the downstream stages, such as a supervision Decider.
+//
+// Type Parameters:
+//   - T: The type of the batch entries
+//
+// Parameters:
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that emits succeeded entries and errors for failed entries
+func FailuresAsErrors[T any](opts ...core.FlowOption) *core.Flow[BatchResult[T], T] {
+	return core.NewFlow(
+		func(ctx context.Context, elem BatchResult[T], out chan<- core.Item[T]) core.StreamAction {
+			for _, entry := range elem.Succeeded {
+				util.Send(ctx, core.Item[T]{Value: entry}, out)
+			}
+			for _, failure := range elem.Failed {
+				util.Send(ctx, core.Item[T]{Err: &BatchEntryError[T]{BatchFailure: failure}}, out)
+			}
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		nil,
+		opts...)
+}
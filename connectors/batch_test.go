@@ -0,0 +1,123 @@
+package connectors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+var (
+	testBatches = []BatchResult[string]{
+		{
+			Succeeded: []string{"a", "b"},
+			Failed: []BatchFailure[string]{
+				{Entry: "c", Code: "Throttled", Reason: "slow down", Retriable: true},
+			},
+		},
+		{
+			Succeeded: []string{"d"},
+			Failed: []BatchFailure[string]{
+				{Entry: "e", Reason: "malformed"},
+			},
+		},
+	}
+)
+
+func TestBatchResult(t *testing.T) {
+	tests := []struct {
+		name              string
+		result            BatchResult[string]
+		expectedSucceeded bool
+		expectedRetriable []string
+	}{
+		{
+			name:              "all succeeded",
+			result:            BatchResult[string]{Succeeded: []string{"a"}},
+			expectedSucceeded: true,
+		},
+		{
+			name:              "retriable failure",
+			result:            testBatches[0],
+			expectedSucceeded: false,
+			expectedRetriable: []string{"c"},
+		},
+		{
+			name:              "non-retriable failure",
+			result:            testBatches[1],
+			expectedSucceeded: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedSucceeded, tt.result.AllSucceeded())
+			assert.Equal(t, tt.expectedRetriable, tt.result.Retriable())
+		})
+	}
+}
+
+func TestSucceededEntries(t *testing.T) {
+	stream := compose.SourceThroughFlowToSink(
+		sources.Slice(testBatches),
+		SucceededEntries[string](),
+		sinks.Slice[string](),
+	)
+
+	res := <-stream.Run(context.Background())
+
+	assert.NoError(t, res.Err)
+	assert.Equal(t, []string{"a", "b", "d"}, res.Value)
+}
+
+func TestFailedEntries(t *testing.T) {
+	stream := compose.SourceThroughFlowToSink(
+		sources.Slice(testBatches),
+		FailedEntries[string](),
+		sinks.Slice[BatchFailure[string]](),
+	)
+
+	res := <-stream.Run(context.Background())
+
+	assert.NoError(t, res.Err)
+	assert.Equal(t, []BatchFailure[string]{testBatches[0].Failed[0], testBatches[1].Failed[0]}, res.Value)
+}
+
+func TestFailuresAsErrors(t *testing.T) {
+	var errs []error
+	sink := core.NewSink(
+		[]string{},
+		func(ctx context.Context, in string, acc core.Item[[]string]) (core.Item[[]string], core.StreamAction) {
+			return core.Item[[]string]{Value: append(acc.Value, in)}, core.ActionProceed
+		},
+		func(ctx context.Context, err error, acc core.Item[[]string]) (core.Item[[]string], core.StreamAction) {
+			errs = append(errs, err)
+			return acc, core.ActionProceed
+		},
+		nil,
+	)
+
+	stream := compose.SourceThroughFlowToSink(
+		sources.Slice(testBatches),
+		FailuresAsErrors[string](),
+		sink,
+	)
+
+	res := <-stream.Run(context.Background())
+
+	assert.NoError(t, res.Err)
+	assert.Equal(t, []string{"a", "b", "d"}, res.Value)
+	if assert.Len(t, errs, 2) {
+		assert.EqualError(t, errs[0], "batch entry failed with Throttled: slow down")
+		assert.EqualError(t, errs[1], "batch entry failed: malformed")
+
+		var entryErr *BatchEntryError[string]
+		assert.ErrorAs(t, errs[0], &entryErr)
+		assert.Equal(t, "c", entryErr.Entry)
+		assert.True(t, entryErr.Retriable)
+	}
+}
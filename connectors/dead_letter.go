@@ -0,0 +1,188 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// DeadLetter is the envelope in which items that could not be processed are sent to a dead
+// letter queue. It holds the original payload together with the details of the failure, so
+// dead letters of all connectors can be inspected and replayed in the same way. Dead letters
+// are serialized to JSON by EncodeDeadLetters and decoded by DecodeDeadLetters.
+//
+// Type Parameters:
+//   - T: The type of the original payload
+type DeadLetter[T any] struct {
+	// Payload is the original item that could not be processed
+	Payload T `json:"payload"`
+
+	// Errors holds the messages of the error chain, from the outermost error to its causes
+	Errors []string `json:"errors"`
+
+	// Attempts is the number of times processing the payload was attempted
+	Attempts int `json:"attempts"`
+
+	// Stage is the name of the stage that failed, if the error was produced by a named stage
+	Stage string `json:"stage,omitempty"`
+
+	// FirstFailedAt is the time processing the payload failed for the first time
+	FirstFailedAt time.Time `json:"firstFailedAt"`
+
+	// DeadLetteredAt is the time the payload was given up on
+	DeadLetteredAt time.Time `json:"deadLetteredAt"`
+}
+
+// NewDeadLetter creates a DeadLetter for a payload that failed with err. The stage is taken
+// from the core.StageError in the error chain, if any.
+//
+// Type Parameters:
+//   - T: The type of the original payload
+//
+// Parameters:
+//   - payload: The original item that could not be processed
+//   - err: The error processing the payload failed with
+//   - attempts: The number of times processing the payload was attempted
+//   - firstFailedAt: The time processing the payload failed for the first time, or the zero
+//     time to use the current time
+//
+// Returns the DeadLetter for the payload
+func NewDeadLetter[T any](payload T, err error, attempts int, firstFailedAt time.Time) DeadLetter[T] {
+	now := time.Now()
+	if firstFailedAt.IsZero() {
+		firstFailedAt = now
+	}
+
+	var stage string
+	var stageErr *core.StageError
+	if errors.As(err, &stageErr) {
+		stage = stageErr.Stage
+	}
+
+	return DeadLetter[T]{
+		Payload:        payload,
+		Errors:         errorChain(err),
+		Attempts:       attempts,
+		Stage:          stage,
+		FirstFailedAt:  firstFailedAt,
+		DeadLetteredAt: now,
+	}
+}
+
+// errorChain returns the messages of err and all errors it wraps, depth first.
+func errorChain(err error) []string {
+	var chain []string
+	for err != nil {
+		chain = append(chain, err.Error())
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, inner := range joined.Unwrap() {
+				chain = append(chain, errorChain(inner)...)
+			}
+			return chain
+		}
+		err = errors.Unwrap(err)
+	}
+	return chain
+}
+
+// DeadLetter creates the DeadLetter of a failed batch entry, recording the code and reason
+// reported by the external system as its error.
+//
+// Parameters:
+//   - attempts: The number of times sending the entry was attempted
+//
+// Returns the DeadLetter for the entry
+func (f BatchFailure[T]) DeadLetter(attempts int) DeadLetter[T] {
+	return NewDeadLetter(f.Entry, &BatchEntryError[T]{BatchFailure: f}, attempts, time.Time{})
+}
+
+// EncodeDeadLetters creates a Flow that serializes dead letters to JSON, so they can be sent
+// to a dead letter queue by any connector. Dead letters that cannot be serialized are passed
+// downstream as errors.
+//
+// Type Parameters:
+//   - T: The type of the original payload
+//
+// Parameters:
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that emits the JSON encoding of each dead letter
+func EncodeDeadLetters[T any](opts ...core.FlowOption) *core.Flow[DeadLetter[T], []byte] {
+	return core.NewFlow(
+		func(ctx context.Context, elem DeadLetter[T], out chan<- core.Item[[]byte]) core.StreamAction {
+			if data, err := json.Marshal(elem); err != nil {
+				util.Send(ctx, core.Item[[]byte]{Err: err}, out)
+			} else {
+				util.Send(ctx, core.Item[[]byte]{Value: data}, out)
+			}
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		nil,
+		opts...)
+}
+
+// DecodeDeadLetters creates a Flow that decodes dead letters serialized by EncodeDeadLetters.
+// Messages that cannot be decoded are passed downstream as errors.
+//
+// Type Parameters:
+//   - T: The type of the original payload
+//
+// Parameters:
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that emits the decoded dead letters
+func DecodeDeadLetters[T any](opts ...core.FlowOption) *core.Flow[[]byte, DeadLetter[T]] {
+	return core.NewFlow(
+		func(ctx context.Context, elem []byte, out chan<- core.Item[DeadLetter[T]]) core.StreamAction {
+			var letter DeadLetter[T]
+			if err := json.Unmarshal(elem, &letter); err != nil {
+				util.Send(ctx, core.Item[DeadLetter[T]]{Err: err}, out)
+			} else {
+				util.Send(ctx, core.Item[DeadLetter[T]]{Value: letter}, out)
+			}
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		nil,
+		opts...)
+}
+
+// ReplayDeadLetters creates a Source that re-injects the original payloads of the dead letters
+// read from a dead letter queue, so they can be processed again by the original pipeline.
+// Messages that cannot be decoded are emitted as errors.
+//
+// Type Parameters:
+//   - T: The type of the original payload
+//
+// Parameters:
+//   - source: Source emitting the serialized dead letters, such as the body of queue messages
+//   - opts: Optional FlowOption functions to configure the decoding flow
+//
+// Returns a Source emitting the original payloads
+func ReplayDeadLetters[T any](source *core.Source[[]byte], opts ...core.FlowOption) *core.Source[T] {
+	return core.AppendFlowToSource(
+		source,
+		core.NewFlow(
+			func(ctx context.Context, elem []byte, out chan<- core.Item[T]) core.StreamAction {
+				var letter DeadLetter[T]
+				if err := json.Unmarshal(elem, &letter); err != nil {
+					util.Send(ctx, core.Item[T]{Err: err}, out)
+				} else {
+					util.Send(ctx, core.Item[T]{Value: letter.Payload}, out)
+				}
+				return core.ActionProceed
+			},
+			nil,
+			nil,
+			nil,
+			opts...,
+		),
+	)
+}
@@ -0,0 +1,155 @@
+package connectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+type testPayload struct {
+	ID   string `json:"id"`
+	Size int    `json:"size"`
+}
+
+func TestNewDeadLetter(t *testing.T) {
+	errRoot := errors.New("connection reset")
+	firstFailedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		err            error
+		firstFailedAt  time.Time
+		expectedErrors []string
+		expectedStage  string
+	}{
+		{
+			name:           "single error",
+			err:            errRoot,
+			expectedErrors: []string{"connection reset"},
+		},
+		{
+			name:           "wrapped error chain",
+			err:            fmt.Errorf("send: %w", errRoot),
+			firstFailedAt:  firstFailedAt,
+			expectedErrors: []string{"send: connection reset", "connection reset"},
+		},
+		{
+			name:           "joined errors",
+			err:            errors.Join(errRoot, errors.New("timeout")),
+			expectedErrors: []string{"connection reset\ntimeout", "connection reset", "timeout"},
+		},
+		{
+			name:           "error of named stage",
+			err:            &core.StageError{Stage: "enrich", Err: errRoot},
+			expectedErrors: []string{"enrich: connection reset", "connection reset"},
+			expectedStage:  "enrich",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			letter := NewDeadLetter(testPayload{ID: "a"}, tt.err, 3, tt.firstFailedAt)
+
+			assert.Equal(t, testPayload{ID: "a"}, letter.Payload)
+			assert.Equal(t, tt.expectedErrors, letter.Errors)
+			assert.Equal(t, 3, letter.Attempts)
+			assert.Equal(t, tt.expectedStage, letter.Stage)
+			assert.False(t, letter.DeadLetteredAt.IsZero())
+			if tt.firstFailedAt.IsZero() {
+				assert.Equal(t, letter.DeadLetteredAt, letter.FirstFailedAt)
+			} else {
+				assert.Equal(t, tt.firstFailedAt, letter.FirstFailedAt)
+			}
+		})
+	}
+}
+
+func TestBatchFailureDeadLetter(t *testing.T) {
+	letter := testBatches[0].Failed[0].DeadLetter(2)
+
+	assert.Equal(t, "c", letter.Payload)
+	assert.Equal(t, []string{"batch entry failed with Throttled: slow down"}, letter.Errors)
+	assert.Equal(t, 2, letter.Attempts)
+}
+
+func TestDeadLetterRoundTrip(t *testing.T) {
+	letters := []DeadLetter[testPayload]{
+		NewDeadLetter(testPayload{ID: "a", Size: 1}, errors.New("failed"), 1, time.Time{}),
+		NewDeadLetter(testPayload{ID: "b", Size: 2}, &core.StageError{Stage: "send", Err: errors.New("throttled")}, 5, time.Time{}),
+	}
+
+	stream := compose.SourceThroughFlowToSink2(
+		sources.Slice(letters),
+		EncodeDeadLetters[testPayload](),
+		DecodeDeadLetters[testPayload](),
+		sinks.Slice[DeadLetter[testPayload]](),
+	)
+
+	res := <-stream.Run(context.Background())
+
+	assert.NoError(t, res.Err)
+	if assert.Len(t, res.Value, 2) {
+		for i, letter := range res.Value {
+			assert.Equal(t, letters[i].Payload, letter.Payload)
+			assert.Equal(t, letters[i].Errors, letter.Errors)
+			assert.Equal(t, letters[i].Attempts, letter.Attempts)
+			assert.Equal(t, letters[i].Stage, letter.Stage)
+			assert.True(t, letters[i].DeadLetteredAt.Equal(letter.DeadLetteredAt))
+		}
+	}
+}
+
+func TestReplayDeadLetters(t *testing.T) {
+	tests := []struct {
+		name        string
+		messages    []string
+		expected    []testPayload
+		expectedErr bool
+	}{
+		{
+			name: "re-injects original payloads",
+			messages: []string{
+				`{"payload":{"id":"a","size":1},"errors":["failed"],"attempts":1}`,
+				`{"payload":{"id":"b","size":2},"errors":["failed"],"attempts":3,"stage":"send"}`,
+			},
+			expected: []testPayload{{ID: "a", Size: 1}, {ID: "b", Size: 2}},
+		},
+		{
+			name:        "emits error for malformed message",
+			messages:    []string{`not json`},
+			expected:    []testPayload{},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := make([][]byte, len(tt.messages))
+			for i, msg := range tt.messages {
+				messages[i] = []byte(msg)
+			}
+
+			stream := compose.SourceToSink(
+				ReplayDeadLetters[testPayload](sources.Slice(messages)),
+				sinks.Slice[testPayload](),
+			)
+
+			res := <-stream.Run(context.Background())
+
+			if tt.expectedErr {
+				assert.Error(t, res.Err)
+			} else {
+				assert.NoError(t, res.Err)
+				assert.Equal(t, tt.expected, res.Value)
+			}
+		})
+	}
+}
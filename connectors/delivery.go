@@ -0,0 +1,34 @@
+package connectors
+
+import "fmt"
+
+// DeliveryMode selects when a connector source acknowledges the messages it receives, which
+// determines the delivery guarantee of the pipeline.
+type DeliveryMode int
+
+const (
+	// AtLeastOnce emits messages without acknowledging them, which is the default. The pipeline
+	// acknowledges a message once it has been processed successfully, typically in its last
+	// stage, such as with the DeleteFlow of the SQS connector. Messages that are not
+	// acknowledged, because processing failed or the process stopped, are delivered again,
+	// so a message may be processed more than once.
+	AtLeastOnce DeliveryMode = iota
+
+	// AtMostOnce acknowledges messages as soon as they are received, before they are emitted.
+	// A message is never delivered again, so it is lost if processing fails or the process
+	// stops before it has been processed. Messages that could not be acknowledged are not
+	// emitted, as they may be delivered again.
+	AtMostOnce
+)
+
+// String returns the name of the delivery mode.
+func (m DeliveryMode) String() string {
+	switch m {
+	case AtLeastOnce:
+		return "at_least_once"
+	case AtMostOnce:
+		return "at_most_once"
+	default:
+		return fmt.Sprintf("DeliveryMode(%d)", int(m))
+	}
+}
@@ -0,0 +1,24 @@
+package connectors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryModeString(t *testing.T) {
+	tests := []struct {
+		mode     DeliveryMode
+		expected string
+	}{
+		{mode: AtLeastOnce, expected: "at_least_once"},
+		{mode: AtMostOnce, expected: "at_most_once"},
+		{mode: DeliveryMode(7), expected: "DeliveryMode(7)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.mode.String())
+		})
+	}
+}
@@ -0,0 +1,14 @@
+// Package connectors provides types shared by the connectors to external systems.
+//
+// Connectors live in their own modules, such as github.com/svenvdam/linea/connectors/aws,
+// so that their dependencies are only pulled in when they are used. This package contains
+// the types they have in common, allowing pipelines to handle the results of different
+// connectors in the same way.
+//
+// It currently offers:
+//   - BatchResult for reporting the partial failure of batch operations
+//   - SucceededEntries, FailedEntries and FailuresAsErrors for splitting and routing batch results
+//   - DeadLetter, a uniform envelope for dead letter queues, with EncodeDeadLetters,
+//     DecodeDeadLetters and ReplayDeadLetters for writing, inspecting and replaying them
+//   - DeliveryMode for selecting at-least-once or at-most-once delivery of connector sources
+package connectors
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// String returns the name of the action.
+func (a StreamAction) String() string {
+	switch a {
+	case ActionProceed:
+		return "proceed"
+	case ActionStop:
+		return "stop"
+	case ActionCancel:
+		return "cancel"
+	case ActionComplete:
+		return "complete"
+	case ActionRestartUpstream:
+		return "restartUpstream"
+	default:
+		return fmt.Sprintf("StreamAction(%d)", int(a))
+	}
+}
+
+// ActionEvent describes a StreamAction other than ActionProceed returned by a stage.
+type ActionEvent struct {
+	// Stage is the name of the stage that returned the action
+	Stage string
+
+	// Kind is the kind of the stage, StageKindFlow or StageKindSink
+	Kind StageKind
+
+	// Action is the action returned by the stage
+	Action StreamAction
+}
+
+// ActionHook is called for every StreamAction other than ActionProceed returned by a stage.
+// It is called synchronously from the stage's goroutine before the action is applied, so it
+// should return quickly.
+type ActionHook func(ctx context.Context, event ActionEvent)
+
+// actionHookKey is the context key under which the ActionHook of a running stream is stored.
+type actionHookKey struct{}
+
+// OnAction registers a hook that is called whenever a flow or sink of the stream returns
+// ActionStop, ActionCancel, ActionComplete or ActionRestartUpstream while handling an element
+// or an error, before the action is applied. This makes the control flow of a stream
+// observable, for example to count restarts in metrics or to flush state before a stage stops.
+// The ActionStop returned once upstream has closed is not reported, as it ends every stage.
+//
+// Hooks are called in order of registration. Hooks must be registered before the stream is
+// started.
+//
+// Parameters:
+//   - hook: Function called with every action
+func (s *Stream[R]) OnAction(hook ActionHook) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.actionHooks = append(s.actionHooks, hook)
+}
+
+// withActionHooks returns a context carrying the given hooks. Hooks already present in the
+// context, for example of a stream running this stream, are called as well.
+func withActionHooks(ctx context.Context, hooks []ActionHook) context.Context {
+	if len(hooks) == 0 {
+		return ctx
+	}
+	parent, _ := ctx.Value(actionHookKey{}).(ActionHook)
+	return context.WithValue(ctx, actionHookKey{}, ActionHook(func(ctx context.Context, event ActionEvent) {
+		if parent != nil {
+			parent(ctx, event)
+		}
+		for _, hook := range hooks {
+			hook(ctx, event)
+		}
+	}))
+}
+
+// reportAction reports an action returned by the stage ctx was passed to.
+func reportAction(ctx context.Context, kind StageKind, action StreamAction) {
+	if action == ActionProceed {
+		return
+	}
+	hook, ok := ctx.Value(actionHookKey{}).(ActionHook)
+	if !ok {
+		return
+	}
+	name, _ := GetAttribute(AttributesFromContext(ctx), NameKey)
+	hook(ctx, ActionEvent{Stage: name, Kind: kind, Action: action})
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testActionFlow creates a Flow passing elements through and returning action for the given element.
+func testActionFlow(at int, action StreamAction, opts ...FlowOption) *Flow[int, int] {
+	return NewFlow(
+		func(ctx context.Context, elem int, out chan<- Item[int]) StreamAction {
+			out <- Item[int]{Value: elem}
+			if elem == at {
+				return action
+			}
+			return ActionProceed
+		},
+		nil,
+		nil,
+		nil,
+		opts...,
+	)
+}
+
+func TestStreamOnAction(t *testing.T) {
+	tests := []struct {
+		name     string
+		stream   func() *Stream[[]int]
+		expected []ActionEvent
+	}{
+		{
+			name: "does not report upstream closed",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(
+					AppendFlowToSource(testSliceSource([]int{1, 2, 3}), testPassFlow(WithFlowName("pass"))),
+					testSliceSink[int](),
+				)
+			},
+		},
+		{
+			name: "reports stop of flow",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(
+					AppendFlowToSource(testSliceSource([]int{1, 2, 3}), testActionFlow(2, ActionStop, WithFlowName("stop"))),
+					testSliceSink[int](),
+				)
+			},
+			expected: []ActionEvent{{Stage: "stop", Kind: StageKindFlow, Action: ActionStop}},
+		},
+		{
+			name: "reports complete of flow",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(
+					AppendFlowToSource(testSliceSource([]int{1, 2, 3}), testActionFlow(3, ActionComplete)),
+					testSliceSink[int](),
+				)
+			},
+			expected: []ActionEvent{{Kind: StageKindFlow, Action: ActionComplete}},
+		},
+		{
+			name: "reports stop of synchronous flow",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(
+					AppendFlowToSource(testSliceSource([]int{1, 2, 3}), ConnectFlows(
+						testSyncMap(func(i int) int { return i }, WithFlowName("map")),
+						testSyncTake(1),
+					)),
+					testSliceSink[int](),
+				)
+			},
+			// The fused map stage proceeds, only the take stage returns ActionStop
+			expected: []ActionEvent{{Kind: StageKindFlow, Action: ActionStop}},
+		},
+		{
+			name: "reports stop of sink",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(
+					testSliceSource([]int{1, 2, 3}),
+					NewSink(
+						[]int{},
+						func(ctx context.Context, in int, acc Item[[]int]) (Item[[]int], StreamAction) {
+							return Item[[]int]{Value: append(acc.Value, in)}, ActionStop
+						},
+						nil,
+						nil,
+						WithSinkName("first"),
+					),
+				)
+			},
+			expected: []ActionEvent{{Stage: "first", Kind: StageKindSink, Action: ActionStop}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := tt.stream()
+
+			var mu sync.Mutex
+			var events []ActionEvent
+			stream.OnAction(func(ctx context.Context, event ActionEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				events = append(events, event)
+			})
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			assert.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, events)
+		})
+	}
+}
+
+func TestStreamActionString(t *testing.T) {
+	tests := []struct {
+		action   StreamAction
+		expected string
+	}{
+		{action: ActionProceed, expected: "proceed"},
+		{action: ActionStop, expected: "stop"},
+		{action: ActionCancel, expected: "cancel"},
+		{action: ActionComplete, expected: "complete"},
+		{action: ActionRestartUpstream, expected: "restartUpstream"},
+		{action: StreamAction(9), expected: "StreamAction(9)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.action.String())
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"sync"
+)
+
+// Attributes is an immutable set of values configuring the stages of a pipeline, such as
+// their buffer size or name. Attributes can be attached to a single stage using
+// WithSourceAttributes, WithFlowAttributes or WithSinkAttributes, to a composed segment of
+// stages using SourceWithAttributes, FlowWithAttributes or SinkWithAttributes, or to a whole
+// stream using ContextWithAttributes.
+//
+// Stages inherit the attributes of the segments they are part of and of the stream they
+// run in. Attributes attached closer to a stage take precedence: a stage's own attributes
+// override those of its segment, which override those of enclosing segments and the stream.
+//
+// The zero value is an empty set of attributes.
+type Attributes struct {
+	values map[any]any
+}
+
+// AttributeKey identifies an attribute holding a value of type T.
+// Keys are compared by identity, so every key should be created once and reused.
+//
+// Type Parameters:
+//   - T: The type of the attribute value
+type AttributeKey[T any] struct {
+	name string
+}
+
+// NewAttributeKey creates a new key for an attribute holding a value of type T.
+//
+// Parameters:
+//   - name: Name of the attribute, used for diagnostics
+//
+// Returns a key that can be used with SetAttribute and GetAttribute
+func NewAttributeKey[T any](name string) *AttributeKey[T] {
+	return &AttributeKey[T]{name: name}
+}
+
+// String returns the name of the attribute.
+func (k *AttributeKey[T]) String() string {
+	return k.name
+}
+
+var (
+	// BufSizeKey is the attribute holding the buffer size of a stage's output channel.
+	BufSizeKey = NewAttributeKey[int]("bufSize")
+
+	// NameKey is the attribute holding the name of a stage, see WithFlowName.
+	NameKey = NewAttributeKey[string]("name")
+
+	// LogLevelKey is the attribute holding the level stages log at.
+	LogLevelKey = NewAttributeKey[slog.Level]("logLevel")
+
+	// ParallelismKey is the attribute holding the number of items a stage processes
+	// concurrently. It is set by parallel stages such as MapPar to describe them.
+	ParallelismKey = NewAttributeKey[int]("parallelism")
+)
+
+// BufSize creates Attributes setting the buffer size of a stage's output channel.
+func BufSize(size int) Attributes {
+	return SetAttribute(Attributes{}, BufSizeKey, size)
+}
+
+// Name creates Attributes setting the name of a stage. When attached to a segment,
+// all unnamed stages of the segment are named after it.
+func Name(name string) Attributes {
+	return SetAttribute(Attributes{}, NameKey, name)
+}
+
+// LogLevel creates Attributes setting the level stages log at.
+func LogLevel(level slog.Level) Attributes {
+	return SetAttribute(Attributes{}, LogLevelKey, level)
+}
+
+// Parallelism creates Attributes describing the number of items a stage processes concurrently.
+func Parallelism(parallelism int) Attributes {
+	return SetAttribute(Attributes{}, ParallelismKey, parallelism)
+}
+
+// SetAttribute returns a copy of the attributes with the given attribute set to value.
+//
+// Type Parameters:
+//   - T: The type of the attribute value
+//
+// Parameters:
+//   - attrs: The attributes to copy
+//   - key: The key of the attribute to set
+//   - value: The value of the attribute
+//
+// Returns the new Attributes
+func SetAttribute[T any](attrs Attributes, key *AttributeKey[T], value T) Attributes {
+	values := make(map[any]any, len(attrs.values)+1)
+	maps.Copy(values, attrs.values)
+	values[key] = value
+	return Attributes{values: values}
+}
+
+// GetAttribute returns the value of the given attribute and whether it is set.
+//
+// Type Parameters:
+//   - T: The type of the attribute value
+//
+// Parameters:
+//   - attrs: The attributes to read from
+//   - key: The key of the attribute to read
+//
+// Returns the value of the attribute, or the zero value of T if it is not set, and whether it is set
+func GetAttribute[T any](attrs Attributes, key *AttributeKey[T]) (T, bool) {
+	value, ok := attrs.values[key].(T)
+	return value, ok
+}
+
+// And returns the union of both sets of attributes. Attributes set in other take
+// precedence over attributes set in a.
+func (a Attributes) And(other Attributes) Attributes {
+	if len(other.values) == 0 {
+		return a
+	}
+	if len(a.values) == 0 {
+		return other
+	}
+	values := make(map[any]any, len(a.values)+len(other.values))
+	maps.Copy(values, a.values)
+	maps.Copy(values, other.values)
+	return Attributes{values: values}
+}
+
+// attributesKey is the context key under which inherited Attributes are stored.
+type attributesKey struct{}
+
+// ContextWithAttributes returns a context carrying the given attributes. When a stream is
+// run with this context, all its stages inherit the attributes. Attributes already present
+// in the context are kept unless overridden.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - attrs: The attributes to attach
+//
+// Returns a context that can be passed to Stream.Run
+func ContextWithAttributes(ctx context.Context, attrs Attributes) context.Context {
+	return context.WithValue(ctx, attributesKey{}, AttributesFromContext(ctx).And(attrs))
+}
+
+// AttributesFromContext returns the attributes in effect for the stage the context was
+// passed to. Custom stages can use this to read attributes such as LogLevelKey.
+func AttributesFromContext(ctx context.Context) Attributes {
+	attrs, _ := ctx.Value(attributesKey{}).(Attributes)
+	return attrs
+}
+
+// withStageAttributes resolves the attributes of a stage by applying its own attributes
+// on top of the inherited ones. It returns a context carrying the resolved attributes
+// together with the resolved attributes themselves.
+func withStageAttributes(ctx context.Context, own Attributes) (context.Context, Attributes) {
+	attrs := AttributesFromContext(ctx).And(own)
+	return context.WithValue(ctx, attributesKey{}, attrs), attrs
+}
+
+// namedStages returns a copy of stages where the segment attributes are attached to every
+// stage, and unnamed stages are named after the segment if it sets a name.
+func namedStages(stages []stageDesc, attrs Attributes) []stageDesc {
+	stages = stagesOf(stages)
+	name, named := GetAttribute(attrs, NameKey)
+	for i := range stages {
+		if named && stages[i].Name == "" {
+			stages[i].Name = name
+		}
+		stages[i].attrs = attrs.And(stages[i].attrs)
+	}
+	return stages
+}
+
+// SourceWithAttributes attaches attributes to all stages of a source, which may be composed
+// of multiple stages. Attributes set by the stages themselves take precedence.
+//
+// Type Parameters:
+//   - O: The type of items produced by the source
+//
+// Parameters:
+//   - source: The source to attach the attributes to
+//   - attrs: The attributes to attach
+//
+// Returns a new Source with the attributes attached
+func SourceWithAttributes[O any](source *Source[O], attrs Attributes) *Source[O] {
+	return &Source[O]{
+		setup: func(
+			ctx context.Context,
+			cancel context.CancelFunc,
+			wg *sync.WaitGroup,
+			complete <-chan struct{},
+		) <-chan Item[O] {
+			return source.setup(ContextWithAttributes(ctx, attrs), cancel, wg, complete)
+		},
+		stages:    namedStages(source.stages, attrs),
+		preflight: source.preflight,
+	}
+}
+
+// FlowWithAttributes attaches attributes to all stages of a flow, which may be composed
+// of multiple stages. Attributes set by the stages themselves take precedence. Stages
+// upstream of the flow do not inherit the attributes.
+//
+// Type Parameters:
+//   - I: The type of input items
+//   - O: The type of output items
+//
+// Parameters:
+//   - flow: The flow to attach the attributes to
+//   - attrs: The attributes to attach
+//
+// Returns a new Flow with the attributes attached
+func FlowWithAttributes[I, O any](flow *Flow[I, O], attrs Attributes) *Flow[I, O] {
+	return &Flow[I, O]{
+		setup: func(
+			ctx context.Context,
+			cancel context.CancelFunc,
+			wg *sync.WaitGroup,
+			complete <-chan struct{},
+			setupUpstream setupFunc[I],
+		) <-chan Item[O] {
+			outer := AttributesFromContext(ctx)
+			return flow.setup(
+				ContextWithAttributes(ctx, attrs),
+				cancel,
+				wg,
+				complete,
+				func(ctx context.Context, cancel context.CancelFunc, wg *sync.WaitGroup, complete <-chan struct{}) <-chan Item[I] {
+					return setupUpstream(context.WithValue(ctx, attributesKey{}, outer), cancel, wg, complete)
+				},
+			)
+		},
+		stages:    namedStages(flow.stages, attrs),
+		preflight: flow.preflight,
+	}
+}
+
+// SinkWithAttributes attaches attributes to all stages of a sink, which may be composed
+// of multiple stages. Attributes set by the stages themselves take precedence. Stages
+// upstream of the sink do not inherit the attributes.
+//
+// Type Parameters:
+//   - I: The type of items consumed by the sink
+//   - R: The type of the final result
+//
+// Parameters:
+//   - sink: The sink to attach the attributes to
+//   - attrs: The attributes to attach
+//
+// Returns a new Sink with the attributes attached
+func SinkWithAttributes[I, R any](sink *Sink[I, R], attrs Attributes) *Sink[I, R] {
+	return &Sink[I, R]{
+		setup: func(
+			ctx context.Context,
+			cancel context.CancelFunc,
+			wg *sync.WaitGroup,
+			complete <-chan struct{},
+			setupUpstream setupFunc[I],
+		) <-chan Item[R] {
+			outer := AttributesFromContext(ctx)
+			return sink.setup(
+				ContextWithAttributes(ctx, attrs),
+				cancel,
+				wg,
+				complete,
+				func(ctx context.Context, cancel context.CancelFunc, wg *sync.WaitGroup, complete <-chan struct{}) <-chan Item[I] {
+					return setupUpstream(context.WithValue(ctx, attributesKey{}, outer), cancel, wg, complete)
+				},
+			)
+		},
+		stages:    namedStages(sink.stages, attrs),
+		preflight: sink.preflight,
+	}
+}
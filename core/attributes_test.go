@@ -0,0 +1,191 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributes(t *testing.T) {
+	key := NewAttributeKey[string]("custom")
+
+	attrs := BufSize(4).And(Name("first"))
+	withCustom := SetAttribute(attrs, key, "value")
+	overridden := withCustom.And(Name("second"))
+
+	size, ok := GetAttribute(attrs, BufSizeKey)
+	assert.True(t, ok)
+	assert.Equal(t, 4, size)
+
+	_, ok = GetAttribute(attrs, key)
+	assert.False(t, ok, "SetAttribute should not modify the original attributes")
+
+	custom, ok := GetAttribute(withCustom, key)
+	assert.True(t, ok)
+	assert.Equal(t, "value", custom)
+
+	name, _ := GetAttribute(overridden, NameKey)
+	assert.Equal(t, "second", name)
+	name, _ = GetAttribute(withCustom, NameKey)
+	assert.Equal(t, "first", name)
+
+	_, ok = GetAttribute(Attributes{}, LogLevelKey)
+	assert.False(t, ok)
+	assert.Equal(t, "custom", key.String())
+}
+
+// testLogLevelFlow creates a Flow emitting the log level in effect for it for every element,
+// or -1 if no log level is set.
+func testLogLevelFlow(opts ...FlowOption) *Flow[int, int] {
+	return NewFlow(
+		func(ctx context.Context, elem int, out chan<- Item[int]) StreamAction {
+			level, ok := GetAttribute(AttributesFromContext(ctx), LogLevelKey)
+			if !ok {
+				level = -1
+			}
+			out <- Item[int]{Value: int(level)}
+			return ActionProceed
+		},
+		nil,
+		nil,
+		nil,
+		opts...,
+	)
+}
+
+func TestAttributeInheritance(t *testing.T) {
+	debug := int(slog.LevelDebug)
+	warn := int(slog.LevelWarn)
+
+	tests := []struct {
+		name     string
+		ctx      func() context.Context
+		flow     func() *Flow[int, int]
+		expected []int
+	}{
+		{
+			name:     "no attributes",
+			ctx:      context.Background,
+			flow:     func() *Flow[int, int] { return testLogLevelFlow() },
+			expected: []int{-1},
+		},
+		{
+			name: "stage attributes",
+			ctx:  context.Background,
+			flow: func() *Flow[int, int] {
+				return testLogLevelFlow(WithFlowAttributes(LogLevel(slog.LevelDebug)))
+			},
+			expected: []int{debug},
+		},
+		{
+			name: "inherited from segment",
+			ctx:  context.Background,
+			flow: func() *Flow[int, int] {
+				return FlowWithAttributes(
+					ConnectFlows(testPassFlow(), testLogLevelFlow()),
+					LogLevel(slog.LevelDebug),
+				)
+			},
+			expected: []int{debug},
+		},
+		{
+			name: "inherited from stream",
+			ctx: func() context.Context {
+				return ContextWithAttributes(context.Background(), LogLevel(slog.LevelWarn))
+			},
+			flow:     func() *Flow[int, int] { return testLogLevelFlow() },
+			expected: []int{warn},
+		},
+		{
+			name: "segment overrides stream",
+			ctx: func() context.Context {
+				return ContextWithAttributes(context.Background(), LogLevel(slog.LevelWarn))
+			},
+			flow: func() *Flow[int, int] {
+				return FlowWithAttributes(testLogLevelFlow(), LogLevel(slog.LevelDebug))
+			},
+			expected: []int{debug},
+		},
+		{
+			name: "stage overrides segment",
+			ctx:  context.Background,
+			flow: func() *Flow[int, int] {
+				return FlowWithAttributes(
+					testLogLevelFlow(WithFlowAttributes(LogLevel(slog.LevelWarn))),
+					LogLevel(slog.LevelDebug),
+				)
+			},
+			expected: []int{warn},
+		},
+		{
+			name: "upstream stages do not inherit segment attributes",
+			ctx:  context.Background,
+			flow: func() *Flow[int, int] {
+				return ConnectFlows(
+					testLogLevelFlow(),
+					FlowWithAttributes(testPassFlow(), LogLevel(slog.LevelDebug)),
+				)
+			},
+			expected: []int{-1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := ConnectSourceToSink(
+				AppendFlowToSource(testSliceSource([]int{1}), tt.flow()),
+				testSliceSink[int](),
+			)
+
+			result := <-stream.Run(tt.ctx())
+			stream.AwaitDone()
+
+			assert.NoError(t, result.Err)
+			assert.Equal(t, tt.expected, result.Value)
+		})
+	}
+}
+
+func TestNamedSegment(t *testing.T) {
+	testErr := errors.New("test error")
+
+	segment := FlowWithAttributes(
+		ConnectFlows(testPassFlow(WithFlowName("inner")), testFailingFlow(testErr)),
+		Name("segment"),
+	)
+	stream := ConnectSourceToSink(
+		AppendFlowToSource(testSliceSource([]int{1}), segment),
+		SinkWithAttributes(testSliceSink[int](), BufSize(2)),
+	)
+
+	assert.Equal(t, []StageInfo{
+		{Kind: StageKindSource},
+		{Kind: StageKindFlow, Name: "inner"},
+		{Kind: StageKindFlow, Name: "segment"},
+		{Kind: StageKindSink},
+	}, stream.Stages())
+
+	result := <-stream.Run(context.Background())
+	stream.AwaitDone()
+
+	var stageErr *StageError
+	assert.ErrorAs(t, result.Err, &stageErr)
+	assert.Equal(t, "segment", stageErr.Stage)
+}
+
+func TestSourceWithAttributes(t *testing.T) {
+	source := SourceWithAttributes(
+		AppendFlowToSource(testSliceSource([]int{1, 2}), testLogLevelFlow()),
+		LogLevel(slog.LevelDebug),
+	)
+	stream := ConnectSourceToSink(source, testSliceSink[int]())
+
+	result := <-stream.Run(context.Background())
+	stream.AwaitDone()
+
+	assert.NoError(t, result.Err)
+	assert.Equal(t, []int{int(slog.LevelDebug), int(slog.LevelDebug)}, result.Value)
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+)
+
+// AwaitAll runs the given streams concurrently and waits for all of them to finish.
+// If any stream fails, all other streams are cancelled and the first error is returned.
+// AwaitAll only returns once all goroutines of all streams have completed.
+//
+// Type Parameters:
+//   - R: The type of the result produced by the streams
+//
+// Parameters:
+//   - ctx: Context controlling the lifecycle of all streams
+//   - streams: The streams to run
+//
+// Returns the results of all streams, in the order the streams were given, or the first
+// error produced by any stream
+func AwaitAll[R any](ctx context.Context, streams ...*Stream[R]) ([]R, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		idx  int
+		item Item[R]
+	}
+
+	results := make(chan result, len(streams))
+	for i, stream := range streams {
+		res := stream.Run(ctx)
+		go func() {
+			results <- result{idx: i, item: <-res}
+		}()
+	}
+
+	values := make([]R, len(streams))
+	var firstErr error
+	for range streams {
+		r := <-results
+		if r.item.Err != nil && firstErr == nil {
+			// The remaining streams are cancelled and report the context error
+			firstErr = r.item.Err
+			cancel()
+		}
+		values[r.idx] = r.item.Value
+	}
+
+	for _, stream := range streams {
+		stream.AwaitDone()
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return values, nil
+}
+
+// Combine runs the given streams concurrently like AwaitAll and combines their results
+// into a single value once all of them have completed successfully.
+//
+// Type Parameters:
+//   - R: The type of the result produced by the streams
+//   - C: The type of the combined result
+//
+// Parameters:
+//   - ctx: Context controlling the lifecycle of all streams
+//   - combine: Function combining the results of all streams, in the order the streams were given
+//   - streams: The streams to run
+//
+// Returns the combined result, or the first error produced by any stream
+func Combine[R, C any](ctx context.Context, combine func([]R) C, streams ...*Stream[R]) (C, error) {
+	values, err := AwaitAll(ctx, streams...)
+	if err != nil {
+		var zero C
+		return zero, err
+	}
+	return combine(values), nil
+}
+
+// RunAndWait runs the stream and blocks until it has finished, returning its result. Unlike
+// Run, it does not return a channel, which makes it convenient for simple callers. RunAndWait
+// only returns once all goroutines of the stream have completed.
+//
+// Parameters:
+//   - ctx: Context used to control the stream's lifecycle and cancellation
+//
+// Returns the result of the stream, or the error it terminated with
+func (s *Stream[R]) RunAndWait(ctx context.Context) (R, error) {
+	res := <-s.Run(ctx)
+	s.AwaitDone()
+	return res.Value, res.Err
+}
+
+// Task returns a function running the stream with RunAndWait, matching the signature of
+// errgroup.Group.Go. The result of the stream is stored in result once it has completed
+// successfully, and the function returns the error the stream terminated with.
+//
+// Parameters:
+//   - ctx: Context used to control the stream's lifecycle and cancellation
+//   - result: Where the result of the stream is stored, or nil to discard it
+//
+// Returns a function running the stream to completion
+func (s *Stream[R]) Task(ctx context.Context, result *R) func() error {
+	return func() error {
+		value, err := s.RunAndWait(ctx)
+		if err != nil {
+			return err
+		}
+		if result != nil {
+			*result = value
+		}
+		return nil
+	}
+}
+
+// RunForEach runs the source, calling fn for every item it produces, and blocks until the
+// source is exhausted. If fn returns an error, the stream stops and the error is returned.
+// RunForEach only returns once all goroutines of the stream have completed.
+//
+// Type Parameters:
+//   - T: The type of items produced by the source
+//
+// Parameters:
+//   - ctx: Context used to control the stream's lifecycle and cancellation
+//   - source: The source to consume
+//   - fn: Function called for every item
+//
+// Returns the first error returned by fn or produced by the source, or nil
+func RunForEach[T any](ctx context.Context, source *Source[T], fn func(ctx context.Context, item T) error) error {
+	sink := NewSink(
+		struct{}{},
+		func(ctx context.Context, in T, acc Item[struct{}]) (Item[struct{}], StreamAction) {
+			if err := fn(ctx, in); err != nil {
+				return Item[struct{}]{Err: err}, ActionStop
+			}
+			return acc, ActionProceed
+		},
+		nil,
+		nil,
+	)
+	_, err := ConnectSourceToSink(source, sink).RunAndWait(ctx)
+	return err
+}
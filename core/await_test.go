@@ -0,0 +1,197 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestAwaitAll(t *testing.T) {
+	testErr := errors.New("test error")
+
+	tests := []struct {
+		name        string
+		streams     func() []*Stream[[]int]
+		expected    [][]int
+		expectedErr error
+	}{
+		{
+			name: "returns results in order of streams",
+			streams: func() []*Stream[[]int] {
+				return []*Stream[[]int]{
+					ConnectSourceToSink(testSliceSource([]int{1, 2}), testSliceSink[int]()),
+					ConnectSourceToSink(testSliceSource([]int{3}), testSliceSink[int]()),
+					ConnectSourceToSink(testSliceSource([]int{}), testSliceSink[int]()),
+				}
+			},
+			expected: [][]int{{1, 2}, {3}, {}},
+		},
+		{
+			name: "no streams",
+			streams: func() []*Stream[[]int] {
+				return nil
+			},
+			expected: [][]int{},
+		},
+		{
+			name: "first error cancels remaining streams",
+			streams: func() []*Stream[[]int] {
+				return []*Stream[[]int]{
+					ConnectSourceToSink(testRepeatSource(1), testSliceSink[int]()),
+					ConnectSourceToSink(testErrSource(testErr), testSliceSink[int]()),
+				}
+			},
+			expectedErr: testErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := AwaitAll(context.Background(), tt.streams()...)
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			if tt.expectedErr == nil {
+				assert.Equal(t, tt.expected, res)
+			}
+		})
+	}
+}
+
+func TestCombine(t *testing.T) {
+	sum := func(results []int) int {
+		total := 0
+		for _, r := range results {
+			total += r
+		}
+		return total
+	}
+	count := func() *Sink[int, int] {
+		return NewSink(
+			0,
+			func(ctx context.Context, in int, acc Item[int]) (Item[int], StreamAction) {
+				return Item[int]{Value: acc.Value + 1}, ActionProceed
+			},
+			nil,
+			nil,
+		)
+	}
+
+	res, err := Combine(
+		context.Background(),
+		sum,
+		ConnectSourceToSink(testSliceSource([]int{1, 2, 3}), count()),
+		ConnectSourceToSink(testSliceSource([]int{4, 5}), count()),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, res)
+}
+
+func TestRunAndWait(t *testing.T) {
+	testErr := errors.New("test error")
+
+	tests := []struct {
+		name        string
+		source      *Source[int]
+		expected    []int
+		expectedErr error
+	}{
+		{
+			name:     "returns result of completed stream",
+			source:   testSliceSource([]int{1, 2, 3}),
+			expected: []int{1, 2, 3},
+		},
+		{
+			name:        "returns error of failed stream",
+			source:      testErrSource(testErr),
+			expected:    []int{},
+			expectedErr: testErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ConnectSourceToSink(tt.source, testSliceSink[int]()).RunAndWait(context.Background())
+
+			assert.Equal(t, tt.expected, res)
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestStreamTask(t *testing.T) {
+	testErr := errors.New("test error")
+
+	var first, second []int
+	g := errgroup.Group{}
+	g.Go(ConnectSourceToSink(testSliceSource([]int{1, 2}), testSliceSink[int]()).Task(context.Background(), &first))
+	g.Go(ConnectSourceToSink(testSliceSource([]int{3}), testSliceSink[int]()).Task(context.Background(), &second))
+	g.Go(ConnectSourceToSink(testErrSource(testErr), testSliceSink[int]()).Task(context.Background(), nil))
+
+	assert.ErrorIs(t, g.Wait(), testErr)
+	assert.Equal(t, []int{1, 2}, first)
+	assert.Equal(t, []int{3}, second)
+}
+
+func TestRunForEach(t *testing.T) {
+	testErr := errors.New("test error")
+
+	tests := []struct {
+		name        string
+		source      *Source[int]
+		fn          func(seen *[]int) func(ctx context.Context, item int) error
+		expected    []int
+		expectedErr error
+	}{
+		{
+			name:   "calls fn for every item",
+			source: testSliceSource([]int{1, 2, 3}),
+			fn: func(seen *[]int) func(ctx context.Context, item int) error {
+				return func(ctx context.Context, item int) error {
+					*seen = append(*seen, item)
+					return nil
+				}
+			},
+			expected: []int{1, 2, 3},
+		},
+		{
+			name:   "stops when fn fails",
+			source: testSliceSource([]int{1, 2, 3}),
+			fn: func(seen *[]int) func(ctx context.Context, item int) error {
+				return func(ctx context.Context, item int) error {
+					if item == 2 {
+						return testErr
+					}
+					*seen = append(*seen, item)
+					return nil
+				}
+			},
+			expected:    []int{1},
+			expectedErr: testErr,
+		},
+		{
+			name:   "returns error of source",
+			source: testErrSource(testErr),
+			fn: func(seen *[]int) func(ctx context.Context, item int) error {
+				return func(ctx context.Context, item int) error {
+					*seen = append(*seen, item)
+					return nil
+				}
+			},
+			expectedErr: testErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen []int
+			err := RunForEach(context.Background(), tt.source, tt.fn(&seen))
+
+			assert.Equal(t, tt.expected, seen)
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrDeploymentUnhealthy is returned by BlueGreen.Deploy when a new version fails its
+// preflight or health checks. The previous version keeps receiving items in that case.
+var ErrDeploymentUnhealthy = errors.New("deployment unhealthy")
+
+// blueGreenVersion is a deployed version of a pipeline.
+type blueGreenVersion[T, R any] struct {
+	name   string
+	hub    *MergeHub[T]
+	stream *Stream[R]
+	res    <-chan Item[R]
+}
+
+// Deployment is a version of a pipeline deployed through BlueGreen.
+//
+// Type Parameters:
+//   - R: The type of the result produced by the pipeline
+type Deployment[R any] struct {
+	// Version identifies the deployed pipeline
+	Version string
+
+	// Stream is the running stream of the version
+	Stream *Stream[R]
+
+	// Result receives the result of the version once it has been drained or cancelled
+	Result <-chan Item[R]
+}
+
+// BlueGreen enables zero-downtime upgrades of a pipeline within a process. A long-lived
+// upstream, such as a queue source, runs into the Sink of a BlueGreen, which routes every
+// item to the active version of the downstream pipeline. Deploy starts a new version
+// alongside the active one, switches items over to it atomically once it is healthy and
+// then drains the old version, so that no item is lost or processed twice.
+//
+// BlueGreen implements Drainable, draining the active version, so it can be shut down
+// through a ShutdownCoordinator.
+//
+// Type Parameters:
+//   - T: The type of items routed to the pipeline
+//   - R: The type of the result produced by each version of the pipeline
+type BlueGreen[T, R any] struct {
+	bufSize int
+
+	mu        sync.RWMutex
+	active    *blueGreenVersion[T, R]
+	ready     chan struct{}
+	readyOnce sync.Once
+}
+
+// NewBlueGreen creates a BlueGreen without any deployed version. Items sent to its Sink
+// are held back until the first version is deployed.
+//
+// Type Parameters:
+//   - T: The type of items routed to the pipeline
+//   - R: The type of the result produced by each version of the pipeline
+//
+// Parameters:
+//   - bufSize: Number of items buffered in front of each version
+//
+// Returns a BlueGreen to which versions can be deployed
+func NewBlueGreen[T, R any](bufSize int) *BlueGreen[T, R] {
+	return &BlueGreen[T, R]{
+		bufSize: bufSize,
+		ready:   make(chan struct{}),
+	}
+}
+
+// Sink returns a Sink routing all items it receives to the active version. The upstream
+// of the pipeline should run into this Sink once, independently of the deployed versions.
+// The Sink fails with ErrHubClosed once the BlueGreen has been drained or cancelled.
+//
+// Parameters:
+//   - opts: Optional SinkOption functions to configure the sink
+//
+// Returns a Sink feeding into the active version
+func (b *BlueGreen[T, R]) Sink(opts ...SinkOption) *Sink[T, NotUsed] {
+	return NewSink(
+		NotUsed{},
+		func(ctx context.Context, elem T, acc Item[NotUsed]) (Item[NotUsed], StreamAction) {
+			if err := b.route(ctx, elem); err != nil {
+				return Item[NotUsed]{Err: err}, ActionStop
+			}
+			return acc, ActionProceed
+		},
+		nil,
+		nil,
+		opts...,
+	)
+}
+
+// route sends an item to the active version, following a swap if the version it sent to
+// was closed in the meantime.
+func (b *BlueGreen[T, R]) route(ctx context.Context, elem T) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.ready:
+	}
+
+	var last *blueGreenVersion[T, R]
+	for {
+		v := b.current()
+		if v == last {
+			// The active version was closed without being replaced
+			return ErrHubClosed
+		}
+		err := v.hub.push(ctx, elem)
+		if !errors.Is(err, ErrHubClosed) {
+			return err
+		}
+		last = v
+	}
+}
+
+// current returns the active version.
+func (b *BlueGreen[T, R]) current() *blueGreenVersion[T, R] {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.active
+}
+
+// Deploy starts a new version of the pipeline and switches all items over to it once it
+// is healthy. The new version first has to pass the preflight checks of its stages and
+// then the given health check, if any. If it does, it becomes the active version and the
+// previous version is drained, processing all items it already accepted. Otherwise, the
+// new version is cancelled and the previous version stays active.
+//
+// Parameters:
+//   - ctx: Context controlling the lifetime of the new version
+//   - version: Name identifying the new version
+//   - pipeline: The Sink processing items in the new version, typically a chain of flows
+//     ending in a sink
+//   - healthy: Optional function returning an error if the new version is not healthy
+//
+// Returns the Deployment of the new version, or an error wrapping ErrDeploymentUnhealthy
+func (b *BlueGreen[T, R]) Deploy(
+	ctx context.Context,
+	version string,
+	pipeline *Sink[T, R],
+	healthy func(ctx context.Context) error,
+) (Deployment[R], error) {
+	hub := NewMergeHub[T](b.bufSize)
+	stream := ConnectSourceToSink(hub.Source(), pipeline)
+
+	if err := stream.Preflight(ctx); err != nil {
+		return Deployment[R]{}, fmt.Errorf("%w: version %s: %w", ErrDeploymentUnhealthy, version, err)
+	}
+
+	next := &blueGreenVersion[T, R]{
+		name:   version,
+		hub:    hub,
+		stream: stream,
+		res:    stream.Run(ctx),
+	}
+
+	if healthy != nil {
+		if err := healthy(ctx); err != nil {
+			stream.Cancel()
+			stream.AwaitDone()
+			return Deployment[R]{}, fmt.Errorf("%w: version %s: %w", ErrDeploymentUnhealthy, version, err)
+		}
+	}
+
+	b.mu.Lock()
+	prev := b.active
+	b.active = next
+	b.mu.Unlock()
+	b.readyOnce.Do(func() {
+		close(b.ready)
+	})
+
+	if prev != nil {
+		prev.stream.Drain()
+		prev.stream.AwaitDone()
+	}
+
+	return Deployment[R]{Version: version, Stream: stream, Result: next.res}, nil
+}
+
+// Active returns the name of the active version, or an empty string if no version is deployed.
+func (b *BlueGreen[T, R]) Active() string {
+	if v := b.current(); v != nil {
+		return v.name
+	}
+	return ""
+}
+
+// Drain drains the active version, processing all items it already accepted. Items sent
+// to the Sink afterwards are rejected with ErrHubClosed.
+func (b *BlueGreen[T, R]) Drain() {
+	if v := b.current(); v != nil {
+		v.stream.Drain()
+	}
+}
+
+// Cancel cancels the active version immediately.
+func (b *BlueGreen[T, R]) Cancel() {
+	if v := b.current(); v != nil {
+		v.stream.Cancel()
+	}
+}
+
+// AwaitDone blocks until the active version has finished.
+func (b *BlueGreen[T, R]) AwaitDone() {
+	if v := b.current(); v != nil {
+		v.stream.AwaitDone()
+	}
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testChanSource creates a Source emitting all items sent on the given channel until it is closed.
+func testChanSource[T any](items <-chan T) *Source[T] {
+	return NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan Item[T] {
+			out := make(chan Item[T])
+			go func() {
+				defer close(out)
+				for item := range items {
+					select {
+					case <-ctx.Done():
+						return
+					case out <- Item[T]{Value: item}:
+					}
+				}
+			}()
+			return out
+		},
+	)
+}
+
+func TestBlueGreenSwapsVersions(t *testing.T) {
+	ctx := context.Background()
+	bg := NewBlueGreen[int, []int](1)
+
+	blue, err := bg.Deploy(ctx, "blue", testSliceSink[int](), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "blue", bg.Active())
+
+	items := make(chan int)
+	upstream := ConnectSourceToSink(testChanSource(items), bg.Sink())
+	upstreamRes := upstream.Run(ctx)
+
+	for i := 1; i <= 3; i++ {
+		items <- i
+	}
+
+	green, err := bg.Deploy(ctx, "green", testSliceSink[int](), func(ctx context.Context) error {
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "green", bg.Active())
+
+	// The old version was drained as part of the swap
+	blueRes := <-blue.Result
+	assert.NoError(t, blueRes.Err)
+
+	for i := 4; i <= 5; i++ {
+		items <- i
+	}
+	close(items)
+	assert.NoError(t, (<-upstreamRes).Err)
+	upstream.AwaitDone()
+
+	bg.Drain()
+	greenRes := <-green.Result
+	bg.AwaitDone()
+
+	assert.NoError(t, greenRes.Err)
+	assert.Subset(t, greenRes.Value, []int{4, 5})
+	assert.ElementsMatch(t, []int{1, 2, 3, 4, 5}, append(blueRes.Value, greenRes.Value...))
+}
+
+func TestBlueGreenKeepsActiveVersionWhenUnhealthy(t *testing.T) {
+	errUnhealthy := errors.New("not ready")
+
+	tests := []struct {
+		name     string
+		pipeline func() *Sink[int, []int]
+		healthy  func(ctx context.Context) error
+		wantErr  error
+	}{
+		{
+			name:     "fails health check",
+			pipeline: testSliceSink[int],
+			healthy: func(ctx context.Context) error {
+				return errUnhealthy
+			},
+			wantErr: errUnhealthy,
+		},
+		{
+			name: "fails preflight",
+			pipeline: func() *Sink[int, []int] {
+				return NewSink[int]([]int{}, nil, nil, nil, WithSinkPreflight("check", func(ctx context.Context) error {
+					return errUnhealthy
+				}))
+			},
+			wantErr: ErrPreflightFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			bg := NewBlueGreen[int, []int](1)
+
+			blue, err := bg.Deploy(ctx, "blue", testSliceSink[int](), nil)
+			require.NoError(t, err)
+
+			_, err = bg.Deploy(ctx, "green", tt.pipeline(), tt.healthy)
+			assert.ErrorIs(t, err, ErrDeploymentUnhealthy)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, "blue", bg.Active())
+
+			upstream := ConnectSourceToSink(testSliceSource([]int{1, 2}), bg.Sink())
+			assert.NoError(t, (<-upstream.Run(ctx)).Err)
+			upstream.AwaitDone()
+
+			bg.Drain()
+			res := <-blue.Result
+			bg.AwaitDone()
+
+			assert.NoError(t, res.Err)
+			assert.Equal(t, []int{1, 2}, res.Value)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// bufferGuardInterval is the interval at which the buffer guard counts the buffered items.
+const bufferGuardInterval = 10 * time.Millisecond
+
+// BufferGuardAction selects how a stream is stopped once it buffers more items than allowed.
+type BufferGuardAction int
+
+const (
+	// BufferGuardCancel cancels the stream, dropping the items in flight.
+	BufferGuardCancel BufferGuardAction = iota
+
+	// BufferGuardDrain drains the stream, processing the items in flight.
+	BufferGuardDrain
+)
+
+// String returns the name of the action.
+func (a BufferGuardAction) String() string {
+	switch a {
+	case BufferGuardCancel:
+		return "cancel"
+	case BufferGuardDrain:
+		return "drain"
+	default:
+		return fmt.Sprintf("BufferGuardAction(%d)", int(a))
+	}
+}
+
+// BufferLimitError is the error a stream fails with when it buffers more items than the limit
+// set with Stream.GuardBuffers.
+type BufferLimitError struct {
+	// Limit is the maximum number of items the stream may buffer
+	Limit int
+
+	// Buffered is the number of items the stream buffered when the limit was exceeded
+	Buffered int
+
+	// Stages holds the status of the stages of the stream, from source to sink
+	Stages []StageStatus
+}
+
+// Error returns a description of the error listing the items buffered by every stage.
+func (e *BufferLimitError) Error() string {
+	stages := make([]string, 0, len(e.Stages))
+	for _, stage := range e.Stages {
+		if stage.Buffered > 0 {
+			stages = append(stages, fmt.Sprintf("%s: %d", stage.StageInfo, stage.Buffered))
+		}
+	}
+	return fmt.Sprintf("stream buffers %d items, exceeding the limit of %d: %s",
+		e.Buffered, e.Limit, strings.Join(stages, ", "))
+}
+
+// GuardBuffers starts a guard whenever the stream is run, which stops the stream once the
+// total number of items buffered between its stages exceeds limit, so a runaway pipeline fails
+// with a descriptive error instead of running out of memory. The stream is cancelled or drained
+// depending on action, and fails with a BufferLimitError listing the items buffered per stage.
+//
+// The buffered items are counted every 10 milliseconds from the output buffers of sources and
+// flows. Items held by stages themselves, such as in a batch, and the buffers of junctions and
+// hubs are not counted. GuardBuffers must be called before the stream is started.
+//
+// Parameters:
+//   - limit: The maximum number of items the stream may buffer
+//   - action: How the stream is stopped once the limit is exceeded
+func (s *Stream[R]) GuardBuffers(limit int, action BufferGuardAction) {
+	s.bufferLimit = limit
+	s.bufferAction = action
+}
+
+// bufferGuardKey is the context key under which the error of the buffer guard of a run is stored.
+type bufferGuardKey struct{}
+
+// bufferGuardError holds the error of the buffer guard once it stopped the stream.
+type bufferGuardError struct {
+	err atomic.Pointer[BufferLimitError]
+}
+
+// bufferLimitErrorOf returns the error of the buffer guard of the run ctx belongs to, or nil
+// if the guard did not stop the stream.
+func bufferLimitErrorOf(ctx context.Context) error {
+	if g, ok := ctx.Value(bufferGuardKey{}).(*bufferGuardError); ok {
+		if err := g.err.Load(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// guardBuffers runs the buffer guard of a stream until ctx is done.
+func guardBuffers(
+	ctx context.Context,
+	cancel context.CancelCauseFunc,
+	complete CompleteFunc,
+	wg *sync.WaitGroup,
+	reg *stageProbes,
+	res *bufferGuardError,
+	limit int,
+	action BufferGuardAction,
+) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(bufferGuardInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				stages := reg.statuses()
+				var buffered int
+				for _, stage := range stages {
+					buffered += stage.Buffered
+				}
+				if buffered <= limit {
+					continue
+				}
+
+				err := &BufferLimitError{Limit: limit, Buffered: buffered, Stages: stages}
+				res.err.Store(err)
+				if action == BufferGuardDrain {
+					complete()
+				} else {
+					cancel(err)
+				}
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuardBuffers(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      func() *Source[int]
+		limit       int
+		action      BufferGuardAction
+		expectedErr bool
+	}{
+		{
+			name:        "cancels stream exceeding limit",
+			source:      func() *Source[int] { return testRepeatSource(1) },
+			limit:       50,
+			action:      BufferGuardCancel,
+			expectedErr: true,
+		},
+		{
+			name:        "drains stream exceeding limit",
+			source:      func() *Source[int] { return testRepeatSource(1) },
+			limit:       50,
+			action:      BufferGuardDrain,
+			expectedErr: true,
+		},
+		{
+			name:   "does not stop stream within limit",
+			source: func() *Source[int] { return testSliceSource(make([]int, 20)) },
+			limit:  1000,
+			action: BufferGuardCancel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := ConnectSourceToSink(
+				AppendFlowToSource(tt.source(), testSyncMap(func(i int) int { return i }, WithFlowBufSize(100), WithFlowName("buffer"))),
+				testSlowSink(time.Millisecond),
+			)
+			stream.GuardBuffers(tt.limit, tt.action)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if !tt.expectedErr {
+				require.NoError(t, res.Err)
+				return
+			}
+			var limitErr *BufferLimitError
+			require.ErrorAs(t, res.Err, &limitErr)
+			assert.Equal(t, tt.limit, limitErr.Limit)
+			assert.Greater(t, limitErr.Buffered, tt.limit)
+			assert.Contains(t, limitErr.Error(), "buffer: ")
+			if tt.action == BufferGuardDrain {
+				// The items in flight were processed, so the sink produced its result
+				assert.Positive(t, res.Value)
+			}
+		})
+	}
+}
+
+func TestBufferGuardActionString(t *testing.T) {
+	assert.Equal(t, "cancel", BufferGuardCancel.String())
+	assert.Equal(t, "drain", BufferGuardDrain.String())
+	assert.Equal(t, "BufferGuardAction(7)", BufferGuardAction(7).String())
+}
+
+func TestBufferLimitErrorMessage(t *testing.T) {
+	err := &BufferLimitError{
+		Limit:    10,
+		Buffered: 12,
+		Stages: []StageStatus{
+			{StageInfo: StageInfo{Kind: StageKindSource, Name: "numbers"}, Buffered: 12},
+			{StageInfo: StageInfo{Kind: StageKindSink, Name: "print"}},
+		},
+	}
+	assert.Equal(t, "stream buffers 12 items, exceeding the limit of 10: numbers: 12", err.Error())
+}
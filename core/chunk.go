@@ -0,0 +1,261 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// ChunkSizeKey is the attribute holding the number of items a stage sends downstream at once.
+var ChunkSizeKey = NewAttributeKey[int]("chunkSize")
+
+// ChunkSize creates Attributes enabling chunked transport: stages exchange slices of up to
+// size items over their channels instead of single items, amortizing the synchronization
+// cost of every channel send. Callbacks of stages still receive individual elements.
+//
+// A chunk is sent once it is full, or as soon as the stage has no further input ready, so
+// chunking does not delay items while upstream is slow. Chunked transport is used between
+// sources or synchronous flows and the stage directly downstream of them, and falls back to
+// single items wherever a stage does not support it, such as for stages with an overflow
+// strategy and junctions.
+func ChunkSize(size int) Attributes {
+	return SetAttribute(Attributes{}, ChunkSizeKey, size)
+}
+
+// chunkRegistryKey is the context key under which a consumer offers to receive chunks.
+type chunkRegistryKey struct{}
+
+// chunkRegistry holds the chunked channels registered by the stages set up by a consumer,
+// keyed by the item channel they return.
+type chunkRegistry struct {
+	mu    sync.Mutex
+	links map[any]any
+}
+
+// chunkLink is the chunked channel of a stage, used instead of its item channel if the
+// consumer claims it before the stage has sent its first item.
+type chunkLink[T any] struct {
+	chunks chan []Item[T]
+
+	// free returns processed chunks to the producer, so their buffers can be reused
+	free chan []Item[T]
+
+	// state is linkUndecided until either the producer or the consumer decides the transport
+	state atomic.Int32
+}
+
+const (
+	linkUndecided int32 = iota
+	linkItems
+	linkChunks
+)
+
+// upstream is the input of a stage, receiving either single items or chunks.
+type upstream[T any] struct {
+	items  <-chan Item[T]
+	chunks <-chan []Item[T]
+	free   chan<- []Item[T]
+	one    [1]Item[T]
+}
+
+// receiveStatus describes the outcome of upstream.receive.
+type receiveStatus int
+
+const (
+	receivedItems receiveStatus = iota
+	upstreamClosed
+	completeSignalled
+	contextDone
+)
+
+// connectUpstream sets up the upstream of a stage, receiving chunks if the upstream stage
+// supports chunked transport.
+func connectUpstream[T any](
+	ctx context.Context,
+	cancel context.CancelFunc,
+	wg *sync.WaitGroup,
+	complete <-chan struct{},
+	setupUpstream setupFunc[T],
+) *upstream[T] {
+	reg := &chunkRegistry{}
+	in := setupUpstream(context.WithValue(ctx, chunkRegistryKey{}, reg), cancel, wg, complete)
+
+	reg.mu.Lock()
+	link, ok := reg.links[in].(*chunkLink[T])
+	reg.mu.Unlock()
+
+	if ok && link.state.CompareAndSwap(linkUndecided, linkChunks) {
+		return &upstream[T]{chunks: link.chunks, free: link.free}
+	}
+	return &upstream[T]{items: in}
+}
+
+// receive waits for the next items from upstream. If idle is not nil, it is called before
+// waiting when no input is ready.
+func (u *upstream[T]) receive(ctx context.Context, complete <-chan struct{}, idle func()) ([]Item[T], receiveStatus) {
+	if idle != nil {
+		select {
+		case <-ctx.Done():
+			return nil, contextDone
+		case <-complete:
+			return nil, completeSignalled
+		case item, ok := <-u.items:
+			return u.single(item, ok)
+		case chunk, ok := <-u.chunks:
+			return u.chunk(chunk, ok)
+		default:
+			idle()
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, contextDone
+	case <-complete:
+		return nil, completeSignalled
+	case item, ok := <-u.items:
+		return u.single(item, ok)
+	case chunk, ok := <-u.chunks:
+		return u.chunk(chunk, ok)
+	}
+}
+
+// release returns items received as a chunk to the producer once they have been processed.
+func (u *upstream[T]) release(items []Item[T]) {
+	if u.free == nil {
+		return
+	}
+	clear(items)
+	select {
+	case u.free <- items[:0]:
+	default:
+	}
+}
+
+func (u *upstream[T]) single(item Item[T], ok bool) ([]Item[T], receiveStatus) {
+	if !ok {
+		return nil, upstreamClosed
+	}
+	u.one[0] = item
+	return u.one[:], receivedItems
+}
+
+func (u *upstream[T]) chunk(chunk []Item[T], ok bool) ([]Item[T], receiveStatus) {
+	if !ok {
+		return nil, upstreamClosed
+	}
+	return chunk, receivedItems
+}
+
+// chunkWriter sends the output of a stage, collecting items into chunks if the consumer
+// claimed chunked transport. It must only be used by a single goroutine.
+type chunkWriter[T any] struct {
+	out     chan Item[T]
+	link    *chunkLink[T]
+	size    int
+	buf     []Item[T]
+	decided bool
+	chunked bool
+}
+
+// newChunkWriter creates the writer for the output channel of a stage, offering chunked
+// transport to its consumer if a chunk size is configured.
+func newChunkWriter[T any](ctx context.Context, attrs Attributes, out chan Item[T]) *chunkWriter[T] {
+	w := &chunkWriter[T]{out: out}
+
+	size, _ := GetAttribute(attrs, ChunkSizeKey)
+	reg, ok := ctx.Value(chunkRegistryKey{}).(*chunkRegistry)
+	if size <= 1 || !ok {
+		return w
+	}
+
+	w.size = size
+	w.link = &chunkLink[T]{
+		chunks: make(chan []Item[T], cap(out)/size),
+		free:   make(chan []Item[T], cap(out)/size+2),
+	}
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if reg.links == nil {
+		reg.links = make(map[any]any)
+	}
+	reg.links[(<-chan Item[T])(out)] = w.link
+	return w
+}
+
+// isChunked decides the transport once the first item is sent.
+func (w *chunkWriter[T]) isChunked() bool {
+	if !w.decided {
+		w.decided = true
+		if w.link != nil && !w.link.state.CompareAndSwap(linkUndecided, linkItems) {
+			w.chunked = true
+			w.buf = w.alloc()
+		}
+	}
+	return w.chunked
+}
+
+// send sends an item downstream, blocking while downstream is backpressured.
+func (w *chunkWriter[T]) send(ctx context.Context, item Item[T]) {
+	if !w.isChunked() {
+		select {
+		case <-ctx.Done():
+		case w.out <- item:
+		}
+		return
+	}
+
+	w.buf = append(w.buf, item)
+	if len(w.buf) >= w.size {
+		w.flush(ctx)
+	}
+}
+
+// alloc returns an empty chunk, reusing a chunk released by the consumer if possible.
+func (w *chunkWriter[T]) alloc() []Item[T] {
+	select {
+	case buf := <-w.link.free:
+		return buf
+	default:
+		return make([]Item[T], 0, w.size)
+	}
+}
+
+// pending returns the channel the collected chunk can be sent on, or nil if no items were
+// collected, so a stage can send the chunk as soon as downstream is ready.
+func (w *chunkWriter[T]) pending() chan<- []Item[T] {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	return w.link.chunks
+}
+
+// sent starts a new chunk after the collected chunk was sent on the pending channel.
+func (w *chunkWriter[T]) sent() {
+	w.buf = w.alloc()
+}
+
+// flush sends the items collected so far as a chunk.
+func (w *chunkWriter[T]) flush(ctx context.Context) {
+	if len(w.buf) == 0 {
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case w.link.chunks <- w.buf:
+	}
+	w.buf = w.alloc()
+}
+
+// close flushes the remaining items and closes the chunked channel, if any. The item
+// channel must be closed by the stage.
+func (w *chunkWriter[T]) close(ctx context.Context) {
+	if w.link == nil {
+		return
+	}
+	if w.isChunked() {
+		w.flush(ctx)
+	}
+	close(w.link.chunks)
+}
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkedTransport(t *testing.T) {
+	testErr := errors.New("test error")
+	input := make([]int, 200)
+	for i := range input {
+		input[i] = i
+	}
+	doubled := make([]int, len(input))
+	for i := range input {
+		doubled[i] = input[i] * 2
+	}
+
+	tests := []struct {
+		name        string
+		source      func() *Source[int]
+		flow        func() *Flow[int, int]
+		expected    []int
+		expectedErr error
+	}{
+		{
+			name:     "synchronous flows exchange chunks",
+			source:   func() *Source[int] { return testSliceSource(input) },
+			flow:     func() *Flow[int, int] { return testSyncMap(func(i int) int { return i * 2 }) },
+			expected: doubled,
+		},
+		{
+			name:     "asynchronous flow receives chunks",
+			source:   func() *Source[int] { return testSliceSource(input) },
+			flow:     func() *Flow[int, int] { return testPassFlow() },
+			expected: input,
+		},
+		{
+			name:   "named stages exchange chunks",
+			source: func() *Source[int] { return testSliceSource(input, WithSourceName("numbers")) },
+			flow: func() *Flow[int, int] {
+				return testSyncMap(func(i int) int { return i * 2 }, WithFlowName("double"))
+			},
+			expected: doubled,
+		},
+		{
+			name:   "buffered stages fall back to single items",
+			source: func() *Source[int] { return testSliceSource(input, WithSourceBuffer(256, OverflowFail)) },
+			flow: func() *Flow[int, int] {
+				return testSyncMap(func(i int) int { return i * 2 }, WithFlowBuffer(256, OverflowFail))
+			},
+			expected: doubled,
+		},
+		{
+			name:   "stop within chunk discards remaining items",
+			source: func() *Source[int] { return testSliceSource(input) },
+			flow: func() *Flow[int, int] {
+				return ConnectFlows(testSyncMap(func(i int) int { return i }), testSyncTake(3))
+			},
+			expected: []int{0, 1, 2},
+		},
+		{
+			name:        "errors are passed within chunks",
+			source:      func() *Source[int] { return testSliceSource(input) },
+			flow:        func() *Flow[int, int] { return testSyncFail(testErr) },
+			expectedErr: testErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := ContextWithAttributes(context.Background(), ChunkSize(16))
+
+			stream := ConnectSourceToSink(AppendFlowToSource(tt.source(), tt.flow()), testSliceSink[int]())
+			res := <-stream.Run(ctx)
+			stream.AwaitDone()
+
+			assert.ErrorIs(t, res.Err, tt.expectedErr)
+			if tt.expectedErr == nil {
+				assert.Equal(t, tt.expected, res.Value)
+			}
+		})
+	}
+}
+
+func TestConnectUpstream(t *testing.T) {
+	tests := []struct {
+		name          string
+		attrs         Attributes
+		source        *Source[int]
+		expectChunked bool
+	}{
+		{
+			name:          "claims chunks of source with chunk size",
+			attrs:         ChunkSize(4),
+			source:        testSliceSource([]int{1, 2, 3, 4, 5}),
+			expectChunked: true,
+		},
+		{
+			name:          "receives items without chunk size",
+			source:        testSliceSource([]int{1, 2, 3, 4, 5}),
+			expectChunked: false,
+		},
+		{
+			name:          "claims chunks of named source",
+			attrs:         ChunkSize(4),
+			source:        testSliceSource([]int{1, 2, 3, 4, 5}, WithSourceName("numbers")),
+			expectChunked: true,
+		},
+		{
+			name:          "receives items of buffered source",
+			attrs:         ChunkSize(4),
+			source:        testSliceSource([]int{1, 2, 3, 4, 5}, WithSourceBuffer(8, OverflowFail)),
+			expectChunked: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(ContextWithAttributes(context.Background(), tt.attrs))
+			defer cancel()
+			wg := &sync.WaitGroup{}
+
+			in := connectUpstream(ctx, cancel, wg, make(chan struct{}), tt.source.setup)
+			assert.Equal(t, tt.expectChunked, in.chunks != nil)
+
+			var received []int
+			for {
+				items, status := in.receive(ctx, nil, nil)
+				if status != receivedItems {
+					assert.Equal(t, upstreamClosed, status)
+					break
+				}
+				for _, item := range items {
+					received = append(received, item.Value)
+				}
+				in.release(items)
+			}
+			wg.Wait()
+
+			assert.Equal(t, []int{1, 2, 3, 4, 5}, received)
+		})
+	}
+}
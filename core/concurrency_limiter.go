@@ -0,0 +1,100 @@
+package core
+
+import (
+	"context"
+)
+
+// ConcurrencyLimiter bounds the number of stages processing items at once, across all streams
+// run with it. Services running hundreds of streams, for example one per tenant, share a
+// limiter between them, so that a burst on some streams cannot take all CPUs from the others.
+//
+// It is a limiter rather than a scheduler: every stage still runs in its own goroutine with its
+// own channel, so it does not reduce the number of goroutines a stream uses. A stage holds a
+// permit of the limiter while its callbacks process an item, and releases it while waiting for
+// items from upstream or for downstream to accept an item, so stages of the same stream never
+// wait on each other for a permit. Synchronous flows created with NewSyncFlow, fused or not,
+// and sinks are limited. Flows created with NewFlow send from their own callbacks, and sources
+// wait on their generators, so they are not limited.
+type ConcurrencyLimiter struct {
+	slots chan struct{}
+}
+
+// NewConcurrencyLimiter creates a ConcurrencyLimiter letting at most size stages process items
+// at once.
+//
+// Parameters:
+//   - size: The maximum number of stages processing items at once
+//
+// Returns:
+//   - A new ConcurrencyLimiter, to be passed to WithConcurrencyLimiter
+func NewConcurrencyLimiter(size int) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		slots: make(chan struct{}, max(size, 1)),
+	}
+}
+
+// Size returns the maximum number of stages processing items at once.
+func (l *ConcurrencyLimiter) Size() int {
+	return cap(l.slots)
+}
+
+// Busy returns the number of stages currently processing items.
+func (l *ConcurrencyLimiter) Busy() int {
+	return len(l.slots)
+}
+
+// concurrencyLimiterKey is the context key under which the ConcurrencyLimiter of a running
+// stream is stored.
+type concurrencyLimiterKey struct{}
+
+// WithConcurrencyLimiter returns a context limiting the stages of every stream run with it
+// using the given ConcurrencyLimiter.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - limiter: The limiter bounding the stages
+//
+// Returns a context that can be passed to Stream.Run
+func WithConcurrencyLimiter(ctx context.Context, limiter *ConcurrencyLimiter) context.Context {
+	return context.WithValue(ctx, concurrencyLimiterKey{}, limiter)
+}
+
+// permit tracks the permit held by a single stage. All methods can be called on a nil permit,
+// which is used when the stream does not run with a ConcurrencyLimiter.
+type permit struct {
+	limiter *ConcurrencyLimiter
+	held    bool
+}
+
+// permitOf returns a permit for a stage of the stream ctx belongs to, or nil if it does not
+// run with a ConcurrencyLimiter.
+func permitOf(ctx context.Context) *permit {
+	limiter, ok := ctx.Value(concurrencyLimiterKey{}).(*ConcurrencyLimiter)
+	if !ok || limiter == nil {
+		return nil
+	}
+	return &permit{limiter: limiter}
+}
+
+// acquire waits for a permit of the limiter, returning false if ctx was cancelled first.
+func (p *permit) acquire(ctx context.Context) bool {
+	if p == nil || p.held {
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case p.limiter.slots <- struct{}{}:
+		p.held = true
+		return true
+	}
+}
+
+// release returns the permit to the limiter, if it is held.
+func (p *permit) release() {
+	if p == nil || !p.held {
+		return
+	}
+	<-p.limiter.slots
+	p.held = false
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	tests := []struct {
+		name          string
+		limiter       *ConcurrencyLimiter
+		streams       int
+		expectedLimit int64
+	}{
+		{
+			name:          "bounds stages across streams",
+			limiter:       NewConcurrencyLimiter(2),
+			streams:       4,
+			expectedLimit: 2,
+		},
+		{
+			name:          "runs stages of a stream with a single permit",
+			limiter:       NewConcurrencyLimiter(1),
+			streams:       3,
+			expectedLimit: 1,
+		},
+		{
+			name:          "does not bound stages without limiter",
+			streams:       4,
+			expectedLimit: 8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var active, peak atomic.Int64
+			// busy marks a stage as processing while it sleeps
+			busy := func() {
+				n := active.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				active.Add(-1)
+			}
+
+			ctx := context.Background()
+			if tt.limiter != nil {
+				ctx = WithConcurrencyLimiter(ctx, tt.limiter)
+			}
+
+			var wg sync.WaitGroup
+			results := make([]Item[int], tt.streams)
+			for i := range tt.streams {
+				stream := ConnectSourceToSink(
+					AppendFlowToSource(
+						testSliceSource([]int{1, 2, 3, 4, 5}),
+						testSyncMap(func(i int) int { busy(); return i * 2 }),
+					),
+					NewSink(
+						0,
+						func(ctx context.Context, in int, acc Item[int]) (Item[int], StreamAction) {
+							busy()
+							return Item[int]{Value: acc.Value + in}, ActionProceed
+						},
+						nil,
+						nil,
+					),
+				)
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					results[i] = <-stream.Run(ctx)
+					stream.AwaitDone()
+				}()
+			}
+			wg.Wait()
+
+			for _, res := range results {
+				require.NoError(t, res.Err)
+				assert.Equal(t, 30, res.Value)
+			}
+			assert.LessOrEqual(t, peak.Load(), tt.expectedLimit)
+			if tt.limiter != nil {
+				assert.Zero(t, tt.limiter.Busy())
+			}
+		})
+	}
+}
+
+func TestConcurrencyLimiterCancel(t *testing.T) {
+	limiter := NewConcurrencyLimiter(1)
+	// Hold the only permit, so the stream can never process an item
+	limiter.slots <- struct{}{}
+
+	stream := ConnectSourceToSink(testRepeatSource(1), testSliceSink[int]())
+	ctx, cancel := context.WithCancel(WithConcurrencyLimiter(context.Background(), limiter))
+	res := stream.Run(ctx)
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	assert.ErrorIs(t, (<-res).Err, context.Canceled)
+	stream.AwaitDone()
+	assert.Equal(t, 1, limiter.Size())
+}
@@ -8,6 +8,9 @@ import (
 // ConnectFlows combines two Flow components into a single Flow, where the output of flow1
 // becomes the input to flow2. This allows for chaining data transformations.
 //
+// If both flows are synchronous flows created with NewSyncFlow, they are fused into a
+// single stage running in one goroutine.
+//
 // Type Parameters:
 //   - I: Type of input data for the first flow
 //   - O1: Type of output data from first flow (and input to second flow)
@@ -22,6 +25,10 @@ func ConnectFlows[I, O1, O2 any](
 	flow1 *Flow[I, O1],
 	flow2 *Flow[O1, O2],
 ) *Flow[I, O2] {
+	if flow1.sync != nil && flow2.sync != nil {
+		return fuseFlows(flow1, flow2)
+	}
+
 	setup := func(
 		ctx context.Context,
 		cancel context.CancelFunc,
@@ -41,13 +48,18 @@ func ConnectFlows[I, O1, O2 any](
 	}
 
 	return &Flow[I, O2]{
-		setup: setup,
+		setup:     setup,
+		stages:    stagesOf(flow1.stages, flow2.stages),
+		preflight: preflightOf(flow1.preflight, flow2.preflight),
 	}
 }
 
 // AppendFlowToSource attaches a Flow to a Source component, creating a new Source that
 // outputs the processed data. This allows for transforming the data as it leaves the source.
 //
+// If the flow is a synchronous flow created with NewSyncFlow, it is fused with the synchronous
+// flows ending the source.
+//
 // Type Parameters:
 //   - I: Type of data produced by the original source
 //   - O: Type of data after processing through the flow
@@ -58,6 +70,16 @@ func ConnectFlows[I, O1, O2 any](
 //
 // Returns a new Source that produces data of type O
 func AppendFlowToSource[I, O any](source *Source[I], flow *Flow[I, O]) *Source[O] {
+	if flow.sync != nil {
+		chain := extendChain(chainOf(source), flow.sync)
+		return &Source[O]{
+			setup:     chainSetup(chain),
+			stages:    stagesOf(source.stages, flow.stages),
+			preflight: preflightOf(source.preflight, flow.preflight),
+			chain:     chain,
+		}
+	}
+
 	setup := func(
 		ctx context.Context,
 		cancel context.CancelFunc,
@@ -68,7 +90,9 @@ func AppendFlowToSource[I, O any](source *Source[I], flow *Flow[I, O]) *Source[O
 	}
 
 	return &Source[O]{
-		setup: setup,
+		setup:     setup,
+		stages:    stagesOf(source.stages, flow.stages),
+		preflight: preflightOf(source.preflight, flow.preflight),
 	}
 }
 
@@ -76,6 +100,9 @@ func AppendFlowToSource[I, O any](source *Source[I], flow *Flow[I, O]) *Source[O
 // accepts the input type of the Flow. This allows for transforming data before
 // it reaches the sink.
 //
+// If the flow is a synchronous flow created with NewSyncFlow, it is fused with the synchronous
+// flows starting the sink.
+//
 // Type Parameters:
 //   - I: Type of input data to the flow
 //   - O: Type of data after flow processing (and input to sink)
@@ -87,6 +114,26 @@ func AppendFlowToSource[I, O any](source *Source[I], flow *Flow[I, O]) *Source[O
 //
 // Returns a new Sink that accepts type I and produces result R
 func PrependFlowToSink[I, O, R any](flow *Flow[I, O], sink *Sink[O, R]) *Sink[I, R] {
+	if flow.sync != nil {
+		head := func(chain *syncChain[I]) setupFunc[R] {
+			return headOf(sink, extendChain(chain, flow.sync))
+		}
+		return &Sink[I, R]{
+			setup: func(
+				ctx context.Context,
+				cancel context.CancelFunc,
+				wg *sync.WaitGroup,
+				complete <-chan struct{},
+				setupUpstream setupFunc[I],
+			) <-chan Item[R] {
+				return head(upstreamChain(setupUpstream))(ctx, cancel, wg, complete)
+			},
+			stages:    stagesOf(flow.stages, sink.stages),
+			preflight: preflightOf(flow.preflight, sink.preflight),
+			head:      head,
+		}
+	}
+
 	setup := func(
 		ctx context.Context,
 		cancel context.CancelFunc,
@@ -106,7 +153,9 @@ func PrependFlowToSink[I, O, R any](flow *Flow[I, O], sink *Sink[O, R]) *Sink[I,
 	}
 
 	return &Sink[I, R]{
-		setup: setup,
+		setup:     setup,
+		stages:    stagesOf(flow.stages, sink.stages),
+		preflight: preflightOf(flow.preflight, sink.preflight),
 	}
 }
 
@@ -114,6 +163,8 @@ func PrependFlowToSink[I, O, R any](flow *Flow[I, O], sink *Sink[O, R]) *Sink[I,
 // that can be executed to produce a result. This is the final step in building a
 // processing pipeline.
 //
+// Synchronous flows ending the source are fused with the synchronous flows starting the sink.
+//
 // Type Parameters:
 //   - I: Type of data produced by the source and consumed by the sink
 //   - R: Type of final result produced by the sink
@@ -132,6 +183,37 @@ func ConnectSourceToSink[I, R any](source *Source[I], sink *Sink[I, R]) *Stream[
 	) <-chan Item[R] {
 		return sink.setup(ctx, cancel, wg, complete, source.setup)
 	}
+	if sink.head != nil {
+		setup = sink.head(chainOf(source))
+	}
 
-	return newStream(setup)
+	stream := newStream(setup)
+	stream.stages = stagesOf(source.stages, sink.stages)
+	stream.preflight = preflightOf(source.preflight, sink.preflight)
+	return stream
+}
+
+// chainOf returns the synchronous flows ending source, or an empty chain fed by source if it
+// does not end with synchronous flows.
+func chainOf[O any](source *Source[O]) *syncChain[O] {
+	if source.chain != nil {
+		return source.chain
+	}
+	return upstreamChain(source.setup)
+}
+
+// headOf returns the setup function of sink fed by chain, fusing the chain with the synchronous
+// flows starting sink.
+func headOf[I, R any](sink *Sink[I, R], chain *syncChain[I]) setupFunc[R] {
+	if sink.head != nil {
+		return sink.head(chain)
+	}
+	return func(
+		ctx context.Context,
+		cancel context.CancelFunc,
+		wg *sync.WaitGroup,
+		complete <-chan struct{},
+	) <-chan Item[R] {
+		return sink.setup(ctx, cancel, wg, complete, chainSetup(chain))
+	}
 }
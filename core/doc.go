@@ -16,6 +16,11 @@
 //     a channel for its output. These functions are composed when connecting components.
 //   - Complete Signal Channels: Used to signal graceful shutdown through the pipeline.
 //     When closed, components stop accepting new items but process remaining ones.
+//     Completing exposes the signal to the callbacks of a flow which run for long.
+//   - Running Multiple Streams: AwaitAll runs streams concurrently and collects their
+//     results, cancelling the others on the first error. Combine additionally merges
+//     the results into a single value. Stream.Go runs a stream in an errgroup, returning
+//     its error to the group and cancelling it along with the group's context.
 //
 // Error Handling:
 //   - Item Container: All data flows through the pipeline in Item[T] containers which
@@ -32,6 +37,111 @@
 //   - Recover from certain errors and continue processing
 //   - Log or report errors while allowing processing to continue
 //   - Ignore specific errors based on type or content
+//   - Named Stages: WithSourceName, WithFlowName and WithSinkName name a stage. Errors
+//     produced by a named stage are wrapped in a StageError identifying it, and
+//     Stream.Stages lists all stages of a stream.
+//   - Supervision: WithSupervision configures a Decider per flow which decides whether
+//     the errors it produces or receives from upstream resume processing, restart upstream
+//     or stop the flow.
+//   - Error Modes: The ContinueOnError and DivertErrors attributes switch all stages using
+//     the default error handler to logging errors, or pushing them into a MergeHub, and
+//     continuing. Pass them to ContextWithAttributes to change a whole stream at once.
+//   - Panic Recovery: Panics in the callbacks of a stage are converted into a PanicError
+//     carrying the stack trace, which is passed to the error handler of the stage. The
+//     PropagatePanics attribute lets panics crash the process instead.
+//   - Sandboxing: WithSandbox runs the callbacks of a synchronous flow, or the work a parallel
+//     flow such as MapPar hands to RunSandboxed, in goroutines of their own, bounding how many
+//     run at once and always recovering their panics, to isolate third-party code.
+//   - Error Listeners: Stream.OnError registers callbacks that receive every error a stage
+//     handled without failing the stream, such as errors logged, diverted or resumed, so
+//     they remain visible for logging and metrics. Custom stages report them with ReportError.
+//   - Action Hooks: Stream.OnAction registers callbacks that receive every StreamAction other
+//     than ActionProceed returned by a flow or sink, such as stops, cancellations and restarts.
+//   - Termination Hooks: Stream.OnTermination registers callbacks that receive the
+//     terminal error of a stream exactly once, whether it completed, failed or was cancelled.
+//   - Flushing: Stages register functions exporting pending telemetry with RegisterFlush, or
+//     Stream.OnFlush. They are called once all stages have stopped and before the result is
+//     delivered, within the timeout set by Stream.SetFlushTimeout.
+//   - Preflight Checks: WithSourcePreflight, WithFlowPreflight and WithSinkPreflight register
+//     checks, such as connectivity or permissions, that Stream.Preflight runs before the
+//     stream is started, reporting all failures at once.
+//
+// Materialized Values:
+//   - MatSource, MatFlow and MatSink create a component together with a value exposed
+//     per stream, such as a KillSwitch or a counter.
+//   - ViaMat and ToMat connect them, selecting values with KeepLeft, KeepRight, KeepBoth or KeepNone.
+//     FlowViaMat and FlowToMat do the same for flows, so that flows exposing a value can be
+//     composed in the middle of a pipeline.
+//   - MatStream.Materialize creates a new Stream together with the selected value.
+//
+// Item Metadata:
+//   - An Envelope carries an item together with its Metadata, such as correlation ids, a trace
+//     parent and the time the item entered the pipeline. Flows operating on envelopes, such as
+//     flows.MapEnvelope and flows.BatchEnvelope, propagate the metadata to their output.
+//
+// Drop Accounting:
+//   - Stages that intentionally discard elements report them through ReportDrop.
+//   - Attach a DropHandler to the context passed to Stream.Run using WithDropHandler
+//     to observe every dropped element, or use a DropCounter to count them.
+//
+// Hubs:
+//   - A MergeHub lets producers attach to a running stream at any time by running their
+//     own streams into the hub's Sink, while the hub's Source feeds a single long-lived stream.
+//   - A BroadcastHub lets consumers attach to and detach from a running stream at any time,
+//     each receiving every item published through the hub's Sink with its own buffer.
+//   - BlueGreen routes items from a long-lived upstream to the active version of a pipeline.
+//     Deploy starts a new version, switches over once it is healthy and drains the old one.
+//
+// Pressure Governor:
+//   - A Governor pauses sources while heap usage, goroutine count or memory relative to
+//     the GOMEMLIMIT soft limit exceed configured thresholds, and resumes them once the
+//     pressure subsides. Attach it to sources using WithSourceGovernor.
+//   - Stream.Pause stops all sources of a stream from emitting until Stream.Resume is called,
+//     while the items already in flight continue to be processed.
+//
+// Concurrency Limiting:
+//   - A ConcurrencyLimiter bounds the number of stages processing items at once across all
+//     streams run with WithConcurrencyLimiter, so that many streams sharing a process cannot
+//     starve each other.
+//   - It is not a scheduler: every stage keeps its own goroutine, so it does not reduce the
+//     number of goroutines a stream uses.
+//
+// Overflow Strategies:
+//   - By default, a stage whose output buffer is full is backpressured. WithFlowBuffer and
+//     WithSourceBuffer select an OverflowStrategy instead, such as OverflowDropHead to keep
+//     only the latest items while downstream is slow, or OverflowFail to fail with
+//     ErrBufferOverflow. Dropped items are reported with DropReasonBufferOverflow.
+//   - When a flow stops after emitting an error, the items in its output buffer are delivered
+//     before the error. WithErrorDrain(ErrorDrainDiscard) discards them instead.
+//
+// Operator Fusion:
+//   - Flows created with NewSyncFlow, such as Map, Filter, TryMap and Tap, are fused when
+//     adjacent, running in a single goroutine without channels between them. This applies
+//     however the flows are connected: with ConnectFlows, by appending them to a Source or
+//     prepending them to a Sink, and so also to pipelines built with the compose package.
+//   - WithAsyncBoundary keeps a synchronous flow in its own goroutine, so expensive stages
+//     can run concurrently with the rest of the pipeline.
+//
+// Stall Detection:
+//   - Stream.WatchStalls runs a watchdog reporting streams that make no progress for a
+//     given duration, listing which stages are receiving, processing or sending. FailOnStall
+//     fails a stalled stream with a StallError.
+//   - Stream.WatchStages periodically reports the time every stage spent waiting on upstream
+//     and blocked sending downstream, the signal for tuning buffer sizes and parallelism.
+//   - Stream.GuardBuffers cancels or drains a stream buffering more items between its stages
+//     than a given limit, failing it with a BufferLimitError instead of running out of memory.
+//
+// Run Reports:
+//   - Stream.OnReport registers callbacks that receive a RunReport once every run has terminated,
+//     summarizing the items handled per stage, the errors handled, the wall-clock duration and
+//     the time taken to drain, so batch jobs can log a summary without instrumenting every flow.
+//   - RunID identifies the run a stage's context belongs to, for correlating logs and metrics.
+//
+// Chunked Transport:
+//   - The ChunkSize attribute lets sources and synchronous flows send slices of items to the
+//     stage downstream of them, amortizing the cost of channel synchronization.
+//   - Callbacks still receive individual elements, and stages that do not support chunks,
+//     such as junctions and stages with an overflow strategy, fall back to single items.
 //
 // While this package provides the building blocks for custom components, most users
 // should prefer the pre-built components from the specialized packages:
@@ -46,4 +156,13 @@
 //
 // This package provides configuration options which can be used to
 // customize the behavior of the components.
+//
+// Attributes:
+//   - Attributes such as BufSize, Name and LogLevel configure stages and compose:
+//     they can be attached to a single stage (WithFlowAttributes), to a composed
+//     segment (FlowWithAttributes) or to a whole stream (ContextWithAttributes).
+//   - Stages inherit the attributes of their enclosing segments and stream, with
+//     attributes attached closer to a stage taking precedence.
+//   - Stream.String describes the stages of a stream with their types and attributes,
+//     for logging how a pipeline is composed when a service starts.
 package core
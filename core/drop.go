@@ -0,0 +1,149 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// DropReason describes why a stage intentionally discarded an element.
+type DropReason string
+
+const (
+	// DropReasonFiltered indicates an element did not satisfy a filter predicate.
+	DropReasonFiltered DropReason = "filtered"
+
+	// DropReasonBufferOverflow indicates an element was discarded because a buffer was full.
+	DropReasonBufferOverflow DropReason = "buffer_overflow"
+
+	// DropReasonConflated indicates an element was replaced by a more recent element.
+	DropReasonConflated DropReason = "conflated"
+
+	// DropReasonSampled indicates an element was skipped while sampling.
+	DropReasonSampled DropReason = "sampled"
+
+	// DropReasonDebounced indicates an element was superseded within a debounce window.
+	DropReasonDebounced DropReason = "debounced"
+
+	// DropReasonSubstreamTerminated indicates an element was routed to a substream that already stopped.
+	DropReasonSubstreamTerminated DropReason = "substream_terminated"
+
+	// DropReasonInvalidEventTime indicates an element was discarded because its event time was missing or invalid.
+	DropReasonInvalidEventTime DropReason = "invalid_event_time"
+
+	// DropReasonDuplicate indicates an element was discarded because it equalled the previous element.
+	DropReasonDuplicate DropReason = "duplicate"
+
+	// DropReasonDrained indicates an element was discarded because the stream was drained before it could be emitted.
+	DropReasonDrained DropReason = "drained"
+
+	// DropReasonDiscardedOnError indicates a buffered element was discarded because its stage stopped on an error.
+	DropReasonDiscardedOnError DropReason = "discarded_on_error"
+)
+
+// DropEvent describes a single element that was intentionally discarded by a stage.
+type DropEvent struct {
+	// Stage is the name of the stage that dropped the element
+	Stage string
+
+	// Reason describes why the element was dropped
+	Reason DropReason
+
+	// Value is the element that was dropped
+	Value any
+}
+
+// DropHandler is called for every element that is intentionally discarded by a stage.
+// It is called synchronously from the stage's goroutine, so it should return quickly.
+type DropHandler func(ctx context.Context, event DropEvent)
+
+// dropHandlerKey is the context key under which the DropHandler is stored.
+type dropHandlerKey struct{}
+
+// WithDropHandler returns a context carrying the given DropHandler. When a stream is run
+// with this context, every stage that intentionally discards an element reports it to the
+// handler. Handlers already present in the context are called as well.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - handler: The handler to call for every dropped element
+//
+// Returns a context that can be passed to Stream.Run
+func WithDropHandler(ctx context.Context, handler DropHandler) context.Context {
+	if parent, ok := ctx.Value(dropHandlerKey{}).(DropHandler); ok {
+		next := handler
+		handler = func(ctx context.Context, event DropEvent) {
+			parent(ctx, event)
+			next(ctx, event)
+		}
+	}
+	return context.WithValue(ctx, dropHandlerKey{}, handler)
+}
+
+// ReportDrop reports an intentionally discarded element to the DropHandler in the context,
+// if any. Stages that drop elements should call this for every element they discard. The
+// drop is reported under the name of the stage if it is named, and under kind otherwise, so
+// that named stages of the same kind can be told apart.
+//
+// Parameters:
+//   - ctx: The context passed to the stage
+//   - kind: The kind of the stage dropping the element, such as "Filter"
+//   - reason: Why the element was dropped
+//   - value: The dropped element
+func ReportDrop(ctx context.Context, kind string, reason DropReason, value any) {
+	if handler, ok := ctx.Value(dropHandlerKey{}).(DropHandler); ok {
+		handler(ctx, DropEvent{Stage: stageNameOf(ctx, kind), Reason: reason, Value: value})
+	}
+}
+
+// stageNameOf returns the name of the stage ctx was passed to, or kind if it is not named.
+func stageNameOf(ctx context.Context, kind string) string {
+	if name, _ := GetAttribute(AttributesFromContext(ctx), NameKey); name != "" {
+		return name
+	}
+	return kind
+}
+
+// DropCounter counts dropped elements per stage and reason.
+// Its Handle method can be used as a DropHandler.
+type DropCounter struct {
+	mu     sync.Mutex
+	counts map[dropKey]int64
+}
+
+// dropKey identifies a combination of stage and reason in a DropCounter.
+type dropKey struct {
+	stage  string
+	reason DropReason
+}
+
+// NewDropCounter creates an empty DropCounter.
+func NewDropCounter() *DropCounter {
+	return &DropCounter{
+		counts: make(map[dropKey]int64),
+	}
+}
+
+// Handle records a dropped element. It satisfies the DropHandler signature.
+func (c *DropCounter) Handle(_ context.Context, event DropEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[dropKey{stage: event.Stage, reason: event.Reason}]++
+}
+
+// Count returns the number of elements dropped by the given stage for the given reason.
+func (c *DropCounter) Count(stage string, reason DropReason) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[dropKey{stage: stage, reason: reason}]
+}
+
+// Total returns the total number of dropped elements across all stages and reasons.
+func (c *DropCounter) Total() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var total int64
+	for _, n := range c.counts {
+		total += n
+	}
+	return total
+}
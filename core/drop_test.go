@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportDrop(t *testing.T) {
+	tests := []struct {
+		name     string
+		handlers int
+	}{
+		{
+			name:     "no handler in context",
+			handlers: 0,
+		},
+		{
+			name:     "single handler",
+			handlers: 1,
+		},
+		{
+			name:     "handlers are chained",
+			handlers: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			var events []DropEvent
+			for range tt.handlers {
+				ctx = WithDropHandler(ctx, func(_ context.Context, event DropEvent) {
+					events = append(events, event)
+				})
+			}
+
+			ReportDrop(ctx, "stage", DropReasonSampled, 42)
+
+			assert.Len(t, events, tt.handlers)
+			for _, event := range events {
+				assert.Equal(t, DropEvent{Stage: "stage", Reason: DropReasonSampled, Value: 42}, event)
+			}
+		})
+	}
+}
+
+func TestDropCounter(t *testing.T) {
+	counter := NewDropCounter()
+	ctx := WithDropHandler(context.Background(), counter.Handle)
+
+	ReportDrop(ctx, "a", DropReasonFiltered, 1)
+	ReportDrop(ctx, "a", DropReasonFiltered, 2)
+	ReportDrop(ctx, "a", DropReasonBufferOverflow, 3)
+	ReportDrop(ctx, "b", DropReasonFiltered, 4)
+
+	assert.Equal(t, int64(2), counter.Count("a", DropReasonFiltered))
+	assert.Equal(t, int64(1), counter.Count("a", DropReasonBufferOverflow))
+	assert.Equal(t, int64(1), counter.Count("b", DropReasonFiltered))
+	assert.Equal(t, int64(0), counter.Count("b", DropReasonConflated))
+	assert.Equal(t, int64(4), counter.Total())
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"maps"
+	"time"
+)
+
+// Metadata is information about an item that is not part of its value, such as correlation
+// ids, the trace it belongs to and the time it entered the pipeline. Metadata is immutable:
+// its methods return modified copies, so it can be shared between items.
+type Metadata struct {
+	// Values holds arbitrary key-value pairs, such as correlation ids
+	Values map[string]string
+
+	// TraceParent is the W3C traceparent header of the trace the item belongs to, if any
+	TraceParent string
+
+	// IngestTime is the time the item entered the pipeline
+	IngestTime time.Time
+}
+
+// Get returns the value stored under key, and whether it is present.
+func (m Metadata) Get(key string) (string, bool) {
+	v, ok := m.Values[key]
+	return v, ok
+}
+
+// With returns a copy of the metadata with value stored under key.
+func (m Metadata) With(key, value string) Metadata {
+	values := make(map[string]string, len(m.Values)+1)
+	maps.Copy(values, m.Values)
+	values[key] = value
+	m.Values = values
+	return m
+}
+
+// Merge combines the metadata of several items, such as the items of a batch, into a copy.
+// Values of other are added unless the key is already present, the trace parent is kept if
+// set and taken from other otherwise, and the earliest ingest time is kept.
+func (m Metadata) Merge(other Metadata) Metadata {
+	if len(other.Values) > 0 {
+		values := make(map[string]string, len(m.Values)+len(other.Values))
+		maps.Copy(values, other.Values)
+		maps.Copy(values, m.Values)
+		m.Values = values
+	}
+	if m.TraceParent == "" {
+		m.TraceParent = other.TraceParent
+	}
+	if m.IngestTime.IsZero() || (!other.IngestTime.IsZero() && other.IngestTime.Before(m.IngestTime)) {
+		m.IngestTime = other.IngestTime
+	}
+	return m
+}
+
+// Envelope carries an item together with its Metadata through a pipeline. Flows operating on
+// envelopes, such as flows.MapEnvelope and flows.BatchEnvelope, propagate the metadata of their
+// input to their output, so that information such as correlation ids received by a source is
+// still available to the sink.
+//
+// Type Parameters:
+//   - T: The type of the item
+type Envelope[T any] struct {
+	// Value is the item
+	Value T
+
+	// Metadata is the metadata of the item
+	Metadata Metadata
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadata(t *testing.T) {
+	early := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		metadata func() Metadata
+		expected Metadata
+	}{
+		{
+			name:     "with adds value",
+			metadata: func() Metadata { return Metadata{}.With("id", "1") },
+			expected: Metadata{Values: map[string]string{"id": "1"}},
+		},
+		{
+			name: "merge keeps existing values and earliest ingest time",
+			metadata: func() Metadata {
+				a := Metadata{Values: map[string]string{"id": "1"}, IngestTime: late}
+				b := Metadata{Values: map[string]string{"id": "2", "tenant": "acme"}, TraceParent: "trace", IngestTime: early}
+				return a.Merge(b)
+			},
+			expected: Metadata{
+				Values:      map[string]string{"id": "1", "tenant": "acme"},
+				TraceParent: "trace",
+				IngestTime:  early,
+			},
+		},
+		{
+			name: "merge ignores unset ingest time",
+			metadata: func() Metadata {
+				return Metadata{IngestTime: late}.Merge(Metadata{})
+			},
+			expected: Metadata{IngestTime: late},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.metadata())
+		})
+	}
+}
+
+func TestMetadataIsImmutable(t *testing.T) {
+	original := Metadata{}.With("id", "1")
+	_ = original.With("id", "2")
+	_ = original.Merge(Metadata{Values: map[string]string{"tenant": "acme"}})
+
+	v, ok := original.Get("id")
+	assert.True(t, ok)
+	assert.Equal(t, "1", v)
+	_, ok = original.Get("tenant")
+	assert.False(t, ok)
+}
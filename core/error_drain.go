@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// ErrorDrain determines what happens to the items buffered in a Flow's output channel when
+// the flow stops after emitting an error.
+type ErrorDrain int
+
+const (
+	// ErrorDrainFIFO delivers the buffered items downstream before the error, in the order
+	// they were emitted. This is the default.
+	ErrorDrainFIFO ErrorDrain = iota
+
+	// ErrorDrainDiscard discards the values still buffered when the flow stops, so the error
+	// is delivered downstream next. Buffered errors are kept in order, and discarded values
+	// are reported with DropReasonDiscardedOnError. Values downstream has already taken from
+	// the buffer are delivered as usual.
+	ErrorDrainDiscard
+)
+
+// String returns the name of the drain.
+func (d ErrorDrain) String() string {
+	switch d {
+	case ErrorDrainFIFO:
+		return "fifo"
+	case ErrorDrainDiscard:
+		return "discard"
+	default:
+		return fmt.Sprintf("ErrorDrain(%d)", int(d))
+	}
+}
+
+// ErrorDrainKey is the attribute holding the ErrorDrain of a Flow's output buffer.
+var ErrorDrainKey = NewAttributeKey[ErrorDrain]("errorDrain")
+
+// WithErrorDrain creates a FlowOption that sets what happens to the items buffered in the
+// Flow's output channel when it stops after emitting an error. By default, buffered items are
+// delivered before the error. Use ErrorDrainDiscard to skip work downstream that is moot once
+// the stream fails, for example when each item is expensive to process.
+//
+// Items held by an OverflowStrategy other than OverflowBackpressure are always delivered.
+//
+// Parameters:
+//   - drain: The ErrorDrain applied when the flow stops
+//
+// Returns:
+//   - A FlowOption that can be passed to NewFlow or NewSyncFlow
+func WithErrorDrain(drain ErrorDrain) FlowOption {
+	return WithFlowAttributes(SetAttribute(Attributes{}, ErrorDrainKey, drain))
+}
+
+// takeBuffered removes the items currently buffered in ch, without waiting for new items.
+func takeBuffered[T any](ch chan T) []T {
+	var taken []T
+	for range len(ch) {
+		select {
+		case item := <-ch:
+			taken = append(taken, item)
+		default:
+			return taken
+		}
+	}
+	return taken
+}
+
+// discardOnError filters the items buffered by a stopping stage: if they contain an error,
+// values are reported as dropped and only the errors are kept, otherwise all items are kept.
+func discardOnError[T any](ctx context.Context, name string, items []Item[T]) []Item[T] {
+	failed := false
+	for _, item := range items {
+		if item.Err != nil {
+			failed = true
+			break
+		}
+	}
+	if !failed {
+		return items
+	}
+
+	kept := items[:0]
+	for _, item := range items {
+		if item.Err != nil {
+			kept = append(kept, item)
+		} else {
+			ReportDrop(ctx, name, DropReasonDiscardedOnError, item.Value)
+		}
+	}
+	return kept
+}
+
+// discardBuffered applies ErrorDrainDiscard to the output channel of a stopping stage. As
+// the stage is the only sender on out, the kept items always fit back into the buffer.
+func discardBuffered[T any](ctx context.Context, name string, out chan Item[T]) {
+	for _, item := range discardOnError(ctx, name, takeBuffered(out)) {
+		out <- item
+	}
+}
+
+// discard applies ErrorDrainDiscard to the items the writer has not yet delivered.
+func (w *chunkWriter[T]) discard(ctx context.Context, name string) {
+	if !w.isChunked() {
+		discardBuffered(ctx, name, w.out)
+		return
+	}
+
+	var items []Item[T]
+	for _, chunk := range takeBuffered(w.link.chunks) {
+		items = append(items, chunk...)
+	}
+	w.buf = discardOnError(ctx, name, append(items, w.buf...))
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testGatedSource creates a Source emitting the given items, closing stopped once downstream
+// has stopped reading from it.
+func testGatedSource(items []Item[int], stopped chan<- struct{}) *Source[int] {
+	return NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan Item[int] {
+			out := make(chan Item[int])
+			go func() {
+				defer close(out)
+				defer close(stopped)
+				for _, item := range items {
+					select {
+					case <-ctx.Done():
+						return
+					case <-complete:
+						return
+					case out <- item:
+					}
+				}
+				select {
+				case <-ctx.Done():
+				case <-complete:
+				}
+			}()
+			return out
+		},
+	)
+}
+
+// testGatedSink creates a Sink recording all items, which waits for stopped after taking the
+// first item so the items emitted after it stay buffered upstream.
+func testGatedSink(stopped <-chan struct{}) *Sink[int, []Item[int]] {
+	record := func(acc Item[[]Item[int]], item Item[int]) Item[[]Item[int]] {
+		if len(acc.Value) == 0 {
+			<-stopped
+		}
+		return Item[[]Item[int]]{Value: append(acc.Value, item)}
+	}
+	return NewSink(
+		[]Item[int]{},
+		func(ctx context.Context, in int, acc Item[[]Item[int]]) (Item[[]Item[int]], StreamAction) {
+			return record(acc, Item[int]{Value: in}), ActionProceed
+		},
+		func(ctx context.Context, err error, acc Item[[]Item[int]]) (Item[[]Item[int]], StreamAction) {
+			return record(acc, Item[int]{Err: err}), ActionProceed
+		},
+		nil,
+	)
+}
+
+func TestErrorDrain(t *testing.T) {
+	testErr := errors.New("test error")
+	input := []Item[int]{{Value: 1}, {Value: 2}, {Value: 3}, {Value: 4}, {Err: testErr}, {Value: 5}}
+
+	flows := map[string]func(opts ...FlowOption) *Flow[int, int]{
+		"flow":      testPassFlow,
+		"sync flow": func(opts ...FlowOption) *Flow[int, int] { return testSyncMap(func(i int) int { return i }, opts...) },
+	}
+
+	tests := []struct {
+		name          string
+		opts          []FlowOption
+		expected      []Item[int]
+		expectedDrops int64
+	}{
+		{
+			name:     "buffered items are delivered before the error by default",
+			opts:     []FlowOption{WithFlowBufSize(8)},
+			expected: []Item[int]{{Value: 1}, {Value: 2}, {Value: 3}, {Value: 4}, {Err: testErr}},
+		},
+		{
+			name:          "buffered items are discarded",
+			opts:          []FlowOption{WithFlowBufSize(8), WithErrorDrain(ErrorDrainDiscard)},
+			expected:      []Item[int]{{Value: 1}, {Err: testErr}},
+			expectedDrops: 3,
+		},
+	}
+
+	for flowName, flow := range flows {
+		for _, tt := range tests {
+			t.Run(flowName+"/"+tt.name, func(t *testing.T) {
+				counter := NewDropCounter()
+				ctx := WithDropHandler(context.Background(), counter.Handle)
+				stopped := make(chan struct{})
+
+				stream := ConnectSourceToSink(
+					AppendFlowToSource(testGatedSource(input, stopped), flow(tt.opts...)),
+					testGatedSink(stopped),
+				)
+				res := <-stream.Run(ctx)
+				stream.AwaitDone()
+
+				assert.NoError(t, res.Err)
+				assert.Equal(t, tt.expected, res.Value)
+				assert.Equal(t, tt.expectedDrops, counter.Count("", DropReasonDiscardedOnError))
+			})
+		}
+	}
+}
+
+func TestChunkWriterDiscard(t *testing.T) {
+	testErr := errors.New("test error")
+
+	tests := []struct {
+		name          string
+		items         []Item[int]
+		expected      []Item[int]
+		expectedDrops int64
+	}{
+		{
+			name:          "values of sent and pending chunks are discarded",
+			items:         []Item[int]{{Value: 1}, {Value: 2}, {Value: 3}, {Err: testErr}},
+			expected:      []Item[int]{{Err: testErr}},
+			expectedDrops: 3,
+		},
+		{
+			name:     "items are kept in order without an error",
+			items:    []Item[int]{{Value: 1}, {Value: 2}, {Value: 3}},
+			expected: []Item[int]{{Value: 1}, {Value: 2}, {Value: 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := NewDropCounter()
+			ctx := context.WithValue(WithDropHandler(context.Background(), counter.Handle), chunkRegistryKey{}, &chunkRegistry{})
+			out := make(chan Item[int], 8)
+
+			writer := newChunkWriter(ctx, ChunkSize(2), out)
+			writer.link.state.Store(linkChunks)
+			for _, item := range tt.items {
+				writer.send(ctx, item)
+			}
+			writer.discard(ctx, "")
+			writer.close(ctx)
+
+			received := []Item[int]{}
+			for chunk := range writer.link.chunks {
+				received = append(received, chunk...)
+			}
+
+			assert.Equal(t, tt.expected, received)
+			assert.Equal(t, tt.expectedDrops, counter.Count("", DropReasonDiscardedOnError))
+		})
+	}
+}
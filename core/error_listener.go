@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+)
+
+// ErrorHandling describes how a stage handled an error without failing the stream.
+type ErrorHandling string
+
+const (
+	// ErrorHandlingLogged indicates the error was logged under ErrorModeContinue.
+	ErrorHandlingLogged ErrorHandling = "logged"
+
+	// ErrorHandlingDiverted indicates the error was pushed into a hub under ErrorModeDivert.
+	ErrorHandlingDiverted ErrorHandling = "diverted"
+
+	// ErrorHandlingResumed indicates the error was discarded by a Decider returning DecisionResume.
+	ErrorHandlingResumed ErrorHandling = "resumed"
+
+	// ErrorHandlingRestarted indicates upstream was restarted by a Decider returning DecisionRestart.
+	ErrorHandlingRestarted ErrorHandling = "restarted"
+
+	// ErrorHandlingRecovered indicates the error was replaced by a fallback element or dropped.
+	ErrorHandlingRecovered ErrorHandling = "recovered"
+
+	// ErrorHandlingRetried indicates the operation that failed with the error was retried.
+	ErrorHandlingRetried ErrorHandling = "retried"
+)
+
+// ErrorEvent describes a single error that a stage handled without failing the stream.
+type ErrorEvent struct {
+	// Stage is the name of the stage that handled the error
+	Stage string
+
+	// Handling describes how the error was handled
+	Handling ErrorHandling
+
+	// Err is the error that was handled
+	Err error
+}
+
+// ErrorListener is called for every error that a stage handles without failing the stream.
+// It is called synchronously from the stage's goroutine, so it should return quickly.
+type ErrorListener func(ctx context.Context, event ErrorEvent)
+
+// errorListenerKey is the context key under which the ErrorListener of a running stream is stored.
+type errorListenerKey struct{}
+
+// OnError registers a listener that is called for every error a stage handles without failing
+// the stream, such as errors logged under ErrorModeContinue, diverted under ErrorModeDivert, or
+// resumed by a Decider. Only the terminal error is reported by the result of the stream, so this
+// makes the other errors visible for logging and metrics.
+//
+// Listeners are called in order of registration. Listeners must be registered before the stream
+// is started.
+//
+// Parameters:
+//   - listener: Function called with every handled error
+func (s *Stream[R]) OnError(listener ErrorListener) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.errorListeners = append(s.errorListeners, listener)
+}
+
+// withErrorListeners returns a context carrying the given listeners. Listeners already present
+// in the context, for example of a stream running this stream, are called as well.
+func withErrorListeners(ctx context.Context, listeners []ErrorListener) context.Context {
+	if len(listeners) == 0 {
+		return ctx
+	}
+	parent, _ := ctx.Value(errorListenerKey{}).(ErrorListener)
+	return context.WithValue(ctx, errorListenerKey{}, ErrorListener(func(ctx context.Context, event ErrorEvent) {
+		if parent != nil {
+			parent(ctx, event)
+		}
+		for _, listener := range listeners {
+			listener(ctx, event)
+		}
+	}))
+}
+
+// ReportError reports an error that was handled without failing the stream to the listeners
+// registered with Stream.OnError, if any. Stages that discard or reroute errors should call
+// this for every error they handle. The error is reported under the name of the stage if it
+// is named, and under kind otherwise.
+//
+// Parameters:
+//   - ctx: The context passed to the stage
+//   - kind: The kind of the stage handling the error, such as "Recover"
+//   - handling: How the error was handled
+//   - err: The handled error
+func ReportError(ctx context.Context, kind string, handling ErrorHandling, err error) {
+	if listener, ok := ctx.Value(errorListenerKey{}).(ErrorListener); ok {
+		listener(ctx, ErrorEvent{Stage: stageNameOf(ctx, kind), Handling: handling, Err: err})
+	}
+}
+
+// reportHandled reports an error handled by the stage ctx was passed to.
+func reportHandled(ctx context.Context, handling ErrorHandling, err error) {
+	if ctx.Value(errorListenerKey{}) == nil {
+		return
+	}
+	if e, ok := err.(*upstreamError); ok {
+		err = e.err
+	}
+	ReportError(ctx, "", handling, err)
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamOnError(t *testing.T) {
+	testErr := errors.New("test error")
+	input := []Item[int]{{Value: 1}, {Err: testErr}, {Value: 2}}
+
+	tests := []struct {
+		name        string
+		attrs       Attributes
+		flow        *Flow[int, int]
+		expected    []ErrorEvent
+		expectedErr error
+	}{
+		{
+			name:        "does not report terminal errors",
+			attrs:       Attributes{},
+			flow:        testPassFlow(WithFlowName("pass")),
+			expectedErr: testErr,
+		},
+		{
+			name:     "reports errors logged by error mode",
+			attrs:    ContinueOnError(),
+			flow:     testPassFlow(WithFlowName("pass")),
+			expected: []ErrorEvent{{Stage: "pass", Handling: ErrorHandlingLogged, Err: testErr}},
+		},
+		{
+			name:     "reports errors resumed by decider",
+			attrs:    Attributes{},
+			flow:     testPassFlow(WithFlowName("pass"), WithSupervision(ResumingDecider)),
+			expected: []ErrorEvent{{Stage: "pass", Handling: ErrorHandlingResumed, Err: testErr}},
+		},
+		{
+			name:  "reports errors resumed by decider of synchronous flow",
+			attrs: Attributes{},
+			flow: testSyncMap(func(i int) int { return i },
+				WithFlowName("map"), WithSupervision(ResumingDecider)),
+			expected: []ErrorEvent{{Stage: "map", Handling: ErrorHandlingResumed, Err: testErr}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultLogger := slog.Default()
+			slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+			defer slog.SetDefault(defaultLogger)
+
+			stream := ConnectSourceToSink(AppendFlowToSource(testItemSource(input), tt.flow), testSliceSink[int]())
+
+			var mu sync.Mutex
+			var events []ErrorEvent
+			var calls []string
+			stream.OnError(func(ctx context.Context, event ErrorEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				events = append(events, event)
+				calls = append(calls, "first")
+			})
+			stream.OnError(func(ctx context.Context, event ErrorEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				calls = append(calls, "second")
+			})
+
+			res := <-stream.Run(ContextWithAttributes(context.Background(), tt.attrs))
+			stream.AwaitDone()
+
+			assert.ErrorIs(t, res.Err, tt.expectedErr)
+			assert.Equal(t, tt.expected, events)
+			if tt.expected != nil {
+				assert.Equal(t, []string{"first", "second"}, calls)
+			}
+		})
+	}
+}
+
+func TestReportError(t *testing.T) {
+	testErr := errors.New("test error")
+
+	// Without listeners, reporting is a no-op
+	ReportError(context.Background(), "stage", ErrorHandlingResumed, testErr)
+
+	var events []ErrorEvent
+	ctx := withErrorListeners(context.Background(), []ErrorListener{
+		func(ctx context.Context, event ErrorEvent) { events = append(events, event) },
+	})
+	ctx = withErrorListeners(ctx, []ErrorListener{
+		func(ctx context.Context, event ErrorEvent) { events = append(events, event) },
+	})
+	ReportError(ctx, "stage", ErrorHandlingDiverted, testErr)
+
+	// Listeners of enclosing streams are called as well
+	expected := ErrorEvent{Stage: "stage", Handling: ErrorHandlingDiverted, Err: testErr}
+	assert.Equal(t, []ErrorEvent{expected, expected}, events)
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// ErrorMode determines how stages using the default error handler handle errors. It lets the
+// error handling of a whole pipeline be switched at once, instead of passing an error handler
+// to every stage. Stages with their own error handler or a Decider are not affected.
+type ErrorMode int
+
+const (
+	// ErrorModeFailFast sends errors downstream and stops the stage, so the first error fails
+	// the stream. This is the default.
+	ErrorModeFailFast ErrorMode = iota
+
+	// ErrorModeContinue logs errors with slog and continues with the next element. Errors are
+	// logged at the level set by LogLevel, or at slog.LevelError if it is not set.
+	ErrorModeContinue
+
+	// ErrorModeDivert pushes errors into the MergeHub set by DivertErrors and continues with
+	// the next element, so errors can be handled by a separate stream. If the hub has
+	// terminated, errors are handled as with ErrorModeFailFast.
+	ErrorModeDivert
+)
+
+// String returns the name of the mode.
+func (m ErrorMode) String() string {
+	switch m {
+	case ErrorModeFailFast:
+		return "failFast"
+	case ErrorModeContinue:
+		return "continue"
+	case ErrorModeDivert:
+		return "divert"
+	default:
+		return fmt.Sprintf("ErrorMode(%d)", int(m))
+	}
+}
+
+var (
+	// ErrorModeKey is the attribute holding the ErrorMode of a stage.
+	ErrorModeKey = NewAttributeKey[ErrorMode]("errorMode")
+
+	// errorHubKey is the attribute holding the hub errors are diverted to.
+	errorHubKey = NewAttributeKey[*MergeHub[error]]("errorHub")
+)
+
+// FailFast creates Attributes making stages stop on the first error, which is the default.
+// Use it to restore the default for a segment of a stream run with another ErrorMode.
+func FailFast() Attributes {
+	return SetAttribute(Attributes{}, ErrorModeKey, ErrorModeFailFast)
+}
+
+// ContinueOnError creates Attributes making stages log errors and continue processing.
+func ContinueOnError() Attributes {
+	return SetAttribute(Attributes{}, ErrorModeKey, ErrorModeContinue)
+}
+
+// DivertErrors creates Attributes making stages push errors into the given hub and continue
+// processing. Run the Source of the hub into a Sink to handle the diverted errors, for
+// example by sending them to a dead letter queue.
+func DivertErrors(hub *MergeHub[error]) Attributes {
+	return SetAttribute(SetAttribute(Attributes{}, ErrorModeKey, ErrorModeDivert), errorHubKey, hub)
+}
+
+// handleByErrorMode applies the ErrorMode in effect for the stage ctx was passed to. It returns
+// true if the error was logged or diverted and the stage should continue, and false if the
+// stage should fail.
+func handleByErrorMode(ctx context.Context, err error) bool {
+	attrs := AttributesFromContext(ctx)
+	mode, _ := GetAttribute(attrs, ErrorModeKey)
+
+	switch mode {
+	case ErrorModeContinue:
+		level, ok := GetAttribute(attrs, LogLevelKey)
+		if !ok {
+			level = slog.LevelError
+		}
+		name, _ := GetAttribute(attrs, NameKey)
+		slog.Log(ctx, level, "stage error", "stage", name, "error", err)
+		reportHandled(ctx, ErrorHandlingLogged, err)
+		return true
+	case ErrorModeDivert:
+		hub, _ := GetAttribute(attrs, errorHubKey)
+		if hub == nil || hub.push(ctx, err) != nil {
+			return false
+		}
+		reportHandled(ctx, ErrorHandlingDiverted, err)
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testItemSource creates a Source emitting the given items, including errors, in order.
+func testItemSource(items []Item[int]) *Source[int] {
+	return NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan Item[int] {
+			out := make(chan Item[int])
+			go func() {
+				defer close(out)
+				for _, item := range items {
+					select {
+					case <-ctx.Done():
+						return
+					case <-complete:
+						return
+					case out <- item:
+					}
+				}
+			}()
+			return out
+		},
+	)
+}
+
+func TestErrorMode(t *testing.T) {
+	testErr := errors.New("test error")
+	input := []Item[int]{{Value: 1}, {Err: testErr}, {Value: 2}, {Err: testErr}, {Value: 3}}
+
+	tests := []struct {
+		name             string
+		attrs            func(hub *MergeHub[error]) Attributes
+		flow             func() *Flow[int, int]
+		expected         []int
+		expectedErr      error
+		expectedLogged   bool
+		expectedDiverted []error
+	}{
+		{
+			name:        "fail fast by default",
+			attrs:       func(*MergeHub[error]) Attributes { return Attributes{} },
+			flow:        func() *Flow[int, int] { return testPassFlow() },
+			expected:    []int{1},
+			expectedErr: testErr,
+		},
+		{
+			name:           "continue on error logs errors",
+			attrs:          func(*MergeHub[error]) Attributes { return ContinueOnError() },
+			flow:           func() *Flow[int, int] { return testPassFlow() },
+			expected:       []int{1, 2, 3},
+			expectedLogged: true,
+		},
+		{
+			name:           "continue on error applies to synchronous flows",
+			attrs:          func(*MergeHub[error]) Attributes { return ContinueOnError() },
+			flow:           func() *Flow[int, int] { return testSyncMap(func(i int) int { return i }) },
+			expected:       []int{1, 2, 3},
+			expectedLogged: true,
+		},
+		{
+			name:             "divert errors pushes errors into hub",
+			attrs:            DivertErrors,
+			flow:             func() *Flow[int, int] { return testPassFlow() },
+			expected:         []int{1, 2, 3},
+			expectedDiverted: []error{testErr, testErr},
+		},
+		{
+			name:           "supervision takes precedence over error mode of flow",
+			attrs:          func(*MergeHub[error]) Attributes { return ContinueOnError() },
+			flow:           func() *Flow[int, int] { return testPassFlow(WithSupervision(StoppingDecider)) },
+			expected:       []int{1},
+			expectedLogged: true,
+		},
+		{
+			name:  "error mode of flow overrides stream",
+			attrs: func(*MergeHub[error]) Attributes { return ContinueOnError() },
+			flow: func() *Flow[int, int] {
+				return testPassFlow(WithFlowAttributes(FailFast()))
+			},
+			expected:       []int{1},
+			expectedLogged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := &bytes.Buffer{}
+			defaultLogger := slog.Default()
+			slog.SetDefault(slog.New(slog.NewTextHandler(logs, nil)))
+			defer slog.SetDefault(defaultLogger)
+
+			hub := NewMergeHub[error](8)
+			errStream := ConnectSourceToSink(hub.Source(), testSliceSink[error]())
+			errRes := errStream.Run(context.Background())
+
+			ctx := ContextWithAttributes(context.Background(), tt.attrs(hub))
+			stream := ConnectSourceToSink(AppendFlowToSource(testItemSource(input), tt.flow()), testSliceSink[int]())
+			res := <-stream.Run(ctx)
+			stream.AwaitDone()
+
+			errStream.Drain()
+			diverted := <-errRes
+			errStream.AwaitDone()
+
+			assert.ErrorIs(t, res.Err, tt.expectedErr)
+			assert.Equal(t, tt.expected, res.Value)
+			assert.Equal(t, tt.expectedLogged, bytes.Contains(logs.Bytes(), []byte("test error")))
+			if tt.expectedDiverted == nil {
+				assert.Empty(t, diverted.Value)
+			} else {
+				assert.Equal(t, tt.expectedDiverted, diverted.Value)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// String returns a human-readable description of the stream listing its stages from source
+// to sink, one per line, with their names, item types and configuration such as buffer sizes
+// and parallelism. It is meant to be logged when a service starts, to show how a pipeline
+// built from configuration is composed. Attributes inherited from the context the stream is
+// run with are not included.
+func (s *Stream[R]) String() string {
+	return explain(fmt.Sprintf("Stream[%s]", typeName[R]()), s.stages)
+}
+
+// String returns a human-readable description of the source, see Stream.String.
+func (s *Source[O]) String() string {
+	return explain(fmt.Sprintf("Source[%s]", typeName[O]()), s.stages)
+}
+
+// String returns a human-readable description of the flow, see Stream.String.
+func (f *Flow[I, O]) String() string {
+	return explain(fmt.Sprintf("Flow[%s, %s]", typeName[I](), typeName[O]()), f.stages)
+}
+
+// String returns a human-readable description of the sink, see Stream.String.
+func (s *Sink[I, R]) String() string {
+	return explain(fmt.Sprintf("Sink[%s, %s]", typeName[I](), typeName[R]()), s.stages)
+}
+
+// explain renders a description of the given stages below a title.
+func explain(title string, stages []stageDesc) string {
+	var b strings.Builder
+	if len(stages) == 1 {
+		fmt.Fprintf(&b, "%s with 1 stage:", title)
+	} else {
+		fmt.Fprintf(&b, "%s with %d stages:", title, len(stages))
+	}
+	for _, stage := range stages {
+		b.WriteString("\n  ")
+		b.WriteString(string(stage.Kind))
+		if stage.Name != "" {
+			fmt.Fprintf(&b, " %q", stage.Name)
+		}
+		switch {
+		case stage.in != "" && stage.out != "":
+			fmt.Fprintf(&b, " (%s -> %s)", stage.in, stage.out)
+		case stage.out != "":
+			fmt.Fprintf(&b, " (%s)", stage.out)
+		}
+		for _, attr := range describeAttributes(stage.attrs) {
+			b.WriteString(" ")
+			b.WriteString(attr)
+		}
+	}
+	return b.String()
+}
+
+// describeAttributes returns the attributes as key=value pairs sorted by key, leaving out
+// the name and values that have no readable representation, such as pointers.
+func describeAttributes(attrs Attributes) []string {
+	var res []string
+	for key, value := range attrs.values {
+		if key == NameKey {
+			continue
+		}
+		if _, ok := value.(fmt.Stringer); !ok {
+			switch reflect.ValueOf(value).Kind() {
+			case reflect.Pointer, reflect.Struct, reflect.Func, reflect.Chan, reflect.Map,
+				reflect.Slice, reflect.Interface, reflect.UnsafePointer, reflect.Invalid:
+				continue
+			}
+		}
+		res = append(res, fmt.Sprintf("%v=%v", key, value))
+	}
+	slices.Sort(res)
+	return res
+}
@@ -17,6 +17,8 @@ import (
 //
 // Fields:
 //   - setup: A function that initializes the Flow's goroutine and connects it to the input channel.
+//   - stages: The stages making up the Flow, in pipeline order.
+//   - preflight: The preflight checks registered by the stages.
 //
 // It receives:
 //   - ctx: Context used to control cancellation
@@ -35,6 +37,11 @@ type Flow[I, O any] struct {
 		complete <-chan struct{},
 		setupUpstream setupFunc[I],
 	) <-chan Item[O]
+	stages    []stageDesc
+	preflight []PreflightCheck
+
+	// sync is the synchronous form of the Flow, if it can be fused with adjacent flows
+	sync *syncStage[I, O]
 }
 
 // FlowOption is a function type for configuring Flow behavior.
@@ -45,9 +52,15 @@ type FlowOption func(*flowConfig)
 // flowConfig holds configuration options for a Flow.
 //
 // Fields:
-//   - bufSize: The size of the buffer for the Flow's output channel
+//   - attrs: The Flow's own attributes, taking precedence over inherited ones
+//   - decider: Optional Decider replacing the Flow's error handler
+//   - preflight: Checks run by Stream.Preflight
+//   - async: Whether a synchronous Flow is kept out of fusion
 type flowConfig struct {
-	bufSize int
+	attrs     Attributes
+	decider   Decider
+	preflight []PreflightCheck
+	async     bool
 }
 
 // WithFlowBufSize creates a FlowOption that configures the buffer size of a Flow's output channel.
@@ -59,14 +72,43 @@ type flowConfig struct {
 // Returns:
 //   - A FlowOption that can be passed to NewFlow
 func WithFlowBufSize(size int) FlowOption {
+	return WithFlowAttributes(BufSize(size))
+}
+
+// WithFlowName creates a FlowOption that names a Flow. Errors produced by a named Flow are
+// wrapped in a StageError carrying its name, while errors received from upstream are passed
+// through unchanged. The name is listed in the stages of any stream the Flow is part of.
+//
+// Parameters:
+//   - name: The name of the Flow
+//
+// Returns:
+//   - A FlowOption that can be passed to NewFlow
+func WithFlowName(name string) FlowOption {
+	return WithFlowAttributes(Name(name))
+}
+
+// WithFlowAttributes creates a FlowOption that attaches attributes to a Flow.
+// Attributes attached to the Flow take precedence over attributes it inherits.
+//
+// Parameters:
+//   - attrs: The attributes to attach
+//
+// Returns:
+//   - A FlowOption that can be passed to NewFlow
+func WithFlowAttributes(attrs Attributes) FlowOption {
 	return func(c *flowConfig) {
-		c.bufSize = size
+		c.attrs = c.attrs.And(attrs)
 	}
 }
 
 // DefaultFlowErrorHandler is the default implementation for handling errors in a Flow.
-// It sends the error downstream and stops the flow by returning ActionStop.
+// It sends the error downstream and stops the flow by returning ActionStop, unless another
+// ErrorMode is in effect for the flow.
 func DefaultFlowErrorHandler[O any](ctx context.Context, err error, out chan<- Item[O]) StreamAction {
+	if handleByErrorMode(ctx, err) {
+		return ActionProceed
+	}
 	util.Send(ctx, Item[O]{Err: err}, out)
 	return ActionStop
 }
@@ -110,6 +152,8 @@ func DefaultFlowUpstreamClosedHandler[O any](ctx context.Context, out chan<- Ite
 //
 // # It receives the current context, the error, output channel, cancel function, and complete function
 // # If nil is provided, a default handler will be used that sends the error and stops the flow
+// # If the flow is configured with WithSupervision, the Decider is used instead
+// # Errors produced by onElem itself can be supervised by passing them to Supervise
 //
 // onDone is responsible for:
 //   - Performing final operations and cleanup
@@ -131,6 +175,32 @@ func NewFlow[I, O any](
 	onUpstreamClosed func(ctx context.Context, out chan<- Item[O]) StreamAction,
 	onDone func(ctx context.Context, out chan<- Item[O]),
 	opts ...FlowOption,
+) *Flow[I, O] {
+	return NewFlowWithStart(nil, onElem, onErr, onUpstreamClosed, onDone, opts...)
+}
+
+// NewFlowWithStart creates a new Flow like NewFlow, which additionally calls onStart at the
+// start of every run, before the first item is received. This lets flows start work that does
+// not wait for items, such as timers, with the context and output channel of the run. Anything
+// started by onStart must be stopped by onDone, after which the output channel is closed.
+//
+// Type Parameters:
+//   - I: The type of input items
+//   - O: The type of output items
+//
+// Parameters:
+//   - onStart: Function called with the context and output channel of every run, or nil
+//   - onElem, onErr, onUpstreamClosed, onDone, opts: As for NewFlow
+//
+// Returns:
+//   - A new Flow instance that will perform the specified transformation
+func NewFlowWithStart[I, O any](
+	onStart func(ctx context.Context, out chan<- Item[O]),
+	onElem func(ctx context.Context, elem I, out chan<- Item[O]) StreamAction,
+	onErr func(ctx context.Context, err error, out chan<- Item[O]) StreamAction,
+	onUpstreamClosed func(ctx context.Context, out chan<- Item[O]) StreamAction,
+	onDone func(ctx context.Context, out chan<- Item[O]),
+	opts ...FlowOption,
 ) *Flow[I, O] {
 	cfg := &flowConfig{}
 
@@ -151,6 +221,10 @@ func NewFlow[I, O any](
 		opt(cfg)
 	}
 
+	if cfg.decider != nil {
+		onErr = supervise[O](cfg.decider)
+	}
+
 	setup := func(
 		ctx context.Context,
 		cancel context.CancelFunc,
@@ -158,60 +232,170 @@ func NewFlow[I, O any](
 		complete <-chan struct{},
 		setupUpstream setupFunc[I],
 	) <-chan Item[O] {
-		out := make(chan Item[O], cfg.bufSize)
 		completeUpstreamChan, completeUpstream := util.NewCompleteChannel()
-		in := setupUpstream(ctx, cancel, wg, completeUpstreamChan)
+		in := connectUpstream(ctx, cancel, wg, completeUpstreamChan, setupUpstream)
+
+		ctx, attrs := withStageAttributes(ctx, cfg.attrs)
+		ctx = withSandbox(ctx, attrs)
+		ctx = withDecider(ctx, cfg.decider)
+		ctx = context.WithValue(ctx, completingKey{}, complete)
+		bufSize := outputBufSize(attrs)
+		name, _ := GetAttribute(attrs, NameKey)
+		drain, _ := GetAttribute(attrs, ErrorDrainKey)
+		policy, _ := GetAttribute(attrs, PanicPolicyKey)
+		out := make(chan Item[O], bufSize)
+
+		probe := probeStage(ctx, StageInfo{Kind: StageKindFlow, Name: name}, outFullOf(out), bufferedOf(out, nil))
+
+		res := (<-chan Item[O])(out)
+		if name != "" {
+			res = attributeErrors(ctx, wg, name, out)
+		}
+		res = withOverflow(ctx, wg, attrs, res)
 
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			defer close(out)
-			defer onDone(ctx, out)
-			defer completeUpstream()
+			defer func() {
+				if err := catchPanic(policy, func() { onDone(ctx, out) }); err != nil {
+					util.Send(ctx, Item[O]{Err: err}, out)
+				}
+			}()
 
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case <-complete:
-					completeUpstream()
-				case elem, ok := <-in:
-					var action StreamAction
-					if !ok {
-						action = onUpstreamClosed(ctx, out)
-					} else if elem.Err != nil {
-						action = onErr(ctx, elem.Err, out)
-					} else {
-						action = onElem(ctx, elem.Value, out)
-					}
+			if onStart != nil {
+				if err := catchPanic(policy, func() { onStart(ctx, out) }); err != nil {
+					util.Send(ctx, Item[O]{Err: err}, out)
+				}
+			}
 
-					switch action {
-					case ActionProceed:
-						continue
-					case ActionStop:
-						return
-					case ActionCancel:
-						cancel()
-						return
-					case ActionComplete:
-						completeUpstream()
-						continue
-					case ActionRestartUpstream:
-						completeUpstream()
-						completeUpstreamChan, completeUpstream = util.NewCompleteChannel()
-						in = setupUpstream(ctx, cancel, wg, completeUpstreamChan)
-						continue
+			runFlowLoop(ctx, cancel, wg, complete, setupUpstream, in, completeUpstream, nil, probe, func(elem Item[I], ok bool) StreamAction {
+				var action StreamAction
+				var panicErr error
+				switch {
+				case !ok:
+					panicErr = catchPanic(policy, func() { action = onUpstreamClosed(ctx, out) })
+				case elem.Err != nil:
+					panicErr = catchPanic(policy, func() { action = onErr(ctx, markUpstream(name, elem.Err), out) })
+				default:
+					if err := catchPanic(policy, func() { action = onElem(ctx, elem.Value, out) }); err != nil {
+						// A panic while processing an element is handled like any other error
+						panicErr = catchPanic(policy, func() { action = onErr(ctx, err, out) })
 					}
 				}
-			}
+				if panicErr != nil {
+					util.Send(ctx, Item[O]{Err: panicErr}, out)
+					action = ActionStop
+				}
+				if ok {
+					reportAction(ctx, StageKindFlow, action)
+				}
+				if action == ActionStop && drain == ErrorDrainDiscard {
+					discardBuffered(ctx, name, out)
+				}
+				return action
+			})
 		}()
 
-		return out
+		return res
 	}
 
 	f := &Flow[I, O]{
-		setup: setup,
+		setup:     setup,
+		stages:    describeStage[O](StageKindFlow, typeName[I](), cfg.attrs),
+		preflight: cfg.preflight,
 	}
 
 	return f
 }
+
+// completingKey is the context key under which the complete signal of a Flow is stored.
+type completingKey struct{}
+
+// Completing returns a channel that is closed once the Flow the context was passed to has been
+// signalled to complete, because a stage downstream completed or the stream is drained. Flows
+// created by NewFlow whose callbacks run for long, such as expanding an item into a long
+// sequence, can use it to stop early rather than waiting for the stream to be cancelled.
+//
+// Parameters:
+//   - ctx: The context passed to a callback of a Flow
+//
+// Returns the complete signal of the Flow, or nil if ctx was not passed to a Flow created by NewFlow
+func Completing(ctx context.Context) <-chan struct{} {
+	complete, _ := ctx.Value(completingKey{}).(<-chan struct{})
+	return complete
+}
+
+// runFlowLoop reads items from upstream until the flow stops, passing each item to handle
+// and applying the StreamAction it returns. handle receives ok set to false once upstream
+// has closed. If idle is not nil, it is called whenever no input is ready. The state of the
+// flow is recorded in probe. Upstream is completed when the loop returns.
+func runFlowLoop[I any](
+	ctx context.Context,
+	cancel context.CancelFunc,
+	wg *sync.WaitGroup,
+	complete <-chan struct{},
+	setupUpstream setupFunc[I],
+	in *upstream[I],
+	completeUpstream func(),
+	idle func(),
+	probe *stageProbe,
+	handle func(elem Item[I], ok bool) StreamAction,
+) {
+	defer func() {
+		completeUpstream()
+		probe.set(StageStopped)
+	}()
+
+	// apply applies the action returned by handle, returning false if the loop should stop
+	// and true if the upstream was restarted
+	apply := func(action StreamAction) (proceed bool, restarted bool) {
+		switch action {
+		case ActionStop:
+			return false, false
+		case ActionCancel:
+			cancel()
+			return false, false
+		case ActionComplete:
+			completeUpstream()
+		case ActionRestartUpstream:
+			completeUpstream()
+			var completeUpstreamChan chan struct{}
+			completeUpstreamChan, completeUpstream = util.NewCompleteChannel()
+			in = connectUpstream(ctx, cancel, wg, completeUpstreamChan, setupUpstream)
+			return true, true
+		}
+		return true, false
+	}
+
+	for {
+		items, status := in.receive(ctx, complete, idle)
+		switch status {
+		case contextDone:
+			return
+		case completeSignalled:
+			completeUpstream()
+		case upstreamClosed:
+			probe.set(StageProcessing)
+			if proceed, _ := apply(handle(Item[I]{}, false)); !proceed {
+				return
+			}
+			probe.set(StageReceiving)
+		case receivedItems:
+			from := in
+			for _, elem := range items {
+				probe.set(StageProcessing)
+				proceed, restarted := apply(handle(elem, true))
+				probe.handled()
+				if !proceed {
+					return
+				}
+				if restarted {
+					// The remaining items belong to the previous upstream
+					break
+				}
+			}
+			from.release(items)
+		}
+	}
+}
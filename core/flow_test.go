@@ -111,7 +111,10 @@ func TestFlow(t *testing.T) {
 				close(completeSignal)
 			},
 			check: func(t *testing.T, out <-chan Item[string], upstreamCompleteCalled *atomic.Bool) {
-				<-out
+				// The fake upstream records the signal before closing its channel, so wait for the
+				// flow to close its output rather than for the first item, which is sent earlier.
+				for range out {
+				}
 				assert.True(t, upstreamCompleteCalled.Load(), "upstream complete function should have been called")
 			},
 		},
@@ -438,3 +441,57 @@ func TestFlowStreamActions(t *testing.T) {
 		})
 	}
 }
+
+func TestFlowStart(t *testing.T) {
+	var starts atomic.Int64
+	flow := NewFlowWithStart(
+		func(ctx context.Context, out chan<- Item[int]) {
+			starts.Add(1)
+			util.Send(ctx, Item[int]{Value: 0}, out)
+		},
+		func(ctx context.Context, elem int, out chan<- Item[int]) StreamAction {
+			util.Send(ctx, Item[int]{Value: elem}, out)
+			return ActionProceed
+		},
+		nil,
+		nil,
+		nil,
+	)
+	stream := ConnectSourceToSink(AppendFlowToSource(testSliceSource([]int{1, 2}), flow), testSliceSink[int]())
+
+	for run := range 2 {
+		res := <-stream.Run(context.Background())
+		stream.AwaitDone()
+
+		assert.NoError(t, res.Err)
+		assert.Equal(t, []int{0, 1, 2}, res.Value)
+		assert.Equal(t, int64(run+1), starts.Load())
+	}
+}
+
+func TestCompleting(t *testing.T) {
+	assert.Nil(t, Completing(context.Background()))
+
+	started := make(chan struct{})
+	flow := NewFlow(
+		func(ctx context.Context, elem int, out chan<- Item[int]) StreamAction {
+			util.Send(ctx, Item[int]{Value: elem}, out)
+			close(started)
+			<-Completing(ctx) // blocks until the stream is drained
+			return ActionComplete
+		},
+		nil,
+		nil,
+		nil,
+	)
+	stream := ConnectSourceToSink(AppendFlowToSource(testSliceSource([]int{1, 2}), flow), testSliceSink[int]())
+
+	resCh := stream.Run(context.Background())
+	<-started
+	stream.Drain()
+	res := <-resCh
+	stream.AwaitDone()
+
+	assert.NoError(t, res.Err)
+	assert.Equal(t, []int{1}, res.Value)
+}
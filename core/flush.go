@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultFlushTimeout is the time a stream waits for its stages to stop and its flush
+// functions to return, unless another timeout is set with Stream.SetFlushTimeout.
+const DefaultFlushTimeout = 5 * time.Second
+
+// FlushError is returned as part of the result of a stream when one of its flush functions
+// failed or did not return within the flush timeout. It is joined with the error the stream
+// terminated with, if any.
+type FlushError struct {
+	// Err holds the errors of the flush functions
+	Err error
+}
+
+// Error returns the error message of the failed flush functions.
+func (e *FlushError) Error() string {
+	return "flush: " + e.Err.Error()
+}
+
+// Unwrap returns the errors of the flush functions.
+func (e *FlushError) Unwrap() error {
+	return e.Err
+}
+
+// flushesKey is the context key under which the flush functions of a running stream are stored.
+type flushesKey struct{}
+
+// flushes holds the flush functions registered for a single run of a stream.
+type flushes struct {
+	mu  sync.Mutex
+	fns []func(ctx context.Context) error
+}
+
+func (f *flushes) add(flush func(ctx context.Context) error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fns = append(f.fns, flush)
+}
+
+func (f *flushes) all() []func(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fns
+}
+
+// flushesOf returns the flush functions registered for the stream ctx belongs to.
+func flushesOf(ctx context.Context) []func(ctx context.Context) error {
+	if f, ok := ctx.Value(flushesKey{}).(*flushes); ok {
+		return f.all()
+	}
+	return nil
+}
+
+// RegisterFlush registers a function that is called once the stream ctx belongs to has
+// terminated, before its result is delivered. Instrumentation stages use it to export
+// buffered metrics, logs or traces, so short-lived pipelines do not lose their final
+// telemetry. It can be called with the context passed to the setup or callbacks of a stage,
+// and has no effect if ctx does not belong to a running stream.
+//
+// Flush functions are called concurrently after all stages of the stream have stopped, with
+// a context that expires after the flush timeout of the stream.
+//
+// Parameters:
+//   - ctx: The context of a stage of a running stream
+//   - flush: Function exporting pending telemetry
+func RegisterFlush(ctx context.Context, flush func(ctx context.Context) error) {
+	if f, ok := ctx.Value(flushesKey{}).(*flushes); ok {
+		f.add(flush)
+	}
+}
+
+// OnFlush registers a function that is called whenever the stream terminates, after all its
+// stages have stopped and before its result is delivered. See RegisterFlush. Flush functions
+// must be registered before the stream is started.
+//
+// Parameters:
+//   - flush: Function exporting pending telemetry
+func (s *Stream[R]) OnFlush(flush func(ctx context.Context) error) {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+	s.flushes = append(s.flushes, flush)
+}
+
+// SetFlushTimeout sets the time the stream waits for its stages to stop and its flush
+// functions to return once it has terminated. If the timeout expires, the result of the stream
+// is delivered with a FlushError. The default is DefaultFlushTimeout. The timeout must be set
+// before the stream is started.
+//
+// Parameters:
+//   - timeout: The maximum time spent flushing
+func (s *Stream[R]) SetFlushTimeout(timeout time.Duration) {
+	s.flushTimeout = timeout
+}
+
+// flush waits for the stages of the stream to stop and calls the flush functions, returning
+// a FlushError if any of them failed or the timeout expired.
+func flush(ctx context.Context, stages *sync.WaitGroup, fns []func(ctx context.Context) error, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		stages.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return &FlushError{Err: errors.New("stages did not stop within flush timeout")}
+	case <-stopped:
+	}
+
+	errs := make([]error, len(fns))
+	done := &sync.WaitGroup{}
+	for i, fn := range fns {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			errs[i] = fn(ctx)
+		}()
+	}
+
+	flushed := make(chan struct{})
+	go func() {
+		done.Wait()
+		close(flushed)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return &FlushError{Err: ctx.Err()}
+	case <-flushed:
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return &FlushError{Err: err}
+	}
+	return nil
+}
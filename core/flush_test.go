@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testMetricsFlow creates a Flow counting its elements, which registers a flush function
+// exporting the count to exported.
+func testMetricsFlow(exported *atomic.Int64) *Flow[int, int] {
+	var count atomic.Int64
+	var once sync.Once
+	return NewFlow(
+		func(ctx context.Context, elem int, out chan<- Item[int]) StreamAction {
+			once.Do(func() {
+				RegisterFlush(ctx, func(ctx context.Context) error {
+					exported.Store(count.Load())
+					return nil
+				})
+			})
+			count.Add(1)
+			out <- Item[int]{Value: elem}
+			return ActionProceed
+		},
+		nil,
+		nil,
+		nil,
+	)
+}
+
+func TestFlush(t *testing.T) {
+	flushErr := errors.New("export failed")
+
+	tests := []struct {
+		name           string
+		onFlush        func(ctx context.Context) error
+		timeout        time.Duration
+		expectedErr    error
+		expectedExport int64
+	}{
+		{
+			name:           "flushes registered by stages before delivering result",
+			expectedExport: 3,
+		},
+		{
+			name:           "failing flush fails result",
+			onFlush:        func(ctx context.Context) error { return flushErr },
+			expectedErr:    flushErr,
+			expectedExport: 3,
+		},
+		{
+			name: "flush exceeding timeout fails result",
+			onFlush: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			timeout:        20 * time.Millisecond,
+			expectedErr:    context.DeadlineExceeded,
+			expectedExport: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exported := &atomic.Int64{}
+			stream := ConnectSourceToSink(
+				AppendFlowToSource(testSliceSource([]int{1, 2, 3}), testMetricsFlow(exported)),
+				testSliceSink[int](),
+			)
+			if tt.onFlush != nil {
+				stream.OnFlush(tt.onFlush)
+			}
+			if tt.timeout > 0 {
+				stream.SetFlushTimeout(tt.timeout)
+			}
+
+			res := <-stream.Run(context.Background())
+			assert.Equal(t, tt.expectedExport, exported.Load())
+			stream.AwaitDone()
+
+			assert.Equal(t, []int{1, 2, 3}, res.Value)
+			assert.ErrorIs(t, res.Err, tt.expectedErr)
+			if tt.expectedErr != nil {
+				var flushError *FlushError
+				assert.ErrorAs(t, res.Err, &flushError)
+			}
+		})
+	}
+}
@@ -0,0 +1,468 @@
+package core
+
+import (
+	"context"
+	"sync"
+
+	"github.com/svenvdam/linea/util"
+)
+
+// syncStage is the synchronous form of a Flow. Adjacent flows that both have a syncStage
+// are fused when they are connected, so that they run in a single goroutine without a
+// channel between them.
+//
+// Fields:
+//   - attrs: The attributes configuring the output channel of the stage
+//   - start: Prepares the stage for a run, returning handlers that emit items through emit
+type syncStage[I, O any] struct {
+	attrs Attributes
+	start func(ctx context.Context, emit func(Item[O])) syncHandlers[I]
+}
+
+// syncHandlers process the items received by a syncStage during a run.
+type syncHandlers[I any] struct {
+	onElem func(elem I) StreamAction
+	onErr  func(err error) StreamAction
+}
+
+// WithAsyncBoundary creates a FlowOption that always runs a synchronous Flow in its own
+// goroutine, instead of fusing it with adjacent synchronous flows. This allows CPU-heavy
+// stages to run concurrently with the rest of the pipeline.
+//
+// Returns:
+//   - A FlowOption that can be passed to NewSyncFlow
+func WithAsyncBoundary() FlowOption {
+	return func(c *flowConfig) {
+		c.async = true
+	}
+}
+
+// DefaultSyncFlowErrorHandler is the default implementation for handling errors in a
+// synchronous Flow. It emits the error downstream and returns ActionStop to stop the flow,
+// unless another ErrorMode is in effect for the flow.
+func DefaultSyncFlowErrorHandler[O any](ctx context.Context, err error, emit func(Item[O])) StreamAction {
+	if handleByErrorMode(ctx, err) {
+		return ActionProceed
+	}
+	emit(Item[O]{Err: err})
+	return ActionStop
+}
+
+// superviseSync creates an error handler for a synchronous Flow applying the decision of
+// the given Decider.
+func superviseSync[O any](decider Decider) func(ctx context.Context, err error, emit func(Item[O])) StreamAction {
+	return func(ctx context.Context, err error, emit func(Item[O])) StreamAction {
+		switch decide(ctx, decider, err) {
+		case DecisionResume:
+			return ActionProceed
+		case DecisionRestart:
+			return ActionRestartUpstream
+		default:
+			emit(Item[O]{Err: err})
+			return ActionStop
+		}
+	}
+}
+
+// NewSyncFlow creates a synchronous Flow, which processes each item entirely within onElem
+// without starting goroutines, waiting on timers or keeping state that must be flushed when
+// the flow stops. Items are emitted by calling emit, which blocks while downstream is
+// backpressured.
+//
+// Every Flow created by NewFlow runs in its own goroutine, so each stage adds a channel hop
+// per item. Adjacent synchronous flows are instead fused into a single goroutine when they
+// are connected, which removes these hops for chains of cheap transformations such as Map and
+// Filter. Use WithAsyncBoundary to keep a synchronous flow in its own goroutine.
+//
+// onElem is called for each input element, and onErr for each error received from upstream.
+// If onErr is nil, DefaultSyncFlowErrorHandler is used, which emits the error and stops the
+// flow. If the flow is configured with WithSupervision, the Decider is used instead, and is
+// also applied to the errors onElem emits: resumed errors are discarded, and restarting or
+// stopping takes effect once onElem returns.
+//
+// Type Parameters:
+//   - I: The type of input items
+//   - O: The type of output items
+//
+// Parameters:
+//   - onElem: Function processing an input element and emitting zero or more items
+//   - onErr: Function handling an error received from upstream
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns:
+//   - A new Flow that can be fused with adjacent synchronous flows
+func NewSyncFlow[I, O any](
+	onElem func(ctx context.Context, elem I, emit func(Item[O])) StreamAction,
+	onErr func(ctx context.Context, err error, emit func(Item[O])) StreamAction,
+	opts ...FlowOption,
+) *Flow[I, O] {
+	cfg := &flowConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if onErr == nil {
+		onErr = DefaultSyncFlowErrorHandler[O]
+	}
+
+	if cfg.decider != nil {
+		onErr = superviseSync[O](cfg.decider)
+	}
+
+	stage := &syncStage[I, O]{
+		attrs: cfg.attrs,
+		start: func(ctx context.Context, emit func(Item[O])) syncHandlers[I] {
+			ctx, attrs := withStageAttributes(ctx, cfg.attrs)
+			ctx = withSandbox(ctx, attrs)
+			ctx = withDecider(ctx, cfg.decider)
+			sb, _ := ctx.Value(sandboxKey{}).(*sandbox)
+			name, _ := GetAttribute(attrs, NameKey)
+			policy, _ := GetAttribute(attrs, PanicPolicyKey)
+
+			send := emit
+			if name != "" {
+				attributor := &errorAttributor{name: name}
+				send = func(item Item[O]) {
+					if item.Err != nil {
+						item.Err = attributor.attribute(item.Err)
+					}
+					emit(item)
+				}
+			}
+
+			// handleErr passes err to onErr, stopping the flow if onErr panics
+			handleErr := func(err error) StreamAction {
+				var action StreamAction
+				var panicErr error
+				if sb == nil {
+					panicErr = catchPanic(policy, func() { action = onErr(ctx, err, send) })
+				} else {
+					action, panicErr = callSandbox(ctx, sb, func() StreamAction { return onErr(ctx, err, send) })
+				}
+				if panicErr != nil {
+					send(Item[O]{Err: panicErr})
+					return ActionStop
+				}
+				return action
+			}
+
+			// Errors emitted while processing an element are supervised, overriding the action
+			// returned by onElem once it returns
+			elemSend := send
+			var override StreamAction
+			if cfg.decider != nil {
+				elemSend = func(item Item[O]) {
+					if item.Err == nil {
+						send(item)
+						return
+					}
+					switch decide(ctx, cfg.decider, item.Err) {
+					case DecisionResume:
+					case DecisionRestart:
+						override = ActionRestartUpstream
+					default:
+						send(item)
+						override = ActionStop
+					}
+				}
+			}
+
+			return syncHandlers[I]{
+				onElem: func(elem I) StreamAction {
+					var action StreamAction
+					var err error
+					override = ActionProceed
+					if sb == nil {
+						err = catchPanic(policy, func() { action = onElem(ctx, elem, elemSend) })
+					} else {
+						action, err = callSandbox(ctx, sb, func() StreamAction { return onElem(ctx, elem, elemSend) })
+					}
+					if err != nil {
+						// A panic while processing an element is handled like any other error
+						action = handleErr(err)
+					}
+					if override != ActionProceed && (action == ActionProceed || action == ActionComplete) {
+						action = override
+					}
+					reportAction(ctx, StageKindFlow, action)
+					return action
+				},
+				onErr: func(err error) StreamAction {
+					action := handleErr(markUpstream(name, err))
+					reportAction(ctx, StageKindFlow, action)
+					return action
+				},
+			}
+		},
+	}
+
+	f := &Flow[I, O]{
+		setup:     syncSetup(stage),
+		stages:    describeStage[O](StageKindFlow, typeName[I](), cfg.attrs),
+		preflight: cfg.preflight,
+	}
+	if !cfg.async {
+		f.sync = stage
+	}
+
+	return f
+}
+
+// syncSetup creates the setup function running a syncStage in its own goroutine.
+func syncSetup[I, O any](stage *syncStage[I, O]) func(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	wg *sync.WaitGroup,
+	complete <-chan struct{},
+	setupUpstream setupFunc[I],
+) <-chan Item[O] {
+	return func(
+		ctx context.Context,
+		cancel context.CancelFunc,
+		wg *sync.WaitGroup,
+		complete <-chan struct{},
+		setupUpstream setupFunc[I],
+	) <-chan Item[O] {
+		return runSyncChain(ctx, cancel, wg, complete, extendChain(upstreamChain(setupUpstream), stage))
+	}
+}
+
+// syncChain is a chain of synchronous stages together with the upstream feeding them, which
+// run as a single stage. It hides the type of the items received from upstream, so that a
+// Source ending in synchronous flows, or a Sink starting with them, can fuse them with the
+// synchronous flows connected to it.
+//
+// Fields:
+//   - attrs: The attributes configuring the output channel of the chain
+//   - connect: Sets up the upstream of the chain, returning a function running the chain
+type syncChain[O any] struct {
+	attrs   Attributes
+	connect func(ctx context.Context, cancel context.CancelFunc, wg *sync.WaitGroup) syncRun[O]
+}
+
+// syncRun runs a syncChain until it stops, sending its items to out.
+type syncRun[O any] func(complete <-chan struct{}, out syncOutput[O])
+
+// syncOutput is where a syncChain sends its items to while it runs.
+//
+// Fields:
+//   - emit: Passes an item on to the stage after the chain
+//   - apply: Combines the action of the chain with the actions of the stages after it
+//   - idle: Called whenever no input is ready
+//   - probe: Records the state of the stage
+//   - permit: The permit of the stage, held while it processes an item
+//   - stopped: Called once the stage stops because an action stopped it
+type syncOutput[O any] struct {
+	emit    func(Item[O])
+	apply   func(StreamAction) StreamAction
+	idle    func()
+	probe   *stageProbe
+	permit  *permit
+	stopped func()
+}
+
+// upstreamChain creates a syncChain without stages, which passes on the items of upstream.
+func upstreamChain[I any](setupUpstream setupFunc[I]) *syncChain[I] {
+	return &syncChain[I]{
+		connect: func(ctx context.Context, cancel context.CancelFunc, wg *sync.WaitGroup) syncRun[I] {
+			completeUpstreamChan, completeUpstream := util.NewCompleteChannel()
+			in := connectUpstream(ctx, cancel, wg, completeUpstreamChan, setupUpstream)
+
+			return func(complete <-chan struct{}, out syncOutput[I]) {
+				handle := func(elem Item[I], ok bool) StreamAction {
+					var action StreamAction
+					if !ok {
+						action = ActionStop
+					} else if !out.permit.acquire(ctx) {
+						return ActionStop
+					} else {
+						out.emit(elem)
+						action = out.apply(ActionProceed)
+					}
+					out.permit.release()
+					if action == ActionStop {
+						out.stopped()
+					}
+					return action
+				}
+				runFlowLoop(ctx, cancel, wg, complete, setupUpstream, in, completeUpstream, out.idle, out.probe, handle)
+			}
+		},
+	}
+}
+
+// extendChain appends a synchronous stage to a syncChain, so that it processes the items of
+// the chain within the same stage.
+func extendChain[I, O any](chain *syncChain[I], stage *syncStage[I, O]) *syncChain[O] {
+	return &syncChain[O]{
+		attrs: stage.attrs,
+		connect: func(ctx context.Context, cancel context.CancelFunc, wg *sync.WaitGroup) syncRun[O] {
+			run := chain.connect(ctx, cancel, wg)
+
+			return func(complete <-chan struct{}, out syncOutput[O]) {
+				send, apply := chainStage(ctx, stage, out.emit)
+				run(complete, syncOutput[I]{
+					emit:    send,
+					apply:   func(action StreamAction) StreamAction { return out.apply(apply(action)) },
+					idle:    out.idle,
+					probe:   out.probe,
+					permit:  out.permit,
+					stopped: out.stopped,
+				})
+			}
+		},
+	}
+}
+
+// chainSetup creates the setup function running a syncChain in its own goroutine.
+func chainSetup[O any](chain *syncChain[O]) setupFunc[O] {
+	return func(
+		ctx context.Context,
+		cancel context.CancelFunc,
+		wg *sync.WaitGroup,
+		complete <-chan struct{},
+	) <-chan Item[O] {
+		return runSyncChain(ctx, cancel, wg, complete, chain)
+	}
+}
+
+// runSyncChain runs a syncChain as a single stage in its own goroutine, returning the channel
+// of its output.
+func runSyncChain[O any](
+	ctx context.Context,
+	cancel context.CancelFunc,
+	wg *sync.WaitGroup,
+	complete <-chan struct{},
+	chain *syncChain[O],
+) <-chan Item[O] {
+	run := chain.connect(ctx, cancel, wg)
+
+	stageCtx, attrs := withStageAttributes(ctx, chain.attrs)
+	bufSize := outputBufSize(attrs)
+	name, _ := GetAttribute(attrs, NameKey)
+	drain, _ := GetAttribute(attrs, ErrorDrainKey)
+	out := make(chan Item[O], bufSize)
+	writer := newChunkWriter(ctx, attrs, out)
+	probe := probeStage(ctx, StageInfo{Kind: StageKindFlow, Name: name}, nil, bufferedOf(out, writer))
+	p := permitOf(ctx)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(out)
+		defer writer.close(ctx)
+
+		run(complete, syncOutput[O]{
+			emit: func(item Item[O]) {
+				probe.set(StageSending)
+				// The permit is released while sending, so downstream stages can take it
+				p.release()
+				writer.send(ctx, item)
+				p.acquire(ctx)
+				probe.set(StageProcessing)
+			},
+			apply: func(action StreamAction) StreamAction { return action },
+			idle: func() {
+				writer.flush(ctx)
+			},
+			probe:  probe,
+			permit: p,
+			stopped: func() {
+				if drain == ErrorDrainDiscard {
+					writer.discard(stageCtx, name)
+				}
+			},
+		})
+	}()
+
+	return withOverflow(stageCtx, wg, attrs, out)
+}
+
+// actionPriority orders StreamActions by how far they reach, so the strongest action of
+// fused stages can be applied.
+var actionPriority = map[StreamAction]int{
+	ActionProceed:         0,
+	ActionComplete:        1,
+	ActionRestartUpstream: 2,
+	ActionStop:            3,
+	ActionCancel:          4,
+}
+
+// strongest returns the StreamAction reaching furthest.
+func strongest(a, b StreamAction) StreamAction {
+	if actionPriority[b] > actionPriority[a] {
+		return b
+	}
+	return a
+}
+
+// fuse combines two synchronous stages into one, where every item emitted by first is
+// processed by second directly.
+func fuse[I, M, O any](first *syncStage[I, M], second *syncStage[M, O]) *syncStage[I, O] {
+	return &syncStage[I, O]{
+		attrs: second.attrs,
+		start: func(ctx context.Context, emit func(Item[O])) syncHandlers[I] {
+			send, apply := chainStage(ctx, second, emit)
+			h1 := first.start(ctx, send)
+
+			return syncHandlers[I]{
+				onElem: func(elem I) StreamAction {
+					return apply(h1.onElem(elem))
+				},
+				onErr: func(err error) StreamAction {
+					return apply(h1.onErr(err))
+				},
+			}
+		},
+	}
+}
+
+// chainStage starts stage for a run within a fused stage, returning the function through which
+// the stage before it emits items, and the function combining the action returned by the stage
+// before it with the actions of stage since the last item.
+//
+// Once stage stops, the items emitted to it are discarded and the fused stage stops, as the
+// unfused stage would stop reading from the stage before it. Other actions of stage are
+// applied to the upstream of the fused stage, as they would be propagated by the stage before it.
+func chainStage[M, O any](
+	ctx context.Context,
+	stage *syncStage[M, O],
+	emit func(Item[O]),
+) (func(Item[M]), func(StreamAction) StreamAction) {
+	h := stage.start(ctx, emit)
+
+	pending := ActionProceed
+	send := func(item Item[M]) {
+		if pending == ActionStop || pending == ActionCancel {
+			return
+		}
+		var action StreamAction
+		if item.Err != nil {
+			action = h.onErr(item.Err)
+		} else {
+			action = h.onElem(item.Value)
+		}
+		pending = strongest(pending, action)
+	}
+
+	apply := func(action StreamAction) StreamAction {
+		action = strongest(action, pending)
+		if pending != ActionStop && pending != ActionCancel {
+			pending = ActionProceed
+		}
+		return action
+	}
+
+	return send, apply
+}
+
+// fuseFlows connects two synchronous flows into a single synchronous Flow.
+func fuseFlows[I, M, O any](flow1 *Flow[I, M], flow2 *Flow[M, O]) *Flow[I, O] {
+	stage := fuse(flow1.sync, flow2.sync)
+	return &Flow[I, O]{
+		setup:     syncSetup(stage),
+		stages:    stagesOf(flow1.stages, flow2.stages),
+		preflight: preflightOf(flow1.preflight, flow2.preflight),
+		sync:      stage,
+	}
+}
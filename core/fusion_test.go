@@ -0,0 +1,247 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testSyncMap creates a synchronous Flow applying fn to every element.
+func testSyncMap(fn func(int) int, opts ...FlowOption) *Flow[int, int] {
+	return NewSyncFlow(
+		func(ctx context.Context, elem int, emit func(Item[int])) StreamAction {
+			emit(Item[int]{Value: fn(elem)})
+			return ActionProceed
+		},
+		nil,
+		opts...,
+	)
+}
+
+// testSyncTake creates a synchronous Flow emitting the first n elements and then stopping.
+func testSyncTake(n int) *Flow[int, int] {
+	seen := 0
+	return NewSyncFlow(
+		func(ctx context.Context, elem int, emit func(Item[int])) StreamAction {
+			seen++
+			emit(Item[int]{Value: elem})
+			if seen >= n {
+				return ActionStop
+			}
+			return ActionProceed
+		},
+		nil,
+	)
+}
+
+// testSyncFail creates a synchronous Flow emitting err for every element.
+func testSyncFail(err error, opts ...FlowOption) *Flow[int, int] {
+	return NewSyncFlow(
+		func(ctx context.Context, elem int, emit func(Item[int])) StreamAction {
+			emit(Item[int]{Err: err})
+			return ActionProceed
+		},
+		nil,
+		opts...,
+	)
+}
+
+func TestSyncFlowFusion(t *testing.T) {
+	testErr := errors.New("test error")
+	double := func(i int) int { return i * 2 }
+	inc := func(i int) int { return i + 1 }
+
+	tests := []struct {
+		name          string
+		flow          func() *Flow[int, int]
+		fused         bool
+		expected      []int
+		expectedErr   error
+		expectedStage string
+	}{
+		{
+			name: "adjacent synchronous flows are fused",
+			flow: func() *Flow[int, int] {
+				return ConnectFlows(ConnectFlows(testSyncMap(double), testSyncMap(inc)), testSyncMap(double))
+			},
+			fused:    true,
+			expected: []int{6, 10, 14},
+		},
+		{
+			name: "async boundary prevents fusion",
+			flow: func() *Flow[int, int] {
+				return ConnectFlows(testSyncMap(double), testSyncMap(inc, WithAsyncBoundary()))
+			},
+			fused:    false,
+			expected: []int{3, 5, 7},
+		},
+		{
+			name: "synchronous flow is not fused with an asynchronous flow",
+			flow: func() *Flow[int, int] {
+				return ConnectFlows(testSyncMap(double), testPassFlow())
+			},
+			fused:    false,
+			expected: []int{2, 4, 6},
+		},
+		{
+			name: "stop of downstream stage stops fused stage",
+			flow: func() *Flow[int, int] {
+				return ConnectFlows(testSyncMap(double), testSyncTake(2))
+			},
+			fused:    true,
+			expected: []int{2, 4},
+		},
+		{
+			name: "error of named stage is attributed within fused stage",
+			flow: func() *Flow[int, int] {
+				return ConnectFlows(testSyncFail(testErr, WithFlowName("parse")), testSyncMap(double, WithFlowName("double")))
+			},
+			fused:         true,
+			expected:      []int{},
+			expectedErr:   testErr,
+			expectedStage: "parse",
+		},
+		{
+			name: "supervision applies within fused stage",
+			flow: func() *Flow[int, int] {
+				return ConnectFlows(testSyncFail(testErr), testSyncMap(double, WithSupervision(ResumingDecider)))
+			},
+			fused:    true,
+			expected: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow := tt.flow()
+			assert.Equal(t, tt.fused, flow.sync != nil)
+
+			stream := ConnectSourceToSink(AppendFlowToSource(testSliceSource([]int{1, 2, 3}), flow), testSliceSink[int]())
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			assert.ErrorIs(t, res.Err, tt.expectedErr)
+			if tt.expectedErr == nil {
+				assert.Equal(t, tt.expected, res.Value)
+			}
+			if tt.expectedStage != "" {
+				var stageErr *StageError
+				assert.ErrorAs(t, res.Err, &stageErr)
+				assert.Equal(t, tt.expectedStage, stageErr.Stage)
+			}
+		})
+	}
+}
+
+func TestConnectFusion(t *testing.T) {
+	double := func(i int) int { return i * 2 }
+	inc := func(i int) int { return i + 1 }
+
+	tests := []struct {
+		name     string
+		stream   func() *Stream[[]int]
+		stages   int
+		expected []int
+	}{
+		{
+			name: "flows appended to a source are fused",
+			stream: func() *Stream[[]int] {
+				source := AppendFlowToSource(testSliceSource([]int{1, 2, 3}), testSyncMap(double))
+				source = AppendFlowToSource(AppendFlowToSource(source, testSyncMap(inc)), testSyncMap(double))
+				return ConnectSourceToSink(source, testSliceSink[int]())
+			},
+			stages:   3,
+			expected: []int{6, 10, 14},
+		},
+		{
+			name: "flows prepended to a sink are fused",
+			stream: func() *Stream[[]int] {
+				sink := PrependFlowToSink(testSyncMap(inc), testSliceSink[int]())
+				sink = PrependFlowToSink(testSyncMap(double), PrependFlowToSink(testSyncMap(double), sink))
+				return ConnectSourceToSink(testSliceSource([]int{1, 2, 3}), sink)
+			},
+			stages:   3,
+			expected: []int{5, 9, 13},
+		},
+		{
+			name: "flows of a source are fused with flows of a sink",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(
+					AppendFlowToSource(testSliceSource([]int{1, 2, 3}), testSyncMap(double)),
+					PrependFlowToSink(testSyncMap(inc), testSliceSink[int]()),
+				)
+			},
+			stages:   3,
+			expected: []int{3, 5, 7},
+		},
+		{
+			name: "async boundary splits the fused flows",
+			stream: func() *Stream[[]int] {
+				source := AppendFlowToSource(testSliceSource([]int{1, 2, 3}), testSyncMap(double))
+				source = AppendFlowToSource(source, testSyncMap(inc, WithAsyncBoundary()))
+				return ConnectSourceToSink(source, PrependFlowToSink(testSyncMap(double), testSliceSink[int]()))
+			},
+			stages:   5,
+			expected: []int{6, 10, 14},
+		},
+		{
+			name: "stop of a later flow stops the fused flows",
+			stream: func() *Stream[[]int] {
+				source := AppendFlowToSource(testSliceSource([]int{1, 2, 3}), testSyncMap(double))
+				return ConnectSourceToSink(source, PrependFlowToSink(testSyncTake(2), testSliceSink[int]()))
+			},
+			stages:   3,
+			expected: []int{2, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := tt.stream()
+			var report RunReport
+			stream.OnReport(func(r RunReport) {
+				report = r
+			})
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			assert.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+			assert.Len(t, report.Stages, tt.stages)
+		})
+	}
+}
+
+// benchmarkMapChain runs n items through a chain of 10 synchronous maps.
+func benchmarkMapChain(b *testing.B, opts ...FlowOption) {
+	flow := testSyncMap(func(i int) int { return i + 1 }, opts...)
+	for range 9 {
+		flow = ConnectFlows(flow, testSyncMap(func(i int) int { return i + 1 }, opts...))
+	}
+
+	items := make([]int, b.N)
+	sink := NewSink(
+		0,
+		func(ctx context.Context, in int, acc Item[int]) (Item[int], StreamAction) {
+			return Item[int]{Value: acc.Value + 1}, ActionProceed
+		},
+		nil,
+		nil,
+	)
+	stream := ConnectSourceToSink(AppendFlowToSource(testSliceSource(items), flow), sink)
+
+	b.ResetTimer()
+	<-stream.Run(context.Background())
+	stream.AwaitDone()
+}
+
+func BenchmarkFusedFlows(b *testing.B) {
+	benchmarkMapChain(b)
+}
+
+func BenchmarkUnfusedFlows(b *testing.B) {
+	benchmarkMapChain(b, WithAsyncBoundary())
+}
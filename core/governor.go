@@ -0,0 +1,216 @@
+package core
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultGovernorInterval is the default interval at which a Governor samples the runtime
+	defaultGovernorInterval = 100 * time.Millisecond
+
+	// defaultGovernorResumeRatio is the default fraction of the thresholds below which
+	// a paused Governor resumes
+	defaultGovernorResumeRatio = 0.9
+)
+
+// GovernorOption is a function that configures a Governor.
+type GovernorOption func(*Governor)
+
+// WithMaxHeapBytes returns a GovernorOption that pauses sources while the heap in use
+// exceeds the given number of bytes.
+//
+// Parameters:
+//   - n: Maximum number of heap bytes in use, 0 disables the check
+func WithMaxHeapBytes(n uint64) GovernorOption {
+	return func(g *Governor) {
+		g.maxHeapBytes = n
+	}
+}
+
+// WithMaxGoroutines returns a GovernorOption that pauses sources while the number of
+// goroutines exceeds the given number.
+//
+// Parameters:
+//   - n: Maximum number of goroutines, 0 disables the check
+func WithMaxGoroutines(n int) GovernorOption {
+	return func(g *Governor) {
+		g.maxGoroutines = n
+	}
+}
+
+// WithMemoryLimitRatio returns a GovernorOption that pauses sources while the memory
+// used by the process exceeds the given fraction of the soft memory limit set through
+// GOMEMLIMIT or debug.SetMemoryLimit. Near the limit the garbage collector runs more
+// often, so pausing early leaves it room to catch up. The check is disabled if no memory
+// limit is set.
+//
+// Parameters:
+//   - ratio: Fraction of the memory limit, such as 0.8, 0 disables the check
+func WithMemoryLimitRatio(ratio float64) GovernorOption {
+	return func(g *Governor) {
+		g.memoryLimitRatio = ratio
+	}
+}
+
+// WithGovernorInterval returns a GovernorOption that sets how often the runtime is sampled.
+// Samples are shared by all sources using the Governor.
+//
+// Parameters:
+//   - interval: Interval between samples
+func WithGovernorInterval(interval time.Duration) GovernorOption {
+	return func(g *Governor) {
+		g.interval = interval
+	}
+}
+
+// WithGovernorResumeRatio returns a GovernorOption that sets the fraction of the thresholds
+// all measurements must drop below before a paused Governor resumes. A ratio below 1 keeps
+// sources from rapidly pausing and resuming around a threshold.
+//
+// Parameters:
+//   - ratio: Fraction of the thresholds, between 0 and 1
+func WithGovernorResumeRatio(ratio float64) GovernorOption {
+	return func(g *Governor) {
+		g.resumeRatio = ratio
+	}
+}
+
+// runtimeSample holds the measurements a Governor compares against its thresholds.
+type runtimeSample struct {
+	heapBytes   uint64
+	totalBytes  uint64
+	goroutines  int
+	memoryLimit int64
+}
+
+// Governor pauses sources while the process is under memory or goroutine pressure, and
+// resumes them once the pressure subsides. This keeps long-running pipelines stable under
+// load spikes, as items already in flight can be processed before new items are admitted.
+// A Governor is attached to sources through WithSourceGovernor and can be shared between
+// any number of sources and streams.
+//
+// A Governor without thresholds never pauses.
+type Governor struct {
+	maxHeapBytes     uint64
+	maxGoroutines    int
+	memoryLimitRatio float64
+	interval         time.Duration
+	resumeRatio      float64
+	sample           func() runtimeSample
+
+	mu         sync.Mutex
+	paused     bool
+	lastSample time.Time
+}
+
+// NewGovernor creates a Governor with the given thresholds.
+//
+// Parameters:
+//   - opts: GovernorOption functions setting the thresholds and sampling behavior
+//
+// Returns a Governor that can be attached to sources
+func NewGovernor(opts ...GovernorOption) *Governor {
+	g := &Governor{
+		interval:    defaultGovernorInterval,
+		resumeRatio: defaultGovernorResumeRatio,
+		sample:      readRuntimeSample,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Paused returns whether the Governor is currently pausing its sources.
+func (g *Governor) Paused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused
+}
+
+// check samples the runtime if the last sample is older than the interval and returns
+// whether sources should pause.
+func (g *Governor) check() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(g.lastSample) < g.interval {
+		return g.paused
+	}
+	g.lastSample = now
+
+	ratio := 1.0
+	if g.paused {
+		ratio = g.resumeRatio
+	}
+	g.paused = g.exceeds(g.sample(), ratio)
+	return g.paused
+}
+
+// exceeds returns whether any measurement exceeds its threshold scaled by ratio.
+func (g *Governor) exceeds(s runtimeSample, ratio float64) bool {
+	if g.maxHeapBytes > 0 && float64(s.heapBytes) > float64(g.maxHeapBytes)*ratio {
+		return true
+	}
+	if g.maxGoroutines > 0 && float64(s.goroutines) > float64(g.maxGoroutines)*ratio {
+		return true
+	}
+	if g.memoryLimitRatio > 0 && s.memoryLimit > 0 && s.memoryLimit != math.MaxInt64 &&
+		float64(s.totalBytes) > float64(s.memoryLimit)*g.memoryLimitRatio*ratio {
+		return true
+	}
+	return false
+}
+
+// wait blocks while the Governor is paused. It returns false if ctx is cancelled or
+// complete is closed before the Governor resumes.
+func (g *Governor) wait(ctx context.Context, complete <-chan struct{}) bool {
+	if !g.check() {
+		return true
+	}
+
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-complete:
+			return false
+		case <-ticker.C:
+			if !g.check() {
+				return true
+			}
+		}
+	}
+}
+
+// readRuntimeSample reads the current measurements from the runtime.
+func readRuntimeSample() runtimeSample {
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/heap/objects:bytes"},
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(samples)
+
+	s := runtimeSample{
+		goroutines:  runtime.NumGoroutine(),
+		memoryLimit: debug.SetMemoryLimit(-1),
+	}
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		s.heapBytes = samples[0].Value.Uint64()
+	}
+	// The memory limit applies to the memory mapped by the runtime minus what it released
+	if samples[1].Value.Kind() == metrics.KindUint64 && samples[2].Value.Kind() == metrics.KindUint64 {
+		s.totalBytes = samples[1].Value.Uint64() - samples[2].Value.Uint64()
+	}
+	return s
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGovernorExceeds(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []GovernorOption
+		sample runtimeSample
+		ratio  float64
+		want   bool
+	}{
+		{
+			name:   "never pauses without thresholds",
+			sample: runtimeSample{heapBytes: 1 << 40, goroutines: 1 << 20},
+			ratio:  1,
+			want:   false,
+		},
+		{
+			name:   "pauses above heap threshold",
+			opts:   []GovernorOption{WithMaxHeapBytes(100)},
+			sample: runtimeSample{heapBytes: 101},
+			ratio:  1,
+			want:   true,
+		},
+		{
+			name:   "pauses above goroutine threshold",
+			opts:   []GovernorOption{WithMaxGoroutines(10)},
+			sample: runtimeSample{goroutines: 11},
+			ratio:  1,
+			want:   true,
+		},
+		{
+			name:   "applies resume ratio",
+			opts:   []GovernorOption{WithMaxGoroutines(10)},
+			sample: runtimeSample{goroutines: 10},
+			ratio:  0.9,
+			want:   true,
+		},
+		{
+			name:   "pauses near memory limit",
+			opts:   []GovernorOption{WithMemoryLimitRatio(0.8)},
+			sample: runtimeSample{totalBytes: 90, memoryLimit: 100},
+			ratio:  1,
+			want:   true,
+		},
+		{
+			name:   "ignores memory limit ratio without memory limit",
+			opts:   []GovernorOption{WithMemoryLimitRatio(0.8)},
+			sample: runtimeSample{totalBytes: 90, memoryLimit: 0},
+			ratio:  1,
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGovernor(tt.opts...)
+			assert.Equal(t, tt.want, g.exceeds(tt.sample, tt.ratio))
+		})
+	}
+}
+
+func TestGovernorPausesSource(t *testing.T) {
+	var goroutines atomic.Int64
+	goroutines.Store(20)
+
+	g := NewGovernor(WithMaxGoroutines(10), WithGovernorInterval(time.Millisecond))
+	g.sample = func() runtimeSample {
+		return runtimeSample{goroutines: int(goroutines.Load())}
+	}
+
+	stream := ConnectSourceToSink(
+		testSliceSource([]int{1, 2, 3}, WithSourceGovernor(g)),
+		testSliceSink[int](),
+	)
+	res := stream.Run(context.Background())
+
+	assert.Eventually(t, g.Paused, time.Second, time.Millisecond)
+	select {
+	case <-res:
+		t.Fatal("stream completed while the governor was paused")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// Pressure must drop below the resume ratio before the source resumes
+	goroutines.Store(10)
+	select {
+	case <-res:
+		t.Fatal("stream completed above the resume ratio")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	goroutines.Store(5)
+	result := <-res
+	stream.AwaitDone()
+
+	assert.NoError(t, result.Err)
+	assert.Equal(t, []int{1, 2, 3}, result.Value)
+	assert.False(t, g.Paused())
+}
+
+func TestGovernorStopsWaitingOnCancel(t *testing.T) {
+	g := NewGovernor(WithMaxGoroutines(10), WithGovernorInterval(time.Millisecond))
+	g.sample = func() runtimeSample {
+		return runtimeSample{goroutines: 20}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := ConnectSourceToSink(
+		testSliceSource([]int{1, 2, 3}, WithSourceGovernor(g)),
+		testSliceSink[int](),
+	)
+	res := stream.Run(ctx)
+
+	assert.Eventually(t, g.Paused, time.Second, time.Millisecond)
+	cancel()
+	<-res
+	stream.AwaitDone()
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"context"
+)
+
+// Group is a collection of goroutines working on subtasks of a common task, such as
+// *errgroup.Group from golang.org/x/sync/errgroup.
+type Group interface {
+	// Go calls the given function in a new goroutine
+	Go(f func() error)
+}
+
+// Go runs the stream as part of a Group, so it participates in the lifecycle of a service
+// built on errgroup. The stream runs until it completes, fails or ctx is cancelled, and the
+// group's function only returns once all goroutines of the stream have finished. The terminal
+// error of the stream is returned to the group, so a failing stream fails the group.
+//
+// To cancel the stream when a sibling fails, pass the context returned by
+// errgroup.WithContext as ctx.
+//
+// Parameters:
+//   - ctx: Context used to control the stream's lifecycle and cancellation
+//   - g: The group to run the stream in
+//
+// Returns a channel receiving the stream's result once it has finished
+func (s *Stream[R]) Go(ctx context.Context, g Group) <-chan Item[R] {
+	out := make(chan Item[R], 1)
+	g.Go(func() error {
+		defer close(out)
+		res := <-s.Run(ctx)
+		s.AwaitDone()
+		out <- res
+		return res.Err
+	})
+	return out
+}
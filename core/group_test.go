@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestStreamGo(t *testing.T) {
+	testErr := errors.New("test error")
+
+	tests := []struct {
+		name        string
+		sibling     func(ctx context.Context) error
+		stream      func() *Stream[[]int]
+		expected    []int
+		expectedErr error
+	}{
+		{
+			name: "returns result of stream",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(testSliceSource([]int{1, 2, 3}), testSliceSink[int]())
+			},
+			expected: []int{1, 2, 3},
+		},
+		{
+			name: "failing stream fails the group",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(testErrSource(testErr), testSliceSink[int]())
+			},
+			expectedErr: testErr,
+		},
+		{
+			name: "failing sibling cancels the stream",
+			sibling: func(ctx context.Context) error {
+				return testErr
+			},
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(testRepeatSource(1), testSliceSink[int]())
+			},
+			expectedErr: testErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, ctx := errgroup.WithContext(context.Background())
+			res := tt.stream().Go(ctx, g)
+			if tt.sibling != nil {
+				g.Go(func() error {
+					return tt.sibling(ctx)
+				})
+			}
+
+			err := g.Wait()
+			r := <-res
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			if tt.expectedErr == nil {
+				assert.NoError(t, r.Err)
+				assert.Equal(t, tt.expected, r.Value)
+			} else {
+				assert.Error(t, r.Err)
+			}
+		})
+	}
+}
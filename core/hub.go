@@ -0,0 +1,423 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/svenvdam/linea/util"
+)
+
+// ErrHubClosed is returned when attaching to or pushing into a hub whose stream has terminated.
+var ErrHubClosed = errors.New("hub closed")
+
+// MergeHub allows producers to attach to a running stream at any time. The stream is built
+// once from the hub's Source and runs for as long as needed, while producers push items into
+// it by running their own streams into a Sink obtained from the hub. This suits server-style
+// applications where request handlers push work into a long-lived processing pipeline.
+//
+// Items from all producers are merged in arrival order. Producers are backpressured once the
+// hub's buffer is full. When the stream consuming the hub terminates, the hub is closed and
+// all producers fail with ErrHubClosed. Items buffered in the hub are still emitted when the
+// consuming stream is drained, but not when it is cancelled.
+//
+// Type Parameters:
+//   - T: The type of items passing through the hub
+type MergeHub[T any] struct {
+	items chan T
+
+	// mu ensures no producer is sending while the hub is closed, so buffered items can be flushed
+	mu       sync.RWMutex
+	isClosed bool
+	closed   chan struct{}
+	once     sync.Once
+}
+
+// NewMergeHub creates a MergeHub buffering up to bufSize items.
+//
+// Type Parameters:
+//   - T: The type of items passing through the hub
+//
+// Parameters:
+//   - bufSize: Number of items that can be pushed before producers are backpressured
+//
+// Returns a MergeHub whose Source should be run by a single stream
+func NewMergeHub[T any](bufSize int) *MergeHub[T] {
+	return &MergeHub[T]{
+		items:  make(chan T, bufSize),
+		closed: make(chan struct{}),
+	}
+}
+
+// close rejects all further items. It returns once no producer is sending anymore.
+func (h *MergeHub[T]) close() {
+	h.once.Do(func() {
+		close(h.closed)
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.isClosed = true
+	})
+}
+
+// push sends an item into the hub, blocking while the buffer is full.
+func (h *MergeHub[T]) push(ctx context.Context, elem T) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.isClosed {
+		return ErrHubClosed
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-h.closed:
+		return ErrHubClosed
+	case h.items <- elem:
+		return nil
+	}
+}
+
+// Source returns the Source emitting all items pushed into the hub. It only completes when
+// the consuming stream is drained or cancelled, after which the hub is closed. When drained,
+// items already accepted by the hub are emitted before the Source completes. While the
+// consuming stream is paused, the Source stops emitting and pushes block once the hub is full.
+//
+// Returns the Source of the hub
+func (h *MergeHub[T]) Source() *Source[T] {
+	setup := func(
+		ctx context.Context,
+		cancel context.CancelFunc,
+		wg *sync.WaitGroup,
+		complete <-chan struct{},
+	) <-chan Item[T] {
+		out := make(chan Item[T])
+
+		gate := pauseGateOf(ctx)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(out)
+			defer h.close()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-complete:
+					h.flush(ctx, out)
+					return
+				case elem := <-h.items:
+					// The item is held while the consuming stream is paused
+					if !gate.wait(ctx, complete) {
+						if ctx.Err() == nil {
+							util.Send(ctx, Item[T]{Value: elem}, out)
+							h.flush(ctx, out)
+						}
+						return
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case out <- Item[T]{Value: elem}:
+					}
+				}
+			}
+		}()
+
+		return out
+	}
+
+	return &Source[T]{
+		setup:  setup,
+		stages: junctionStage("merge_hub"),
+	}
+}
+
+// flush closes the hub and emits all items still buffered in it.
+func (h *MergeHub[T]) flush(ctx context.Context, out chan<- Item[T]) {
+	h.close()
+	for {
+		select {
+		case elem := <-h.items:
+			select {
+			case <-ctx.Done():
+				return
+			case out <- Item[T]{Value: elem}:
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Sink returns a Sink pushing all items it receives into the hub. A new stream can be
+// connected to the Sink at any time, and the same Sink can be used by many streams.
+// The Sink fails with ErrHubClosed once the stream consuming the hub has terminated.
+//
+// Parameters:
+//   - opts: Optional SinkOption functions to configure the sink
+//
+// Returns a Sink feeding into the hub
+func (h *MergeHub[T]) Sink(opts ...SinkOption) *Sink[T, NotUsed] {
+	return NewSink(
+		NotUsed{},
+		func(ctx context.Context, elem T, acc Item[NotUsed]) (Item[NotUsed], StreamAction) {
+			if err := h.push(ctx, elem); err != nil {
+				return Item[NotUsed]{Err: err}, ActionStop
+			}
+			return acc, ActionProceed
+		},
+		nil,
+		nil,
+		opts...,
+	)
+}
+
+// hubSubscriber is a consumer attached to a BroadcastHub.
+type hubSubscriber[T any] struct {
+	items chan Item[T]
+	done  chan struct{}
+}
+
+// BroadcastHub allows consumers to attach to and detach from a running stream at any time.
+// A producer stream runs into the hub's Sink, and every stream built from the hub's Source
+// subscribes to the hub for as long as it runs, receiving all items published from then on.
+// This suits fanning out a single source, such as an SQS queue, to dynamically registered
+// handlers.
+//
+// Each subscriber has its own buffer. The producer is backpressured by the slowest subscriber
+// once its buffer is full, and while no subscriber is attached, so no item is dropped. A
+// subscriber detaches when its stream is drained or cancelled. When the producer completes,
+// subscribers emit their remaining items and complete. When the producer fails, subscribers
+// emit its error, and when it is cancelled, they emit ErrHubClosed.
+//
+// Type Parameters:
+//   - T: The type of items passing through the hub
+type BroadcastHub[T any] struct {
+	bufSize int
+
+	mu         sync.Mutex
+	subs       []*hubSubscriber[T]
+	subscribed chan struct{}
+	isClosed   bool
+	err        error
+}
+
+// NewBroadcastHub creates a BroadcastHub giving every subscriber a buffer of bufSize items.
+//
+// Type Parameters:
+//   - T: The type of items passing through the hub
+//
+// Parameters:
+//   - bufSize: Number of items buffered per subscriber
+//
+// Returns a BroadcastHub whose Sink should be run by a single stream
+func NewBroadcastHub[T any](bufSize int) *BroadcastHub[T] {
+	return &BroadcastHub[T]{
+		bufSize:    bufSize,
+		subscribed: make(chan struct{}),
+	}
+}
+
+// subscribe attaches a new subscriber. If the hub is already closed, the subscriber
+// receives the terminal error of the hub, if any, and completes.
+func (h *BroadcastHub[T]) subscribe() *hubSubscriber[T] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.isClosed {
+		sub := &hubSubscriber[T]{
+			items: make(chan Item[T], 1),
+			done:  make(chan struct{}),
+		}
+		if h.err != nil {
+			sub.items <- Item[T]{Err: h.err}
+		}
+		close(sub.items)
+		return sub
+	}
+
+	sub := &hubSubscriber[T]{
+		items: make(chan Item[T], h.bufSize),
+		done:  make(chan struct{}),
+	}
+
+	// Copy on write, so the producer can publish to a snapshot without holding the lock
+	h.subs = append(slices.Clip(h.subs), sub)
+	close(h.subscribed)
+	h.subscribed = make(chan struct{})
+	return sub
+}
+
+// Subscribers returns the number of subscribers currently attached to the hub.
+func (h *BroadcastHub[T]) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// unsubscribe detaches a subscriber, so the producer stops publishing to it.
+func (h *BroadcastHub[T]) unsubscribe(sub *hubSubscriber[T]) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	close(sub.done)
+	h.subs = slices.DeleteFunc(slices.Clone(h.subs), func(s *hubSubscriber[T]) bool {
+		return s == sub
+	})
+}
+
+// publish sends an item to all subscribers, waiting for at least one subscriber to attach.
+// It returns false if ctx is cancelled first.
+func (h *BroadcastHub[T]) publish(ctx context.Context, elem T) bool {
+	var subs []*hubSubscriber[T]
+	for {
+		h.mu.Lock()
+		subs = h.subs
+		subscribed := h.subscribed
+		h.mu.Unlock()
+
+		if len(subs) > 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-subscribed:
+		}
+	}
+
+	for _, sub := range subs {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-sub.done:
+		case sub.items <- Item[T]{Value: elem}:
+		}
+	}
+	return true
+}
+
+// close detaches all subscribers, emitting err to them first if it is not nil.
+func (h *BroadcastHub[T]) close(err error) {
+	h.mu.Lock()
+	subs := h.subs
+	h.subs = nil
+	h.isClosed = true
+	h.err = err
+	h.mu.Unlock()
+
+	// Subscribers may detach while the error is delivered, so this must not hold the lock
+	for _, sub := range subs {
+		if err != nil {
+			select {
+			case <-sub.done:
+			case sub.items <- Item[T]{Err: err}:
+			}
+		}
+		close(sub.items)
+	}
+}
+
+// Sink returns the Sink publishing all items it receives to the subscribers of the hub.
+// It should be run by a single producer stream.
+//
+// Returns the Sink of the hub
+func (h *BroadcastHub[T]) Sink() *Sink[T, NotUsed] {
+	setup := func(
+		ctx context.Context,
+		cancel context.CancelFunc,
+		wg *sync.WaitGroup,
+		complete <-chan struct{},
+		setupUpstream setupFunc[T],
+	) <-chan Item[NotUsed] {
+		out := make(chan Item[NotUsed], 1)
+
+		completeUpstreamChan, completeUpstream := util.NewCompleteChannel()
+		in := setupUpstream(ctx, cancel, wg, completeUpstreamChan)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(out)
+			defer completeUpstream()
+
+			for {
+				select {
+				case <-ctx.Done():
+					h.close(ErrHubClosed)
+					return
+				case <-complete:
+					completeUpstream()
+				case elem, ok := <-in:
+					switch {
+					case !ok:
+						h.close(nil)
+						out <- Item[NotUsed]{}
+						return
+					case elem.Err != nil:
+						h.close(elem.Err)
+						out <- Item[NotUsed]{Err: elem.Err}
+						return
+					case !h.publish(ctx, elem.Value):
+						h.close(ErrHubClosed)
+						return
+					}
+				}
+			}
+		}()
+
+		return out
+	}
+
+	return &Sink[T, NotUsed]{
+		setup:  setup,
+		stages: junctionStage("broadcast_hub"),
+	}
+}
+
+// Source returns a Source subscribing to the hub. Every stream the Source is part of
+// subscribes when it starts and detaches when it is drained or cancelled.
+//
+// Parameters:
+//   - opts: Optional SourceOption functions to configure the source
+//
+// Returns a Source emitting the items published to the hub
+func (h *BroadcastHub[T]) Source(opts ...SourceOption) *Source[T] {
+	return NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan Item[T] {
+			sub := h.subscribe()
+			out := make(chan Item[T])
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer close(out)
+				defer h.unsubscribe(sub)
+
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-complete:
+						return
+					case elem, ok := <-sub.items:
+						if !ok {
+							return
+						}
+						select {
+						case <-ctx.Done():
+							return
+						case <-complete:
+							return
+						case out <- elem:
+						}
+					}
+				}
+			}()
+			return out
+		},
+		opts...,
+	)
+}
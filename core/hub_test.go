@@ -0,0 +1,178 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeHub(t *testing.T) {
+	tests := []struct {
+		name      string
+		producers [][]int
+		want      []int
+	}{
+		{
+			name:      "merges items of all producers",
+			producers: [][]int{{1, 2, 3}, {4, 5}, {6}},
+			want:      []int{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name: "completes without producers",
+			want: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := NewMergeHub[int](2)
+			stream := ConnectSourceToSink(hub.Source(), testSliceSink[int]())
+			res := stream.Run(context.Background())
+
+			for _, items := range tt.producers {
+				producer := ConnectSourceToSink(testSliceSource(items), hub.Sink())
+				assert.NoError(t, (<-producer.Run(context.Background())).Err)
+				producer.AwaitDone()
+			}
+
+			stream.Drain()
+			result := <-res
+			stream.AwaitDone()
+
+			assert.NoError(t, result.Err)
+			assert.ElementsMatch(t, tt.want, result.Value)
+		})
+	}
+}
+
+func TestMergeHubFailsProducersOnceClosed(t *testing.T) {
+	hub := NewMergeHub[int](0)
+	stream := ConnectSourceToSink(hub.Source(), testSliceSink[int]())
+	res := stream.Run(context.Background())
+
+	// A producer blocked on the hub fails once the consuming stream is cancelled
+	blocked := ConnectSourceToSink(testRepeatSource(1), hub.Sink())
+	blockedRes := blocked.Run(context.Background())
+
+	stream.Cancel()
+	<-res
+	stream.AwaitDone()
+
+	assert.ErrorIs(t, (<-blockedRes).Err, ErrHubClosed)
+	blocked.AwaitDone()
+
+	// Producers attaching later fail immediately
+	late := ConnectSourceToSink(testSliceSource([]int{1}), hub.Sink())
+	assert.ErrorIs(t, (<-late.Run(context.Background())).Err, ErrHubClosed)
+	late.AwaitDone()
+}
+
+func TestBroadcastHub(t *testing.T) {
+	testErr := errors.New("test error")
+
+	tests := []struct {
+		name        string
+		producer    *Source[int]
+		subscribers int
+		want        []int
+		wantErr     error
+	}{
+		{
+			name:        "publishes all items to every subscriber",
+			producer:    testSliceSource([]int{1, 2, 3}),
+			subscribers: 2,
+			want:        []int{1, 2, 3},
+		},
+		{
+			name:        "propagates producer errors to subscribers",
+			producer:    testErrSource(testErr),
+			subscribers: 2,
+			wantErr:     testErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			hub := NewBroadcastHub[int](1)
+
+			subscribers := make([]*Stream[[]int], tt.subscribers)
+			results := make([]<-chan Item[[]int], tt.subscribers)
+			for i := range subscribers {
+				subscribers[i] = ConnectSourceToSink(hub.Source(), testSliceSink[int]())
+				results[i] = subscribers[i].Run(ctx)
+			}
+			assert.Eventually(t, func() bool {
+				return hub.Subscribers() == tt.subscribers
+			}, time.Second, time.Millisecond)
+
+			producer := ConnectSourceToSink(tt.producer, hub.Sink())
+			producerRes := <-producer.Run(ctx)
+			producer.AwaitDone()
+
+			for i, res := range results {
+				result := <-res
+				subscribers[i].AwaitDone()
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, result.Err, tt.wantErr)
+					continue
+				}
+				assert.NoError(t, result.Err)
+				assert.Equal(t, tt.want, result.Value)
+			}
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, producerRes.Err, tt.wantErr)
+			} else {
+				assert.NoError(t, producerRes.Err)
+			}
+
+			// Subscribers attaching after the producer finished complete immediately
+			late := ConnectSourceToSink(hub.Source(), testSliceSink[int]())
+			lateRes := <-late.Run(ctx)
+			late.AwaitDone()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, lateRes.Err, tt.wantErr)
+			} else {
+				assert.Empty(t, lateRes.Value)
+			}
+		})
+	}
+}
+
+func TestBroadcastHubDetachesSubscribers(t *testing.T) {
+	ctx := context.Background()
+	hub := NewBroadcastHub[int](0)
+
+	items := make(chan int)
+	producer := ConnectSourceToSink(testChanSource(items), hub.Sink())
+	producerRes := producer.Run(ctx)
+
+	detaching := ConnectSourceToSink(hub.Source(), testSliceSink[int]())
+	detachingRes := detaching.Run(ctx)
+	staying := ConnectSourceToSink(hub.Source(), testSliceSink[int]())
+	stayingRes := staying.Run(ctx)
+	assert.Eventually(t, func() bool { return hub.Subscribers() == 2 }, time.Second, time.Millisecond)
+
+	items <- 1
+	detaching.Cancel()
+	<-detachingRes
+	detaching.AwaitDone()
+	assert.Equal(t, 1, hub.Subscribers())
+
+	// The producer is no longer backpressured by the detached subscriber
+	items <- 2
+	items <- 3
+	close(items)
+
+	assert.NoError(t, (<-producerRes).Err)
+	producer.AwaitDone()
+
+	res := <-stayingRes
+	staying.AwaitDone()
+	assert.NoError(t, res.Err)
+	assert.Equal(t, []int{1, 2, 3}, res.Value)
+}
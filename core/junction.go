@@ -0,0 +1,286 @@
+package core
+
+import (
+	"context"
+	"sync"
+
+	"github.com/svenvdam/linea/util"
+)
+
+// BroadcastSource splits a Source into n Sources that each receive every item
+// produced by the original source. The original source is only set up once per run,
+// regardless of how many of the returned sources are connected downstream.
+//
+// All returned sources must be connected within the same stream, as the original
+// source is only started once every branch has been set up. Items are delivered to
+// all branches in lockstep, so the slowest branch determines the pace of the others.
+//
+// When a branch signals completion it is detached and will no longer receive items.
+// Once all branches have completed, the original source is signalled to complete.
+//
+// Type Parameters:
+//   - T: Type of items produced by the source
+//
+// Parameters:
+//   - source: Source component whose items are broadcast
+//   - n: Number of branches to create
+//
+// Returns n Sources that each produce all items of the original source
+func BroadcastSource[T any](source *Source[T], n int) []*Source[T] {
+	b := &broadcast[T]{
+		source: source,
+		n:      n,
+	}
+
+	branches := make([]*Source[T], n)
+	for i := range n {
+		branches[i] = &Source[T]{
+			setup: func(
+				ctx context.Context,
+				cancel context.CancelFunc,
+				wg *sync.WaitGroup,
+				complete <-chan struct{},
+			) <-chan Item[T] {
+				return b.attach(ctx, cancel, wg, complete, i)
+			},
+			stages: stagesOf(source.stages, junctionStage("broadcast")),
+			// Only the first branch carries the checks of the shared source, so they run once
+			// per stream even if several branches are merged back together
+			preflight: broadcastPreflight(source.preflight, i),
+		}
+	}
+
+	return branches
+}
+
+// broadcast holds the state shared between the branches of a BroadcastSource.
+// The state is reset once all branches have been attached, so the broadcast can
+// be set up again on a subsequent run.
+type broadcast[T any] struct {
+	mu        sync.Mutex
+	source    *Source[T]
+	n         int
+	outs      []chan Item[T]
+	completes []<-chan struct{}
+	attached  int
+}
+
+// attach registers branch i and returns its output channel. The last branch to
+// attach starts the original source and the goroutine distributing its items.
+func (b *broadcast[T]) attach(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	wg *sync.WaitGroup,
+	complete <-chan struct{},
+	i int,
+) <-chan Item[T] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.attached == 0 {
+		b.outs = make([]chan Item[T], b.n)
+		b.completes = make([]<-chan struct{}, b.n)
+		for j := range b.n {
+			b.outs[j] = make(chan Item[T])
+		}
+	}
+
+	out := b.outs[i]
+	b.completes[i] = complete
+	b.attached++
+
+	if b.attached == b.n {
+		b.start(ctx, cancel, wg, b.outs, b.completes)
+		b.attached = 0
+		b.outs = nil
+		b.completes = nil
+	}
+
+	return out
+}
+
+// start sets up the original source and distributes its items over all outputs.
+func (b *broadcast[T]) start(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	wg *sync.WaitGroup,
+	outs []chan Item[T],
+	completes []<-chan struct{},
+) {
+	completeUpstreamChan, completeUpstream := util.NewCompleteChannel()
+	in := b.source.setup(ctx, cancel, wg, completeUpstreamChan)
+
+	done := make(chan struct{})
+	detach := make(chan int)
+
+	// Watch the complete signal of each branch so branches can be detached while idle
+	for j, c := range completes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case <-ctx.Done():
+			case <-done:
+			case <-c:
+				select {
+				case <-ctx.Done():
+				case <-done:
+				case detach <- j:
+				}
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		defer completeUpstream()
+
+		open := len(outs)
+		closeOut := func(j int) {
+			if outs[j] == nil {
+				return
+			}
+			close(outs[j])
+			outs[j] = nil
+			open--
+			if open == 0 {
+				completeUpstream()
+			}
+		}
+		defer func() {
+			for j := range outs {
+				closeOut(j)
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case j := <-detach:
+				closeOut(j)
+			case elem, ok := <-in:
+				if !ok {
+					return
+				}
+				for j := range outs {
+					sent := false
+					for !sent && outs[j] != nil {
+						select {
+						case <-ctx.Done():
+							return
+						case k := <-detach:
+							closeOut(k)
+						case outs[j] <- elem:
+							sent = true
+						}
+					}
+				}
+			}
+		}
+	}()
+}
+
+// MergeSources combines multiple Sources into a single Source that emits the items
+// of all inputs as they become available. No ordering is guaranteed between items
+// of different inputs.
+//
+// The merged source completes once all inputs have closed. Signalling completion
+// to the merged source propagates the signal to all inputs.
+//
+// Type Parameters:
+//   - T: Type of items produced by the sources
+//
+// Parameters:
+//   - sources: Source components whose items are merged
+//
+// Returns a new Source that produces the items of all inputs
+func MergeSources[T any](sources ...*Source[T]) *Source[T] {
+	setup := func(
+		ctx context.Context,
+		cancel context.CancelFunc,
+		wg *sync.WaitGroup,
+		complete <-chan struct{},
+	) <-chan Item[T] {
+		out := make(chan Item[T])
+
+		completeFns := make([]func(), len(sources))
+		ins := make([]<-chan Item[T], len(sources))
+		for i, source := range sources {
+			completeChan, completeFn := util.NewCompleteChannel()
+			completeFns[i] = completeFn
+			ins[i] = source.setup(ctx, cancel, wg, completeChan)
+		}
+
+		inputsDone := make(chan struct{})
+		inputsWg := &sync.WaitGroup{}
+		for _, in := range ins {
+			inputsWg.Add(1)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer inputsWg.Done()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case elem, ok := <-in:
+						if !ok {
+							return
+						}
+						select {
+						case <-ctx.Done():
+							return
+						case out <- elem:
+						}
+					}
+				}
+			}()
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			inputsWg.Wait()
+			close(inputsDone)
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(out)
+			defer func() {
+				for _, completeFn := range completeFns {
+					completeFn()
+				}
+			}()
+
+			select {
+			case <-inputsDone:
+			case <-complete:
+				for _, completeFn := range completeFns {
+					completeFn()
+				}
+				<-inputsDone
+			}
+		}()
+
+		return out
+	}
+
+	stages := make([][]stageDesc, 0, len(sources)+1)
+	preflight := make([][]PreflightCheck, 0, len(sources))
+	for _, source := range sources {
+		stages = append(stages, source.stages)
+		preflight = append(preflight, source.preflight)
+	}
+	stages = append(stages, junctionStage("merge"))
+
+	return &Source[T]{
+		setup:     setup,
+		stages:    stagesOf(stages...),
+		preflight: preflightOf(preflight...),
+	}
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/util"
+)
+
+func TestBroadcastSource(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []int
+		n     int
+	}{
+		{
+			name:  "single branch receives all items",
+			input: []int{1, 2, 3},
+			n:     1,
+		},
+		{
+			name:  "every branch receives all items",
+			input: []int{1, 2, 3},
+			n:     3,
+		},
+		{
+			name:  "handles empty input",
+			input: []int{},
+			n:     2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			branches := BroadcastSource(testSliceSource(tt.input), tt.n)
+			assert.Len(t, branches, tt.n)
+
+			stream := ConnectSourceToSink(MergeSources(branches...), testSliceSink[int]())
+			res := <-stream.Run(context.Background())
+			assert.NoError(t, res.Err)
+
+			expected := make([]int, 0, len(tt.input)*tt.n)
+			for range tt.n {
+				expected = append(expected, tt.input...)
+			}
+			assert.ElementsMatch(t, expected, res.Value)
+		})
+	}
+}
+
+func TestBroadcastSourceDetachesCompletedBranch(t *testing.T) {
+	branches := BroadcastSource(testSliceSource([]int{1, 2, 3, 4, 5}), 2)
+
+	// The first branch stops after the first element, the second should still receive all items
+	stopAfterFirst := NewFlow(
+		func(ctx context.Context, elem int, out chan<- Item[int]) StreamAction {
+			util.Send(ctx, Item[int]{Value: elem * 10}, out)
+			return ActionStop
+		},
+		nil,
+		nil,
+		nil,
+	)
+
+	merged := MergeSources(AppendFlowToSource(branches[0], stopAfterFirst), branches[1])
+	stream := ConnectSourceToSink(merged, testSliceSink[int]())
+
+	res := <-stream.Run(context.Background())
+	assert.NoError(t, res.Err)
+	assert.ElementsMatch(t, []int{10, 1, 2, 3, 4, 5}, res.Value)
+}
+
+func TestMergeSources(t *testing.T) {
+	tests := []struct {
+		name     string
+		inputs   [][]int
+		expected []int
+	}{
+		{
+			name:     "merges all inputs",
+			inputs:   [][]int{{1, 2}, {3, 4}, {5}},
+			expected: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name:     "handles empty inputs",
+			inputs:   [][]int{{}, {1}},
+			expected: []int{1},
+		},
+		{
+			name:     "handles no inputs",
+			inputs:   [][]int{},
+			expected: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources := make([]*Source[int], len(tt.inputs))
+			for i, input := range tt.inputs {
+				sources[i] = testSliceSource(input)
+			}
+
+			stream := ConnectSourceToSink(MergeSources(sources...), testSliceSink[int]())
+			res := <-stream.Run(context.Background())
+			assert.NoError(t, res.Err)
+			assert.ElementsMatch(t, tt.expected, res.Value)
+		})
+	}
+}
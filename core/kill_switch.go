@@ -0,0 +1,150 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/svenvdam/linea/util"
+)
+
+// ErrKillSwitchAborted is the error emitted when a KillSwitch is aborted without an explicit error.
+var ErrKillSwitchAborted = errors.New("kill switch aborted")
+
+// KillSwitch allows terminating streams from outside, independent of the context passed to Run.
+// A KillSwitch is injected into a pipeline through KillSwitchFlow. The same KillSwitch can be
+// shared between any number of flows and streams, in which case triggering it terminates all of them.
+//
+// A KillSwitch can only be triggered once. Subsequent calls to Shutdown or Abort have no effect.
+// Flows created from a KillSwitch that was already triggered terminate as soon as they are started.
+type KillSwitch struct {
+	once     sync.Once
+	shutdown chan struct{}
+	aborted  chan struct{}
+	err      error
+}
+
+// NewKillSwitch creates a new KillSwitch that can be shared between multiple flows and streams.
+func NewKillSwitch() *KillSwitch {
+	return &KillSwitch{
+		shutdown: make(chan struct{}),
+		aborted:  make(chan struct{}),
+	}
+}
+
+// Shutdown gracefully completes all flows attached to the KillSwitch. Upstream components
+// stop producing new items, while items already in flight are still processed downstream.
+func (k *KillSwitch) Shutdown() {
+	k.once.Do(func() {
+		close(k.shutdown)
+	})
+}
+
+// Abort fails all flows attached to the KillSwitch. Each flow emits the given error downstream
+// and stops processing immediately. If err is nil, ErrKillSwitchAborted is emitted instead.
+func (k *KillSwitch) Abort(err error) {
+	k.once.Do(func() {
+		if err == nil {
+			err = ErrKillSwitchAborted
+		}
+		k.err = err
+		close(k.aborted)
+	})
+}
+
+// KillSwitchFlow creates a Flow that passes items through unchanged until the given KillSwitch
+// is triggered. On Shutdown, the flow signals its upstream to complete and keeps forwarding
+// remaining items. On Abort, the flow emits the abort error downstream and stops.
+//
+// Errors received from upstream are passed through unchanged, unless the flow is configured
+// with WithSupervision.
+//
+// Type Parameters:
+//   - I: The type of items passing through the flow
+//
+// Parameters:
+//   - k: The KillSwitch controlling the flow
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that can be terminated through the KillSwitch
+func KillSwitchFlow[I any](k *KillSwitch, opts ...FlowOption) *Flow[I, I] {
+	// forward sends item downstream, emitting the abort error instead if the KillSwitch is
+	// aborted first
+	forward := func(ctx context.Context, item Item[I], out chan<- Item[I]) StreamAction {
+		select {
+		case <-k.aborted:
+			util.Send(ctx, Item[I]{Err: k.err}, out)
+			return ActionStop
+		default:
+		}
+		select {
+		case <-ctx.Done():
+			return ActionStop
+		case <-k.aborted:
+			util.Send(ctx, Item[I]{Err: k.err}, out)
+			return ActionStop
+		case out <- item:
+			return ActionProceed
+		}
+	}
+
+	flow := NewFlow(
+		func(ctx context.Context, elem I, out chan<- Item[I]) StreamAction {
+			return forward(ctx, Item[I]{Value: elem}, out)
+		},
+		func(ctx context.Context, err error, out chan<- Item[I]) StreamAction {
+			return forward(ctx, Item[I]{Err: err}, out)
+		},
+		func(ctx context.Context, out chan<- Item[I]) StreamAction {
+			select {
+			case <-k.aborted:
+				util.Send(ctx, Item[I]{Err: k.err}, out)
+			default:
+			}
+			return ActionStop
+		},
+		nil,
+		opts...)
+
+	// Triggering the KillSwitch completes the flow like a complete signal from downstream, so
+	// upstream stops producing while the flow is waiting for items
+	setup := flow.setup
+	flow.setup = func(
+		ctx context.Context,
+		cancel context.CancelFunc,
+		wg *sync.WaitGroup,
+		complete <-chan struct{},
+		setupUpstream setupFunc[I],
+	) <-chan Item[I] {
+		triggered, trigger := util.NewCompleteChannel()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer trigger()
+			select {
+			case <-ctx.Done():
+			case <-complete:
+			case <-k.shutdown:
+			case <-k.aborted:
+			}
+		}()
+		return setup(ctx, cancel, wg, triggered, setupUpstream)
+	}
+
+	return flow
+}
+
+// SingleKillSwitchFlow creates a Flow together with a KillSwitch dedicated to it. This is a
+// convenience for the common case where a KillSwitch controls a single stream.
+//
+// Type Parameters:
+//   - I: The type of items passing through the flow
+//
+// Parameters:
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns the Flow and the KillSwitch controlling it
+func SingleKillSwitchFlow[I any](opts ...FlowOption) (*Flow[I, I], *KillSwitch) {
+	k := NewKillSwitch()
+	return KillSwitchFlow[I](k, opts...), k
+}
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKillSwitch(t *testing.T) {
+	errCustom := errors.New("custom")
+
+	tests := []struct {
+		name        string
+		trigger     func(k *KillSwitch)
+		expectedErr error
+	}{
+		{
+			name:        "shutdown completes the stream gracefully",
+			trigger:     func(k *KillSwitch) { k.Shutdown() },
+			expectedErr: nil,
+		},
+		{
+			name:        "abort fails the stream with the given error",
+			trigger:     func(k *KillSwitch) { k.Abort(errCustom) },
+			expectedErr: errCustom,
+		},
+		{
+			name:        "abort without error uses default error",
+			trigger:     func(k *KillSwitch) { k.Abort(nil) },
+			expectedErr: ErrKillSwitchAborted,
+		},
+		{
+			name: "only the first trigger takes effect",
+			trigger: func(k *KillSwitch) {
+				k.Shutdown()
+				k.Abort(errCustom)
+			},
+			expectedErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow, k := SingleKillSwitchFlow[int]()
+			stream := ConnectSourceToSink(AppendFlowToSource(testRepeatSource(1), flow), testSliceSink[int]())
+
+			resChan := stream.Run(context.Background())
+			time.Sleep(10 * time.Millisecond)
+			tt.trigger(k)
+
+			res := <-resChan
+			assert.ErrorIs(t, res.Err, tt.expectedErr)
+			stream.AwaitDone()
+		})
+	}
+}
+
+func TestSharedKillSwitch(t *testing.T) {
+	k := NewKillSwitch()
+	streams := []*Stream[[]int]{
+		ConnectSourceToSink(AppendFlowToSource(testRepeatSource(1), KillSwitchFlow[int](k)), testSliceSink[int]()),
+		ConnectSourceToSink(AppendFlowToSource(testRepeatSource(2), KillSwitchFlow[int](k)), testSliceSink[int]()),
+	}
+
+	results := make([]<-chan Item[[]int], len(streams))
+	for i, stream := range streams {
+		results[i] = stream.Run(context.Background())
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	k.Shutdown()
+
+	for _, res := range results {
+		assert.NoError(t, (<-res).Err)
+	}
+}
+
+func TestKillSwitchTriggeredBeforeRun(t *testing.T) {
+	k := NewKillSwitch()
+	k.Shutdown()
+
+	stream := ConnectSourceToSink(AppendFlowToSource(testRepeatSource(1), KillSwitchFlow[int](k)), testSliceSink[int]())
+
+	select {
+	case res := <-stream.Run(context.Background()):
+		assert.NoError(t, res.Err)
+	case <-time.After(time.Second):
+		t.Fatal("stream did not complete")
+	}
+}
+
+func TestKillSwitchIdleUpstream(t *testing.T) {
+	errCustom := errors.New("custom")
+
+	tests := []struct {
+		name        string
+		trigger     func(k *KillSwitch)
+		expectedErr error
+	}{
+		{
+			name:    "shutdown completes an idle upstream",
+			trigger: func(k *KillSwitch) { k.Shutdown() },
+		},
+		{
+			name:        "abort fails with an idle upstream",
+			trigger:     func(k *KillSwitch) { k.Abort(errCustom) },
+			expectedErr: errCustom,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// idle never emits, and only closes once it is completed
+			idle := NewSource(
+				func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan Item[int] {
+					out := make(chan Item[int])
+					go func() {
+						defer close(out)
+						select {
+						case <-ctx.Done():
+						case <-complete:
+						}
+					}()
+					return out
+				},
+			)
+
+			flow, k := SingleKillSwitchFlow[int]()
+			stream := ConnectSourceToSink(AppendFlowToSource(idle, flow), testSliceSink[int]())
+			resChan := stream.Run(context.Background())
+			time.Sleep(10 * time.Millisecond)
+			tt.trigger(k)
+
+			select {
+			case res := <-resChan:
+				assert.ErrorIs(t, res.Err, tt.expectedErr)
+			case <-time.After(time.Second):
+				t.Fatal("stream did not terminate")
+			}
+			stream.AwaitDone()
+		})
+	}
+}
+
+func TestKillSwitchSupervision(t *testing.T) {
+	flow := KillSwitchFlow[int](NewKillSwitch(), WithSupervision(ResumingDecider))
+	stream := ConnectSourceToSink(
+		AppendFlowToSource(testFailingSource(errors.New("failed")), flow),
+		testSliceSink[int](),
+	)
+
+	res := <-stream.Run(context.Background())
+	stream.AwaitDone()
+
+	assert.NoError(t, res.Err)
+	assert.Equal(t, []int{1, 2}, res.Value)
+}
@@ -0,0 +1,228 @@
+package core
+
+// NotUsed is the materialized value of components that do not expose one.
+type NotUsed struct{}
+
+// Pair holds two materialized values, as produced by KeepBoth.
+type Pair[L, R any] struct {
+	Left  L
+	Right R
+}
+
+// MatSource creates a Source together with a value that is exposed when the stream is
+// materialized, such as a handle to push items into the source. It is called once per
+// materialization, so every stream gets a fresh component and value.
+//
+// Type Parameters:
+//   - O: The type of items produced by the source
+//   - M: The type of the materialized value
+type MatSource[O, M any] func() (*Source[O], M)
+
+// MatFlow creates a Flow together with a value that is exposed when the stream is
+// materialized, such as a KillSwitch controlling the flow. It is called once per
+// materialization, so every stream gets a fresh component and value.
+//
+// Type Parameters:
+//   - I: The type of input items
+//   - O: The type of output items
+//   - M: The type of the materialized value
+type MatFlow[I, O, M any] func() (*Flow[I, O], M)
+
+// MatSink creates a Sink together with a value that is exposed when the stream is
+// materialized. It is called once per materialization, so every stream gets a fresh
+// component and value.
+//
+// Type Parameters:
+//   - I: The type of items consumed by the sink
+//   - R: The type of the final result
+//   - M: The type of the materialized value
+type MatSink[I, R, M any] func() (*Sink[I, R], M)
+
+// MatStream is a runnable blueprint of a stream whose stages expose materialized values.
+// Each call to Materialize creates a new Stream together with the selected materialized value.
+//
+// Type Parameters:
+//   - R: The type of the final result produced by the stream
+//   - M: The type of the materialized value
+type MatStream[R, M any] struct {
+	build func() (*Stream[R], M)
+}
+
+// Materialize creates a new Stream together with its materialized value.
+// The returned Stream is not running yet and must be started with Run.
+func (s *MatStream[R, M]) Materialize() (*Stream[R], M) {
+	return s.build()
+}
+
+// KeepLeft selects the materialized value of the upstream component.
+func KeepLeft[L, R any](left L, _ R) L {
+	return left
+}
+
+// KeepRight selects the materialized value of the downstream component.
+func KeepRight[L, R any](_ L, right R) R {
+	return right
+}
+
+// KeepBoth combines the materialized values of both components into a Pair.
+func KeepBoth[L, R any](left L, right R) Pair[L, R] {
+	return Pair[L, R]{Left: left, Right: right}
+}
+
+// KeepNone discards the materialized values of both components.
+func KeepNone[L, R any](L, R) NotUsed {
+	return NotUsed{}
+}
+
+// MatSourceOf lifts a Source without a materialized value into a MatSource.
+// The same Source is returned for every materialization.
+func MatSourceOf[O any](source *Source[O]) MatSource[O, NotUsed] {
+	return func() (*Source[O], NotUsed) {
+		return source, NotUsed{}
+	}
+}
+
+// MatFlowOf lifts a Flow without a materialized value into a MatFlow.
+// The same Flow is returned for every materialization.
+func MatFlowOf[I, O any](flow *Flow[I, O]) MatFlow[I, O, NotUsed] {
+	return func() (*Flow[I, O], NotUsed) {
+		return flow, NotUsed{}
+	}
+}
+
+// MatSinkOf lifts a Sink without a materialized value into a MatSink.
+// The same Sink is returned for every materialization.
+func MatSinkOf[I, R any](sink *Sink[I, R]) MatSink[I, R, NotUsed] {
+	return func() (*Sink[I, R], NotUsed) {
+		return sink, NotUsed{}
+	}
+}
+
+// ViaMat attaches a MatFlow to a MatSource, combining their materialized values with keep.
+//
+// Type Parameters:
+//   - I: Type of data produced by the source
+//   - O: Type of data after processing through the flow
+//   - M1: Materialized value type of the source
+//   - M2: Materialized value type of the flow
+//   - M: Combined materialized value type
+//
+// Parameters:
+//   - source: The source producing type I
+//   - flow: The flow transforming I to O
+//   - keep: Function combining the materialized values, such as KeepLeft or KeepBoth
+//
+// Returns a new MatSource that produces data of type O
+func ViaMat[I, O, M1, M2, M any](
+	source MatSource[I, M1],
+	flow MatFlow[I, O, M2],
+	keep func(M1, M2) M,
+) MatSource[O, M] {
+	return func() (*Source[O], M) {
+		s, m1 := source()
+		f, m2 := flow()
+		return AppendFlowToSource(s, f), keep(m1, m2)
+	}
+}
+
+// ToMat connects a MatSource to a MatSink, combining their materialized values with keep.
+//
+// Type Parameters:
+//   - I: Type of data produced by the source and consumed by the sink
+//   - R: Type of final result produced by the sink
+//   - M1: Materialized value type of the source
+//   - M2: Materialized value type of the sink
+//   - M: Combined materialized value type
+//
+// Parameters:
+//   - source: The source producing type I
+//   - sink: The sink consuming type I and producing result R
+//   - keep: Function combining the materialized values, such as KeepLeft or KeepBoth
+//
+// Returns a MatStream that can be materialized into a runnable Stream
+func ToMat[I, R, M1, M2, M any](
+	source MatSource[I, M1],
+	sink MatSink[I, R, M2],
+	keep func(M1, M2) M,
+) *MatStream[R, M] {
+	return &MatStream[R, M]{
+		build: func() (*Stream[R], M) {
+			s, m1 := source()
+			k, m2 := sink()
+			return ConnectSourceToSink(s, k), keep(m1, m2)
+		},
+	}
+}
+
+// FlowViaMat connects two MatFlows into a single MatFlow, combining their materialized values
+// with keep. This allows materialized values to be exposed by flows in the middle of a pipeline.
+//
+// Type Parameters:
+//   - I: Type of input data for the first flow
+//   - O1: Type of output data from the first flow (and input to the second flow)
+//   - O2: Type of output data from the second flow
+//   - M1: Materialized value type of the first flow
+//   - M2: Materialized value type of the second flow
+//   - M: Combined materialized value type
+//
+// Parameters:
+//   - flow1: The flow transforming I to O1
+//   - flow2: The flow transforming O1 to O2
+//   - keep: Function combining the materialized values, such as KeepLeft or KeepBoth
+//
+// Returns a new MatFlow that transforms data from type I to type O2
+func FlowViaMat[I, O1, O2, M1, M2, M any](
+	flow1 MatFlow[I, O1, M1],
+	flow2 MatFlow[O1, O2, M2],
+	keep func(M1, M2) M,
+) MatFlow[I, O2, M] {
+	return func() (*Flow[I, O2], M) {
+		f1, m1 := flow1()
+		f2, m2 := flow2()
+		return ConnectFlows(f1, f2), keep(m1, m2)
+	}
+}
+
+// FlowToMat prepends a MatFlow to a MatSink, combining their materialized values with keep.
+//
+// Type Parameters:
+//   - I: Type of input data for the flow
+//   - O: Type of output data from the flow (and input to the sink)
+//   - R: Type of final result produced by the sink
+//   - M1: Materialized value type of the flow
+//   - M2: Materialized value type of the sink
+//   - M: Combined materialized value type
+//
+// Parameters:
+//   - flow: The flow transforming I to O
+//   - sink: The sink consuming type O and producing result R
+//   - keep: Function combining the materialized values, such as KeepLeft or KeepBoth
+//
+// Returns a new MatSink that accepts type I and produces result R
+func FlowToMat[I, O, R, M1, M2, M any](
+	flow MatFlow[I, O, M1],
+	sink MatSink[O, R, M2],
+	keep func(M1, M2) M,
+) MatSink[I, R, M] {
+	return func() (*Sink[I, R], M) {
+		f, m1 := flow()
+		k, m2 := sink()
+		return PrependFlowToSink(f, k), keep(m1, m2)
+	}
+}
+
+// KillSwitchMat creates a MatFlow that materializes a new KillSwitch for every stream.
+// This is the per-stream variant of KillSwitchFlow.
+//
+// Type Parameters:
+//   - I: The type of items passing through the flow
+//
+// Parameters:
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a MatFlow materializing a KillSwitch controlling the stream
+func KillSwitchMat[I any](opts ...FlowOption) MatFlow[I, I, *KillSwitch] {
+	return func() (*Flow[I, I], *KillSwitch) {
+		return SingleKillSwitchFlow[I](opts...)
+	}
+}
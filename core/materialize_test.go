@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeep(t *testing.T) {
+	assert.Equal(t, 1, KeepLeft(1, "a"))
+	assert.Equal(t, "a", KeepRight(1, "a"))
+	assert.Equal(t, Pair[int, string]{Left: 1, Right: "a"}, KeepBoth(1, "a"))
+	assert.Equal(t, NotUsed{}, KeepNone(1, "a"))
+}
+
+func TestMatStream(t *testing.T) {
+	sourceMat := func() (*Source[int], string) {
+		return testSliceSource([]int{1, 2, 3}), "source"
+	}
+	sinkMat := func() (*Sink[int, []int], string) {
+		return testSliceSink[int](), "sink"
+	}
+
+	tests := []struct {
+		name  string
+		build func() *MatStream[[]int, any]
+		want  any
+	}{
+		{
+			name: "keep left",
+			build: func() *MatStream[[]int, any] {
+				return ToMat(sourceMat, sinkMat, func(l, r string) any { return KeepLeft(l, r) })
+			},
+			want: "source",
+		},
+		{
+			name: "keep right",
+			build: func() *MatStream[[]int, any] {
+				return ToMat(sourceMat, sinkMat, func(l, r string) any { return KeepRight(l, r) })
+			},
+			want: "sink",
+		},
+		{
+			name: "keep both",
+			build: func() *MatStream[[]int, any] {
+				return ToMat(sourceMat, sinkMat, func(l, r string) any { return KeepBoth(l, r) })
+			},
+			want: Pair[string, string]{Left: "source", Right: "sink"},
+		},
+		{
+			name: "lifted components",
+			build: func() *MatStream[[]int, any] {
+				source := ViaMat(
+					MatSourceOf(testSliceSource([]int{1, 2, 3})),
+					MatFlowOf(KillSwitchFlow[int](NewKillSwitch())),
+					KeepNone,
+				)
+				return ToMat(source, MatSinkOf(testSliceSink[int]()), func(l, r NotUsed) any { return KeepNone(l, r) })
+			},
+			want: NotUsed{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, mat := tt.build().Materialize()
+			assert.Equal(t, tt.want, mat)
+
+			res := <-stream.Run(context.Background())
+			assert.NoError(t, res.Err)
+			assert.Equal(t, []int{1, 2, 3}, res.Value)
+		})
+	}
+}
+
+func TestKillSwitchMat(t *testing.T) {
+	blueprint := ToMat(
+		ViaMat(MatSourceOf(testRepeatSource(1)), KillSwitchMat[int](), KeepRight),
+		MatSinkOf(testSliceSink[int]()),
+		KeepLeft,
+	)
+
+	stream1, k1 := blueprint.Materialize()
+	stream2, k2 := blueprint.Materialize()
+	assert.NotSame(t, k1, k2)
+
+	res1 := stream1.Run(context.Background())
+	res2 := stream2.Run(context.Background())
+	time.Sleep(10 * time.Millisecond)
+
+	// Each stream is controlled by its own kill switch
+	k1.Shutdown()
+	assert.NoError(t, (<-res1).Err)
+
+	k2.Abort(nil)
+	assert.ErrorIs(t, (<-res2).Err, ErrKillSwitchAborted)
+}
+
+func TestFlowViaMat(t *testing.T) {
+	flow := FlowViaMat(
+		KillSwitchMat[int](),
+		func() (*Flow[int, int], string) {
+			return testSyncMap(func(i int) int { return i * 2 }), "double"
+		},
+		KeepBoth,
+	)
+	blueprint := ToMat(
+		ViaMat(MatSourceOf(testSliceSource([]int{1, 2, 3})), flow, KeepRight),
+		MatSinkOf(testSliceSink[int]()),
+		KeepLeft,
+	)
+
+	stream1, mat1 := blueprint.Materialize()
+	_, mat2 := blueprint.Materialize()
+	assert.Equal(t, "double", mat1.Right)
+	assert.NotSame(t, mat1.Left, mat2.Left)
+
+	res := <-stream1.Run(context.Background())
+	assert.NoError(t, res.Err)
+	assert.Equal(t, []int{2, 4, 6}, res.Value)
+}
+
+func TestFlowToMat(t *testing.T) {
+	sink := FlowToMat(
+		KillSwitchMat[int](),
+		func() (*Sink[int, []int], string) {
+			return testSliceSink[int](), "sink"
+		},
+		KeepBoth,
+	)
+	blueprint := ToMat(MatSourceOf(testRepeatSource(1)), sink, KeepRight)
+
+	stream, mat := blueprint.Materialize()
+	assert.Equal(t, "sink", mat.Right)
+
+	res := stream.Run(context.Background())
+	time.Sleep(10 * time.Millisecond)
+
+	// The kill switch of the flow in front of the sink controls the stream
+	mat.Left.Abort(nil)
+	assert.ErrorIs(t, (<-res).Err, ErrKillSwitchAborted)
+}
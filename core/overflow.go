@@ -0,0 +1,205 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrBufferOverflow is emitted by a stage using OverflowFail when its buffer is full.
+var ErrBufferOverflow = errors.New("buffer overflow")
+
+// OverflowStrategy determines what a stage does with a new item when its output buffer is full.
+type OverflowStrategy int
+
+const (
+	// OverflowBackpressure blocks the stage until downstream has taken an item from the buffer.
+	// This is the default.
+	OverflowBackpressure OverflowStrategy = iota
+
+	// OverflowDropHead drops the oldest item in the buffer to make room for the new item.
+	// Use this to keep the latest items when downstream is slow.
+	OverflowDropHead
+
+	// OverflowDropTail drops the newest item in the buffer to make room for the new item.
+	OverflowDropTail
+
+	// OverflowDropNew drops the new item, keeping the buffer unchanged.
+	OverflowDropNew
+
+	// OverflowDropBuffer drops all items in the buffer and keeps only the new item.
+	OverflowDropBuffer
+
+	// OverflowFail emits ErrBufferOverflow after the buffered items and drops all further items.
+	OverflowFail
+)
+
+// String returns the name of the strategy.
+func (s OverflowStrategy) String() string {
+	switch s {
+	case OverflowBackpressure:
+		return "backpressure"
+	case OverflowDropHead:
+		return "dropHead"
+	case OverflowDropTail:
+		return "dropTail"
+	case OverflowDropNew:
+		return "dropNew"
+	case OverflowDropBuffer:
+		return "dropBuffer"
+	case OverflowFail:
+		return "fail"
+	default:
+		return fmt.Sprintf("OverflowStrategy(%d)", int(s))
+	}
+}
+
+// OverflowKey is the attribute holding the OverflowStrategy of a stage's output buffer.
+var OverflowKey = NewAttributeKey[OverflowStrategy]("overflow")
+
+// Buffer creates Attributes setting the size of a stage's output buffer and the strategy
+// applied when it is full. Strategies other than OverflowBackpressure buffer at least one item.
+func Buffer(size int, strategy OverflowStrategy) Attributes {
+	return SetAttribute(BufSize(size), OverflowKey, strategy)
+}
+
+// WithSourceBuffer returns a SourceOption that sets the size of the source's output buffer
+// and the strategy applied when it is full.
+//
+// Parameters:
+//   - size: The number of items to buffer
+//   - strategy: The OverflowStrategy applied when the buffer is full
+func WithSourceBuffer(size int, strategy OverflowStrategy) SourceOption {
+	return WithSourceAttributes(Buffer(size, strategy))
+}
+
+// WithFlowBuffer creates a FlowOption that sets the size of a Flow's output buffer and the
+// strategy applied when it is full. Unlike WithFlowBufSize, which always backpressures, this
+// allows a Flow to keep running while downstream is slow, for example keeping only the
+// latest items with OverflowDropHead.
+//
+// Parameters:
+//   - size: The number of items to buffer
+//   - strategy: The OverflowStrategy applied when the buffer is full
+//
+// Returns:
+//   - A FlowOption that can be passed to NewFlow
+func WithFlowBuffer(size int, strategy OverflowStrategy) FlowOption {
+	return WithFlowAttributes(Buffer(size, strategy))
+}
+
+// outputBufSize returns the buffer size of a stage's output channel. Stages with a strategy
+// other than OverflowBackpressure buffer their items in withOverflow, so their output channel
+// is unbuffered, as buffering it as well would hold stale items beyond the configured size.
+func outputBufSize(attrs Attributes) int {
+	if strategy, _ := GetAttribute(attrs, OverflowKey); strategy != OverflowBackpressure {
+		return 0
+	}
+	size, _ := GetAttribute(attrs, BufSizeKey)
+	return size
+}
+
+// withOverflow applies the OverflowStrategy of a stage to its output. For OverflowBackpressure,
+// in is returned unchanged. Otherwise, items are read from in as soon as they are produced and
+// buffered until downstream takes them, applying the strategy when the buffer is full.
+// Dropped items are reported with DropReasonBufferOverflow. Errors are never dropped.
+func withOverflow[T any](ctx context.Context, wg *sync.WaitGroup, attrs Attributes, in <-chan Item[T]) <-chan Item[T] {
+	strategy, _ := GetAttribute(attrs, OverflowKey)
+	if strategy == OverflowBackpressure {
+		return in
+	}
+
+	size, _ := GetAttribute(attrs, BufSizeKey)
+	size = max(size, 1)
+	name, _ := GetAttribute(attrs, NameKey)
+
+	out := make(chan Item[T])
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(out)
+
+		var queue []Item[T]
+		failed := false
+		drop := func(item Item[T]) {
+			ReportDrop(ctx, name, DropReasonBufferOverflow, item.Value)
+		}
+
+		for in != nil || len(queue) > 0 {
+			// Only offer an item downstream while the buffer holds one
+			var send chan<- Item[T]
+			var head Item[T]
+			if len(queue) > 0 {
+				send = out
+				head = queue[0]
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case send <- head:
+				queue = queue[1:]
+			case item, ok := <-in:
+				switch {
+				case !ok:
+					in = nil
+				case failed:
+					drop(item)
+				case item.Err != nil || len(queue) < size:
+					queue = append(queue, item)
+				default:
+					queue, failed = overflow(strategy, queue, item, drop)
+					if failed {
+						queue = append(queue, Item[T]{Err: attribute(name, ErrBufferOverflow)})
+					}
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// overflow applies the strategy to a full buffer receiving item. It returns the new buffer
+// and whether the stage failed.
+func overflow[T any](strategy OverflowStrategy, queue []Item[T], item Item[T], drop func(Item[T])) ([]Item[T], bool) {
+	isValue := func(i Item[T]) bool {
+		return i.Err == nil
+	}
+
+	switch strategy {
+	case OverflowDropHead:
+		if idx := slices.IndexFunc(queue, isValue); idx >= 0 {
+			drop(queue[idx])
+			queue = slices.Delete(queue, idx, idx+1)
+		}
+		return append(queue, item), false
+	case OverflowDropTail:
+		for idx := len(queue) - 1; idx >= 0; idx-- {
+			if isValue(queue[idx]) {
+				drop(queue[idx])
+				queue = slices.Delete(queue, idx, idx+1)
+				break
+			}
+		}
+		return append(queue, item), false
+	case OverflowDropBuffer:
+		queue = slices.DeleteFunc(queue, func(i Item[T]) bool {
+			if isValue(i) {
+				drop(i)
+				return true
+			}
+			return false
+		})
+		return append(queue, item), false
+	case OverflowFail:
+		drop(item)
+		return queue, true
+	default: // OverflowDropNew
+		drop(item)
+		return queue, false
+	}
+}
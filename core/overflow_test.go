@@ -0,0 +1,180 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/util"
+)
+
+func TestWithOverflow(t *testing.T) {
+	testErr := errors.New("test error")
+
+	tests := []struct {
+		name          string
+		attrs         Attributes
+		input         []Item[int]
+		expected      []Item[int]
+		expectedDrops int64
+	}{
+		{
+			name:     "drop head keeps latest items",
+			attrs:    Buffer(2, OverflowDropHead),
+			input:    []Item[int]{{Value: 1}, {Value: 2}, {Value: 3}, {Value: 4}, {Value: 5}},
+			expected: []Item[int]{{Value: 4}, {Value: 5}},
+
+			expectedDrops: 3,
+		},
+		{
+			name:     "drop tail replaces newest buffered item",
+			attrs:    Buffer(2, OverflowDropTail),
+			input:    []Item[int]{{Value: 1}, {Value: 2}, {Value: 3}, {Value: 4}, {Value: 5}},
+			expected: []Item[int]{{Value: 1}, {Value: 5}},
+
+			expectedDrops: 3,
+		},
+		{
+			name:     "drop new keeps earliest items",
+			attrs:    Buffer(2, OverflowDropNew),
+			input:    []Item[int]{{Value: 1}, {Value: 2}, {Value: 3}, {Value: 4}, {Value: 5}},
+			expected: []Item[int]{{Value: 1}, {Value: 2}},
+
+			expectedDrops: 3,
+		},
+		{
+			name:     "drop buffer keeps only new item",
+			attrs:    Buffer(2, OverflowDropBuffer),
+			input:    []Item[int]{{Value: 1}, {Value: 2}, {Value: 3}, {Value: 4}, {Value: 5}},
+			expected: []Item[int]{{Value: 5}},
+
+			expectedDrops: 4,
+		},
+		{
+			name:     "fail emits error after buffered items",
+			attrs:    Buffer(2, OverflowFail),
+			input:    []Item[int]{{Value: 1}, {Value: 2}, {Value: 3}, {Value: 4}},
+			expected: []Item[int]{{Value: 1}, {Value: 2}, {Err: ErrBufferOverflow}},
+
+			expectedDrops: 2,
+		},
+		{
+			name:     "errors are never dropped",
+			attrs:    Buffer(2, OverflowDropHead),
+			input:    []Item[int]{{Value: 1}, {Err: testErr}, {Value: 2}, {Value: 3}},
+			expected: []Item[int]{{Err: testErr}, {Value: 3}},
+
+			expectedDrops: 2,
+		},
+		{
+			name:     "zero buffer size buffers one item",
+			attrs:    Buffer(0, OverflowDropHead),
+			input:    []Item[int]{{Value: 1}, {Value: 2}},
+			expected: []Item[int]{{Value: 2}},
+
+			expectedDrops: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drops := NewDropCounter()
+			ctx := WithDropHandler(context.Background(), drops.Handle)
+			wg := &sync.WaitGroup{}
+
+			in := make(chan Item[int])
+			out := withOverflow(ctx, wg, tt.attrs, in)
+
+			// in is unbuffered, so all items are taken by the buffer before out is read
+			for _, item := range tt.input {
+				in <- item
+			}
+			close(in)
+
+			res := []Item[int]{}
+			for item := range out {
+				res = append(res, item)
+			}
+			wg.Wait()
+
+			assert.Equal(t, tt.expected, res)
+			assert.Equal(t, tt.expectedDrops, drops.Total())
+		})
+	}
+}
+
+func TestWithOverflowBackpressure(t *testing.T) {
+	in := make(chan Item[int])
+	out := withOverflow(context.Background(), &sync.WaitGroup{}, BufSize(2), in)
+
+	assert.Equal(t, (<-chan Item[int])(in), out)
+}
+
+func TestWithFlowBuffer(t *testing.T) {
+	drops := NewDropCounter()
+	ctx := WithDropHandler(context.Background(), drops.Handle)
+
+	stream := ConnectSourceToSink(
+		AppendFlowToSource(testSliceSource([]int{1, 2, 3}), testPassFlow(WithFlowBuffer(1, OverflowDropNew))),
+		testSliceSink[int](),
+	)
+	res := <-stream.Run(ctx)
+	stream.AwaitDone()
+
+	// Items are only dropped while the sink is slower than the flow
+	assert.NoError(t, res.Err)
+	assert.Equal(t, int64(3), int64(len(res.Value))+drops.Count("", DropReasonBufferOverflow))
+}
+
+func TestOutputBufSize(t *testing.T) {
+	assert.Equal(t, 3, outputBufSize(BufSize(3)))
+	assert.Equal(t, 3, outputBufSize(Buffer(3, OverflowBackpressure)))
+	assert.Equal(t, 0, outputBufSize(Buffer(3, OverflowDropHead)))
+}
+
+func TestWithFlowBufferKeepsLatest(t *testing.T) {
+	input := make([]int, 20)
+	for i := range input {
+		input[i] = i + 1
+	}
+
+	var sent atomic.Int64
+	flow := NewFlow(
+		func(ctx context.Context, elem int, out chan<- Item[int]) StreamAction {
+			util.Send(ctx, Item[int]{Value: elem}, out)
+			sent.Add(1)
+			return ActionProceed
+		},
+		nil,
+		nil,
+		nil,
+		WithFlowBuffer(3, OverflowDropHead),
+	)
+
+	// The sink holds the first item until the flow has sent all items
+	release := make(chan struct{})
+	sink := NewSink(
+		[]int{},
+		func(ctx context.Context, elem int, acc Item[[]int]) (Item[[]int], StreamAction) {
+			<-release
+			return Item[[]int]{Value: append(acc.Value, elem)}, ActionProceed
+		},
+		nil,
+		nil,
+	)
+
+	stream := ConnectSourceToSink(AppendFlowToSource(testSliceSource(input), flow), sink)
+	res := stream.Run(context.Background())
+	assert.Eventually(t, func() bool { return sent.Load() == int64(len(input)) }, time.Second, time.Millisecond)
+	close(release)
+
+	result := <-res
+	stream.AwaitDone()
+
+	assert.NoError(t, result.Err)
+	assert.Equal(t, []int{1, 18, 19, 20}, result.Value)
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is the error a panic in a callback of a stage is converted into. It is handled
+// by the error handler of the stage like any other error produced by the stage, so by default
+// the stream fails with it instead of crashing the process.
+type PanicError struct {
+	// Value is the value the callback panicked with
+	Value any
+
+	// Stack is the stack trace of the goroutine at the time of the panic
+	Stack []byte
+}
+
+// Error returns a message describing the panic value.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error, so that errors.Is and errors.As can
+// inspect it.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// PanicPolicy determines what happens when a callback of a stage panics.
+type PanicPolicy int
+
+const (
+	// PanicRecover converts the panic into a PanicError, which is passed to the error handler
+	// of the stage. This is the default.
+	PanicRecover PanicPolicy = iota
+
+	// PanicPropagate lets the panic propagate, crashing the process unless it is recovered
+	// elsewhere. Use it to get the original crash while debugging.
+	PanicPropagate
+)
+
+// String returns the name of the policy.
+func (p PanicPolicy) String() string {
+	switch p {
+	case PanicRecover:
+		return "recover"
+	case PanicPropagate:
+		return "propagate"
+	default:
+		return fmt.Sprintf("PanicPolicy(%d)", int(p))
+	}
+}
+
+// PanicPolicyKey is the attribute holding the PanicPolicy of a stage.
+var PanicPolicyKey = NewAttributeKey[PanicPolicy]("panicPolicy")
+
+// RecoverPanics creates Attributes making stages convert panics in their callbacks into
+// errors, which is the default. Use it to restore the default for a segment of a stream run
+// with PropagatePanics.
+func RecoverPanics() Attributes {
+	return SetAttribute(Attributes{}, PanicPolicyKey, PanicRecover)
+}
+
+// PropagatePanics creates Attributes making stages let panics in their callbacks propagate.
+func PropagatePanics() Attributes {
+	return SetAttribute(Attributes{}, PanicPolicyKey, PanicPropagate)
+}
+
+// CatchPanic calls fn, returning a PanicError if it panics and the PanicPolicy in effect for
+// the stage ctx was passed to recovers panics. Stages call their callbacks through it
+// automatically; use it for callbacks invoked from goroutines started by a stage, such as
+// the goroutine of a Source generating items.
+//
+// Parameters:
+//   - ctx: The context passed to the setup or callbacks of a stage
+//   - fn: The function to call
+//
+// Returns:
+//   - A PanicError if fn panicked, or nil
+func CatchPanic(ctx context.Context, fn func()) error {
+	policy, _ := GetAttribute(AttributesFromContext(ctx), PanicPolicyKey)
+	return catchPanic(policy, fn)
+}
+
+// catchPanic calls fn, converting a panic into a PanicError unless policy propagates it.
+func catchPanic(policy PanicPolicy, fn func()) (err error) {
+	if policy == PanicRecover {
+		defer func() {
+			if v := recover(); v != nil {
+				err = &PanicError{Value: v, Stack: debug.Stack()}
+			}
+		}()
+	}
+	fn()
+	return nil
+}
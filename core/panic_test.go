@@ -0,0 +1,179 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testPanicFlow creates a Flow applying fn to every element in its own goroutine.
+func testPanicFlow(fn func(int) int) *Flow[int, int] {
+	return NewFlow(
+		func(ctx context.Context, elem int, out chan<- Item[int]) StreamAction {
+			out <- Item[int]{Value: fn(elem)}
+			return ActionProceed
+		},
+		nil,
+		nil,
+		nil,
+	)
+}
+
+// testPanicSink creates a Sink collecting all elements after applying fn to them.
+func testPanicSink(fn func(int) int) *Sink[int, []int] {
+	return NewSink(
+		[]int{},
+		func(ctx context.Context, in int, acc Item[[]int]) (Item[[]int], StreamAction) {
+			return Item[[]int]{Value: append(acc.Value, fn(in))}, ActionProceed
+		},
+		nil,
+		nil,
+	)
+}
+
+func TestPanicRecovery(t *testing.T) {
+	panicErr := errors.New("panic error")
+	panicOn := func(value any) func(int) int {
+		return func(i int) int {
+			if i == 2 {
+				panic(value)
+			}
+			return i
+		}
+	}
+	identity := func(i int) int { return i }
+
+	tests := []struct {
+		name          string
+		flow          *Flow[int, int]
+		sink          *Sink[int, []int]
+		expected      []int
+		expectedValue any
+		expectedErr   error
+		expectedStage string
+	}{
+		{
+			name:          "panic in synchronous flow fails stream",
+			flow:          testSyncMap(panicOn("boom")),
+			sink:          testSliceSink[int](),
+			expected:      []int{1},
+			expectedValue: "boom",
+		},
+		{
+			name:          "panic in flow fails stream",
+			flow:          testPanicFlow(panicOn("boom")),
+			sink:          testSliceSink[int](),
+			expected:      []int{1},
+			expectedValue: "boom",
+		},
+		{
+			name:          "panic in sink fails stream",
+			flow:          testPassFlow(),
+			sink:          testPanicSink(panicOn("boom")),
+			expected:      []int{1},
+			expectedValue: "boom",
+		},
+		{
+			name:          "panic with error value unwraps to it",
+			flow:          testSyncMap(panicOn(panicErr)),
+			sink:          testSliceSink[int](),
+			expected:      []int{1},
+			expectedValue: panicErr,
+			expectedErr:   panicErr,
+		},
+		{
+			name:          "panic in named flow is attributed to it",
+			flow:          testSyncMap(panicOn("boom"), WithFlowName("parse")),
+			sink:          testSliceSink[int](),
+			expected:      []int{1},
+			expectedValue: "boom",
+			expectedStage: "parse",
+		},
+		{
+			name: "panic is passed to supervision",
+			flow: ConnectFlows(
+				testSyncMap(identity),
+				testSyncMap(panicOn("boom"), WithSupervision(ResumingDecider)),
+			),
+			sink:     testSliceSink[int](),
+			expected: []int{1, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := ConnectSourceToSink(
+				AppendFlowToSource(testSliceSource([]int{1, 2, 3}), tt.flow),
+				tt.sink,
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			assert.Equal(t, tt.expected, res.Value)
+			if tt.expectedValue == nil {
+				assert.NoError(t, res.Err)
+				return
+			}
+
+			var pe *PanicError
+			if assert.ErrorAs(t, res.Err, &pe) {
+				assert.Equal(t, tt.expectedValue, pe.Value)
+				assert.NotEmpty(t, pe.Stack)
+			}
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, res.Err, tt.expectedErr)
+			}
+			var stageErr *StageError
+			assert.Equal(t, tt.expectedStage != "", errors.As(res.Err, &stageErr))
+			if tt.expectedStage != "" {
+				assert.Equal(t, tt.expectedStage, stageErr.Stage)
+			}
+		})
+	}
+}
+
+func TestCatchPanic(t *testing.T) {
+	tests := []struct {
+		name          string
+		attrs         Attributes
+		fn            func()
+		expectedErr   bool
+		expectedPanic bool
+	}{
+		{
+			name:  "returns nil without panic",
+			attrs: Attributes{},
+			fn:    func() {},
+		},
+		{
+			name:        "recovers panic by default",
+			attrs:       Attributes{},
+			fn:          func() { panic("boom") },
+			expectedErr: true,
+		},
+		{
+			name:          "propagates panic when configured",
+			attrs:         PropagatePanics(),
+			fn:            func() { panic("boom") },
+			expectedPanic: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := ContextWithAttributes(context.Background(), tt.attrs)
+
+			if tt.expectedPanic {
+				assert.Panics(t, func() { _ = CatchPanic(ctx, tt.fn) })
+				return
+			}
+
+			err := CatchPanic(ctx, tt.fn)
+			var pe *PanicError
+			assert.Equal(t, tt.expectedErr, errors.As(err, &pe))
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// pauseGateKey is the context key under which the pause gate of a running stream is stored.
+type pauseGateKey struct{}
+
+// pauseGate holds back the sources of a stream while it is paused. The gate is open while
+// its channel is closed, so waiting sources are released at once when it is resumed. All
+// methods can be called on a nil gate, which never pauses.
+type pauseGate struct {
+	mu     sync.Mutex
+	open   chan struct{}
+	paused bool
+}
+
+// newPauseGate creates an open pause gate.
+func newPauseGate() *pauseGate {
+	open := make(chan struct{})
+	close(open)
+	return &pauseGate{open: open}
+}
+
+// pauseGateOf returns the pause gate of the stream ctx belongs to, or nil if there is none.
+func pauseGateOf(ctx context.Context) *pauseGate {
+	g, _ := ctx.Value(pauseGateKey{}).(*pauseGate)
+	return g
+}
+
+// pause closes the gate.
+func (g *pauseGate) pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.paused {
+		g.open = make(chan struct{})
+		g.paused = true
+	}
+}
+
+// resume opens the gate, releasing all waiting sources.
+func (g *pauseGate) resume() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.paused {
+		close(g.open)
+		g.paused = false
+	}
+}
+
+// isPaused returns whether the gate is closed.
+func (g *pauseGate) isPaused() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused
+}
+
+// wait blocks while the gate is closed. It returns false if ctx is cancelled or complete
+// is closed before the gate opens.
+func (g *pauseGate) wait(ctx context.Context, complete <-chan struct{}) bool {
+	if g == nil {
+		return true
+	}
+	g.mu.Lock()
+	open := g.open
+	g.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-complete:
+		return false
+	case <-open:
+		return true
+	}
+}
+
+// Pause stops the sources of the stream from emitting items, until Resume is called.
+// Items already in flight are not dropped: a source holds on to the item it received
+// while paused, and items buffered further down the pipeline continue to be processed.
+// This allows a stream to be halted for maintenance windows or while a consumer is
+// lagging, without tearing it down.
+//
+// Every source that emits items itself respects the pause, including sources created by
+// NewSource and the Source of a MergeHub. Sources composed of other sources, such as
+// those created by MergeSources, BroadcastSource and SourceWithAttributes, are paused
+// through the sources they are composed of.
+//
+// Cancel and Drain stop a paused stream as usual. A stream that is paused when it is
+// run starts paused. Pausing a paused stream has no effect.
+func (s *Stream[R]) Pause() {
+	s.gate.pause()
+}
+
+// Resume lets the sources of a paused stream continue emitting items.
+// Resuming a stream that is not paused has no effect.
+func (s *Stream[R]) Resume() {
+	s.gate.resume()
+}
+
+// Paused returns whether the stream is paused.
+func (s *Stream[R]) Paused() bool {
+	return s.gate.isPaused()
+}
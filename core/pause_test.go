@@ -0,0 +1,149 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamPause(t *testing.T) {
+	tests := []struct {
+		name        string
+		stop        func(s *Stream[[]int])
+		expected    []int
+		expectedErr error
+	}{
+		{
+			name:     "resumes paused stream",
+			stop:     func(s *Stream[[]int]) { s.Resume() },
+			expected: []int{1, 2, 3},
+		},
+		{
+			name:     "drains paused stream",
+			stop:     func(s *Stream[[]int]) { s.Drain() },
+			expected: []int{},
+		},
+		{
+			name:        "cancels paused stream",
+			stop:        func(s *Stream[[]int]) { s.Cancel() },
+			expectedErr: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen atomic.Int32
+			stream := ConnectSourceToSink(
+				AppendFlowToSource(testSliceSource([]int{1, 2, 3}), testSyncMap(func(i int) int {
+					seen.Add(1)
+					return i
+				})),
+				testSliceSink[int](),
+			)
+
+			stream.Pause()
+			assert.True(t, stream.Paused())
+			res := stream.Run(context.Background())
+
+			time.Sleep(50 * time.Millisecond)
+			assert.Equal(t, int32(0), seen.Load())
+
+			tt.stop(stream)
+			result := <-res
+			stream.AwaitDone()
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, result.Err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, result.Err)
+			assert.Equal(t, tt.expected, result.Value)
+		})
+	}
+}
+
+func TestStreamPauseRunning(t *testing.T) {
+	var seen atomic.Int32
+	stream := ConnectSourceToSink(
+		AppendFlowToSource(testRepeatSource(1), testSyncMap(func(i int) int {
+			seen.Add(1)
+			return i
+		})),
+		testSliceSink[int](),
+	)
+
+	res := stream.Run(context.Background())
+	assert.Eventually(t, func() bool { return seen.Load() > 0 }, time.Second, time.Millisecond)
+
+	// Only the items already in flight are processed once the stream is paused
+	stream.Pause()
+	time.Sleep(50 * time.Millisecond)
+	paused := seen.Load()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, paused, seen.Load())
+
+	stream.Resume()
+	assert.False(t, stream.Paused())
+	assert.Eventually(t, func() bool { return seen.Load() > paused }, time.Second, time.Millisecond)
+
+	stream.Drain()
+	result := <-res
+	stream.AwaitDone()
+
+	assert.NoError(t, result.Err)
+}
+
+func TestStreamPauseHub(t *testing.T) {
+	tests := []struct {
+		name   string
+		source func(hub *MergeHub[int]) *Source[int]
+	}{
+		{
+			name:   "pauses merge hub",
+			source: func(hub *MergeHub[int]) *Source[int] { return hub.Source() },
+		},
+		{
+			name: "pauses broadcast of merge hub",
+			source: func(hub *MergeHub[int]) *Source[int] {
+				return MergeSources(BroadcastSource(hub.Source(), 2)...)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := NewMergeHub[int](0)
+			var seen atomic.Int32
+			stream := ConnectSourceToSink(
+				AppendFlowToSource(
+					SourceWithAttributes(tt.source(hub), Name("hub")),
+					testSyncMap(func(i int) int {
+						seen.Add(1)
+						return i
+					}),
+				),
+				testSliceSink[int](),
+			)
+
+			stream.Pause()
+			res := stream.Run(context.Background())
+			producer := ConnectSourceToSink(testSliceSource([]int{1, 2, 3}), hub.Sink())
+			producerRes := producer.Run(context.Background())
+
+			time.Sleep(50 * time.Millisecond)
+			assert.Equal(t, int32(0), seen.Load())
+
+			stream.Resume()
+			assert.NoError(t, (<-producerRes).Err)
+			assert.Eventually(t, func() bool { return seen.Load() > 0 }, time.Second, time.Millisecond)
+
+			stream.Drain()
+			result := <-res
+			stream.AwaitDone()
+			assert.NoError(t, result.Err)
+		})
+	}
+}
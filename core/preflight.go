@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrPreflightFailed is returned by Stream.Preflight when at least one check failed.
+var ErrPreflightFailed = errors.New("preflight failed")
+
+// PreflightCheck is a named check verifying that a stage can run, such as whether a
+// connector can reach its service, a queue exists or the required permissions are granted.
+type PreflightCheck struct {
+	// Name identifies the check in errors
+	Name string
+
+	// Check returns an error if the stage cannot run
+	Check func(ctx context.Context) error
+}
+
+// WithSourcePreflight returns a SourceOption that registers a preflight check for the source.
+// The check is run by Stream.Preflight of any stream the source is part of.
+//
+// Parameters:
+//   - name: Name identifying the check in errors
+//   - check: Function returning an error if the source cannot run
+func WithSourcePreflight(name string, check func(ctx context.Context) error) SourceOption {
+	return func(c *sourceConfig) {
+		c.preflight = append(c.preflight, PreflightCheck{Name: name, Check: check})
+	}
+}
+
+// WithFlowPreflight returns a FlowOption that registers a preflight check for the flow.
+// The check is run by Stream.Preflight of any stream the flow is part of.
+//
+// Parameters:
+//   - name: Name identifying the check in errors
+//   - check: Function returning an error if the flow cannot run
+func WithFlowPreflight(name string, check func(ctx context.Context) error) FlowOption {
+	return func(c *flowConfig) {
+		c.preflight = append(c.preflight, PreflightCheck{Name: name, Check: check})
+	}
+}
+
+// WithSinkPreflight returns a SinkOption that registers a preflight check for the sink.
+// The check is run by Stream.Preflight of any stream the sink is part of.
+//
+// Parameters:
+//   - name: Name identifying the check in errors
+//   - check: Function returning an error if the sink cannot run
+func WithSinkPreflight(name string, check func(ctx context.Context) error) SinkOption {
+	return func(c *sinkConfig) {
+		c.preflight = append(c.preflight, PreflightCheck{Name: name, Check: check})
+	}
+}
+
+// broadcastPreflight returns the checks of a broadcast source for the given branch.
+func broadcastPreflight(checks []PreflightCheck, branch int) []PreflightCheck {
+	if branch > 0 {
+		return nil
+	}
+	return checks
+}
+
+// preflightOf concatenates the preflight checks of connected components.
+func preflightOf(lists ...[]PreflightCheck) []PreflightCheck {
+	return slices.Concat(lists...)
+}
+
+// AddPreflight registers an additional preflight check for the stream, next to the checks
+// registered by its stages.
+//
+// Parameters:
+//   - name: Name identifying the check in errors
+//   - check: Function returning an error if the stream cannot run
+func (s *Stream[R]) AddPreflight(name string, check func(ctx context.Context) error) {
+	s.preflight = append(s.preflight, PreflightCheck{Name: name, Check: check})
+}
+
+// Preflight runs all preflight checks registered by the stages of the stream and through
+// AddPreflight. Checks run concurrently, and Preflight waits for all of them to finish so
+// every problem is reported at once. Call Preflight before Run to fail fast on problems
+// such as missing permissions, instead of discovering them once items are processed.
+//
+// Parameters:
+//   - ctx: Context bounding the checks
+//
+// Returns nil if all checks passed, or an error wrapping ErrPreflightFailed and joining
+// the errors of all failed checks
+func (s *Stream[R]) Preflight(ctx context.Context) error {
+	errs := make([]error, len(s.preflight))
+
+	var wg sync.WaitGroup
+	for i, check := range s.preflight {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := check.Check(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", check.Name, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%w: %w", ErrPreflightFailed, err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamPreflight(t *testing.T) {
+	errQueue := errors.New("queue does not exist")
+	errAccess := errors.New("access denied")
+	pass := func(ctx context.Context) error { return nil }
+
+	tests := []struct {
+		name        string
+		stream      func() *Stream[[]int]
+		wantErrs    []error
+		wantMessage string
+	}{
+		{
+			name: "passes without checks",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(testSliceSource([]int{1}), testSliceSink[int]())
+			},
+		},
+		{
+			name: "passes when all checks pass",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(
+					AppendFlowToSource(
+						testSliceSource([]int{1}, WithSourcePreflight("source", pass)),
+						testPassFlow(WithFlowPreflight("flow", pass)),
+					),
+					testSliceSink[int](),
+				)
+			},
+		},
+		{
+			name: "aggregates failures of all stages",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(
+					testSliceSource([]int{1}, WithSourcePreflight("queue", func(ctx context.Context) error {
+						return errQueue
+					})),
+					PrependFlowToSink(
+						testPassFlow(WithFlowPreflight("flow", pass)),
+						NewSink[int]([]int{}, nil, nil, nil, WithSinkPreflight("bus", func(ctx context.Context) error {
+							return errAccess
+						})),
+					),
+				)
+			},
+			wantErrs:    []error{ErrPreflightFailed, errQueue, errAccess},
+			wantMessage: "preflight failed: queue: queue does not exist\nbus: access denied",
+		},
+		{
+			name: "runs checks added to the stream",
+			stream: func() *Stream[[]int] {
+				stream := ConnectSourceToSink(testSliceSource([]int{1}), testSliceSink[int]())
+				stream.AddPreflight("permissions", func(ctx context.Context) error {
+					return errAccess
+				})
+				return stream
+			},
+			wantErrs: []error{ErrPreflightFailed, errAccess},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.stream().Preflight(context.Background())
+			if len(tt.wantErrs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			for _, want := range tt.wantErrs {
+				assert.ErrorIs(t, err, want)
+			}
+			if tt.wantMessage != "" {
+				assert.EqualError(t, err, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestStreamPreflightBroadcastRunsSourceChecksOnce(t *testing.T) {
+	var calls atomic.Int32
+	source := testSliceSource([]int{1}, WithSourcePreflight("source", func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}))
+
+	branches := BroadcastSource(source, 2)
+	stream := ConnectSourceToSink(MergeSources(branches...), testSliceSink[int]())
+
+	assert.NoError(t, stream.Preflight(context.Background()))
+	assert.Equal(t, int32(1), calls.Load())
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// StageReport summarizes the items handled by a single stage during a run of a stream, and the
+// time it spent waiting on upstream and blocked by downstream. See Stream.WatchStages.
+type StageReport struct {
+	StageInfo
+
+	// Items is the number of items the stage has handled
+	Items int64
+
+	// Waiting is the total time the stage spent waiting for items from upstream
+	Waiting time.Duration
+
+	// Blocked is the total time the stage spent waiting for downstream to accept items
+	Blocked time.Duration
+}
+
+// String returns the stage together with the number of items it handled.
+func (r StageReport) String() string {
+	return fmt.Sprintf("%s: %d items", r.StageInfo, r.Items)
+}
+
+// RunReport summarizes a single run of a stream, as delivered to the functions registered
+// with Stream.OnReport.
+type RunReport struct {
+	// Start is the time the run was started
+	Start time.Time
+
+	// Duration is the wall-clock time from starting the run until its result was produced
+	Duration time.Duration
+
+	// DrainLatency is the time from calling Drain until the result was produced,
+	// or zero if the stream was not drained
+	DrainLatency time.Duration
+
+	// Stages holds the items handled per stage, from source to sink
+	Stages []StageReport
+
+	// Errors is the number of errors stages handled without failing the stream,
+	// as reported to the listeners registered with Stream.OnError
+	Errors int64
+
+	// Err is the terminal error of the run, which is nil if it completed successfully
+	Err error
+}
+
+// String returns a one-line summary of the run, suitable for logging.
+func (r RunReport) String() string {
+	stages := make([]string, len(r.Stages))
+	for i, stage := range r.Stages {
+		stages[i] = stage.String()
+	}
+	status := "completed"
+	if r.Err != nil {
+		status = "failed: " + r.Err.Error()
+	}
+	return fmt.Sprintf("%s in %s with %d handled errors: %s",
+		status, r.Duration.Round(time.Millisecond), r.Errors, strings.Join(stages, ", "))
+}
+
+// OnReport registers a function that is called with a RunReport once every run of the stream
+// has terminated, so batch jobs can log a summary without instrumenting every flow. Reports are
+// only collected if a function is registered, as counting the items of every stage adds a small
+// cost per item.
+//
+// Report functions are called once all stages have stopped, after the termination hooks and
+// before the result is delivered on the channel returned by Run. Adjacent synchronous flows
+// fused into a single stage are reported as one stage, and stages of junctions and hubs are not
+// reported. Report functions must be registered before the stream is started.
+//
+// Parameters:
+//   - fn: Function called with the report of every run
+func (s *Stream[R]) OnReport(fn func(report RunReport)) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.reporters = append(s.reporters, fn)
+}
+
+// runStatsKey is the context key under which the statistics of a run are collected.
+type runStatsKey struct{}
+
+// runStats collects the statistics of a single run for its RunReport.
+type runStats struct {
+	start  time.Time
+	probes *stageProbes
+	errors atomic.Int64
+}
+
+// countError is an ErrorListener counting the errors handled during the run.
+func (r *runStats) countError(_ context.Context, _ ErrorEvent) {
+	r.errors.Add(1)
+}
+
+// report creates the RunReport of the run, given the time Drain was called in unix
+// nanoseconds, or zero if it was not.
+func (r *runStats) report(err error, drainedAt int64) RunReport {
+	end := time.Now()
+
+	r.probes.mu.Lock()
+	probes := r.probes.probes
+	r.probes.mu.Unlock()
+
+	stages := make([]StageReport, len(probes))
+	for i, probe := range probes {
+		status := probe.status()
+		stages[i] = StageReport{
+			StageInfo: status.StageInfo,
+			Items:     status.Items,
+			Waiting:   status.Waiting,
+			Blocked:   status.Blocked,
+		}
+	}
+
+	var drainLatency time.Duration
+	if drainedAt != 0 {
+		drainLatency = end.Sub(time.Unix(0, drainedAt))
+	}
+
+	return RunReport{
+		Start:        r.start,
+		Duration:     end.Sub(r.start),
+		DrainLatency: drainLatency,
+		Stages:       stages,
+		Errors:       r.errors.Load(),
+		Err:          err,
+	}
+}
+
+// report calls the registered report functions with the report of the run ctx belongs to,
+// if reports are collected.
+func (s *Stream[R]) report(ctx context.Context, err error) {
+	stats, ok := ctx.Value(runStatsKey{}).(*runStats)
+	if !ok {
+		return
+	}
+
+	s.hooksMu.Lock()
+	reporters := s.reporters
+	s.hooksMu.Unlock()
+
+	report := stats.report(err, s.drainedAt.Load())
+	for _, fn := range reporters {
+		fn(report)
+	}
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamOnReport(t *testing.T) {
+	testErr := errors.New("test error")
+
+	tests := []struct {
+		name           string
+		input          []Item[int]
+		flow           *Flow[int, int]
+		expectedStages []StageReport
+		expectedErrors int64
+		expectedErr    error
+	}{
+		{
+			name:  "counts items per stage",
+			input: []Item[int]{{Value: 1}, {Value: 2}, {Value: 3}},
+			flow:  testPassFlow(WithFlowName("pass")),
+			expectedStages: []StageReport{
+				{StageInfo: StageInfo{Kind: StageKindSource}, Items: 3},
+				{StageInfo: StageInfo{Kind: StageKindFlow, Name: "pass"}, Items: 3},
+				{StageInfo: StageInfo{Kind: StageKindSink}, Items: 3},
+			},
+		},
+		{
+			name:  "counts handled errors",
+			input: []Item[int]{{Value: 1}, {Err: testErr}, {Value: 2}},
+			flow:  testPassFlow(WithFlowName("pass"), WithSupervision(ResumingDecider)),
+			expectedStages: []StageReport{
+				{StageInfo: StageInfo{Kind: StageKindSource}, Items: 3},
+				{StageInfo: StageInfo{Kind: StageKindFlow, Name: "pass"}, Items: 3},
+				{StageInfo: StageInfo{Kind: StageKindSink}, Items: 2},
+			},
+			expectedErrors: 1,
+		},
+		{
+			name:  "reports terminal error",
+			input: []Item[int]{{Value: 1}, {Err: testErr}},
+			flow:  testPassFlow(WithFlowName("pass")),
+			expectedStages: []StageReport{
+				{StageInfo: StageInfo{Kind: StageKindSource}, Items: 2},
+				{StageInfo: StageInfo{Kind: StageKindFlow, Name: "pass"}, Items: 2},
+				{StageInfo: StageInfo{Kind: StageKindSink}, Items: 2},
+			},
+			expectedErr: testErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := ConnectSourceToSink(AppendFlowToSource(testItemSource(tt.input), tt.flow), testSliceSink[int]())
+
+			var reports []RunReport
+			stream.OnReport(func(report RunReport) {
+				reports = append(reports, report)
+			})
+
+			start := time.Now()
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			assert.ErrorIs(t, res.Err, tt.expectedErr)
+			require.Len(t, reports, 1)
+			report := reports[0]
+			for i := range report.Stages {
+				// The time spent waiting and blocked depends on scheduling
+				report.Stages[i].Waiting, report.Stages[i].Blocked = 0, 0
+			}
+			assert.Equal(t, tt.expectedStages, report.Stages)
+			assert.Equal(t, tt.expectedErrors, report.Errors)
+			assert.Equal(t, res.Err, report.Err)
+			assert.False(t, report.Start.Before(start))
+			assert.Positive(t, report.Duration)
+			assert.Zero(t, report.DrainLatency)
+		})
+	}
+}
+
+func TestStreamOnReportDrain(t *testing.T) {
+	stream := ConnectSourceToSink(testRepeatSource(1), testSliceSink[int]())
+
+	reports := make(chan RunReport, 1)
+	stream.OnReport(func(report RunReport) {
+		reports <- report
+	})
+
+	res := stream.Run(context.Background())
+	time.Sleep(10 * time.Millisecond)
+	stream.Drain()
+	<-res
+	stream.AwaitDone()
+
+	report := <-reports
+	assert.Positive(t, report.DrainLatency)
+	assert.LessOrEqual(t, report.DrainLatency, report.Duration)
+	require.Len(t, report.Stages, 2)
+	assert.Positive(t, report.Stages[1].Items)
+}
+
+func TestRunReportString(t *testing.T) {
+	report := RunReport{
+		Duration: 1500 * time.Millisecond,
+		Stages: []StageReport{
+			{StageInfo: StageInfo{Kind: StageKindSource, Name: "numbers"}, Items: 3},
+		},
+		Errors: 2,
+	}
+	assert.Equal(t, "completed in 1.5s with 2 handled errors: numbers: 3 items", report.String())
+
+	report.Err = errors.New("boom")
+	assert.Equal(t, "failed: boom in 1.5s with 2 handled errors: numbers: 3 items", report.String())
+}
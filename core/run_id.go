@@ -0,0 +1,36 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// runIDKey is the context key under which the identifier of a run is stored.
+type runIDKey struct{}
+
+// lastRunID is the identifier of the last run started in this process.
+var lastRunID atomic.Uint64
+
+// withRunID assigns a new identifier to the run ctx belongs to, unless it is nested in
+// another run, such as the substreams of a flow, in which case it keeps the identifier of
+// the enclosing run.
+func withRunID(ctx context.Context) context.Context {
+	if RunID(ctx) != 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, runIDKey{}, lastRunID.Add(1))
+}
+
+// RunID returns the identifier of the run of a stream the context belongs to, which is unique
+// within the process. Every call to Stream.Run starts a run with a new identifier, while
+// streams run by stages, such as substreams, share the identifier of the run they are part of.
+// Stages can use it to correlate their logs and metrics.
+//
+// Parameters:
+//   - ctx: The context passed to a stage
+//
+// Returns the identifier of the run, or zero if ctx does not belong to a run
+func RunID(ctx context.Context) uint64 {
+	id, _ := ctx.Value(runIDKey{}).(uint64)
+	return id
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunID(t *testing.T) {
+	var ids []uint64
+	stream := ConnectSourceToSink(
+		AppendFlowToSource(testSliceSource([]int{1}), testSyncMap(func(i int) int { return i })),
+		NewSink(
+			0,
+			func(ctx context.Context, elem int, acc Item[int]) (Item[int], StreamAction) {
+				ids = append(ids, RunID(ctx))
+				return acc, ActionProceed
+			},
+			nil,
+			nil,
+		),
+	)
+
+	for range 2 {
+		res := <-stream.Run(context.Background())
+		stream.AwaitDone()
+		require.NoError(t, res.Err)
+	}
+
+	require.Len(t, ids, 2)
+	assert.NotZero(t, ids[0])
+	assert.NotEqual(t, ids[0], ids[1])
+	assert.Zero(t, RunID(context.Background()))
+}
+
+func TestRunIDNested(t *testing.T) {
+	ctx := withRunID(context.Background())
+	assert.Equal(t, RunID(ctx), RunID(withRunID(ctx)))
+}
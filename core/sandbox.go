@@ -0,0 +1,115 @@
+package core
+
+import (
+	"context"
+)
+
+// SandboxKey is the attribute holding the maximum number of concurrent executions of the
+// sandbox of a stage. Stages without the attribute run their callbacks directly.
+var SandboxKey = NewAttributeKey[int]("sandbox")
+
+// Sandbox creates Attributes running the callbacks of a stage in a dedicated sandbox. Each
+// execution runs in its own goroutine, at most maxConcurrent at a time, and panics are always
+// converted into a PanicError, regardless of the PanicPolicy of the stream. This keeps a
+// misbehaving library from exhausting the goroutines of a pipeline or crashing other stages.
+//
+// Synchronous flows created with NewSyncFlow run their callbacks in the sandbox. Parallel flows
+// such as MapPar run their function in it from each of their workers, so that the sandbox
+// bounds their concurrency. The callbacks of other flows created with NewFlow are not run in
+// the sandbox, as they hand work to it themselves using RunSandboxed.
+//
+// A callback that does not return once the stream is cancelled is abandoned, so that it cannot
+// hang the stage. It keeps its slot of the sandbox until it returns.
+//
+// Parameters:
+//   - maxConcurrent: The maximum number of callbacks running at once
+//
+// Returns:
+//   - Attributes configuring the sandbox
+func Sandbox(maxConcurrent int) Attributes {
+	return SetAttribute(SetAttribute(Attributes{}, SandboxKey, maxConcurrent), PanicPolicyKey, PanicRecover)
+}
+
+// WithSandbox creates a FlowOption that runs the callbacks of a synchronous Flow, or the work
+// a Flow hands to RunSandboxed, in a dedicated sandbox. See Sandbox.
+//
+// Parameters:
+//   - maxConcurrent: The maximum number of callbacks running at once
+//
+// Returns:
+//   - A FlowOption that can be passed to NewSyncFlow, or to NewFlow for flows using RunSandboxed
+func WithSandbox(maxConcurrent int) FlowOption {
+	return WithFlowAttributes(Sandbox(maxConcurrent))
+}
+
+// sandboxKey is the context key under which the sandbox of a running stage is stored.
+type sandboxKey struct{}
+
+// sandbox bounds the number of callbacks of a stage running at once, each in its own goroutine.
+type sandbox struct {
+	slots chan struct{}
+}
+
+// withSandbox returns a context carrying a new sandbox if attrs configure one. It must be
+// called with the context of a single stage, after its attributes have been resolved.
+func withSandbox(ctx context.Context, attrs Attributes) context.Context {
+	size, _ := GetAttribute(attrs, SandboxKey)
+	if size <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, sandboxKey{}, &sandbox{slots: make(chan struct{}, size)})
+}
+
+// call runs fn in the sandbox, waiting for it to return. If s is nil, fn is called directly
+// and panics are handled according to policy. An error is returned if fn panicked, or if ctx
+// was cancelled while waiting for a free slot or for fn to return. In the latter case, fn is
+// abandoned and releases its slot once it returns.
+func (s *sandbox) call(ctx context.Context, policy PanicPolicy, fn func()) error {
+	if s == nil {
+		return catchPanic(policy, fn)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case s.slots <- struct{}{}:
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() { <-s.slots }()
+		done <- catchPanic(PanicRecover, fn)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		return err
+	}
+}
+
+// callSandbox runs fn in the sandbox s like call, returning its result. Stages call fn directly
+// with catchPanic when they have no sandbox, as fn escapes to the goroutine running it here.
+func callSandbox[T any](ctx context.Context, s *sandbox, fn func() T) (T, error) {
+	var res T
+	err := s.call(ctx, PanicRecover, func() { res = fn() })
+	return res, err
+}
+
+// RunSandboxed calls fn in the sandbox of the stage ctx was passed to, waiting for it to
+// return. If the stage has no sandbox, fn is called directly as with CatchPanic. Parallel
+// stages call it from each of their workers, so that the sandbox bounds their concurrency.
+//
+// Parameters:
+//   - ctx: The context passed to the setup or callbacks of a stage
+//   - fn: The function to call
+//
+// Returns:
+//   - A PanicError if fn panicked, the context error if ctx was cancelled while waiting for
+//     the sandbox or for fn, or nil
+func RunSandboxed(ctx context.Context, fn func()) error {
+	s, _ := ctx.Value(sandboxKey{}).(*sandbox)
+	policy, _ := GetAttribute(AttributesFromContext(ctx), PanicPolicyKey)
+	return s.call(ctx, policy, fn)
+}
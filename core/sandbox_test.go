@@ -0,0 +1,159 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSandboxedStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		attrs    Attributes
+		flow     *Flow[int, int]
+		expected []int
+		panics   bool
+	}{
+		{
+			name:     "runs synchronous flow in sandbox",
+			attrs:    Attributes{},
+			flow:     testSyncMap(func(i int) int { return i * 2 }, WithSandbox(1)),
+			expected: []int{2, 4, 6},
+		},
+		{
+			name:  "recovers panics regardless of stream policy",
+			attrs: PropagatePanics(),
+			flow: testSyncMap(func(i int) int {
+				if i == 2 {
+					panic("boom")
+				}
+				return i
+			}, WithSandbox(1)),
+			expected: []int{1},
+			panics:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := ConnectSourceToSink(
+				AppendFlowToSource(testSliceSource([]int{1, 2, 3}), tt.flow),
+				testSliceSink[int](),
+			)
+
+			res := <-stream.Run(ContextWithAttributes(context.Background(), tt.attrs))
+			stream.AwaitDone()
+
+			assert.Equal(t, tt.expected, res.Value)
+			var pe *PanicError
+			assert.Equal(t, tt.panics, errors.As(res.Err, &pe))
+		})
+	}
+}
+
+func TestRunSandboxed(t *testing.T) {
+	tests := []struct {
+		name          string
+		attrs         Attributes
+		calls         int
+		expectedMax   int32
+		cancelWaiting bool
+	}{
+		{
+			name:        "bounds concurrent executions",
+			attrs:       Sandbox(2),
+			calls:       6,
+			expectedMax: 2,
+		},
+		{
+			name:        "runs directly without sandbox",
+			attrs:       Attributes{},
+			calls:       6,
+			expectedMax: 6,
+		},
+		{
+			name:          "returns context error while waiting for sandbox",
+			attrs:         Sandbox(1),
+			calls:         1,
+			cancelWaiting: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ctx, attrs := withStageAttributes(ctx, tt.attrs)
+			ctx = withSandbox(ctx, attrs)
+
+			if tt.cancelWaiting {
+				release := make(chan struct{})
+				started := make(chan struct{})
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					_ = RunSandboxed(ctx, func() {
+						close(started)
+						<-release
+					})
+				}()
+				<-started
+
+				waitCtx, cancelWait := context.WithCancel(ctx)
+				cancelWait()
+				err := RunSandboxed(waitCtx, func() { t.Error("unexpected call") })
+				assert.ErrorIs(t, err, context.Canceled)
+
+				close(release)
+				<-done
+				return
+			}
+
+			var running, maxRunning atomic.Int32
+			wg := sync.WaitGroup{}
+			for i := 0; i < tt.calls; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					err := RunSandboxed(ctx, func() {
+						n := running.Add(1)
+						for {
+							m := maxRunning.Load()
+							if n <= m || maxRunning.CompareAndSwap(m, n) {
+								break
+							}
+						}
+						time.Sleep(20 * time.Millisecond)
+						running.Add(-1)
+					})
+					assert.NoError(t, err)
+				}()
+			}
+			wg.Wait()
+
+			assert.LessOrEqual(t, maxRunning.Load(), tt.expectedMax)
+		})
+	}
+}
+
+func TestRunSandboxedCancelsWaitForCallback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, attrs := withStageAttributes(ctx, Sandbox(1))
+	ctx = withSandbox(ctx, attrs)
+
+	release := make(chan struct{})
+	time.AfterFunc(20*time.Millisecond, cancel)
+	err := RunSandboxed(ctx, func() { <-release })
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// The abandoned callback keeps its slot until it returns
+	s := ctx.Value(sandboxKey{}).(*sandbox)
+	assert.Equal(t, 1, len(s.slots))
+	close(release)
+	assert.Eventually(t, func() bool { return len(s.slots) == 0 }, time.Second, time.Millisecond)
+}
@@ -1,6 +1,8 @@
 package core
 
 import (
+	"context"
+	"sync"
 	"testing"
 
 	"go.uber.org/goleak"
@@ -9,3 +11,60 @@ import (
 func TestMain(m *testing.M) {
 	goleak.VerifyTestMain(m)
 }
+
+// testSliceSource creates a Source emitting the given items in order.
+func testSliceSource[T any](items []T, opts ...SourceOption) *Source[T] {
+	return NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan Item[T] {
+			out := make(chan Item[T])
+			go func() {
+				defer close(out)
+				for _, item := range items {
+					select {
+					case <-ctx.Done():
+						return
+					case <-complete:
+						return
+					case out <- Item[T]{Value: item}:
+					}
+				}
+			}()
+			return out
+		},
+		opts...,
+	)
+}
+
+// testSliceSink creates a Sink collecting all items into a slice.
+func testSliceSink[T any]() *Sink[T, []T] {
+	return NewSink(
+		[]T{},
+		func(ctx context.Context, in T, acc Item[[]T]) (Item[[]T], StreamAction) {
+			return Item[[]T]{Value: append(acc.Value, in)}, ActionProceed
+		},
+		nil,
+		nil,
+	)
+}
+
+// testRepeatSource creates a Source emitting the given item until completed or cancelled.
+func testRepeatSource[T any](item T) *Source[T] {
+	return NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan Item[T] {
+			out := make(chan Item[T])
+			go func() {
+				defer close(out)
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-complete:
+						return
+					case out <- Item[T]{Value: item}:
+					}
+				}
+			}()
+			return out
+		},
+	)
+}
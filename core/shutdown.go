@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Drainable is implemented by every Stream and allows coordinating the shutdown of streams
+// with different result types.
+type Drainable interface {
+	Drain()
+	Cancel()
+	AwaitDone()
+}
+
+// closeHook is a named function called during shutdown to release a resource.
+type closeHook struct {
+	name string
+	fn   func(context.Context) error
+}
+
+// ShutdownCoordinator coordinates the graceful shutdown of one or more streams and the
+// resources used by their connectors.
+//
+// Shutdown happens in the following order:
+//  1. All streams are drained. Sources stop fetching new items first, as the drain signal
+//     travels upstream from the sink to the source.
+//  2. Items already in flight are processed by all downstream stages, so that flows near
+//     the end of the pipeline (such as acknowledging or deleting messages) run last.
+//  3. Once all streams are done, the registered close hooks are called in reverse order of
+//     registration, so that connector clients can be closed.
+//
+// If the context passed to Shutdown expires before the streams are done, the streams are
+// cancelled and the close hooks are still called.
+type ShutdownCoordinator struct {
+	mu    sync.Mutex
+	hooks []closeHook
+	once  sync.Once
+	err   error
+}
+
+// NewShutdownCoordinator creates a ShutdownCoordinator without any close hooks.
+func NewShutdownCoordinator() *ShutdownCoordinator {
+	return &ShutdownCoordinator{}
+}
+
+// OnClose registers a hook that is called after all streams have finished during Shutdown.
+// Hooks are called in reverse order of registration.
+//
+// Parameters:
+//   - name: Name of the resource, used to identify errors returned by the hook
+//   - hook: Function releasing the resource
+func (c *ShutdownCoordinator) OnClose(name string, hook func(context.Context) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, closeHook{name: name, fn: hook})
+}
+
+// Shutdown drains the given streams, waits for them to finish and then calls all close hooks.
+// If ctx expires before all streams are done, the streams are cancelled and the context error
+// is included in the returned error. Shutdown only runs once; subsequent calls return the
+// result of the first call.
+//
+// Parameters:
+//   - ctx: Context bounding how long to wait for the streams to drain
+//   - streams: The streams to shut down
+//
+// Returns an error joining the context error, if the deadline was exceeded, and all errors
+// returned by close hooks
+func (c *ShutdownCoordinator) Shutdown(ctx context.Context, streams ...Drainable) error {
+	c.once.Do(func() {
+		for _, stream := range streams {
+			stream.Drain()
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for _, stream := range streams {
+				stream.AwaitDone()
+			}
+		}()
+
+		var errs []error
+		select {
+		case <-done:
+		case <-ctx.Done():
+			errs = append(errs, ctx.Err())
+			for _, stream := range streams {
+				stream.Cancel()
+			}
+			<-done
+		}
+
+		c.mu.Lock()
+		hooks := c.hooks
+		c.mu.Unlock()
+
+		// Use a context that is not cancelled, so hooks can still release resources
+		// if the drain deadline was exceeded
+		hookCtx := context.WithoutCancel(ctx)
+		for i := len(hooks) - 1; i >= 0; i-- {
+			if err := hooks[i].fn(hookCtx); err != nil {
+				errs = append(errs, fmt.Errorf("closing %s: %w", hooks[i].name, err))
+			}
+		}
+
+		c.err = errors.Join(errs...)
+	})
+
+	return c.err
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownCoordinator(t *testing.T) {
+	errHook := errors.New("hook failed")
+
+	tests := []struct {
+		name        string
+		sink        func() *Sink[int, []int]
+		timeout     time.Duration
+		hookErr     error
+		expectedErr []error
+	}{
+		{
+			name:    "drains streams before closing",
+			sink:    testSliceSink[int],
+			timeout: time.Second,
+		},
+		{
+			name: "cancels streams when deadline is exceeded",
+			sink: func() *Sink[int, []int] {
+				return NewSink(
+					[]int{},
+					func(ctx context.Context, in int, acc Item[[]int]) (Item[[]int], StreamAction) {
+						<-ctx.Done()
+						return acc, ActionProceed
+					},
+					nil,
+					nil,
+				)
+			},
+			timeout:     20 * time.Millisecond,
+			expectedErr: []error{context.DeadlineExceeded},
+		},
+		{
+			name:        "reports hook errors",
+			sink:        testSliceSink[int],
+			timeout:     time.Second,
+			hookErr:     errHook,
+			expectedErr: []error{errHook},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streams := []*Stream[[]int]{
+				ConnectSourceToSink(testRepeatSource(1), tt.sink()),
+				ConnectSourceToSink(testRepeatSource(2), tt.sink()),
+			}
+			results := make([]<-chan Item[[]int], len(streams))
+			for i, stream := range streams {
+				results[i] = stream.Run(context.Background())
+			}
+
+			var closed []string
+			c := NewShutdownCoordinator()
+			for _, name := range []string{"first", "second"} {
+				c.OnClose(name, func(context.Context) error {
+					// All streams must be done before hooks are called
+					for _, res := range results {
+						select {
+						case <-res:
+						default:
+							t.Error("hook called before stream finished")
+						}
+					}
+					closed = append(closed, name)
+					return tt.hookErr
+				})
+			}
+
+			time.Sleep(10 * time.Millisecond)
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			err := c.Shutdown(ctx, streams[0], streams[1])
+			if len(tt.expectedErr) == 0 {
+				assert.NoError(t, err)
+			}
+			for _, expected := range tt.expectedErr {
+				assert.ErrorIs(t, err, expected)
+			}
+			assert.Equal(t, []string{"second", "first"}, closed)
+
+			// Subsequent calls return the same result without calling hooks again
+			assert.Equal(t, err, c.Shutdown(ctx, streams[0], streams[1]))
+			assert.Len(t, closed, 2)
+		})
+	}
+}
@@ -24,6 +24,8 @@ import (
 //   - setupUpstream: The setup function of the upstream component, allowing composition
 //     of pipeline components through function composition
 //     The setup function returns a channel that provides the sink's final result
+//   - stages: The stages making up the sink, in pipeline order
+//   - preflight: The preflight checks registered by the stages
 type Sink[I, R any] struct {
 	setup func(
 		ctx context.Context,
@@ -32,15 +34,67 @@ type Sink[I, R any] struct {
 		complete <-chan struct{},
 		setupUpstream setupFunc[I],
 	) <-chan Item[R]
+	stages    []stageDesc
+	preflight []PreflightCheck
+
+	// head sets up the Sink fed by the given chain, fusing the chain with the synchronous
+	// flows starting the Sink. It is nil if the Sink does not start with a synchronous flow.
+	head func(chain *syncChain[I]) setupFunc[R]
+}
+
+// SinkOption is a function type for configuring Sink behavior.
+// It follows the functional options pattern, allowing optional parameters
+// to be passed when creating a new Sink.
+type SinkOption func(*sinkConfig)
+
+// sinkConfig holds configuration options for a Sink.
+//
+// Fields:
+//   - attrs: The Sink's own attributes, taking precedence over inherited ones
+//   - preflight: Checks run by Stream.Preflight
+type sinkConfig struct {
+	attrs     Attributes
+	preflight []PreflightCheck
+}
+
+// WithSinkName creates a SinkOption that names a Sink. Errors produced by a named Sink are
+// wrapped in a StageError carrying its name, while errors received from upstream are passed
+// through unchanged. The name is listed in the stages of any stream the Sink is part of.
+//
+// Parameters:
+//   - name: The name of the Sink
+//
+// Returns:
+//   - A SinkOption that can be passed to NewSink
+func WithSinkName(name string) SinkOption {
+	return WithSinkAttributes(Name(name))
+}
+
+// WithSinkAttributes creates a SinkOption that attaches attributes to a Sink.
+// Attributes attached to the Sink take precedence over attributes it inherits.
+//
+// Parameters:
+//   - attrs: The attributes to attach
+//
+// Returns:
+//   - A SinkOption that can be passed to NewSink
+func WithSinkAttributes(attrs Attributes) SinkOption {
+	return func(c *sinkConfig) {
+		c.attrs = c.attrs.And(attrs)
+	}
 }
 
 // DefaultSinkErrorHandler is the default implementation for handling errors in a Sink.
-// It returns the value of the accumulator and the error as-is and stops further processing by returning ActionStop.
+// It returns the value of the accumulator and the error as-is and stops further processing by returning ActionStop,
+// unless another ErrorMode is in effect for the sink.
 func DefaultSinkErrorHandler[R any](
 	ctx context.Context,
 	err error,
 	acc Item[R],
 ) (Item[R], StreamAction) {
+	if handleByErrorMode(ctx, err) {
+		return acc, ActionProceed
+	}
 	return Item[R]{Value: acc.Value, Err: err}, ActionStop
 }
 
@@ -90,6 +144,8 @@ func DefaultSinkUpstreamClosedHandler[R any](
 //   - A boolean indicating whether to continue processing (true) or stop (false)
 //   - If nil is provided, a default handler will be used that returns the error and stops processing
 //
+// opts are optional SinkOption functions to configure the sink.
+//
 // Type Parameters:
 //   - I: The type of items consumed by this sink
 //   - R: The type of the final result
@@ -101,7 +157,13 @@ func NewSink[I, R any](
 	onElem func(ctx context.Context, in I, acc Item[R]) (Item[R], StreamAction),
 	onErr func(ctx context.Context, err error, acc Item[R]) (Item[R], StreamAction),
 	onUpstreamClosed func(ctx context.Context, acc Item[R]) (Item[R], StreamAction),
+	opts ...SinkOption,
 ) *Sink[I, R] {
+	cfg := &sinkConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	if onErr == nil {
 		onErr = DefaultSinkErrorHandler[R]
 	}
@@ -121,48 +183,96 @@ func NewSink[I, R any](
 
 		completeUpstreamChan, completeUpstream := util.NewCompleteChannel()
 
-		in := setupUpstream(ctx, cancel, wg, completeUpstreamChan)
+		in := connectUpstream(ctx, cancel, wg, completeUpstreamChan, setupUpstream)
+
+		ctx, attrs := withStageAttributes(ctx, cfg.attrs)
+		name, _ := GetAttribute(attrs, NameKey)
+		policy, _ := GetAttribute(attrs, PanicPolicyKey)
+		probe := probeStage(ctx, StageInfo{Kind: StageKindSink, Name: name}, nil, nil)
+		p := permitOf(ctx)
 
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			defer close(out)
 			defer completeUpstream()
+			defer probe.set(StageStopped)
 			acc := Item[R]{Value: initial}
+
+			// handle passes an item to the sink, returning false once the sink stopped
+			handle := func(elem Item[I], ok bool) (proceed bool, restarted bool) {
+				if !p.acquire(ctx) {
+					return false, false
+				}
+				var action StreamAction
+				var panicErr error
+				switch {
+				case !ok:
+					panicErr = catchPanic(policy, func() { acc, action = onUpstreamClosed(ctx, acc) })
+				case elem.Err != nil:
+					panicErr = catchPanic(policy, func() { acc, action = onErr(ctx, markUpstream(name, elem.Err), acc) })
+				default:
+					if err := catchPanic(policy, func() { acc, action = onElem(ctx, elem.Value, acc) }); err != nil {
+						// A panic while processing an element is handled like any other error
+						panicErr = catchPanic(policy, func() { acc, action = onErr(ctx, err, acc) })
+					}
+				}
+				p.release()
+				if panicErr != nil {
+					acc.Err = panicErr
+					action = ActionStop
+				}
+				if ok {
+					reportAction(ctx, StageKindSink, action)
+				}
+
+				switch action {
+				case ActionStop:
+					acc.Err = attribute(name, acc.Err)
+					out <- acc
+					return false, false
+				case ActionCancel:
+					cancel()
+					return false, false
+				case ActionComplete:
+					completeUpstream()
+				case ActionRestartUpstream:
+					completeUpstream()
+					completeUpstreamChan, completeUpstream = util.NewCompleteChannel()
+					in = connectUpstream(ctx, cancel, wg, completeUpstreamChan, setupUpstream)
+					return true, true
+				}
+				return true, false
+			}
+
 			for {
-				select {
-				case <-ctx.Done():
+				items, status := in.receive(ctx, complete, nil)
+				switch status {
+				case contextDone:
 					return
-				case <-complete:
+				case completeSignalled:
 					completeUpstream()
-				case elem, ok := <-in:
-					var action StreamAction
-					if !ok {
-						acc, action = onUpstreamClosed(ctx, acc)
-					} else if elem.Err != nil {
-						acc, action = onErr(ctx, elem.Err, acc)
-					} else {
-						acc, action = onElem(ctx, elem.Value, acc)
-					}
-
-					switch action {
-					case ActionProceed:
-						continue
-					case ActionStop:
-						out <- acc
+				case upstreamClosed:
+					probe.set(StageProcessing)
+					if proceed, _ := handle(Item[I]{}, false); !proceed {
 						return
-					case ActionCancel:
-						cancel()
-						return
-					case ActionComplete:
-						completeUpstream()
-						continue
-					case ActionRestartUpstream:
-						completeUpstream()
-						completeUpstreamChan, completeUpstream = util.NewCompleteChannel()
-						in = setupUpstream(ctx, cancel, wg, completeUpstreamChan)
-						continue
 					}
+					probe.set(StageReceiving)
+				case receivedItems:
+					from := in
+					for _, elem := range items {
+						probe.set(StageProcessing)
+						proceed, restarted := handle(elem, true)
+						probe.handled()
+						if !proceed {
+							return
+						}
+						if restarted {
+							// The remaining items belong to the previous upstream
+							break
+						}
+					}
+					from.release(items)
 				}
 			}
 		}()
@@ -171,6 +281,8 @@ func NewSink[I, R any](
 	}
 
 	return &Sink[I, R]{
-		setup: setup,
+		setup:     setup,
+		stages:    describeStage[R](StageKindSink, typeName[I](), cfg.attrs),
+		preflight: cfg.preflight,
 	}
 }
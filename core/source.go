@@ -3,6 +3,8 @@ package core
 import (
 	"context"
 	"sync"
+
+	"github.com/svenvdam/linea/util"
 )
 
 // SourceOption is a function that configures a Source.
@@ -11,8 +13,14 @@ type SourceOption func(*sourceConfig)
 
 // sourceConfig holds configuration options for a Source.
 type sourceConfig struct {
-	// bufSize determines the buffer size of the output channel
-	bufSize int
+	// attrs are the source's own attributes, taking precedence over inherited ones
+	attrs Attributes
+
+	// governor pauses the source while the process is under pressure
+	governor *Governor
+
+	// preflight are the checks run by Stream.Preflight
+	preflight []PreflightCheck
 }
 
 // WithSourceBufSize returns a SourceOption that sets the buffer size for the source's output channel.
@@ -21,8 +29,38 @@ type sourceConfig struct {
 // Parameters:
 //   - size: The desired buffer size for the output channel
 func WithSourceBufSize(size int) SourceOption {
+	return WithSourceAttributes(BufSize(size))
+}
+
+// WithSourceName returns a SourceOption that names the source. Errors produced by a named
+// source are wrapped in a StageError carrying its name, and the name is listed in the
+// stages of any stream the source is part of.
+//
+// Parameters:
+//   - name: The name of the source
+func WithSourceName(name string) SourceOption {
+	return WithSourceAttributes(Name(name))
+}
+
+// WithSourceAttributes returns a SourceOption that attaches attributes to the source.
+// Attributes attached to the source take precedence over attributes it inherits.
+//
+// Parameters:
+//   - attrs: The attributes to attach
+func WithSourceAttributes(attrs Attributes) SourceOption {
 	return func(c *sourceConfig) {
-		c.bufSize = size
+		c.attrs = c.attrs.And(attrs)
+	}
+}
+
+// WithSourceGovernor returns a SourceOption that attaches a Governor to the source.
+// The source holds back items while the Governor is paused.
+//
+// Parameters:
+//   - g: The Governor controlling the source
+func WithSourceGovernor(g *Governor) SourceOption {
+	return func(c *sourceConfig) {
+		c.governor = g
 	}
 }
 
@@ -35,6 +73,8 @@ func WithSourceBufSize(size int) SourceOption {
 //
 // Fields:
 //   - setup: Function called to initialize and start the source.
+//   - stages: The stages making up the source, in pipeline order.
+//   - preflight: The preflight checks registered by the stages.
 //
 // It receives:
 //   - ctx: Context used to control cancellation
@@ -51,6 +91,12 @@ type Source[O any] struct {
 		wg *sync.WaitGroup,
 		complete <-chan struct{},
 	) <-chan Item[O]
+	stages    []stageDesc
+	preflight []PreflightCheck
+
+	// chain holds the synchronous flows ending the Source, if they can be fused with the
+	// synchronous flows appended to it
+	chain *syncChain[O]
 }
 
 // NewSource creates a new data source that can be connected to other components in a data processing pipeline.
@@ -90,41 +136,94 @@ func NewSource[O any](
 		wg *sync.WaitGroup,
 		complete <-chan struct{},
 	) <-chan Item[O] {
-		out := make(chan Item[O], cfg.bufSize)
+		ctx, attrs := withStageAttributes(ctx, cfg.attrs)
+		bufSize := outputBufSize(attrs)
+		name, _ := GetAttribute(attrs, NameKey)
+		out := make(chan Item[O], bufSize)
+
+		writer := newChunkWriter(ctx, attrs, out)
+		probe := probeStage(ctx, StageInfo{Kind: StageKindSource, Name: name}, nil, bufferedOf(out, writer))
 
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			defer close(out)
-			in := generate(ctx, complete, cancel, wg)
+			defer probe.set(StageStopped)
+			defer writer.close(ctx)
+			var in <-chan Item[O]
+			if err := CatchPanic(ctx, func() { in = generate(ctx, complete, cancel, wg) }); err != nil {
+				item := Item[O]{Err: attribute(name, err)}
+				if writer.isChunked() {
+					writer.send(ctx, item)
+				} else {
+					util.Send(ctx, item, out)
+				}
+				return
+			}
+			attributor := &errorAttributor{name: name}
+			gate := pauseGateOf(ctx)
 
 			for {
+				var elem Item[O]
+				var ok bool
 				select {
 				case <-ctx.Done():
 					return
 				case <-complete:
 					return
-				case elem, ok := <-in:
-					if !ok {
-						return
-					}
-					select {
-					case <-ctx.Done():
-						return
-					case <-complete:
-						return
-					case out <- elem:
-					}
+				case writer.pending() <- writer.buf:
+					// Downstream took the collected chunk before the next item was ready
+					writer.sent()
+					continue
+				case elem, ok = <-in:
+				}
+
+				if !ok {
+					return
+				}
+				item := Item[O]{Value: elem.Value, Err: attributor.attribute(elem.Err)}
+				if cfg.governor != nil && !cfg.governor.wait(ctx, complete) {
+					reportDrained(ctx, item)
+					return
+				}
+				if !gate.wait(ctx, complete) {
+					reportDrained(ctx, item)
+					return
+				}
+				probe.set(StageSending)
+				if writer.isChunked() {
+					writer.send(ctx, item)
+					probe.handled()
+					continue
 				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-complete:
+					reportDrained(ctx, item)
+					return
+				case out <- item:
+				}
+				probe.handled()
 			}
 		}()
 
-		return out
+		return withOverflow(ctx, wg, attrs, out)
 	}
 
 	source := &Source[O]{
-		setup: setup,
+		setup:     setup,
+		stages:    describeStage[O](StageKindSource, "", cfg.attrs),
+		preflight: cfg.preflight,
 	}
 
 	return source
 }
+
+// reportDrained reports an item that a source received from its generator but discards because
+// the stream is drained. Items discarded because the stream is cancelled are not reported.
+func reportDrained[O any](ctx context.Context, item Item[O]) {
+	if ctx.Err() == nil && item.Err == nil {
+		ReportDrop(ctx, "Source", DropReasonDrained, item.Value)
+	}
+}
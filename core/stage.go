@@ -0,0 +1,210 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// StageKind describes the role of a stage in a pipeline.
+type StageKind string
+
+const (
+	// StageKindSource is a stage producing items.
+	StageKindSource StageKind = "source"
+
+	// StageKindFlow is a stage transforming items.
+	StageKindFlow StageKind = "flow"
+
+	// StageKindSink is a stage consuming items and producing the final result.
+	StageKindSink StageKind = "sink"
+
+	// StageKindJunction is a stage broadcasting or merging items between pipelines.
+	StageKindJunction StageKind = "junction"
+)
+
+// StageInfo describes a single stage of a pipeline.
+type StageInfo struct {
+	// Kind is the role of the stage in the pipeline
+	Kind StageKind
+
+	// Name is the name given to the stage, or empty if the stage is unnamed
+	Name string
+}
+
+// String returns the name of the stage, or its kind if the stage is unnamed.
+func (s StageInfo) String() string {
+	if s.Name == "" {
+		return string(s.Kind)
+	}
+	return s.Name
+}
+
+// StageError is an error produced by a named stage. Errors produced by stages configured
+// with WithSourceName, WithFlowName or WithSinkName are wrapped in a StageError before
+// they are propagated downstream, so that the stage causing an error can be identified.
+// Errors received from upstream are passed through without being wrapped again.
+type StageError struct {
+	// Stage is the name of the stage producing the error
+	Stage string
+
+	// Err is the error produced by the stage
+	Err error
+}
+
+// Error returns the error message prefixed with the name of the stage.
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Stage, e.Err)
+}
+
+// Unwrap returns the error produced by the stage.
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// upstreamError marks an error that a named stage received from upstream, so that it is
+// not attributed to the stage when it is passed through.
+type upstreamError struct {
+	err error
+}
+
+func (e *upstreamError) Error() string {
+	return e.err.Error()
+}
+
+func (e *upstreamError) Unwrap() error {
+	return e.err
+}
+
+// markUpstream marks an error received from upstream by the stage with the given name.
+// Errors received by unnamed stages are returned unchanged.
+func markUpstream(name string, err error) error {
+	if name == "" || err == nil {
+		return err
+	}
+	return &upstreamError{err: err}
+}
+
+// attribute prepares an error emitted by the stage with the given name to be sent
+// downstream. Errors produced by the stage are wrapped in a StageError, while errors
+// received from upstream are passed through unchanged.
+func attribute(name string, err error) error {
+	if name == "" || err == nil {
+		return err
+	}
+
+	switch e := err.(type) {
+	case *upstreamError:
+		return e.err
+	case *StageError:
+		return e
+	default:
+		return &StageError{Stage: name, Err: err}
+	}
+}
+
+// errorAttributor attributes the errors emitted by a named stage like attribute. A stage
+// typically emits the same sentinel error repeatedly, so the StageError wrapping the last
+// error is reused instead of allocating a new one for every item. It must only be used by
+// a single goroutine.
+type errorAttributor struct {
+	name    string
+	last    error
+	wrapped error
+}
+
+// attribute prepares an error emitted by the stage to be sent downstream.
+func (a *errorAttributor) attribute(err error) error {
+	if a.last != nil && sameError(a.last, err) {
+		return a.wrapped
+	}
+
+	wrapped := attribute(a.name, err)
+	if _, ok := wrapped.(*StageError); ok && wrapped != err {
+		a.last, a.wrapped = err, wrapped
+	}
+	return wrapped
+}
+
+// sameError reports whether both errors are identical, without panicking on errors of
+// non-comparable types.
+func sameError(a, b error) bool {
+	ta := reflect.TypeOf(a)
+	return ta == reflect.TypeOf(b) && ta.Comparable() && a == b
+}
+
+// attributeErrors forwards all items from in, preparing errors to be sent downstream by
+// the stage with the given name. The returned channel is closed once in is closed.
+func attributeErrors[T any](ctx context.Context, wg *sync.WaitGroup, name string, in <-chan Item[T]) <-chan Item[T] {
+	out := make(chan Item[T], cap(in))
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(out)
+		attributor := &errorAttributor{name: name}
+		for item := range in {
+			if item.Err != nil {
+				item.Err = attributor.attribute(item.Err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- item:
+			}
+		}
+	}()
+
+	return out
+}
+
+// stagesOf returns a new list of stages containing all given lists in order.
+func stagesOf(lists ...[]stageDesc) []stageDesc {
+	return slices.Concat(lists...)
+}
+
+// stageDesc describes a stage of a pipeline for Stages and String.
+//
+// Fields:
+//   - in: The type of items the stage receives, or empty for sources and junctions
+//   - out: The type of items or result the stage produces, or empty for junctions
+//   - attrs: The attributes attached to the stage and the segments it is part of
+type stageDesc struct {
+	StageInfo
+	in    string
+	out   string
+	attrs Attributes
+}
+
+// describeStage describes a stage configured with the given attributes. in is empty for
+// sources, which do not receive items.
+func describeStage[O any](kind StageKind, in string, attrs Attributes) []stageDesc {
+	name, _ := GetAttribute(attrs, NameKey)
+	return []stageDesc{{
+		StageInfo: StageInfo{Kind: kind, Name: name},
+		in:        in,
+		out:       typeName[O](),
+		attrs:     attrs,
+	}}
+}
+
+// junctionStage describes a junction stage of the given name.
+func junctionStage(name string) []stageDesc {
+	return []stageDesc{{StageInfo: StageInfo{Kind: StageKindJunction, Name: name}}}
+}
+
+// typeName returns the name of type T.
+func typeName[T any]() string {
+	return reflect.TypeFor[T]().String()
+}
+
+// infos returns the StageInfo of the given stages.
+func infos(stages []stageDesc) []StageInfo {
+	res := make([]StageInfo, len(stages))
+	for i, stage := range stages {
+		res[i] = stage.StageInfo
+	}
+	return res
+}
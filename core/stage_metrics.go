@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WatchStages calls onStages with the status of every stage each interval while the stream is
+// running, and once more when it terminates. Each status holds the time the stage has spent
+// waiting for items from upstream and blocked sending items downstream, which is the signal
+// for tuning buffer sizes and parallelism: a stage that is mostly blocked is backpressured by
+// a slower stage downstream, while a stage that is mostly waiting is starved by upstream.
+//
+// Stages created by NewFlow send from their own callbacks, so the time they spend blocked on
+// a full output buffer is counted as processing. Use NewSyncFlow or WithAsyncBoundary for
+// stages whose blocked time must be measured. Stages of junctions and hubs are not reported.
+// WatchStages must be called before the stream is started.
+//
+// Parameters:
+//   - interval: The time between two calls of onStages
+//   - onStages: Function called with the status of every stage, from source to sink
+func (s *Stream[R]) WatchStages(interval time.Duration, onStages func(stages []StageStatus)) {
+	s.stagesInterval = interval
+	s.onStages = onStages
+}
+
+// statuses returns the current status of all registered stages.
+func (r *stageProbes) statuses() []StageStatus {
+	r.mu.Lock()
+	probes := r.probes
+	r.mu.Unlock()
+
+	stages := make([]StageStatus, len(probes))
+	for i, probe := range probes {
+		stages[i] = probe.status()
+	}
+	return stages
+}
+
+// watchStages calls onStages with the status of the stages in reg every interval until ctx
+// is done, which happens once the run of the stream has terminated, and once more after that.
+func watchStages(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	reg *stageProbes,
+	interval time.Duration,
+	onStages func(stages []StageStatus),
+) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				onStages(reg.statuses())
+				return
+			case <-ticker.C:
+				onStages(reg.statuses())
+			}
+		}
+	}()
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSlowSource creates a Source emitting n elements, waiting delay before each of them.
+func testSlowSource(n int, delay time.Duration) *Source[int] {
+	return NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan Item[int] {
+			out := make(chan Item[int])
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer close(out)
+				for i := range n {
+					time.Sleep(delay)
+					select {
+					case <-ctx.Done():
+						return
+					case out <- Item[int]{Value: i}:
+					}
+				}
+			}()
+			return out
+		},
+	)
+}
+
+// testSlowSink creates a Sink counting elements, waiting delay for each of them.
+func testSlowSink(delay time.Duration) *Sink[int, int] {
+	return NewSink(
+		0,
+		func(ctx context.Context, in int, acc Item[int]) (Item[int], StreamAction) {
+			time.Sleep(delay)
+			return Item[int]{Value: acc.Value + 1}, ActionProceed
+		},
+		nil,
+		nil,
+	)
+}
+
+func TestWatchStages(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream func() *Stream[int]
+		check  func(t *testing.T, source, sink StageStatus)
+	}{
+		{
+			name: "records time blocked by slow downstream",
+			stream: func() *Stream[int] {
+				return ConnectSourceToSink(
+					testSliceSource(make([]int, 20), WithSourceBufSize(0)),
+					testSlowSink(5*time.Millisecond),
+				)
+			},
+			check: func(t *testing.T, source, sink StageStatus) {
+				assert.Greater(t, source.Blocked, 50*time.Millisecond)
+				assert.Less(t, sink.Waiting, source.Blocked)
+			},
+		},
+		{
+			name: "records time waiting on slow upstream",
+			stream: func() *Stream[int] {
+				return ConnectSourceToSink(testSlowSource(20, 5*time.Millisecond), testSlowSink(0))
+			},
+			check: func(t *testing.T, source, sink StageStatus) {
+				assert.Greater(t, sink.Waiting, 50*time.Millisecond)
+				assert.Less(t, source.Blocked, sink.Waiting)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := tt.stream()
+
+			var mu sync.Mutex
+			var calls [][]StageStatus
+			stream.WatchStages(10*time.Millisecond, func(stages []StageStatus) {
+				mu.Lock()
+				defer mu.Unlock()
+				calls = append(calls, stages)
+			})
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			require.NoError(t, res.Err)
+			assert.Equal(t, 20, res.Value)
+
+			mu.Lock()
+			defer mu.Unlock()
+			require.Greater(t, len(calls), 1)
+			final := calls[len(calls)-1]
+			require.Len(t, final, 2)
+			assert.Equal(t, int64(20), final[1].Items)
+			tt.check(t, final[0], final[1])
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testErrSource creates a Source emitting a single error.
+func testErrSource(err error, opts ...SourceOption) *Source[int] {
+	return NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan Item[int] {
+			out := make(chan Item[int], 1)
+			out <- Item[int]{Err: err}
+			close(out)
+			return out
+		},
+		opts...,
+	)
+}
+
+// testFailingFlow creates a Flow emitting err for every element.
+func testFailingFlow(err error, opts ...FlowOption) *Flow[int, int] {
+	return NewFlow(
+		func(ctx context.Context, elem int, out chan<- Item[int]) StreamAction {
+			out <- Item[int]{Err: err}
+			return ActionProceed
+		},
+		nil,
+		nil,
+		nil,
+		opts...,
+	)
+}
+
+// testPassFlow creates a Flow passing all elements through unchanged.
+func testPassFlow(opts ...FlowOption) *Flow[int, int] {
+	return NewFlow(
+		func(ctx context.Context, elem int, out chan<- Item[int]) StreamAction {
+			out <- Item[int]{Value: elem}
+			return ActionProceed
+		},
+		nil,
+		nil,
+		nil,
+		opts...,
+	)
+}
+
+// testNamedSliceSink creates a named Sink collecting all items into a slice.
+func testNamedSliceSink(name string) *Sink[int, []int] {
+	return NewSink(
+		[]int{},
+		func(ctx context.Context, in int, acc Item[[]int]) (Item[[]int], StreamAction) {
+			return Item[[]int]{Value: append(acc.Value, in)}, ActionProceed
+		},
+		nil,
+		nil,
+		WithSinkName(name),
+	)
+}
+
+func TestStageErrors(t *testing.T) {
+	testErr := errors.New("test error")
+
+	tests := []struct {
+		name          string
+		stream        func() *Stream[[]int]
+		expectedStage string
+	}{
+		{
+			name: "error of named flow is wrapped",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(
+					AppendFlowToSource(testSliceSource([]int{1}), testFailingFlow(testErr, WithFlowName("parse"))),
+					testSliceSink[int](),
+				)
+			},
+			expectedStage: "parse",
+		},
+		{
+			name: "error of named source passes through named flow",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(
+					AppendFlowToSource(testErrSource(testErr, WithSourceName("input")), testPassFlow(WithFlowName("parse"))),
+					testNamedSliceSink("output"),
+				)
+			},
+			expectedStage: "input",
+		},
+		{
+			name: "error of unnamed source is not attributed to named stages",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(
+					AppendFlowToSource(testErrSource(testErr), testPassFlow(WithFlowName("parse"))),
+					testNamedSliceSink("output"),
+				)
+			},
+			expectedStage: "",
+		},
+		{
+			name: "error of unnamed flow is not wrapped",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(
+					AppendFlowToSource(testSliceSource([]int{1}), testFailingFlow(testErr)),
+					testSliceSink[int](),
+				)
+			},
+			expectedStage: "",
+		},
+		{
+			name: "error of named sink is wrapped",
+			stream: func() *Stream[[]int] {
+				sink := NewSink(
+					[]int{},
+					func(ctx context.Context, in int, acc Item[[]int]) (Item[[]int], StreamAction) {
+						return Item[[]int]{Err: testErr}, ActionStop
+					},
+					nil,
+					nil,
+					WithSinkName("output"),
+				)
+				return ConnectSourceToSink(testSliceSource([]int{1}), sink)
+			},
+			expectedStage: "output",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := tt.stream()
+			result := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			assert.ErrorIs(t, result.Err, testErr)
+
+			var stageErr *StageError
+			if tt.expectedStage == "" {
+				assert.Equal(t, testErr, result.Err)
+				return
+			}
+			assert.ErrorAs(t, result.Err, &stageErr)
+			assert.Equal(t, tt.expectedStage, stageErr.Stage)
+			assert.Equal(t, tt.expectedStage+": test error", result.Err.Error())
+		})
+	}
+}
+
+func TestStreamStages(t *testing.T) {
+	source := AppendFlowToSource(
+		testSliceSource([]int{1}, WithSourceName("input")),
+		ConnectFlows(testPassFlow(WithFlowName("first")), testPassFlow()),
+	)
+	sink := PrependFlowToSink(testPassFlow(WithFlowName("last")), testNamedSliceSink("output"))
+	stream := ConnectSourceToSink(source, sink)
+
+	stages := stream.Stages()
+
+	assert.Equal(t, []StageInfo{
+		{Kind: StageKindSource, Name: "input"},
+		{Kind: StageKindFlow, Name: "first"},
+		{Kind: StageKindFlow},
+		{Kind: StageKindFlow, Name: "last"},
+		{Kind: StageKindSink, Name: "output"},
+	}, stages)
+	assert.Equal(t, "flow", stages[2].String())
+
+	// The returned list is a copy
+	stages[0].Name = "changed"
+	assert.Equal(t, "input", stream.Stages()[0].Name)
+}
+
+// testUncomparableError is an error whose type cannot be compared with ==.
+type testUncomparableError struct {
+	msgs []string
+}
+
+func (e testUncomparableError) Error() string {
+	return "uncomparable"
+}
+
+func TestErrorAttributor(t *testing.T) {
+	testErr := errors.New("test error")
+	otherErr := errors.New("other error")
+
+	tests := []struct {
+		name       string
+		attributor *errorAttributor
+		errs       []error
+		reused     bool
+	}{
+		{
+			name:       "reuses wrapper for repeated error",
+			attributor: &errorAttributor{name: "stage"},
+			errs:       []error{testErr, testErr},
+			reused:     true,
+		},
+		{
+			name:       "wraps different errors separately",
+			attributor: &errorAttributor{name: "stage"},
+			errs:       []error{testErr, otherErr},
+			reused:     false,
+		},
+		{
+			name:       "handles uncomparable errors",
+			attributor: &errorAttributor{name: "stage"},
+			errs:       []error{testUncomparableError{}, testUncomparableError{}},
+			reused:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := tt.attributor.attribute(tt.errs[0])
+			second := tt.attributor.attribute(tt.errs[1])
+
+			var stageErr *StageError
+			assert.ErrorAs(t, second, &stageErr)
+			assert.Equal(t, "stage", stageErr.Stage)
+			assert.Equal(t, tt.errs[0], errors.Unwrap(first))
+			assert.Equal(t, tt.reused, first.(*StageError) == second.(*StageError))
+		})
+	}
+}
@@ -3,8 +3,10 @@ package core
 import (
 	"context"
 	"errors"
+	"slices"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/svenvdam/linea/util"
 )
@@ -21,20 +23,66 @@ import (
 //   - cancel: Function to cancel stream execution
 //   - complete: Function to signal graceful shutdown to all components in the pipeline
 //   - wg: WaitGroup to coordinate goroutine completion
-//   - res: Channel that receives the stream results
-//   - run: Function called to initialize and start the stream
+//   - runMu: Mutex serializing calls to Run
+//   - res: Channel that receives the results of the current run
+//   - run: Function called to build and start a run of the stream, returning its result channel
+//   - hooksMu: Mutex guarding the termination hooks
+//   - hooks: Functions called once the stream has terminated
+//   - errorListeners: Functions called for every error handled without failing the stream
+//   - actionHooks: Functions called for every action returned by a stage
+//   - reporters: Functions called with the report of every run
+//   - drainedAt: The time Drain was called during the current run in unix nanoseconds, or zero
+//   - stages: The stages making up the stream, in pipeline order
+//   - preflight: The checks run by Preflight
+//   - watchTimeout: The time without progress after which onStall is called
+//   - onStall: The stall handler registered by WatchStalls
+//   - stagesInterval: The time between two calls of onStages
+//   - onStages: The function registered by WatchStages
+//   - bufferLimit: The maximum number of items buffered set by GuardBuffers, or zero
+//   - bufferAction: How the stream is stopped once bufferLimit is exceeded
+//   - flushMu: Mutex guarding the flush functions
+//   - flushes: Functions called once the stream has terminated, before its result is delivered
+//   - flushTimeout: The time allowed for the stages to stop and the flush functions to return
+//   - gate: The gate holding back the sources while the stream is paused
 type Stream[R any] struct {
 	isRunning atomic.Bool
+	runMu     sync.Mutex
 	cancel    context.CancelFunc
 	complete  CompleteFunc
 	wg        *sync.WaitGroup
 	res       <-chan Item[R]
-	run       func(
+	hooksMu   sync.Mutex
+	hooks     []func(err error)
+
+	errorListeners []ErrorListener
+	actionHooks    []ActionHook
+	reporters      []func(report RunReport)
+	drainedAt      atomic.Int64
+
+	stages    []stageDesc
+	preflight []PreflightCheck
+
+	watchTimeout time.Duration
+	onStall      func(report StallReport) error
+
+	stagesInterval time.Duration
+	onStages       func(stages []StageStatus)
+
+	bufferLimit  int
+	bufferAction BufferGuardAction
+
+	flushMu      sync.Mutex
+	flushes      []func(ctx context.Context) error
+	flushTimeout time.Duration
+
+	gate *pauseGate
+
+	run func(
 		ctx context.Context,
 		cancel context.CancelFunc,
 		wg *sync.WaitGroup,
 		complete <-chan struct{},
-	)
+	) <-chan Item[R]
 }
 
 // newStream creates a new Stream with the provided setup function.
@@ -74,54 +122,101 @@ func newStream[R any](
 		complete:  nil,
 		wg:        &sync.WaitGroup{},
 		res:       nil,
-	}
 
-	out := make(chan Item[R], 1)
-	stream.res = out
+		flushTimeout: DefaultFlushTimeout,
+		gate:         newPauseGate(),
+	}
 
 	stream.run = func(
 		ctx context.Context,
 		cancel context.CancelFunc,
 		wg *sync.WaitGroup,
 		complete <-chan struct{},
-	) {
-		res := setup(ctx, cancel, wg, complete)
+	) <-chan Item[R] {
+		out := make(chan Item[R], 1)
+
+		// The stages are tracked separately, so their flush functions can run once they stopped
+		stages := &sync.WaitGroup{}
+		res := setup(ctx, cancel, stages, complete)
 		stream.isRunning.Store(true)
 
-		wg.Add(1)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			stages.Wait()
+		}()
 		go func() {
 			defer close(out)
 			defer cancel()
 			defer wg.Done()
-			defer stream.isRunning.Store(false)
-
-			select {
-			case <-ctx.Done():
-				out <- Item[R]{Err: ctx.Err()}
-				return
-			case r, ok := <-res:
-				if !ok {
-					if ctx.Err() != nil {
-						out <- Item[R]{Err: ctx.Err()}
-					} else {
-						out <- Item[R]{Err: errors.New("result channel closed unexpectedly")}
-					}
-					return
+
+			r := awaitResult(ctx, res)
+			// Stages are stopped before flushing or reporting, so they can account for every item
+			if fns := flushesOf(ctx); len(fns) > 0 || ctx.Value(runStatsKey{}) != nil {
+				cancel()
+				if err := flush(ctx, stages, fns, stream.flushTimeout); err != nil {
+					r.Err = errors.Join(r.Err, err)
 				}
-				out <- r
-				return
 			}
+			if err := bufferLimitErrorOf(ctx); err != nil && !errors.Is(r.Err, err) {
+				// The buffer guard drained the stream, which completed without error
+				r.Err = errors.Join(r.Err, err)
+			}
+			stream.terminate(r.Err)
+			stream.report(ctx, r.Err)
+			// The stream is marked as stopped before the result is delivered, so a caller
+			// receiving the result can immediately run the stream again
+			stream.isRunning.Store(false)
+			out <- r
 		}()
+
+		return out
 	}
 
 	return stream
 }
 
+// awaitResult waits for the result produced by the sink. If the stream was cancelled,
+// the cause of the cancellation is returned instead of the result.
+func awaitResult[R any](ctx context.Context, res <-chan Item[R]) Item[R] {
+	select {
+	case <-ctx.Done():
+		return Item[R]{Err: context.Cause(ctx)}
+	case r, ok := <-res:
+		if !ok {
+			if ctx.Err() != nil {
+				return Item[R]{Err: context.Cause(ctx)}
+			}
+			return Item[R]{Err: errors.New("result channel closed unexpectedly")}
+		}
+		if ctx.Err() != nil {
+			// The result was produced because the stream was cancelled
+			return Item[R]{Err: context.Cause(ctx)}
+		}
+		return r
+	}
+}
+
+// terminate calls all registered termination hooks with the terminal error of the stream.
+func (s *Stream[R]) terminate(err error) {
+	s.hooksMu.Lock()
+	hooks := s.hooks
+	s.hooksMu.Unlock()
+
+	for _, hook := range hooks {
+		hook(err)
+	}
+}
+
 // Run starts the stream execution with the provided context.
 // It initializes all components and begins processing items through the pipeline.
 // If the stream is already running, this method will not restart it and will
 // simply return the existing result channel.
 //
+// A stream can be run again once it has terminated. Each run rebuilds the pipeline from
+// its stages, waits for the goroutines of the previous run to complete, and delivers its
+// result on a new channel. Termination hooks and flush functions are called for every run.
+//
 // Parameters:
 //   - ctx: Context used to control the stream's lifecycle and cancellation
 //
@@ -129,18 +224,86 @@ func newStream[R any](
 //   - A channel that will receive a single Item[R] value containing the stream's output result
 //   - The channel will be closed when the stream completes or encounters an error
 func (s *Stream[R]) Run(ctx context.Context) <-chan Item[R] {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
 	if !s.isRunning.Load() {
-		ctx, cancel := context.WithCancel(ctx)
+		// The previous run has produced its result, wait for it to release its resources
+		s.wg.Wait()
+
+		ctx, cancelCause := context.WithCancelCause(ctx)
+		cancel := func() { cancelCause(nil) }
 		s.cancel = cancel
 
 		complete, completeFn := util.NewCompleteChannel()
 		s.complete = completeFn
-		s.run(ctx, cancel, s.wg, complete)
+
+		s.flushMu.Lock()
+		reg := &flushes{fns: slices.Clone(s.flushes)}
+		s.flushMu.Unlock()
+		ctx = context.WithValue(ctx, flushesKey{}, reg)
+		ctx = context.WithValue(ctx, pauseGateKey{}, s.gate)
+		ctx = withRunID(ctx)
+
+		s.hooksMu.Lock()
+		listeners := slices.Clone(s.errorListeners)
+		ctx = withActionHooks(ctx, slices.Clone(s.actionHooks))
+		collectReport := len(s.reporters) > 0
+		s.hooksMu.Unlock()
+
+		var probes *stageProbes
+		if s.onStall != nil || s.onStages != nil || s.bufferLimit > 0 || collectReport {
+			probes = &stageProbes{}
+			ctx = context.WithValue(ctx, stageProbeKey{}, probes)
+		}
+		if collectReport {
+			stats := &runStats{start: time.Now(), probes: probes}
+			ctx = context.WithValue(ctx, runStatsKey{}, stats)
+			listeners = append(listeners, stats.countError)
+		}
+		ctx = withErrorListeners(ctx, listeners)
+		s.drainedAt.Store(0)
+
+		if s.onStall != nil {
+			watch(ctx, cancelCause, s.wg, probes, s.gate, s.watchTimeout, s.onStall)
+		}
+		if s.onStages != nil {
+			watchStages(ctx, s.wg, probes, s.stagesInterval, s.onStages)
+		}
+		if s.bufferLimit > 0 {
+			res := &bufferGuardError{}
+			ctx = context.WithValue(ctx, bufferGuardKey{}, res)
+			guardBuffers(ctx, cancelCause, completeFn, s.wg, probes, res, s.bufferLimit, s.bufferAction)
+		}
+		s.res = s.run(ctx, cancel, s.wg, complete)
 	}
 
 	return s.res
 }
 
+// OnTermination registers a function that is called exactly once when the stream
+// terminates, whether it completed, failed or was cancelled. The function receives
+// the terminal error of the stream, which is nil if the stream completed successfully
+// and the context error if it was cancelled.
+//
+// Hooks are called in order of registration, after the result has been produced and
+// before it is delivered on the channel returned by Run. Hooks must be registered
+// before the stream is started.
+//
+// Parameters:
+//   - hook: Function called with the terminal error of the stream
+func (s *Stream[R]) OnTermination(hook func(err error)) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.hooks = append(s.hooks, hook)
+}
+
+// Stages returns the stages making up the stream, in pipeline order from source to sink.
+// Junctions such as MergeSources list the stages of all their inputs before the junction itself.
+func (s *Stream[R]) Stages() []StageInfo {
+	return infos(s.stages)
+}
+
 // Cancel cancels the stream's context and triggers immediate shutdown.
 // This will stop all processing as soon as possible without waiting for
 // in-flight items to complete. After cancellation, any items still in the
@@ -170,10 +333,44 @@ func (s *Stream[R]) Cancel() {
 // If the stream is not running, this method has no effect.
 func (s *Stream[R]) Drain() {
 	if s.isRunning.Load() {
+		s.drainedAt.CompareAndSwap(0, time.Now().UnixNano())
 		s.complete()
 	}
 }
 
+// DrainWithTimeout drains the stream and waits for it to finish. If the stream has not
+// finished within the timeout, it is cancelled, dropping the items still in flight. It
+// returns once all goroutines of the stream have completed.
+//
+// If the stream is not running, this method returns true immediately.
+//
+// Parameters:
+//   - timeout: The time the stream is given to drain before it is cancelled
+//
+// Returns:
+//   - true if the stream finished gracefully, false if it had to be cancelled
+func (s *Stream[R]) DrainWithTimeout(timeout time.Duration) bool {
+	s.Drain()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.AwaitDone()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		s.Cancel()
+		<-done
+		return false
+	}
+}
+
 // AwaitDone blocks until all goroutines in the stream have completed.
 // Use this method to wait for all processing to finish after calling Cancel or Drain.
 //
@@ -321,6 +321,10 @@ func TestStreamIsNotRunning(t *testing.T) {
 			name:   "await done on non-running stream",
 			method: func(s *Stream[int]) { s.AwaitDone() },
 		},
+		{
+			name:   "drain with timeout on non-running stream",
+			method: func(s *Stream[int]) { s.DrainWithTimeout(time.Millisecond) },
+		},
 	}
 
 	for _, tt := range tests {
@@ -410,3 +414,207 @@ func TestResultChannelCloseWithContextCancellation(t *testing.T) {
 	// Wait for all goroutines to complete
 	stream.AwaitDone()
 }
+
+// TestResultProducedByCancellation tests the case where the sink produces a result because the
+// context was cancelled, ensuring the cancellation is reported instead of the partial result
+func TestResultProducedByCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Both the result and the cancellation are ready, so repeat to cover either being selected
+	for i := 0; i < 100; i++ {
+		res := make(chan Item[int], 1)
+		res <- Item[int]{Value: 42}
+
+		item := awaitResult(ctx, res)
+		assert.ErrorIs(t, item.Err, context.Canceled)
+		assert.Zero(t, item.Value)
+	}
+}
+
+func TestStreamOnTermination(t *testing.T) {
+	testErr := errors.New("source failed")
+
+	tests := []struct {
+		name        string
+		stream      func() *Stream[[]int]
+		action      func(s *Stream[[]int])
+		expectedErr error
+	}{
+		{
+			name: "called with nil when stream completes",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(testSliceSource([]int{1, 2, 3}), testSliceSink[int]())
+			},
+			action:      func(s *Stream[[]int]) {},
+			expectedErr: nil,
+		},
+		{
+			name: "called with error when stream fails",
+			stream: func() *Stream[[]int] {
+				source := NewSource(func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan Item[int] {
+					out := make(chan Item[int], 1)
+					out <- Item[int]{Err: testErr}
+					close(out)
+					return out
+				})
+				return ConnectSourceToSink(source, testSliceSink[int]())
+			},
+			action:      func(s *Stream[[]int]) {},
+			expectedErr: testErr,
+		},
+		{
+			name: "called with context error when stream is cancelled",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(testRepeatSource(1), testSliceSink[int]())
+			},
+			action:      func(s *Stream[[]int]) { s.Cancel() },
+			expectedErr: context.Canceled,
+		},
+		{
+			name: "called with nil when stream is drained",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(testRepeatSource(1), testSliceSink[int]())
+			},
+			action:      func(s *Stream[[]int]) { s.Drain() },
+			expectedErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := tt.stream()
+
+			var calls []string
+			var errs []error
+			stream.OnTermination(func(err error) {
+				calls = append(calls, "first")
+				errs = append(errs, err)
+			})
+			stream.OnTermination(func(err error) {
+				calls = append(calls, "second")
+			})
+
+			res := stream.Run(context.Background())
+			tt.action(stream)
+			result := <-res
+			stream.AwaitDone()
+
+			// Hooks run before the result is delivered, so they are visible here
+			assert.Equal(t, []string{"first", "second"}, calls)
+			assert.Len(t, errs, 1)
+			if tt.expectedErr == nil {
+				assert.NoError(t, errs[0])
+			} else {
+				assert.ErrorIs(t, errs[0], tt.expectedErr)
+			}
+			assert.Equal(t, result.Err, errs[0])
+		})
+	}
+}
+
+func TestStreamDrainWithTimeout(t *testing.T) {
+	tests := []struct {
+		name             string
+		blockSink        bool
+		timeout          time.Duration
+		expectedGraceful bool
+		expectedErr      error
+	}{
+		{
+			name:             "drains gracefully within timeout",
+			timeout:          time.Second,
+			expectedGraceful: true,
+		},
+		{
+			name:             "cancels stream exceeding timeout",
+			blockSink:        true,
+			timeout:          50 * time.Millisecond,
+			expectedGraceful: false,
+			expectedErr:      context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			if !tt.blockSink {
+				close(release)
+			}
+			started := make(chan struct{})
+			var once sync.Once
+			stream := ConnectSourceToSink(
+				AppendFlowToSource(testRepeatSource(1), testSyncMap(func(i int) int {
+					once.Do(func() { close(started) })
+					return i
+				})),
+				testBlockingSink(release),
+			)
+
+			res := stream.Run(context.Background())
+			<-started
+			graceful := stream.DrainWithTimeout(tt.timeout)
+			result := <-res
+
+			assert.Equal(t, tt.expectedGraceful, graceful)
+			assert.ErrorIs(t, result.Err, tt.expectedErr)
+			if tt.expectedErr == nil {
+				assert.NoError(t, result.Err)
+			}
+		})
+	}
+}
+
+func TestStreamRerun(t *testing.T) {
+	tests := []struct {
+		name     string
+		stream   func() *Stream[[]int]
+		action   func(s *Stream[[]int])
+		expected [][]int
+	}{
+		{
+			name: "reruns completed stream",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(
+					AppendFlowToSource(testSliceSource([]int{1, 2, 3}), testSyncMap(func(i int) int { return i * 2 })),
+					testSliceSink[int](),
+				)
+			},
+			action:   func(s *Stream[[]int]) {},
+			expected: [][]int{{2, 4, 6}, {2, 4, 6}, {2, 4, 6}},
+		},
+		{
+			name: "reruns cancelled stream",
+			stream: func() *Stream[[]int] {
+				return ConnectSourceToSink(testRepeatSource(1), testSliceSink[int]())
+			},
+			action:   func(s *Stream[[]int]) { s.Cancel() },
+			expected: [][]int{nil, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := tt.stream()
+
+			var terminations int
+			stream.OnTermination(func(err error) { terminations++ })
+
+			for _, expected := range tt.expected {
+				res := stream.Run(context.Background())
+				tt.action(stream)
+				result := <-res
+
+				if expected != nil {
+					assert.NoError(t, result.Err)
+					assert.Equal(t, expected, result.Value)
+				} else {
+					assert.ErrorIs(t, result.Err, context.Canceled)
+				}
+			}
+			stream.AwaitDone()
+
+			assert.Equal(t, len(tt.expected), terminations)
+		})
+	}
+}
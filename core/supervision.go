@@ -0,0 +1,119 @@
+package core
+
+import (
+	"context"
+
+	"github.com/svenvdam/linea/util"
+)
+
+// Decision describes how a flow handles an error.
+type Decision int
+
+const (
+	// DecisionStop sends the error downstream and stops the flow.
+	// This matches the behavior of DefaultFlowErrorHandler.
+	DecisionStop Decision = iota
+
+	// DecisionResume discards the error and continues processing the next element.
+	DecisionResume
+
+	// DecisionRestart discards the error and restarts the upstream components of the flow.
+	DecisionRestart
+)
+
+// Decider decides how a flow handles an error.
+type Decider func(err error) Decision
+
+// WithSupervision creates a FlowOption that handles errors using the given Decider. The
+// Decider is applied both to the errors the flow produces while processing its elements,
+// such as the errors returned by the function of TryMap or MapPar, and to the errors received
+// from upstream, instead of the flow's own error handler.
+//
+// Errors emitted by flows created with NewSyncFlow while processing an element are supervised
+// automatically. Flows created with NewFlow call Supervise for the errors they produce.
+//
+// This allows unifying error handling across flows without writing a custom onErr
+// handler per stage.
+//
+// Parameters:
+//   - decider: Function deciding how to handle each error
+//
+// Returns:
+//   - A FlowOption that can be passed to NewFlow
+func WithSupervision(decider Decider) FlowOption {
+	return func(c *flowConfig) {
+		c.decider = decider
+	}
+}
+
+// ResumingDecider is a Decider that resumes processing for every error.
+func ResumingDecider(error) Decision {
+	return DecisionResume
+}
+
+// RestartingDecider is a Decider that restarts upstream for every error.
+func RestartingDecider(error) Decision {
+	return DecisionRestart
+}
+
+// StoppingDecider is a Decider that stops the flow for every error.
+func StoppingDecider(error) Decision {
+	return DecisionStop
+}
+
+// deciderKey is the context key under which the Decider of a flow is stored.
+type deciderKey struct{}
+
+// withDecider returns a context carrying the Decider of a flow, which is nil if the flow is not
+// supervised. It is always set, so that stages do not inherit the Decider of an enclosing flow.
+func withDecider(ctx context.Context, decider Decider) context.Context {
+	return context.WithValue(ctx, deciderKey{}, decider)
+}
+
+// Supervise applies the Decider the flow was configured with using WithSupervision to an error
+// the flow produced while processing an element, such as an error returned by a user function.
+// Resumed and restarted errors are reported to the listeners registered with Stream.OnError.
+// A supervised flow emits the error only for DecisionStop, after which it stops, discards it
+// for DecisionResume and restarts upstream for DecisionRestart.
+//
+// Parameters:
+//   - ctx: The context passed to the callbacks of the flow
+//   - err: The error produced by the flow
+//
+// Returns:
+//   - The Decision for the error
+//   - Whether the flow is supervised. If not, the flow handles the error as it would otherwise
+func Supervise(ctx context.Context, err error) (Decision, bool) {
+	decider, _ := ctx.Value(deciderKey{}).(Decider)
+	if decider == nil {
+		return DecisionStop, false
+	}
+	return decide(ctx, decider, err), true
+}
+
+// decide applies the decision of decider to err, reporting resumed and restarted errors.
+func decide(ctx context.Context, decider Decider, err error) Decision {
+	decision := decider(err)
+	switch decision {
+	case DecisionResume:
+		reportHandled(ctx, ErrorHandlingResumed, err)
+	case DecisionRestart:
+		reportHandled(ctx, ErrorHandlingRestarted, err)
+	}
+	return decision
+}
+
+// supervise creates an error handler applying the decision of the given Decider.
+func supervise[O any](decider Decider) func(ctx context.Context, err error, out chan<- Item[O]) StreamAction {
+	return func(ctx context.Context, err error, out chan<- Item[O]) StreamAction {
+		switch decide(ctx, decider, err) {
+		case DecisionResume:
+			return ActionProceed
+		case DecisionRestart:
+			return ActionRestartUpstream
+		default:
+			util.Send(ctx, Item[O]{Err: err}, out)
+			return ActionStop
+		}
+	}
+}
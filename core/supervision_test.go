@@ -0,0 +1,202 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testFailingSource creates a Source emitting a value, an error and another value.
+// The error is only emitted on the first run, so restarting the source succeeds.
+func testFailingSource(err error) *Source[int] {
+	runs := &atomic.Int32{}
+	return NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan Item[int] {
+			items := []Item[int]{{Value: 1}, {Err: err}, {Value: 2}}
+			if runs.Add(1) > 1 {
+				items = []Item[int]{{Value: 3}}
+			}
+
+			out := make(chan Item[int])
+			go func() {
+				defer close(out)
+				for _, item := range items {
+					select {
+					case <-ctx.Done():
+						return
+					case <-complete:
+						return
+					case out <- item:
+					}
+				}
+			}()
+			return out
+		},
+	)
+}
+
+func TestWithSupervision(t *testing.T) {
+	errFatal := errors.New("fatal")
+	errTransient := errors.New("transient")
+
+	tests := []struct {
+		name        string
+		err         error
+		decider     Decider
+		expected    []int
+		expectedErr error
+	}{
+		{
+			name:     "resume discards the error",
+			err:      errTransient,
+			decider:  ResumingDecider,
+			expected: []int{1, 2},
+		},
+		{
+			name:     "restart restarts upstream",
+			err:      errTransient,
+			decider:  RestartingDecider,
+			expected: []int{1, 3},
+		},
+		{
+			name:        "stop propagates the error",
+			err:         errFatal,
+			decider:     StoppingDecider,
+			expectedErr: errFatal,
+		},
+		{
+			name: "decider can distinguish errors",
+			err:  errFatal,
+			decider: func(err error) Decision {
+				if errors.Is(err, errTransient) {
+					return DecisionResume
+				}
+				return DecisionStop
+			},
+			expectedErr: errFatal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onErrCalled := &atomic.Bool{}
+			flow := NewFlow(
+				func(ctx context.Context, elem int, out chan<- Item[int]) StreamAction {
+					out <- Item[int]{Value: elem}
+					return ActionProceed
+				},
+				func(ctx context.Context, err error, out chan<- Item[int]) StreamAction {
+					onErrCalled.Store(true)
+					return ActionProceed
+				},
+				nil,
+				nil,
+				WithSupervision(tt.decider),
+			)
+
+			stream := ConnectSourceToSink(AppendFlowToSource(testFailingSource(tt.err), flow), testSliceSink[int]())
+			result := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			assert.False(t, onErrCalled.Load(), "decider should replace the flow's error handler")
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, result.Err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, result.Err)
+			assert.Equal(t, tt.expected, result.Value)
+		})
+	}
+}
+
+func TestSupervise(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name        string
+		decider     Decider
+		expected    []int
+		expectedErr error
+		expectedRes []ErrorHandling
+	}{
+		{
+			name:        "resume discards errors of the flow itself",
+			decider:     ResumingDecider,
+			expected:    []int{1, 3},
+			expectedRes: []ErrorHandling{ErrorHandlingResumed},
+		},
+		{
+			name:        "stop emits the error of the flow itself",
+			decider:     StoppingDecider,
+			expectedErr: errFailed,
+		},
+		{
+			name:        "restart restarts upstream of the flow itself",
+			decider:     RestartingDecider,
+			expected:    []int{1, 1, 3},
+			expectedRes: []ErrorHandling{ErrorHandlingRestarted},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := &atomic.Int32{}
+			source := NewSource(
+				func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan Item[int] {
+					items := []int{1, 2, 3}
+					if runs.Add(1) > 1 {
+						items = []int{1, 3}
+					}
+					out := make(chan Item[int])
+					go func() {
+						defer close(out)
+						for _, item := range items {
+							select {
+							case <-ctx.Done():
+								return
+							case out <- Item[int]{Value: item}:
+							}
+						}
+					}()
+					return out
+				},
+			)
+
+			flow := NewSyncFlow(
+				func(ctx context.Context, elem int, emit func(Item[int])) StreamAction {
+					if elem == 2 {
+						emit(Item[int]{Err: errFailed})
+						return ActionProceed
+					}
+					emit(Item[int]{Value: elem})
+					return ActionProceed
+				},
+				nil,
+				WithSupervision(tt.decider),
+			)
+
+			stream := ConnectSourceToSink(AppendFlowToSource(source, flow), testSliceSink[int]())
+			var handled []ErrorHandling
+			mu := sync.Mutex{}
+			stream.OnError(func(ctx context.Context, event ErrorEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				handled = append(handled, event.Handling)
+			})
+			result := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, result.Err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, result.Err)
+			assert.Equal(t, tt.expected, result.Value)
+			assert.Equal(t, tt.expectedRes, handled)
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StageState describes what a stage of a running stream is doing.
+type StageState int32
+
+const (
+	// StageReceiving indicates the stage is waiting for an item from upstream. A source in
+	// this state is waiting for its generator to produce an item.
+	StageReceiving StageState = iota
+
+	// StageProcessing indicates the stage is handling an item in its callbacks.
+	StageProcessing
+
+	// StageSending indicates the stage is waiting for downstream to accept an item.
+	StageSending
+
+	// StageStopped indicates the stage has stopped.
+	StageStopped
+)
+
+// String returns a description of the state.
+func (s StageState) String() string {
+	switch s {
+	case StageReceiving:
+		return "receiving"
+	case StageProcessing:
+		return "processing"
+	case StageSending:
+		return "sending"
+	case StageStopped:
+		return "stopped"
+	default:
+		return fmt.Sprintf("StageState(%d)", int32(s))
+	}
+}
+
+// StageStatus describes the state of a single stage of a running stream.
+type StageStatus struct {
+	StageInfo
+
+	// State is what the stage is doing
+	State StageState
+
+	// Since is the time the stage entered its state
+	Since time.Time
+
+	// Items is the number of items the stage has handled
+	Items int64
+
+	// Waiting is the total time the stage has spent waiting for items from upstream
+	Waiting time.Duration
+
+	// Blocked is the total time the stage has spent waiting for downstream to accept items
+	Blocked time.Duration
+
+	// Buffered is the number of items in the output buffer of the stage
+	Buffered int
+}
+
+// String returns the stage together with its state.
+func (s StageStatus) String() string {
+	return fmt.Sprintf("%s (%s for %s)", s.StageInfo, s.State, time.Since(s.Since).Round(time.Millisecond))
+}
+
+// StallReport describes a stream that has made no progress for the duration configured with
+// Stream.WatchStalls. A stage processing an item while all stages upstream of it are sending
+// is usually the cause of the stall. If all stages are receiving, the source has no items.
+type StallReport struct {
+	// Duration is the time since any stage last handled an item
+	Duration time.Duration
+
+	// Stages holds the status of the stages of the stream, from source to sink
+	Stages []StageStatus
+}
+
+// String returns a description of the stall listing the status of every stage.
+func (r StallReport) String() string {
+	stages := make([]string, len(r.Stages))
+	for i, stage := range r.Stages {
+		stages[i] = stage.String()
+	}
+	return fmt.Sprintf("no progress for %s: %s", r.Duration.Round(time.Millisecond), strings.Join(stages, ", "))
+}
+
+// StallError is the error a stream fails with when FailOnStall is passed to
+// Stream.WatchStalls and the stream stalls.
+type StallError struct {
+	// Report describes the stall
+	Report StallReport
+}
+
+// Error returns a description of the stall.
+func (e *StallError) Error() string {
+	return "stream stalled: " + e.Report.String()
+}
+
+// FailOnStall is a stall handler for Stream.WatchStalls that fails the stream with a
+// StallError.
+func FailOnStall(report StallReport) error {
+	return &StallError{Report: report}
+}
+
+// WatchStalls starts a watchdog whenever the stream is run, which calls onStall once the
+// stream has made no progress for the given timeout, so that misconfigured buffers or hung
+// callbacks do not go unnoticed. The report lists which stages are receiving, processing or
+// sending. onStall is called again only after the stream has made progress in between.
+//
+// If onStall returns an error, the stream is cancelled and fails with that error. Return nil
+// to only observe the stall, for example by logging the report. Use FailOnStall to fail the
+// stream with a StallError.
+//
+// Stages created by NewFlow send from their own callbacks, so they are reported as sending
+// while their output buffer is full, and as processing otherwise. Stages of junctions and
+// hubs are not reported. A paused stream is not considered stalled. WatchStalls must be
+// called before the stream is started.
+//
+// Parameters:
+//   - timeout: The time without progress after which the stream is considered stalled
+//   - onStall: Function called with the report of the stall
+func (s *Stream[R]) WatchStalls(timeout time.Duration, onStall func(report StallReport) error) {
+	s.watchTimeout = timeout
+	s.onStall = onStall
+}
+
+// stageProbeKey is the context key under which the stall watchdog and run reports collect stage probes.
+type stageProbeKey struct{}
+
+// stageProbes holds the probes of the stages of a running stream, in the order they were set up.
+type stageProbes struct {
+	mu     sync.Mutex
+	probes []*stageProbe
+}
+
+// stageProbe tracks the state of a single stage for the stall watchdog. All methods can be
+// called on a nil probe, which is used when no probes are collected. Only the goroutine of the
+// stage changes the state of its probe, while any goroutine can read its status.
+type stageProbe struct {
+	info  StageInfo
+	state atomic.Int32
+	since atomic.Int64
+	items atomic.Int64
+
+	// waiting and blocked hold the time spent receiving and sending in the states left so far
+	waiting atomic.Int64
+	blocked atomic.Int64
+
+	// outFull reports whether the output buffer of the stage is full, if known
+	outFull func() bool
+
+	// buffered returns the number of items in the output buffer of the stage, if known
+	buffered func() int
+}
+
+// probeStage registers a probe for a stage with the stream ctx belongs to, returning nil if
+// neither a watchdog is running nor a report is collected.
+//
+// outFull and buffered describe the output buffer of the stage, and may be nil if unknown.
+func probeStage(ctx context.Context, info StageInfo, outFull func() bool, buffered func() int) *stageProbe {
+	reg, ok := ctx.Value(stageProbeKey{}).(*stageProbes)
+	if !ok {
+		return nil
+	}
+
+	probe := &stageProbe{info: info, outFull: outFull, buffered: buffered}
+	probe.since.Store(time.Now().UnixNano())
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.probes = append(reg.probes, probe)
+	return probe
+}
+
+// set records that the stage entered the given state.
+func (p *stageProbe) set(state StageState) {
+	if p == nil {
+		return
+	}
+	now := time.Now().UnixNano()
+	switch StageState(p.state.Load()) {
+	case StageReceiving:
+		p.waiting.Add(now - p.since.Load())
+	case StageSending:
+		p.blocked.Add(now - p.since.Load())
+	}
+	p.state.Store(int32(state))
+	p.since.Store(now)
+}
+
+// handled records that the stage handled an item and is waiting for the next one.
+func (p *stageProbe) handled() {
+	if p == nil {
+		return
+	}
+	p.items.Add(1)
+	p.set(StageReceiving)
+}
+
+// status returns the current status of the stage.
+func (p *stageProbe) status() StageStatus {
+	recorded := StageState(p.state.Load())
+	since := time.Unix(0, p.since.Load())
+	waiting := time.Duration(p.waiting.Load())
+	blocked := time.Duration(p.blocked.Load())
+
+	// The time spent in the current state has not been added yet
+	switch recorded {
+	case StageReceiving:
+		waiting += time.Since(since)
+	case StageSending:
+		blocked += time.Since(since)
+	}
+
+	state := recorded
+	if state == StageProcessing && p.outFull != nil && p.outFull() {
+		state = StageSending
+	}
+	var buffered int
+	if p.buffered != nil {
+		buffered = p.buffered()
+	}
+	return StageStatus{
+		StageInfo: p.info,
+		State:     state,
+		Since:     since,
+		Items:     p.items.Load(),
+		Waiting:   waiting,
+		Blocked:   blocked,
+		Buffered:  buffered,
+	}
+}
+
+// outFullOf returns a function reporting whether the buffer of out is full, or nil if out is
+// unbuffered, in which case a blocked send cannot be told apart from processing.
+func outFullOf[T any](out chan Item[T]) func() bool {
+	if cap(out) == 0 {
+		return nil
+	}
+	return func() bool {
+		return len(out) == cap(out)
+	}
+}
+
+// bufferedOf returns a function counting the items in the output buffer of a stage sending
+// on out, including the items in chunks sent by writer if it is not nil.
+func bufferedOf[T any](out chan Item[T], writer *chunkWriter[T]) func() int {
+	return func() int {
+		n := len(out)
+		if writer != nil && writer.link != nil {
+			n += len(writer.link.chunks) * writer.size
+		}
+		return n
+	}
+}
+
+// watch runs the stall watchdog of a stream until ctx is done.
+func watch(
+	ctx context.Context,
+	cancel context.CancelCauseFunc,
+	wg *sync.WaitGroup,
+	reg *stageProbes,
+	gate *pauseGate,
+	timeout time.Duration,
+	onStall func(report StallReport) error,
+) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(timeout / 4)
+		defer ticker.Stop()
+
+		var progress int64
+		lastProgress := time.Now()
+		reported := false
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				stages := reg.statuses()
+				var current int64
+				for _, stage := range stages {
+					current += stage.Items
+				}
+
+				// A paused stream is not expected to make progress
+				if current != progress || gate.isPaused() {
+					progress = current
+					lastProgress = now
+					reported = false
+					continue
+				}
+
+				stalled := now.Sub(lastProgress)
+				if reported || stalled < timeout {
+					continue
+				}
+				reported = true
+
+				if err := onStall(StallReport{Duration: stalled, Stages: stages}); err != nil {
+					cancel(err)
+					return
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testBlockingSink creates a Sink that blocks on its first element until release is closed.
+func testBlockingSink(release <-chan struct{}) *Sink[int, int] {
+	return NewSink(
+		0,
+		func(ctx context.Context, in int, acc Item[int]) (Item[int], StreamAction) {
+			select {
+			case <-ctx.Done():
+			case <-release:
+			}
+			return Item[int]{Value: acc.Value + 1}, ActionProceed
+		},
+		nil,
+		nil,
+		WithSinkName("slow"),
+	)
+}
+
+func TestWatchStalls(t *testing.T) {
+	tests := []struct {
+		name           string
+		blockSink      bool
+		onStall        func(reports chan<- StallReport) func(StallReport) error
+		expectedStages []StageState
+		expectedErr    bool
+	}{
+		{
+			name:      "reports blocked stages",
+			blockSink: true,
+			onStall: func(reports chan<- StallReport) func(StallReport) error {
+				return func(report StallReport) error {
+					reports <- report
+					return nil
+				}
+			},
+			expectedStages: []StageState{StageSending, StageSending, StageProcessing},
+		},
+		{
+			name:      "fails stream on stall",
+			blockSink: true,
+			onStall: func(reports chan<- StallReport) func(StallReport) error {
+				return func(report StallReport) error {
+					reports <- report
+					return FailOnStall(report)
+				}
+			},
+			expectedStages: []StageState{StageSending, StageSending, StageProcessing},
+			expectedErr:    true,
+		},
+		{
+			name: "progressing stream is not reported",
+			onStall: func(reports chan<- StallReport) func(StallReport) error {
+				return func(report StallReport) error {
+					reports <- report
+					return nil
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			if !tt.blockSink {
+				close(release)
+			}
+			reports := make(chan StallReport, 1)
+
+			stream := ConnectSourceToSink(
+				AppendFlowToSource(testRepeatSource(1), testSyncMap(func(i int) int { return i })),
+				testBlockingSink(release),
+			)
+			stream.WatchStalls(40*time.Millisecond, tt.onStall(reports))
+			resChan := stream.Run(context.Background())
+
+			select {
+			case report := <-reports:
+				if tt.expectedStages == nil {
+					t.Fatalf("unexpected stall: %s", report)
+				}
+				states := make([]StageState, len(report.Stages))
+				for i, stage := range report.Stages {
+					states[i] = stage.State
+				}
+				assert.Equal(t, tt.expectedStages, states)
+				assert.Equal(t, "slow", report.Stages[2].Name)
+				assert.GreaterOrEqual(t, report.Duration, 40*time.Millisecond)
+			case <-time.After(200 * time.Millisecond):
+				if tt.expectedStages != nil {
+					t.Fatal("stall was not reported")
+				}
+			}
+
+			if !tt.expectedErr {
+				stream.Cancel()
+			}
+			res := <-resChan
+			if tt.blockSink {
+				close(release)
+			}
+			stream.AwaitDone()
+
+			var stallErr *StallError
+			assert.Equal(t, tt.expectedErr, errors.As(res.Err, &stallErr))
+		})
+	}
+}
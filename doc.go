@@ -24,6 +24,9 @@
 //	result := <-stream.Run(context.Background())
 //	// result.Value contains []string{"2", "4"}
 //
+// For long-running services, RunUntilSignal runs a stream until the process receives
+// SIGTERM or SIGINT, and then drains it, cancelling it if draining takes too long.
+//
 // The library provides a rich set of built-in components while allowing custom
 // implementations through the core interfaces.
 package linea
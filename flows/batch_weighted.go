@@ -0,0 +1,62 @@
+package flows
+
+import (
+	"context"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// BatchWeighted creates a Flow that groups incoming items into slices whose total weight does
+// not exceed maxWeight, where the weight of each item is given by weightFn. This suits limits
+// expressed in cost rather than count, such as the maximum size in bytes of a request. A batch
+// is emitted once its weight reaches maxWeight, or before an item that would make it exceed
+// maxWeight. An item weighing more than maxWeight on its own is emitted as a batch of its own.
+// If the stream ends with a partial batch remaining, it is emitted as a final batch.
+//
+// Type Parameters:
+//   - I: The type of items to batch
+//
+// Parameters:
+//   - maxWeight: The maximum total weight of each batch
+//   - weightFn: Function that returns the weight of an item
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that transforms individual items into slices of limited weight
+func BatchWeighted[I any](
+	maxWeight int64,
+	weightFn func(I) int64,
+	opts ...core.FlowOption,
+) *core.Flow[I, []I] {
+	var batch []I
+	var weight int64
+
+	// emit emits the current batch, if any, and starts a new one
+	emit := func(ctx context.Context, out chan<- core.Item[[]I]) {
+		if len(batch) > 0 {
+			util.Send(ctx, core.Item[[]I]{Value: batch}, out)
+		}
+		batch, weight = nil, 0
+	}
+
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[[]I]) core.StreamAction {
+			w := weightFn(elem)
+			if weight+w > maxWeight {
+				emit(ctx, out)
+			}
+			batch = append(batch, elem)
+			weight += w
+			if weight >= maxWeight {
+				emit(ctx, out)
+			}
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[[]I]) {
+			emit(ctx, out)
+		},
+		opts...,
+	)
+}
@@ -0,0 +1,62 @@
+package flows
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestBatchWeighted(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     []string
+		maxWeight int64
+		expected  [][]string
+	}{
+		{
+			name:      "batches items up to the maximum weight",
+			input:     []string{"ab", "cd", "e", "fgh", "ij"},
+			maxWeight: 5,
+			expected:  [][]string{{"ab", "cd", "e"}, {"fgh", "ij"}},
+		},
+		{
+			name:      "emits batches before exceeding the maximum weight",
+			input:     []string{"abc", "def", "g"},
+			maxWeight: 5,
+			expected:  [][]string{{"abc"}, {"def", "g"}},
+		},
+		{
+			name:      "emits heavy items on their own",
+			input:     []string{"a", "bcdefgh", "i"},
+			maxWeight: 5,
+			expected:  [][]string{{"a"}, {"bcdefgh"}, {"i"}},
+		},
+		{
+			name:      "handles empty input",
+			input:     []string{},
+			maxWeight: 5,
+			expected:  [][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(tt.input),
+				BatchWeighted(tt.maxWeight, func(s string) int64 { return int64(len(s)) }),
+				sinks.Slice[[]string](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+		})
+	}
+}
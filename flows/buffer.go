@@ -0,0 +1,49 @@
+package flows
+
+import (
+	"context"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// Buffer creates a Flow that buffers up to size items between upstream and downstream,
+// applying strategy when the buffer is full. Items pass through unchanged. Unlike configuring
+// the buffer of an existing stage with core.WithFlowBuffer, this makes the capacity decisions
+// of a pipeline visible in its definition and in Stream.String. Errors are passed on unchanged.
+//
+// With core.OverflowBackpressure, upstream waits while the buffer is full. The other
+// strategies, such as core.OverflowDropHead, keep upstream running while downstream is slow,
+// and core.OverflowFail fails the stream with core.ErrBufferOverflow. Dropped items are
+// reported with core.DropReasonBufferOverflow.
+//
+// Type Parameters:
+//   - I: The type of items
+//
+// Parameters:
+//   - size: The number of items to buffer
+//   - strategy: The strategy applied when the buffer is full
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that buffers items
+func Buffer[I any](
+	size int,
+	strategy core.OverflowStrategy,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			util.Send(ctx, core.Item[I]{Value: elem}, out)
+			return core.ActionProceed
+		},
+		passThroughError[I],
+		nil,
+		nil,
+		append([]core.FlowOption{core.WithFlowBuffer(size, strategy)}, opts...)...)
+}
+
+// passThroughError passes an error received from upstream on unchanged and continues processing.
+func passThroughError[I any](ctx context.Context, err error, out chan<- core.Item[I]) core.StreamAction {
+	util.Send(ctx, core.Item[I]{Err: err}, out)
+	return core.ActionProceed
+}
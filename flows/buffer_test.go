@@ -0,0 +1,89 @@
+package flows
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestBuffer(t *testing.T) {
+	input := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	tests := []struct {
+		name        string
+		strategy    core.OverflowStrategy
+		expectDrops bool
+		expectedErr error
+	}{
+		{
+			name:     "backpressures upstream",
+			strategy: core.OverflowBackpressure,
+		},
+		{
+			name:        "drops items while downstream is slow",
+			strategy:    core.OverflowDropHead,
+			expectDrops: true,
+		},
+		{
+			name:        "fails while downstream is slow",
+			strategy:    core.OverflowFail,
+			expectDrops: true,
+			expectedErr: core.ErrBufferOverflow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drops := core.NewDropCounter()
+			ctx := core.WithDropHandler(context.Background(), drops.Handle)
+
+			// The sink holds the first item until the buffer overflowed, or briefly if it
+			// backpressures
+			first := true
+			sink := core.NewSink(
+				[]int{},
+				func(ctx context.Context, elem int, acc core.Item[[]int]) (core.Item[[]int], core.StreamAction) {
+					if first {
+						first = false
+						deadline := time.Now().Add(100 * time.Millisecond)
+						for drops.Total() == 0 && time.Now().Before(deadline) {
+							time.Sleep(time.Millisecond)
+						}
+					}
+					return core.Item[[]int]{Value: append(acc.Value, elem)}, core.ActionProceed
+				},
+				nil,
+				nil,
+			)
+
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(input),
+				Buffer[int](2, tt.strategy),
+				sink,
+			)
+
+			res := <-stream.Run(ctx)
+			stream.AwaitDone()
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, res.Err)
+			if tt.expectDrops {
+				assert.Positive(t, drops.Total())
+				assert.Equal(t, len(input), len(res.Value)+int(drops.Total()))
+				assert.Equal(t, 10, res.Value[len(res.Value)-1])
+			} else {
+				assert.Zero(t, drops.Total())
+				assert.Equal(t, input, res.Value)
+			}
+		})
+	}
+}
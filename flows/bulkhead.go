@@ -0,0 +1,123 @@
+package flows
+
+import (
+	"context"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// Bulkhead bounds the number of items in flight within a segment of a stream, between the
+// flows created by EnterBulkhead and ExitBulkhead. This bounds the memory used by a segment
+// of several stages, such as parallel stages followed by a slow sink, which the buffers of
+// the individual stages do not.
+type Bulkhead struct {
+	permits chan struct{}
+}
+
+// NewBulkhead creates a Bulkhead allowing at most limit items in flight.
+//
+// Parameters:
+//   - limit: The maximum number of items in flight
+//
+// Returns a new Bulkhead, to be passed to EnterBulkhead and ExitBulkhead
+func NewBulkhead(limit int) *Bulkhead {
+	return &Bulkhead{
+		permits: make(chan struct{}, max(limit, 1)),
+	}
+}
+
+// Limit returns the maximum number of items in flight.
+func (b *Bulkhead) Limit() int {
+	return cap(b.permits)
+}
+
+// InFlight returns the number of items currently in flight.
+func (b *Bulkhead) InFlight() int {
+	return len(b.permits)
+}
+
+// Release releases the permits of n items that will not reach ExitBulkhead, such as items
+// dropped by a Filter or combined by a Batch within the segment.
+//
+// Parameters:
+//   - n: The number of permits to release
+func (b *Bulkhead) Release(n int) {
+	for range n {
+		select {
+		case <-b.permits:
+		default:
+			return
+		}
+	}
+}
+
+// EnterBulkhead creates a Flow marking the start of the segment bounded by b. Each item takes
+// a permit of b before it is passed on, waiting while b.Limit() items are in flight, until
+// ExitBulkhead releases the permit of an item leaving the segment. Errors are passed on
+// without taking a permit.
+//
+// Type Parameters:
+//   - I: The type of items
+//
+// Parameters:
+//   - b: The Bulkhead bounding the segment
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow entering the segment bounded by b
+func EnterBulkhead[I any](
+	b *Bulkhead,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			select {
+			case <-ctx.Done():
+				return core.ActionStop
+			case b.permits <- struct{}{}:
+			}
+			util.Send(ctx, core.Item[I]{Value: elem}, out)
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		nil,
+		opts...)
+}
+
+// ExitBulkhead creates a Flow marking the end of the segment bounded by b, releasing the
+// permit of every item and error leaving it. Errors are passed on unchanged, leaving their
+// handling to the stages downstream. The stages in between should emit one item or
+// error per item, or release the permits of the items they drop with Bulkhead.Release, as
+// the segment would otherwise fill up. All permits are released once the flow stops, so
+// the segment can be run again.
+//
+// Type Parameters:
+//   - I: The type of items
+//
+// Parameters:
+//   - b: The Bulkhead bounding the segment
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow exiting the segment bounded by b
+func ExitBulkhead[I any](
+	b *Bulkhead,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			b.Release(1)
+			util.Send(ctx, core.Item[I]{Value: elem}, out)
+			return core.ActionProceed
+		},
+		func(ctx context.Context, err error, out chan<- core.Item[I]) core.StreamAction {
+			b.Release(1)
+			util.Send(ctx, core.Item[I]{Err: err}, out)
+			return core.ActionProceed
+		},
+		nil,
+		func(ctx context.Context, out chan<- core.Item[I]) {
+			b.Release(b.Limit())
+		},
+		opts...)
+}
@@ -0,0 +1,114 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestBulkhead(t *testing.T) {
+	errOdd := errors.New("odd")
+
+	tests := []struct {
+		name     string
+		limit    int
+		input    []int
+		segment  func(b *Bulkhead) *core.Flow[int, int]
+		expected []int
+	}{
+		{
+			name:  "bounds items in flight",
+			limit: 3,
+			input: []int{1, 2, 3, 4, 5, 6, 7, 8},
+			segment: func(b *Bulkhead) *core.Flow[int, int] {
+				return MapPar(func(ctx context.Context, i int) int { return i }, 8)
+			},
+			expected: []int{1, 2, 3, 4, 5, 6, 7, 8},
+		},
+		{
+			name:  "releases permits of errors",
+			limit: 2,
+			input: []int{1, 2, 3, 4, 5, 6},
+			segment: func(b *Bulkhead) *core.Flow[int, int] {
+				return TryMap(func(ctx context.Context, i int) (int, error) {
+					if i%2 == 1 {
+						return 0, errOdd
+					}
+					return i, nil
+				})
+			},
+			expected: []int{2, 4, 6},
+		},
+		{
+			name:  "releases permits of dropped items",
+			limit: 2,
+			input: []int{1, 2, 3, 4, 5, 6},
+			segment: func(b *Bulkhead) *core.Flow[int, int] {
+				return Filter(func(ctx context.Context, i int) bool {
+					if i%2 == 1 {
+						b.Release(1)
+						return false
+					}
+					return true
+				})
+			},
+			expected: []int{2, 4, 6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBulkhead(tt.limit)
+			var maxInFlight atomic.Int64
+
+			stream := compose.SourceThroughFlowToSink3(
+				sources.Slice(tt.input),
+				compose.MergeFlows(EnterBulkhead[int](b), tt.segment(b)),
+				ExitBulkhead[int](b),
+				Map(func(ctx context.Context, i int) int {
+					if n := int64(b.InFlight()); n > maxInFlight.Load() {
+						maxInFlight.Store(n)
+					}
+					time.Sleep(5 * time.Millisecond)
+					return i
+				}, core.WithSupervision(core.ResumingDecider)),
+				sinks.Slice[int](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			require.NoError(t, res.Err)
+			assert.ElementsMatch(t, tt.expected, res.Value)
+			assert.LessOrEqual(t, maxInFlight.Load(), int64(tt.limit))
+			assert.Zero(t, b.InFlight())
+		})
+	}
+}
+
+func TestBulkheadCancel(t *testing.T) {
+	b := NewBulkhead(1)
+	stream := compose.SourceThroughFlowToSink2(
+		sources.Slice([]int{1, 2, 3}),
+		EnterBulkhead[int](b),
+		Map(func(ctx context.Context, i int) int { return i }),
+		sinks.Slice[int](),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	res := <-stream.Run(ctx)
+	stream.AwaitDone()
+
+	assert.ErrorIs(t, res.Err, context.DeadlineExceeded)
+	assert.Equal(t, 1, b.InFlight())
+}
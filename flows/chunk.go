@@ -0,0 +1,90 @@
+package flows
+
+import (
+	"context"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// Chunk creates a Flow that re-slices a stream of bytes into chunks of exactly size bytes,
+// regardless of the sizes of the incoming slices. This prepares byte streams for stages that
+// require fixed-size blocks, such as multipart uploads or block ciphers. If the stream ends
+// with fewer than size bytes remaining, they are emitted as a final, shorter chunk.
+//
+// Emitted chunks are newly allocated, so the incoming slices may be reused by upstream.
+//
+// Parameters:
+//   - size: The size of each chunk in bytes
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that re-slices bytes into fixed-size chunks
+func Chunk(
+	size int,
+	opts ...core.FlowOption,
+) *core.Flow[[]byte, []byte] {
+	chunk := make([]byte, 0, size)
+	return core.NewFlow(
+		func(ctx context.Context, elem []byte, out chan<- core.Item[[]byte]) core.StreamAction {
+			for len(elem) > 0 {
+				n := min(size-len(chunk), len(elem))
+				chunk = append(chunk, elem[:n]...)
+				elem = elem[n:]
+				if len(chunk) == size {
+					util.Send(ctx, core.Item[[]byte]{Value: chunk}, out)
+					chunk = make([]byte, 0, size)
+				}
+			}
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[[]byte]) {
+			if len(chunk) > 0 {
+				util.Send(ctx, core.Item[[]byte]{Value: chunk}, out)
+				chunk = make([]byte, 0, size)
+			}
+		},
+		opts...,
+	)
+}
+
+// ChunkMin creates a Flow that accumulates a stream of bytes into chunks of at least minSize
+// bytes. Incoming slices are appended until the accumulated chunk reaches minSize, after which
+// it is emitted whole, so chunks are never split but may exceed minSize. This avoids flushing
+// many small writes, for example to compression stages or to multipart uploads with a minimum
+// part size. If the stream ends with fewer than minSize bytes remaining, they are emitted as a
+// final, shorter chunk.
+//
+// Emitted chunks are newly allocated, so the incoming slices may be reused by upstream.
+//
+// Parameters:
+//   - minSize: The minimum size of each chunk in bytes
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that accumulates bytes into chunks of a minimum size
+func ChunkMin(
+	minSize int,
+	opts ...core.FlowOption,
+) *core.Flow[[]byte, []byte] {
+	var chunk []byte
+	return core.NewFlow(
+		func(ctx context.Context, elem []byte, out chan<- core.Item[[]byte]) core.StreamAction {
+			chunk = append(chunk, elem...)
+			if len(chunk) >= minSize {
+				util.Send(ctx, core.Item[[]byte]{Value: chunk}, out)
+				chunk = nil
+			}
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[[]byte]) {
+			if len(chunk) > 0 {
+				util.Send(ctx, core.Item[[]byte]{Value: chunk}, out)
+				chunk = nil
+			}
+		},
+		opts...,
+	)
+}
@@ -0,0 +1,74 @@
+package flows
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		flow     *core.Flow[[]byte, []byte]
+		expected []string
+	}{
+		{
+			name:     "Chunk splits large slices",
+			input:    []string{"abcdefg"},
+			flow:     Chunk(3),
+			expected: []string{"abc", "def", "g"},
+		},
+		{
+			name:     "Chunk joins small slices",
+			input:    []string{"a", "bc", "d", "ef"},
+			flow:     Chunk(3),
+			expected: []string{"abc", "def"},
+		},
+		{
+			name:     "Chunk handles empty input",
+			input:    []string{},
+			flow:     Chunk(3),
+			expected: []string{},
+		},
+		{
+			name:     "ChunkMin accumulates small slices",
+			input:    []string{"a", "bc", "d", "ef"},
+			flow:     ChunkMin(3),
+			expected: []string{"abc", "def"},
+		},
+		{
+			name:     "ChunkMin keeps large slices whole",
+			input:    []string{"ab", "cdefg", "h"},
+			flow:     ChunkMin(3),
+			expected: []string{"abcdefg", "h"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := make([][]byte, len(tt.input))
+			for i, s := range tt.input {
+				input[i] = []byte(s)
+			}
+			stream := compose.SourceThroughFlowToSink2(
+				sources.Slice(input),
+				tt.flow,
+				Map(func(ctx context.Context, b []byte) string { return string(b) }),
+				sinks.Slice[string](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+		})
+	}
+}
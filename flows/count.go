@@ -0,0 +1,39 @@
+package flows
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// Count creates a MatFlow that passes items through unchanged while counting them.
+// Every materialization creates a fresh counter, which can be read while the stream
+// is running or after it has completed.
+//
+// Type Parameters:
+//   - I: The type of items to count
+//
+// Parameters:
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a MatFlow materializing the number of items that passed through the flow
+func Count[I any](
+	opts ...core.FlowOption,
+) core.MatFlow[I, I, *atomic.Int64] {
+	return func() (*core.Flow[I, I], *atomic.Int64) {
+		count := &atomic.Int64{}
+		flow := core.NewFlow(
+			func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+				count.Add(1)
+				util.Send(ctx, core.Item[I]{Value: elem}, out)
+				return core.ActionProceed
+			},
+			nil,
+			nil,
+			nil,
+			opts...)
+		return flow, count
+	}
+}
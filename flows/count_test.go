@@ -0,0 +1,49 @@
+package flows
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []int
+		want  int64
+	}{
+		{
+			name:  "counts all items",
+			input: []int{1, 2, 3},
+			want:  3,
+		},
+		{
+			name:  "handles empty input",
+			input: []int{},
+			want:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blueprint := core.ToMat(
+				core.ViaMat(core.MatSourceOf(sources.Slice(tt.input)), Count[int](), core.KeepRight),
+				core.MatSinkOf(sinks.Slice[int]()),
+				core.KeepLeft,
+			)
+
+			// Every materialization gets its own counter
+			for range 2 {
+				stream, count := blueprint.Materialize()
+				res := <-stream.Run(context.Background())
+				assert.NoError(t, res.Err)
+				assert.Equal(t, tt.input, res.Value)
+				assert.Equal(t, tt.want, count.Load())
+			}
+		})
+	}
+}
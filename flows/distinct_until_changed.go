@@ -0,0 +1,46 @@
+package flows
+
+import (
+	"context"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// DistinctUntilChanged creates a Flow that discards items equal to the item before them, so
+// only changes pass through. This is useful after polling sources that repeatedly return the
+// same state. Discarded items are reported to the stream's DropHandler, if any, with reason
+// core.DropReasonDuplicate.
+//
+// Type Parameters:
+//   - I: The type of items to compare
+//
+// Parameters:
+//   - eq: Function that returns true if two consecutive items are equal
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that suppresses consecutive duplicate items
+func DistinctUntilChanged[I any](
+	eq func(prev, next I) bool,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	var prev I
+	seen := false
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			if seen && eq(prev, elem) {
+				core.ReportDrop(ctx, "DistinctUntilChanged", core.DropReasonDuplicate, elem)
+				return core.ActionProceed
+			}
+			prev, seen = elem, true
+			util.Send(ctx, core.Item[I]{Value: elem}, out)
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[I]) {
+			var zero I
+			prev, seen = zero, false
+		},
+		opts...)
+}
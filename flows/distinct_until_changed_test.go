@@ -0,0 +1,71 @@
+package flows
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestDistinctUntilChanged(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           []string
+		eq              func(prev, next string) bool
+		expected        []string
+		expectedDropped int64
+	}{
+		{
+			name:            "suppresses consecutive duplicates",
+			input:           []string{"a", "a", "b", "b", "b", "a"},
+			eq:              func(prev, next string) bool { return prev == next },
+			expected:        []string{"a", "b", "a"},
+			expectedDropped: 3,
+		},
+		{
+			name:     "passes changing items through",
+			input:    []string{"a", "b", "c"},
+			eq:       func(prev, next string) bool { return prev == next },
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:            "uses equality function",
+			input:           []string{"a", "A", "b", "B"},
+			eq:              strings.EqualFold,
+			expected:        []string{"a", "b"},
+			expectedDropped: 2,
+		},
+		{
+			name:     "handles empty input",
+			input:    []string{},
+			eq:       func(prev, next string) bool { return prev == next },
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := core.NewDropCounter()
+			ctx := core.WithDropHandler(context.Background(), counter.Handle)
+
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(tt.input),
+				DistinctUntilChanged(tt.eq),
+				sinks.Slice[string](),
+			)
+
+			res := <-stream.Run(ctx)
+			stream.AwaitDone()
+
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+			assert.Equal(t, tt.expectedDropped, counter.Count("DistinctUntilChanged", core.DropReasonDuplicate))
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// ElementError pairs an error with the element that caused it. Return it from the function
+// passed to a flow such as TryMap to keep the offending element available to the Sink of
+// DivertOnError, which can retrieve it using errors.As.
+//
+// Type Parameters:
+//   - I: The type of the element
+type ElementError[I any] struct {
+	// Elem is the element that caused the error
+	Elem I
+
+	// Err is the error caused by the element
+	Err error
+
+	// Attempts is the number of times processing the element was attempted, such as by
+	// RetryMap, or zero if it was not tracked
+	Attempts int
+}
+
+// Error returns the message of the error together with the element.
+func (e *ElementError[I]) Error() string {
+	return fmt.Sprintf("element %v: %v", e.Elem, e.Err)
+}
+
+// Unwrap returns the error caused by the element.
+func (e *ElementError[I]) Unwrap() error {
+	return e.Err
+}
+
+// DivertOnError creates a Flow that diverts errors received from upstream to a separate
+// Sink, such as a dead letter queue, while items continue downstream. The Sink is run in a
+// stream of its own, which is started on the first error and completed once the flow
+// completes. Diverted errors are reported to the listeners registered with Stream.OnError
+// with core.ErrorHandlingDiverted.
+//
+// If the Sink fails or stops early, the error that could not be diverted is passed
+// downstream and the flow stops, so that no error is lost silently. The error the Sink
+// failed with is passed downstream when the flow completes.
+//
+// Type Parameters:
+//   - I: The type of items
+//   - R: The type of the result of the Sink
+//
+// Parameters:
+//   - sink: Sink receiving the diverted errors
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that diverts errors to a Sink
+func DivertOnError[I, R any](
+	sink *core.Sink[error, R],
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	side := &sideSink[error, R]{sink: sink}
+	failed := false
+
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			util.Send(ctx, core.Item[I]{Value: elem}, out)
+			return core.ActionProceed
+		},
+		func(ctx context.Context, err error, out chan<- core.Item[I]) core.StreamAction {
+			if !side.send(ctx, err) {
+				if ctx.Err() != nil {
+					return core.ActionStop
+				}
+				// The Sink stopped early, so the error is passed on rather than lost
+				failed = true
+				util.Send(ctx, core.Item[I]{Err: err}, out)
+				return core.ActionStop
+			}
+			core.ReportError(ctx, "DivertOnError", core.ErrorHandlingDiverted, err)
+			return core.ActionProceed
+		},
+		nil,
+		func(ctx context.Context, out chan<- core.Item[I]) {
+			// If an error was already passed on because the Sink stopped early, the flow failed
+			if err := side.stop(); err != nil && !failed {
+				util.Send(ctx, core.Item[I]{Err: err}, out)
+			}
+			failed = false
+		},
+		opts...)
+}
+
+// sideSink runs a Sink in a stream of its own, which is fed by a flow and started once the
+// first value is sent to it.
+type sideSink[T, R any] struct {
+	sink   *core.Sink[T, R]
+	in     chan core.Item[T]
+	done   chan struct{}
+	stream *core.Stream[R]
+	res    <-chan core.Item[R]
+}
+
+// send sends v to the Sink, starting its stream if needed. It returns false if v could not
+// be sent, because ctx was cancelled or the Sink stopped early.
+func (s *sideSink[T, R]) send(ctx context.Context, v T) bool {
+	if s.in == nil {
+		s.in = make(chan core.Item[T])
+		done := make(chan struct{})
+		s.done = done
+		in := s.in
+		source := core.NewSource(
+			func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan core.Item[T] {
+				return in
+			},
+		)
+		s.stream = core.ConnectSourceToSink(source, s.sink)
+		s.stream.OnTermination(func(error) {
+			close(done)
+		})
+		s.res = s.stream.Run(ctx)
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-s.done:
+		return false
+	case s.in <- core.Item[T]{Value: v}:
+		return true
+	}
+}
+
+// stop completes the stream of the Sink, if it was started, and returns the error it failed with.
+func (s *sideSink[T, R]) stop() error {
+	if s.in == nil {
+		return nil
+	}
+	close(s.in)
+	r := <-s.res
+	s.stream.AwaitDone()
+	s.in, s.done, s.stream, s.res = nil, nil, nil, nil
+	return r.Err
+}
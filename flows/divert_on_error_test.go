@@ -0,0 +1,123 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestDivertOnError(t *testing.T) {
+	errOdd := errors.New("odd")
+	errSink := errors.New("sink failed")
+
+	tests := []struct {
+		name        string
+		input       []int
+		failSink    func(calls int) bool
+		failOnClose bool
+		expected    []int
+		expectedErr error
+		diverted    []int
+	}{
+		{
+			name:     "diverts errors while items continue",
+			input:    []int{1, 2, 3, 4, 5},
+			expected: []int{2, 4},
+			diverted: []int{1, 3, 5},
+		},
+		{
+			name:     "does not start the sink without errors",
+			input:    []int{2, 4},
+			expected: []int{2, 4},
+		},
+		{
+			name:        "passes errors on once the sink stopped",
+			input:       []int{1, 2, 3},
+			failSink:    func(calls int) bool { return calls == 1 },
+			expectedErr: errOdd,
+			diverted:    []int{1},
+		},
+		{
+			name:        "fails when the sink fails on completion",
+			input:       []int{1, 2},
+			failOnClose: true,
+			expectedErr: errSink,
+			diverted:    []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diverted []int
+			calls := 0
+			sink := core.NewSink(
+				struct{}{},
+				func(ctx context.Context, err error, acc core.Item[struct{}]) (core.Item[struct{}], core.StreamAction) {
+					var elemErr *ElementError[int]
+					if errors.As(err, &elemErr) {
+						diverted = append(diverted, elemErr.Elem)
+					}
+					calls++
+					if tt.failSink != nil && tt.failSink(calls) {
+						return core.Item[struct{}]{Err: errSink}, core.ActionStop
+					}
+					return acc, core.ActionProceed
+				},
+				nil,
+				func(ctx context.Context, acc core.Item[struct{}]) (core.Item[struct{}], core.StreamAction) {
+					if tt.failOnClose {
+						return core.Item[struct{}]{Err: errSink}, core.ActionStop
+					}
+					return acc, core.ActionStop
+				},
+			)
+
+			stream := compose.SourceThroughFlowToSink2(
+				sources.Slice(tt.input),
+				TryMap(func(ctx context.Context, i int) (int, error) {
+					if i%2 == 1 {
+						return 0, &ElementError[int]{Elem: i, Err: errOdd}
+					}
+					return i, nil
+				}),
+				DivertOnError[int](sink),
+				sinks.Slice[int](),
+			)
+
+			var events []core.ErrorEvent
+			stream.OnError(func(ctx context.Context, event core.ErrorEvent) {
+				events = append(events, event)
+			})
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			assert.Equal(t, tt.diverted, diverted)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+			require.Len(t, events, len(tt.diverted))
+			for _, event := range events {
+				assert.Equal(t, core.ErrorHandlingDiverted, event.Handling)
+			}
+		})
+	}
+}
+
+func TestElementError(t *testing.T) {
+	errFailed := errors.New("failed")
+	err := error(&ElementError[string]{Elem: "a", Err: errFailed})
+
+	assert.ErrorIs(t, err, errFailed)
+	assert.Equal(t, "element a: failed", err.Error())
+}
@@ -0,0 +1,95 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// FailedItem describes an item that failed to be processed, as diverted by DivertTo.
+//
+// Type Parameters:
+//   - I: The type of the item
+type FailedItem[I any] struct {
+	// Input is the item that failed to be processed
+	Input I
+
+	// Err is the error the item failed with
+	Err error
+
+	// Attempts is the number of times processing the item was attempted
+	Attempts int
+}
+
+// DivertTo creates a MatFlow that routes failed items to a dead letter Sink, while healthy
+// items continue downstream. Failed items are recognized by errors received from upstream
+// that wrap an ElementError, as returned by flows such as RetryMap or by wrapping errors
+// returned from TryMap. Each is sent to the Sink as a FailedItem carrying the item, the error
+// and the number of attempts, which is 1 if the ElementError did not track it. Diverted errors
+// are reported to the listeners registered with Stream.OnError with core.ErrorHandlingDiverted.
+//
+// Errors that do not wrap an ElementError of the item type, or that decider rejects, follow the
+// standard error path and stop the flow. Like DivertOnError, the Sink is run in a stream of its
+// own, and if it fails or stops early, the error that could not be diverted is passed downstream.
+//
+// Type Parameters:
+//   - I: The type of items
+//   - R: The type of the result of the Sink
+//
+// Parameters:
+//   - sink: Sink receiving the failed items
+//   - decider: Function deciding whether an error is diverted, or nil to divert all failed items
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a MatFlow materializing the number of items diverted to the Sink
+func DivertTo[I, R any](
+	sink *core.Sink[FailedItem[I], R],
+	decider func(err error) bool,
+	opts ...core.FlowOption,
+) core.MatFlow[I, I, *atomic.Int64] {
+	return func() (*core.Flow[I, I], *atomic.Int64) {
+		diverted := &atomic.Int64{}
+		side := &sideSink[FailedItem[I], R]{sink: sink}
+		failed := false
+
+		flow := core.NewFlow(
+			func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+				util.Send(ctx, core.Item[I]{Value: elem}, out)
+				return core.ActionProceed
+			},
+			func(ctx context.Context, err error, out chan<- core.Item[I]) core.StreamAction {
+				var elemErr *ElementError[I]
+				if !errors.As(err, &elemErr) || (decider != nil && !decider(err)) {
+					util.Send(ctx, core.Item[I]{Err: err}, out)
+					return core.ActionStop
+				}
+
+				item := FailedItem[I]{Input: elemErr.Elem, Err: elemErr.Err, Attempts: max(elemErr.Attempts, 1)}
+				if !side.send(ctx, item) {
+					if ctx.Err() != nil {
+						return core.ActionStop
+					}
+					// The Sink stopped early, so the error is passed on rather than lost
+					failed = true
+					util.Send(ctx, core.Item[I]{Err: err}, out)
+					return core.ActionStop
+				}
+				diverted.Add(1)
+				core.ReportError(ctx, "DivertTo", core.ErrorHandlingDiverted, err)
+				return core.ActionProceed
+			},
+			nil,
+			func(ctx context.Context, out chan<- core.Item[I]) {
+				// If an error was already passed on because the Sink stopped early, the flow failed
+				if err := side.stop(); err != nil && !failed {
+					util.Send(ctx, core.Item[I]{Err: err}, out)
+				}
+				failed = false
+			},
+			opts...)
+		return flow, diverted
+	}
+}
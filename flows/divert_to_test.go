@@ -0,0 +1,106 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/retry"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestDivertTo(t *testing.T) {
+	errOdd := errors.New("odd")
+	errFatal := errors.New("fatal")
+	config := retry.NewConfig(time.Millisecond, time.Millisecond, 0, retry.WithMaxRetries(2))
+
+	tests := []struct {
+		name        string
+		input       []int
+		fn          func(ctx context.Context, i int) (int, error)
+		decider     func(err error) bool
+		expected    []int
+		expectedErr error
+		diverted    []FailedItem[int]
+	}{
+		{
+			name:  "diverts failed items with their attempts",
+			input: []int{1, 2, 3, 4},
+			fn: func(ctx context.Context, i int) (int, error) {
+				if i%2 == 1 {
+					return 0, errOdd
+				}
+				return i, nil
+			},
+			expected: []int{2, 4},
+			diverted: []FailedItem[int]{
+				{Input: 1, Err: errOdd, Attempts: 3},
+				{Input: 3, Err: errOdd, Attempts: 3},
+			},
+		},
+		{
+			name:  "fails on errors rejected by the decider",
+			input: []int{1, 2, 3},
+			fn: func(ctx context.Context, i int) (int, error) {
+				switch i {
+				case 1:
+					return 0, errOdd
+				case 3:
+					return 0, errFatal
+				}
+				return i, nil
+			},
+			decider:     func(err error) bool { return errors.Is(err, errOdd) },
+			expectedErr: errFatal,
+			diverted:    []FailedItem[int]{{Input: 1, Err: errOdd, Attempts: 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diverted []FailedItem[int]
+			dlq := sinks.ForEach(func(ctx context.Context, item FailedItem[int]) {
+				diverted = append(diverted, item)
+			})
+			blueprint := core.ToMat(
+				core.ViaMat(
+					core.MatSourceOf(sources.Slice(tt.input)),
+					core.FlowViaMat(core.MatFlowOf(RetryMap(config, tt.fn)), DivertTo(dlq, tt.decider), core.KeepRight),
+					core.KeepRight,
+				),
+				core.MatSinkOf(sinks.Slice[int]()),
+				core.KeepLeft,
+			)
+
+			// Every materialization gets its own counter
+			for range 2 {
+				diverted = nil
+				stream, count := blueprint.Materialize()
+				var events []core.ErrorEvent
+				stream.OnError(func(ctx context.Context, event core.ErrorEvent) {
+					if event.Handling == core.ErrorHandlingDiverted {
+						events = append(events, event)
+					}
+				})
+
+				res := <-stream.Run(context.Background())
+				stream.AwaitDone()
+
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, res.Err, tt.expectedErr)
+				} else {
+					require.NoError(t, res.Err)
+					assert.Equal(t, tt.expected, res.Value)
+				}
+				assert.Equal(t, tt.diverted, diverted)
+				assert.Equal(t, int64(len(tt.diverted)), count.Load())
+				assert.Len(t, events, len(tt.diverted))
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package flows
+
+import (
+	"context"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// Envelop creates a Flow that wraps each item in a core.Envelope, so that metadata can be
+// carried along with it through the pipeline. The ingest time of each envelope is set to the
+// time the item is received, and meta is called to extract the other metadata from the item,
+// such as correlation ids from message attributes.
+//
+// Type Parameters:
+//   - T: The type of items
+//
+// Parameters:
+//   - meta: Function returning the metadata of an item, or nil to only set the ingest time.
+//     The ingest time is only set if meta leaves it unset.
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that wraps items in envelopes
+func Envelop[T any](
+	meta func(context.Context, T) core.Metadata,
+	opts ...core.FlowOption,
+) *core.Flow[T, core.Envelope[T]] {
+	return Map(func(ctx context.Context, elem T) core.Envelope[T] {
+		var m core.Metadata
+		if meta != nil {
+			m = meta(ctx, elem)
+		}
+		if m.IngestTime.IsZero() {
+			m.IngestTime = time.Now()
+		}
+		return core.Envelope[T]{Value: elem, Metadata: m}
+	}, opts...)
+}
+
+// Unenvelop creates a Flow that removes the envelope from each item, discarding its metadata.
+//
+// Type Parameters:
+//   - T: The type of items
+//
+// Parameters:
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that emits the values of envelopes
+func Unenvelop[T any](
+	opts ...core.FlowOption,
+) *core.Flow[core.Envelope[T], T] {
+	return Map(func(ctx context.Context, elem core.Envelope[T]) T {
+		return elem.Value
+	}, opts...)
+}
+
+// MapEnvelope creates a Flow that transforms the value of each envelope using the provided
+// mapping function, keeping its metadata.
+//
+// Type Parameters:
+//   - I: The type of input values
+//   - O: The type of output values
+//
+// Parameters:
+//   - fn: Function that transforms an input value into an output value
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that transforms the values of envelopes
+func MapEnvelope[I, O any](
+	fn func(context.Context, I) O,
+	opts ...core.FlowOption,
+) *core.Flow[core.Envelope[I], core.Envelope[O]] {
+	return Map(func(ctx context.Context, elem core.Envelope[I]) core.Envelope[O] {
+		return core.Envelope[O]{Value: fn(ctx, elem.Value), Metadata: elem.Metadata}
+	}, opts...)
+}
+
+// TryMapEnvelope creates a Flow that transforms the value of each envelope using the provided
+// mapping function that can return errors, keeping its metadata. If the mapping function
+// returns an error, it is emitted instead.
+//
+// Type Parameters:
+//   - I: The type of input values
+//   - O: The type of output values
+//
+// Parameters:
+//   - fn: Function that transforms an input value into an output value or returns an error
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that transforms the values of envelopes
+func TryMapEnvelope[I, O any](
+	fn func(context.Context, I) (O, error),
+	opts ...core.FlowOption,
+) *core.Flow[core.Envelope[I], core.Envelope[O]] {
+	return TryMap(func(ctx context.Context, elem core.Envelope[I]) (core.Envelope[O], error) {
+		res, err := fn(ctx, elem.Value)
+		if err != nil {
+			return core.Envelope[O]{}, err
+		}
+		return core.Envelope[O]{Value: res, Metadata: elem.Metadata}, nil
+	}, opts...)
+}
+
+// BatchEnvelope creates a Flow that groups the values of incoming envelopes into slices of the
+// specified size, like Batch. The metadata of each batch is the merged metadata of its
+// envelopes, see core.Metadata.Merge, so it carries the values of all of them and the
+// earliest ingest time.
+//
+// Type Parameters:
+//   - T: The type of values to batch
+//
+// Parameters:
+//   - n: The size of each batch
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that transforms envelopes into envelopes of slices
+func BatchEnvelope[T any](
+	n int,
+	opts ...core.FlowOption,
+) *core.Flow[core.Envelope[T], core.Envelope[[]T]] {
+	batch := core.Envelope[[]T]{Value: make([]T, 0, n)}
+	return core.NewFlow(
+		func(ctx context.Context, elem core.Envelope[T], out chan<- core.Item[core.Envelope[[]T]]) core.StreamAction {
+			batch.Value = append(batch.Value, elem.Value)
+			batch.Metadata = batch.Metadata.Merge(elem.Metadata)
+			if len(batch.Value) == n {
+				util.Send(ctx, core.Item[core.Envelope[[]T]]{Value: batch}, out)
+				batch = core.Envelope[[]T]{Value: make([]T, 0, n)}
+			}
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[core.Envelope[[]T]]) {
+			if len(batch.Value) > 0 {
+				util.Send(ctx, core.Item[core.Envelope[[]T]]{Value: batch}, out)
+				batch = core.Envelope[[]T]{Value: make([]T, 0, n)}
+			}
+		},
+		opts...)
+}
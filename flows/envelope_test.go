@@ -0,0 +1,89 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestEnvelopeFlows(t *testing.T) {
+	ingest := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	meta := func(ctx context.Context, i int) core.Metadata {
+		return core.Metadata{IngestTime: ingest.Add(time.Duration(i) * time.Second)}.With("id", strconv.Itoa(i))
+	}
+
+	t.Run("propagates metadata through map and batch", func(t *testing.T) {
+		stream := compose.SourceThroughFlowToSink3(
+			sources.Slice([]int{1, 2, 3}),
+			Envelop(meta),
+			MapEnvelope(func(ctx context.Context, i int) string { return strconv.Itoa(i * 10) }),
+			BatchEnvelope[string](2),
+			sinks.Slice[core.Envelope[[]string]](),
+		)
+
+		res := <-stream.Run(context.Background())
+		stream.AwaitDone()
+
+		assert.NoError(t, res.Err)
+		assert.Equal(t, []core.Envelope[[]string]{
+			{
+				Value:    []string{"10", "20"},
+				Metadata: core.Metadata{Values: map[string]string{"id": "1"}, IngestTime: ingest.Add(time.Second)},
+			},
+			{
+				Value:    []string{"30"},
+				Metadata: core.Metadata{Values: map[string]string{"id": "3"}, IngestTime: ingest.Add(3 * time.Second)},
+			},
+		}, res.Value)
+	})
+
+	t.Run("sets ingest time", func(t *testing.T) {
+		before := time.Now()
+		stream := compose.SourceThroughFlowToSink2(
+			sources.Slice([]int{1}),
+			Envelop[int](nil),
+			Unenvelop[int](),
+			sinks.Slice[int](),
+		)
+		envelopes := compose.SourceThroughFlowToSink(
+			sources.Slice([]int{1}),
+			Envelop[int](nil),
+			sinks.Slice[core.Envelope[int]](),
+		)
+
+		res := <-stream.Run(context.Background())
+		envRes := <-envelopes.Run(context.Background())
+
+		assert.Equal(t, []int{1}, res.Value)
+		assert.Len(t, envRes.Value, 1)
+		assert.False(t, envRes.Value[0].Metadata.IngestTime.Before(before))
+	})
+
+	t.Run("try map keeps metadata and emits errors", func(t *testing.T) {
+		testErr := errors.New("odd")
+		stream := compose.SourceThroughFlowToSink2(
+			sources.Slice([]int{2, 3}),
+			Envelop(meta),
+			TryMapEnvelope(func(ctx context.Context, i int) (int, error) {
+				if i%2 == 1 {
+					return 0, testErr
+				}
+				return i / 2, nil
+			}),
+			sinks.Slice[core.Envelope[int]](),
+		)
+
+		res := <-stream.Run(context.Background())
+
+		assert.ErrorIs(t, res.Err, testErr)
+		assert.Equal(t, []core.Envelope[int]{{Value: 1, Metadata: meta(context.Background(), 2)}}, res.Value)
+	})
+}
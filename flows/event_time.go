@@ -0,0 +1,180 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// ErrInvalidEventTime is the error wrapped when the event time of an item is missing or invalid.
+var ErrInvalidEventTime = errors.New("invalid event time")
+
+// Timestamped is an item with the time the event it describes occurred.
+//
+// Type Parameters:
+//   - T: The type of the item
+type Timestamped[T any] struct {
+	// Value is the item
+	Value T
+
+	// EventTime is the time the event occurred
+	EventTime time.Time
+}
+
+// EventTimePolicy determines how AssignEventTime handles items without a valid event time.
+type EventTimePolicy int
+
+const (
+	// EventTimeFail emits an error wrapping ErrInvalidEventTime for the item, which is the default.
+	EventTimeFail EventTimePolicy = iota
+
+	// EventTimeDrop discards the item, reporting it with reason core.DropReasonInvalidEventTime.
+	EventTimeDrop
+
+	// EventTimeProcessingTime assigns the time the item is processed as its event time.
+	EventTimeProcessingTime
+)
+
+// String returns the name of the policy.
+func (p EventTimePolicy) String() string {
+	switch p {
+	case EventTimeFail:
+		return "fail"
+	case EventTimeDrop:
+		return "drop"
+	case EventTimeProcessingTime:
+		return "processing_time"
+	default:
+		return fmt.Sprintf("EventTimePolicy(%d)", int(p))
+	}
+}
+
+// AssignEventTime creates a Flow that attaches the time the event an item describes occurred,
+// as extracted by the provided function, so that downstream stages can process items by event
+// time rather than by the time they arrive. It is the standard entry point of event-time
+// pipelines.
+//
+// An event time is missing if extract returns the zero time, and invalid if extract returns
+// an error. Such items are handled according to the policy. Use ParseTimestamp to extract
+// timestamps that may be formatted in several ways.
+//
+// Type Parameters:
+//   - T: The type of items
+//
+// Parameters:
+//   - extract: Function that returns the event time of an item
+//   - policy: How items with a missing or invalid event time are handled
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that produces Timestamped items
+func AssignEventTime[T any](
+	extract func(T) (time.Time, error),
+	policy EventTimePolicy,
+	opts ...core.FlowOption,
+) *core.Flow[T, Timestamped[T]] {
+	return core.NewSyncFlow(
+		func(ctx context.Context, elem T, emit func(core.Item[Timestamped[T]])) core.StreamAction {
+			eventTime, err := extract(elem)
+			if err == nil && eventTime.IsZero() {
+				err = errors.New("missing")
+			}
+			if err == nil {
+				emit(core.Item[Timestamped[T]]{Value: Timestamped[T]{Value: elem, EventTime: eventTime}})
+				return core.ActionProceed
+			}
+
+			switch policy {
+			case EventTimeDrop:
+				core.ReportDrop(ctx, "AssignEventTime", core.DropReasonInvalidEventTime, elem)
+			case EventTimeProcessingTime:
+				emit(core.Item[Timestamped[T]]{Value: Timestamped[T]{Value: elem, EventTime: time.Now()}})
+			default:
+				emit(core.Item[Timestamped[T]]{Err: fmt.Errorf("%w: %w", ErrInvalidEventTime, err)})
+			}
+			return core.ActionProceed
+		},
+		nil,
+		opts...)
+}
+
+// defaultTimestampLayouts are the layouts tried by ParseTimestamp if none are given.
+var defaultTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	time.DateTime,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.DateOnly,
+}
+
+// ParseTimestamp parses a timestamp that may be formatted in several ways. Numeric timestamps
+// are interpreted as Unix time, in seconds, milliseconds, microseconds or nanoseconds depending
+// on their magnitude, and may have a fractional part if given in seconds. Other timestamps are
+// parsed with each layout in turn. Timestamps without a time zone are interpreted as UTC.
+//
+// Parameters:
+//   - value: The timestamp to parse
+//   - layouts: Layouts to try, in order. If none are given, RFC 3339, "2006-01-02 15:04:05",
+//     RFC 1123 and "2006-01-02" are tried, with and without fractional seconds and time zone.
+//
+// Returns the parsed time, or an error if no layout matches
+func ParseTimestamp(value string, layouts ...string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, ok := parseUnixTimestamp(value); ok {
+		return t, nil
+	}
+
+	if len(layouts) == 0 {
+		layouts = defaultTimestampLayouts
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("parsing timestamp %q: unsupported format", value)
+}
+
+// parseUnixTimestamp parses a numeric Unix timestamp, guessing its unit from its magnitude.
+func parseUnixTimestamp(value string) (time.Time, bool) {
+	if secs, frac, ok := strings.Cut(value, "."); ok {
+		// Fractional seconds are parsed as digits, as a float cannot hold nanoseconds
+		if len(frac) == 0 || len(frac) > 9 || strings.HasPrefix(secs, "-") {
+			return time.Time{}, false
+		}
+		s, err := strconv.ParseInt(secs, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		ns, err := strconv.ParseUint(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(s, int64(ns)).UTC(), true
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs < 1e11:
+		return time.Unix(n, 0).UTC(), true
+	case abs < 1e14:
+		return time.UnixMilli(n).UTC(), true
+	case abs < 1e17:
+		return time.UnixMicro(n).UTC(), true
+	default:
+		return time.Unix(0, n).UTC(), true
+	}
+}
@@ -0,0 +1,144 @@
+package flows
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestAssignEventTime(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	extract := func(s string) (time.Time, error) {
+		if s == "" {
+			return time.Time{}, nil
+		}
+		return ParseTimestamp(s)
+	}
+
+	tests := []struct {
+		name          string
+		policy        EventTimePolicy
+		input         []string
+		expected      []Timestamped[string]
+		expectedErr   error
+		expectedDrops int
+	}{
+		{
+			name:   "assigns event time",
+			policy: EventTimeFail,
+			input:  []string{"2024-01-02T03:04:05Z", "1704164645"},
+			expected: []Timestamped[string]{
+				{Value: "2024-01-02T03:04:05Z", EventTime: ts},
+				{Value: "1704164645", EventTime: ts},
+			},
+		},
+		{
+			name:        "fails on invalid event time",
+			policy:      EventTimeFail,
+			input:       []string{"yesterday"},
+			expected:    []Timestamped[string]{},
+			expectedErr: ErrInvalidEventTime,
+		},
+		{
+			name:        "fails on missing event time",
+			policy:      EventTimeFail,
+			input:       []string{""},
+			expected:    []Timestamped[string]{},
+			expectedErr: ErrInvalidEventTime,
+		},
+		{
+			name:          "drops items without event time",
+			policy:        EventTimeDrop,
+			input:         []string{"", "yesterday", "1704164645"},
+			expected:      []Timestamped[string]{{Value: "1704164645", EventTime: ts}},
+			expectedDrops: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drops := 0
+			ctx := core.WithDropHandler(context.Background(), func(ctx context.Context, event core.DropEvent) {
+				assert.Equal(t, core.DropReasonInvalidEventTime, event.Reason)
+				drops++
+			})
+
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(tt.input),
+				AssignEventTime(extract, tt.policy),
+				sinks.Slice[Timestamped[string]](),
+			)
+
+			res := <-stream.Run(ctx)
+			stream.AwaitDone()
+
+			assert.Equal(t, tt.expected, res.Value)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, res.Err, tt.expectedErr)
+			} else {
+				assert.NoError(t, res.Err)
+			}
+			assert.Equal(t, tt.expectedDrops, drops)
+		})
+	}
+}
+
+func TestAssignEventTimeProcessingTime(t *testing.T) {
+	before := time.Now()
+	stream := compose.SourceThroughFlowToSink(
+		sources.Slice([]string{"yesterday"}),
+		AssignEventTime(func(s string) (time.Time, error) { return ParseTimestamp(s) }, EventTimeProcessingTime),
+		sinks.Slice[Timestamped[string]](),
+	)
+
+	res := <-stream.Run(context.Background())
+
+	assert.NoError(t, res.Err)
+	assert.Len(t, res.Value, 1)
+	assert.False(t, res.Value[0].EventTime.Before(before))
+}
+
+func TestParseTimestamp(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		layouts  []string
+		expected time.Time
+		wantErr  bool
+	}{
+		{name: "RFC 3339", value: "2024-01-02T03:04:05Z", expected: ts},
+		{name: "RFC 3339 with offset", value: "2024-01-02T05:04:05+02:00", expected: ts},
+		{name: "RFC 3339 with fraction", value: "2024-01-02T03:04:05.123Z", expected: ts.Add(123 * time.Millisecond)},
+		{name: "without time zone", value: "2024-01-02T03:04:05", expected: ts},
+		{name: "date time", value: "2024-01-02 03:04:05", expected: ts},
+		{name: "RFC 1123", value: "Tue, 02 Jan 2024 03:04:05 UTC", expected: ts},
+		{name: "date", value: "2024-01-02", expected: ts.Truncate(24 * time.Hour)},
+		{name: "unix seconds", value: "1704164645", expected: ts},
+		{name: "unix seconds with fraction", value: "1704164645.5", expected: ts.Add(500 * time.Millisecond)},
+		{name: "unix milliseconds", value: "1704164645123", expected: ts.Add(123 * time.Millisecond)},
+		{name: "unix microseconds", value: "1704164645123456", expected: ts.Add(123456 * time.Microsecond)},
+		{name: "unix nanoseconds", value: "1704164645123456789", expected: ts.Add(123456789)},
+		{name: "custom layout", value: "02/01/2024", layouts: []string{"02/01/2006"}, expected: ts.Truncate(24 * time.Hour)},
+		{name: "unsupported format", value: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTimestamp(tt.value, tt.layouts...)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.expected.Equal(got), "expected %s, got %s", tt.expected, got)
+		})
+	}
+}
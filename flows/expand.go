@@ -0,0 +1,131 @@
+package flows
+
+import (
+	"context"
+	"iter"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// Expand creates a Flow that keeps emitting items extrapolated from the latest item while
+// upstream is slower than downstream. For every item received, extrapolate returns a sequence
+// of items, which are emitted as fast as downstream accepts them until the next item arrives
+// from upstream, at which point the sequence is abandoned in favor of the sequence of the new
+// item. Once a sequence is exhausted, the flow waits for the next item.
+//
+// The first item of every sequence is always emitted, so if extrapolate starts its sequence
+// with the item itself, no item from upstream is lost. An infinite sequence, such as the item
+// followed by repeated "stale" markers, gives downstream a steady cadence driven by its own
+// pace, which suits heartbeat-style outputs from irregular inputs. Upstream is backpressured
+// only until the first item of the sequence of its previous item was emitted.
+//
+// Type Parameters:
+//   - I: The type of input items
+//   - O: The type of output items
+//
+// Parameters:
+//   - extrapolate: Function returning the sequence of items to emit for an input item
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that emits extrapolated items while upstream is idle
+func Expand[I, O any](
+	extrapolate func(ctx context.Context, elem I) iter.Seq[O],
+	opts ...core.FlowOption,
+) *core.Flow[I, O] {
+	// elems passes items to the goroutine emitting the sequences, which is started with the
+	// first item of a run
+	var elems chan I
+	var stop, stopped chan struct{}
+
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[O]) core.StreamAction {
+			if elems == nil {
+				elems, stop, stopped = make(chan I), make(chan struct{}), make(chan struct{})
+				go expand(ctx, extrapolate, elems, stop, stopped, out)
+			}
+			select {
+			case <-ctx.Done():
+				return core.ActionStop
+			case elems <- elem:
+				return core.ActionProceed
+			}
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[O]) {
+			if elems != nil {
+				close(stop)
+				<-stopped
+				elems, stop, stopped = nil, nil, nil
+			}
+		},
+		opts...)
+}
+
+// expand emits the sequences extrapolated from the items received on elems until stop is
+// closed, switching to the sequence of every new item.
+func expand[I, O any](
+	ctx context.Context,
+	extrapolate func(ctx context.Context, elem I) iter.Seq[O],
+	elems <-chan I,
+	stop <-chan struct{},
+	stopped chan<- struct{},
+	out chan<- core.Item[O],
+) {
+	defer close(stopped)
+
+	var next func() (O, bool)
+	release := func() {}
+	defer func() { release() }()
+
+	// start switches to the sequence of elem and emits its first item, returning false if
+	// ctx is done. The first item of a sequence is always emitted.
+	start := func(elem I) bool {
+		release()
+		next, release = iter.Pull(extrapolate(ctx, elem))
+		first, ok := next()
+		if !ok {
+			next = nil
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case out <- core.Item[O]{Value: first}:
+			return true
+		}
+	}
+
+	for {
+		if next == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case elem := <-elems:
+				if !start(elem) {
+					return
+				}
+			}
+			continue
+		}
+
+		item, ok := next()
+		if !ok {
+			next = nil
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case out <- core.Item[O]{Value: item}:
+		case elem := <-elems:
+			if !start(elem) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package flows
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+// staleAfter returns a sequence of the item followed by infinite stale markers.
+func staleAfter(ctx context.Context, elem int) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if !yield(strconv.Itoa(elem)) {
+			return
+		}
+		for yield("stale") {
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	t.Run("emits extrapolated items while upstream is idle", func(t *testing.T) {
+		ch := make(chan int)
+		stream := compose.SourceThroughFlowToSink(
+			sources.Chan(ch),
+			Expand(func(ctx context.Context, elem int) iter.Seq[int] {
+				return slices.Values([]int{elem, elem * 10})
+			}),
+			sinks.Slice[int](),
+		)
+		res := stream.Run(context.Background())
+
+		ch <- 1
+		time.Sleep(20 * time.Millisecond)
+		ch <- 2
+		time.Sleep(20 * time.Millisecond)
+		close(ch)
+
+		result := <-res
+		stream.AwaitDone()
+		require.NoError(t, result.Err)
+		assert.Equal(t, []int{1, 10, 2, 20}, result.Value)
+	})
+
+	t.Run("switches to sequence of new item", func(t *testing.T) {
+		ch := make(chan int)
+		stream := compose.SourceThroughFlowToSink(
+			sources.Chan(ch),
+			Expand(staleAfter),
+			sinks.Slice[string](),
+		)
+		res := stream.Run(context.Background())
+
+		ch <- 1
+		time.Sleep(10 * time.Millisecond)
+		ch <- 2
+		time.Sleep(10 * time.Millisecond)
+		close(ch)
+
+		result := <-res
+		stream.AwaitDone()
+		require.NoError(t, result.Err)
+
+		// Every item is emitted once, followed by stale markers until the next item
+		var items []string
+		for _, v := range result.Value {
+			if v != "stale" {
+				items = append(items, v)
+			}
+		}
+		assert.Equal(t, []string{"1", "2"}, items)
+		assert.Equal(t, "1", result.Value[0])
+		assert.Greater(t, len(result.Value), 4)
+	})
+
+	t.Run("emits nothing without items", func(t *testing.T) {
+		stream := compose.SourceThroughFlowToSink(
+			sources.Slice([]int{}),
+			Expand(staleAfter),
+			sinks.Slice[string](),
+		)
+
+		result := <-stream.Run(context.Background())
+		stream.AwaitDone()
+		require.NoError(t, result.Err)
+		assert.Empty(t, result.Value)
+	})
+}
+
+func TestExpandCancel(t *testing.T) {
+	ch := make(chan int, 1)
+	ch <- 1
+	stream := compose.SourceThroughFlowToSink(
+		sources.Chan(ch),
+		Expand(staleAfter),
+		sinks.Noop[string](),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// The sequence of the only item is expanded until the stream is cancelled
+	res := <-stream.Run(ctx)
+	close(ch)
+	stream.AwaitDone()
+	assert.ErrorIs(t, res.Err, context.DeadlineExceeded)
+}
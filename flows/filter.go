@@ -4,11 +4,11 @@ import (
 	"context"
 
 	"github.com/svenvdam/linea/core"
-	"github.com/svenvdam/linea/util"
 )
 
 // Filter creates a Flow that only allows items satisfying a predicate to pass through.
-// Items that don't match the predicate are discarded.
+// Items that don't match the predicate are discarded and reported to the stream's
+// DropHandler, if any, with reason core.DropReasonFiltered.
 //
 // Type Parameters:
 //   - I: The type of items to filter
@@ -22,15 +22,15 @@ func Filter[I any](
 	pred func(context.Context, I) bool,
 	opts ...core.FlowOption,
 ) *core.Flow[I, I] {
-	return core.NewFlow(
-		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+	return core.NewSyncFlow(
+		func(ctx context.Context, elem I, emit func(core.Item[I])) core.StreamAction {
 			if pred(ctx, elem) {
-				util.Send(ctx, core.Item[I]{Value: elem}, out)
+				emit(core.Item[I]{Value: elem})
+			} else {
+				core.ReportDrop(ctx, "Filter", core.DropReasonFiltered, elem)
 			}
 			return core.ActionProceed
 		},
 		nil,
-		nil,
-		nil,
 		opts...)
 }
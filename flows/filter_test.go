@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
 	"github.com/svenvdam/linea/sinks"
 	"github.com/svenvdam/linea/sources"
 	"github.com/svenvdam/linea/test"
@@ -46,7 +47,8 @@ func TestFilter(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ctx := context.Background()
+			counter := core.NewDropCounter()
+			ctx := core.WithDropHandler(context.Background(), counter.Handle)
 
 			stream := compose.SourceThroughFlowToSink3(
 				sources.Slice(tt.input),
@@ -62,6 +64,25 @@ func TestFilter(t *testing.T) {
 
 			res := <-stream.Run(ctx)
 			assert.NoError(t, res.Err)
+			assert.Equal(t, int64(len(tt.input)-len(tt.want)), counter.Count("Filter", core.DropReasonFiltered))
 		})
 	}
 }
+
+func TestFilterNamedDrops(t *testing.T) {
+	counter := core.NewDropCounter()
+	ctx := core.WithDropHandler(context.Background(), counter.Handle)
+
+	stream := compose.SourceThroughFlowToSink2(
+		sources.Slice([]int{1, 2, 3, 4, 5, 6}),
+		Filter(func(ctx context.Context, i int) bool { return i%2 == 0 }, core.WithFlowName("even")),
+		Filter(func(ctx context.Context, i int) bool { return i > 2 }),
+		sinks.Slice[int](),
+	)
+
+	res := <-stream.Run(ctx)
+	assert.NoError(t, res.Err)
+	assert.Equal(t, []int{4, 6}, res.Value)
+	assert.Equal(t, int64(3), counter.Count("even", core.DropReasonFiltered))
+	assert.Equal(t, int64(1), counter.Count("Filter", core.DropReasonFiltered))
+}
@@ -2,9 +2,9 @@ package flows
 
 import (
 	"context"
+	"iter"
 
 	"github.com/svenvdam/linea/core"
-	"github.com/svenvdam/linea/util"
 )
 
 // FlatMap creates a Flow that transforms each input item into zero or more output items.
@@ -23,15 +23,50 @@ import (
 func FlatMap[I, O any](
 	fn func(context.Context, I) []O,
 	opts ...core.FlowOption,
+) *core.Flow[I, O] {
+	return core.NewSyncFlow(
+		func(ctx context.Context, elem I, emit func(core.Item[O])) core.StreamAction {
+			for _, item := range fn(ctx, elem) {
+				emit(core.Item[O]{Value: item})
+			}
+			return core.ActionProceed
+		},
+		nil,
+		opts...)
+}
+
+// FlatMapSeq creates a Flow that transforms each input item into a sequence of output items,
+// which are emitted individually downstream. Unlike FlatMap, the sequence is consumed lazily:
+// every output item is produced only once downstream has accepted the previous one, so an
+// item can expand into many, or even infinitely many, output items without materializing
+// them. The sequence is abandoned once the flow is signalled to complete, for example because
+// a stage downstream such as Take completed, or once the stream is cancelled.
+//
+// Type Parameters:
+//   - I: The type of input items
+//   - O: The type of output items
+//
+// Parameters:
+//   - fn: Function that maps an input item to a sequence of output items
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that transforms items into sequences of items
+func FlatMapSeq[I, O any](
+	fn func(context.Context, I) iter.Seq[O],
+	opts ...core.FlowOption,
 ) *core.Flow[I, O] {
 	return core.NewFlow(
 		func(ctx context.Context, elem I, out chan<- core.Item[O]) core.StreamAction {
-			res := fn(ctx, elem)
-			items := make([]core.Item[O], len(res))
-			for i, item := range res {
-				items[i] = core.Item[O]{Value: item}
+			completing := core.Completing(ctx)
+			for item := range fn(ctx, elem) {
+				select {
+				case <-ctx.Done():
+					return core.ActionStop
+				case <-completing:
+					return core.ActionComplete
+				case out <- core.Item[O]{Value: item}:
+				}
 			}
-			util.SendMany(ctx, items, out)
 			return core.ActionProceed
 		},
 		nil,
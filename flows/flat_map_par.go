@@ -12,6 +12,9 @@ import (
 // function. Up to 'parallelism' items will be processed concurrently. The order of
 // output items is not guaranteed to match the input order.
 //
+// A panic in fn is emitted as a core.PanicError. If the flow is configured with
+// core.WithSandbox, fn runs in its sandbox, which further bounds the concurrency.
+//
 // Type Parameters:
 //   - I: The type of input items
 //   - O: The type of output items
@@ -38,7 +41,11 @@ func FlatMapPar[I, O any](
 					wg.Done()
 					<-sem // release the slot
 				}()
-				res := fn(ctx, elem)
+				var res []O
+				if err := core.RunSandboxed(ctx, func() { res = fn(ctx, elem) }); err != nil {
+					util.Send(ctx, core.Item[O]{Err: err}, out)
+					return
+				}
 				items := make([]core.Item[O], len(res))
 				for i, item := range res {
 					items[i] = core.Item[O]{Value: item}
@@ -52,5 +59,5 @@ func FlatMapPar[I, O any](
 		func(ctx context.Context, out chan<- core.Item[O]) {
 			wg.Wait() // wait for all goroutines to finish
 		},
-		opts...)
+		append([]core.FlowOption{core.WithFlowAttributes(core.Parallelism(parallelism))}, opts...)...)
 }
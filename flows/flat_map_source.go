@@ -0,0 +1,108 @@
+package flows
+
+import (
+	"context"
+	"sync"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// forwardSink creates a Sink that forwards all items and errors it receives to out.
+// It is used to run nested streams whose output is emitted by an enclosing flow.
+func forwardSink[O any](out chan<- core.Item[O]) *core.Sink[O, struct{}] {
+	return core.NewSink(
+		struct{}{},
+		func(ctx context.Context, elem O, acc core.Item[struct{}]) (core.Item[struct{}], core.StreamAction) {
+			util.Send(ctx, core.Item[O]{Value: elem}, out)
+			return acc, core.ActionProceed
+		},
+		func(ctx context.Context, err error, acc core.Item[struct{}]) (core.Item[struct{}], core.StreamAction) {
+			util.Send(ctx, core.Item[O]{Err: err}, out)
+			return acc, core.ActionProceed
+		},
+		nil,
+	)
+}
+
+// runForwarding runs source to completion, forwarding all its items and errors to out.
+func runForwarding[O any](ctx context.Context, source *core.Source[O], out chan<- core.Item[O]) {
+	stream := core.ConnectSourceToSink(source, forwardSink(out))
+	<-stream.Run(ctx)
+	stream.AwaitDone()
+}
+
+// FlatMapConcat creates a Flow that maps each input item to a Source and emits all items
+// of that Source before moving on to the next input item. Unlike FlatMap, the results of
+// an item are streamed rather than fully materialized, and the order of the output matches
+// the order of the input.
+//
+// Errors emitted by a sub-source are passed downstream.
+//
+// Type Parameters:
+//   - I: The type of input items
+//   - O: The type of output items
+//
+// Parameters:
+//   - fn: Function that maps an input item to a Source of output items
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that concatenates the sub-sources of all items
+func FlatMapConcat[I, O any](
+	fn func(context.Context, I) *core.Source[O],
+	opts ...core.FlowOption,
+) *core.Flow[I, O] {
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[O]) core.StreamAction {
+			runForwarding(ctx, fn(ctx, elem), out)
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		nil,
+		opts...)
+}
+
+// FlatMapMerge creates a Flow that maps each input item to a Source and runs up to
+// 'breadth' of these sub-sources concurrently, emitting their items as they arrive.
+// The order of output items is not guaranteed to match the input order.
+//
+// Errors emitted by a sub-source are passed downstream.
+//
+// Type Parameters:
+//   - I: The type of input items
+//   - O: The type of output items
+//
+// Parameters:
+//   - fn: Function that maps an input item to a Source of output items
+//   - breadth: Maximum number of sub-sources to run concurrently
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that merges the sub-sources of all items
+func FlatMapMerge[I, O any](
+	fn func(context.Context, I) *core.Source[O],
+	breadth int,
+	opts ...core.FlowOption,
+) *core.Flow[I, O] {
+	sem := make(chan struct{}, breadth)
+	wg := sync.WaitGroup{}
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[O]) core.StreamAction {
+			sem <- struct{}{} // wait for a slot
+			wg.Add(1)
+			go func() {
+				defer func() {
+					wg.Done()
+					<-sem // release the slot
+				}()
+				runForwarding(ctx, fn(ctx, elem), out)
+			}()
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[O]) {
+			wg.Wait() // wait for all sub-sources to finish
+		},
+		opts...)
+}
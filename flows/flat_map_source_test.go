@@ -0,0 +1,153 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+	"github.com/svenvdam/linea/test"
+)
+
+// testRangeSource creates a Source emitting n copies of i, or the given error if not nil.
+func testRangeSource(i, n int, err error) *core.Source[int] {
+	if err != nil {
+		return compose.SourceThroughFlow(
+			sources.Slice([]int{i}),
+			TryMap(func(ctx context.Context, i int) (int, error) { return 0, err }),
+		)
+	}
+	items := make([]int, n)
+	for j := range items {
+		items[j] = i
+	}
+	return sources.Slice(items)
+}
+
+func TestFlatMapConcat(t *testing.T) {
+	testErr := errors.New("test error")
+
+	tests := []struct {
+		name    string
+		input   []int
+		fn      func(context.Context, int) *core.Source[int]
+		want    []int
+		wantErr error
+	}{
+		{
+			name:  "concatenates sub-sources in order",
+			input: []int{1, 2, 3},
+			fn: func(ctx context.Context, i int) *core.Source[int] {
+				return testRangeSource(i, i, nil)
+			},
+			want: []int{1, 2, 2, 3, 3, 3},
+		},
+		{
+			name:  "handles empty sub-sources",
+			input: []int{0, 1, 0},
+			fn: func(ctx context.Context, i int) *core.Source[int] {
+				return testRangeSource(i, i, nil)
+			},
+			want: []int{1},
+		},
+		{
+			name:  "propagates sub-source errors",
+			input: []int{1, 2},
+			fn: func(ctx context.Context, i int) *core.Source[int] {
+				return testRangeSource(i, 1, testErr)
+			},
+			wantErr: testErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(tt.input),
+				FlatMapConcat(tt.fn),
+				sinks.Slice[int](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, res.Err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, res.Err)
+			assert.Equal(t, tt.want, res.Value)
+		})
+	}
+}
+
+func TestFlatMapMerge(t *testing.T) {
+	testErr := errors.New("test error")
+	maxBreadth := 2
+
+	tests := []struct {
+		name    string
+		input   []int
+		setup   func() func(context.Context, int) *core.Source[int]
+		want    []int
+		wantErr error
+	}{
+		{
+			name:  "merges sub-sources concurrently",
+			input: []int{1, 2, 3, 4},
+			setup: func() func(context.Context, int) *core.Source[int] {
+				parTracker := test.NewParallelTracker()
+				return func(ctx context.Context, i int) *core.Source[int] {
+					return compose.SourceThroughFlow(
+						testRangeSource(i, 2, nil),
+						Map(func(ctx context.Context, i int) int {
+							parallelism, cleanup := parTracker.Track()
+							defer cleanup()
+
+							assert.LessOrEqual(t, parallelism, maxBreadth)
+
+							time.Sleep(10 * time.Millisecond) // simulate work
+							return i
+						}),
+					)
+				}
+			},
+			want: []int{1, 1, 2, 2, 3, 3, 4, 4},
+		},
+		{
+			name:  "propagates sub-source errors",
+			input: []int{1, 2},
+			setup: func() func(context.Context, int) *core.Source[int] {
+				return func(ctx context.Context, i int) *core.Source[int] {
+					return testRangeSource(i, 1, testErr)
+				}
+			},
+			wantErr: testErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(tt.input),
+				FlatMapMerge(tt.setup(), maxBreadth),
+				sinks.Slice[int](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, res.Err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, res.Err)
+			assert.ElementsMatch(t, tt.want, res.Value)
+		})
+	}
+}
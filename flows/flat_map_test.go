@@ -2,6 +2,7 @@ package flows
 
 import (
 	"context"
+	"iter"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -78,3 +79,66 @@ func TestFlatMap(t *testing.T) {
 		})
 	}
 }
+
+func TestFlatMapSeq(t *testing.T) {
+	// repeat creates a sequence repeating s n times, or infinitely if n is negative
+	repeat := func(ctx context.Context, s string, n int) iter.Seq[string] {
+		return func(yield func(string) bool) {
+			for i := 0; n < 0 || i < n; i++ {
+				if !yield(s) {
+					return
+				}
+			}
+		}
+	}
+
+	tests := []struct {
+		name  string
+		input []string
+		n     int
+		take  int
+		want  []string
+	}{
+		{
+			name:  "expands items into sequences",
+			input: []string{"a", "b"},
+			n:     2,
+			want:  []string{"a", "a", "b", "b"},
+		},
+		{
+			name:  "handles empty sequences",
+			input: []string{"a", "b"},
+			n:     0,
+			want:  []string{},
+		},
+		{
+			name:  "consumes infinite sequences lazily",
+			input: []string{"a"},
+			n:     -1,
+			take:  3,
+			want:  []string{"a", "a", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow := FlatMapSeq(func(ctx context.Context, s string) iter.Seq[string] {
+				return repeat(ctx, s, tt.n)
+			})
+			if tt.take > 0 {
+				flow = compose.MergeFlows(flow, Take[string](tt.take))
+			}
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(tt.input),
+				flow,
+				sinks.Slice[string](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			assert.NoError(t, res.Err)
+			assert.Equal(t, tt.want, res.Value)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package flows
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+var (
+	// ErrFrameTooLong is emitted by Frame when a frame exceeds the maximum frame length.
+	ErrFrameTooLong = errors.New("frame too long")
+
+	// ErrTruncatedFrame is emitted by Frame when the stream ends within a length-prefixed frame.
+	ErrTruncatedFrame = errors.New("truncated frame")
+)
+
+// Framing determines how Frame splits a stream of bytes into frames. Create it with
+// Delimiter or LengthPrefix.
+type Framing struct {
+	// split returns the number of bytes to advance buf by and the frame at its start, or zero
+	// if buf does not hold a complete frame yet. atEOF is set once the stream has ended.
+	split func(buf []byte, atEOF bool, maxFrameLength int) (advance int, frame []byte, err error)
+}
+
+// Delimiter creates a Framing of frames terminated by delim, such as a newline. The delimiter
+// is not part of the frames. If the stream ends with bytes after the last delimiter, they are
+// emitted as a final frame.
+//
+// Parameters:
+//   - delim: The bytes terminating each frame
+//
+// Returns a Framing splitting frames on delim
+func Delimiter(delim []byte) Framing {
+	return Framing{
+		split: func(buf []byte, atEOF bool, maxFrameLength int) (int, []byte, error) {
+			if i := bytes.Index(buf, delim); i >= 0 {
+				if i > maxFrameLength {
+					return 0, nil, fmt.Errorf("%w: %d bytes exceed limit of %d", ErrFrameTooLong, i, maxFrameLength)
+				}
+				return i + len(delim), buf[:i], nil
+			}
+			// Unless the stream has ended, buf may end with part of the delimiter
+			partial := len(buf)
+			if !atEOF {
+				partial -= len(delim) - 1
+			}
+			if partial > maxFrameLength {
+				return 0, nil, fmt.Errorf("%w: exceeds limit of %d bytes", ErrFrameTooLong, maxFrameLength)
+			}
+			if atEOF && len(buf) > 0 {
+				return len(buf), buf, nil
+			}
+			return 0, nil, nil
+		},
+	}
+}
+
+// LengthPrefix creates a Framing of frames preceded by their length, encoded as an unsigned
+// integer of size bytes in the given byte order. The prefix is not part of the frames.
+//
+// Parameters:
+//   - size: The size of the length prefix in bytes, which is 1, 2, 4 or 8
+//   - order: The byte order of the length prefix, such as binary.BigEndian
+//
+// Returns a Framing splitting length-prefixed frames
+func LengthPrefix(size int, order binary.ByteOrder) Framing {
+	return Framing{
+		split: func(buf []byte, atEOF bool, maxFrameLength int) (int, []byte, error) {
+			if len(buf) < size {
+				if atEOF && len(buf) > 0 {
+					return 0, nil, fmt.Errorf("%w: %d bytes of length prefix", ErrTruncatedFrame, len(buf))
+				}
+				return 0, nil, nil
+			}
+
+			var length uint64
+			switch size {
+			case 1:
+				length = uint64(buf[0])
+			case 2:
+				length = uint64(order.Uint16(buf))
+			case 4:
+				length = uint64(order.Uint32(buf))
+			default:
+				length = order.Uint64(buf)
+			}
+			if length > uint64(maxFrameLength) {
+				return 0, nil, fmt.Errorf("%w: %d bytes exceed limit of %d", ErrFrameTooLong, length, maxFrameLength)
+			}
+
+			end := size + int(length)
+			if len(buf) < end {
+				if atEOF {
+					return 0, nil, fmt.Errorf("%w: %d of %d bytes", ErrTruncatedFrame, len(buf)-size, length)
+				}
+				return 0, nil, nil
+			}
+			return end, buf[size:end], nil
+		},
+	}
+}
+
+// Frame creates a Flow that converts a stream of byte chunks, such as read from a file or a
+// socket, into discrete frames using the given Framing. Frames may be split across any number
+// of chunks, and a chunk may hold any number of frames.
+//
+// If a frame exceeds maxFrameLength bytes, ErrFrameTooLong is emitted and the flow stops, so
+// that malformed input cannot make it buffer without bound. If the stream ends within a
+// length-prefixed frame, ErrTruncatedFrame is emitted. Emitted frames are newly allocated, so
+// the incoming chunks may be reused by upstream.
+//
+// Parameters:
+//   - framing: How the bytes are split into frames, see Delimiter and LengthPrefix
+//   - maxFrameLength: The maximum length of a frame in bytes
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that splits byte chunks into frames
+func Frame(
+	framing Framing,
+	maxFrameLength int,
+	opts ...core.FlowOption,
+) *core.Flow[[]byte, []byte] {
+	var buf []byte
+
+	// emitFrames emits all complete frames in buf, returning false if the flow must stop
+	emitFrames := func(ctx context.Context, atEOF bool, out chan<- core.Item[[]byte]) bool {
+		for {
+			advance, frame, err := framing.split(buf, atEOF, maxFrameLength)
+			if err != nil {
+				buf = nil
+				util.Send(ctx, core.Item[[]byte]{Err: err}, out)
+				return false
+			}
+			if advance == 0 {
+				return true
+			}
+			buf = buf[advance:]
+			util.Send(ctx, core.Item[[]byte]{Value: bytes.Clone(frame)}, out)
+		}
+	}
+
+	return core.NewFlow(
+		func(ctx context.Context, elem []byte, out chan<- core.Item[[]byte]) core.StreamAction {
+			buf = append(buf, elem...)
+			if !emitFrames(ctx, false, out) {
+				return core.ActionStop
+			}
+			return core.ActionProceed
+		},
+		nil,
+		func(ctx context.Context, out chan<- core.Item[[]byte]) core.StreamAction {
+			emitFrames(ctx, true, out)
+			return core.ActionStop
+		},
+		func(ctx context.Context, out chan<- core.Item[[]byte]) {
+			buf = nil
+		},
+		opts...,
+	)
+}
@@ -0,0 +1,113 @@
+package flows
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestFrame(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          []string
+		framing        Framing
+		maxFrameLength int
+		expected       []string
+		expectedErr    error
+	}{
+		{
+			name:           "splits lines across chunks",
+			input:          []string{"ab", "c\nd", "e\nf\n"},
+			framing:        Delimiter([]byte("\n")),
+			maxFrameLength: 10,
+			expected:       []string{"abc", "de", "f"},
+		},
+		{
+			name:           "handles delimiters split across chunks",
+			input:          []string{"ab\r", "\ncd\r\n"},
+			framing:        Delimiter([]byte("\r\n")),
+			maxFrameLength: 10,
+			expected:       []string{"ab", "cd"},
+		},
+		{
+			name:           "emits final unterminated frame",
+			input:          []string{"a\nbc"},
+			framing:        Delimiter([]byte("\n")),
+			maxFrameLength: 10,
+			expected:       []string{"a", "bc"},
+		},
+		{
+			name:           "emits empty frames",
+			input:          []string{"a\n\nb\n"},
+			framing:        Delimiter([]byte("\n")),
+			maxFrameLength: 10,
+			expected:       []string{"a", "", "b"},
+		},
+		{
+			name:           "fails on delimited frames exceeding the limit",
+			input:          []string{"ab\n", "cdef"},
+			framing:        Delimiter([]byte("\n")),
+			maxFrameLength: 3,
+			expectedErr:    ErrFrameTooLong,
+		},
+		{
+			name:           "splits length-prefixed frames across chunks",
+			input:          []string{"\x00", "\x02ab\x00\x01", "c\x00\x00"},
+			framing:        LengthPrefix(2, binary.BigEndian),
+			maxFrameLength: 10,
+			expected:       []string{"ab", "c", ""},
+		},
+		{
+			name:           "reads little endian prefixes",
+			input:          []string{"\x03\x00\x00\x00abc"},
+			framing:        LengthPrefix(4, binary.LittleEndian),
+			maxFrameLength: 10,
+			expected:       []string{"abc"},
+		},
+		{
+			name:           "fails on length-prefixed frames exceeding the limit",
+			input:          []string{"\x05abcde"},
+			framing:        LengthPrefix(1, binary.BigEndian),
+			maxFrameLength: 3,
+			expectedErr:    ErrFrameTooLong,
+		},
+		{
+			name:           "fails on truncated frames",
+			input:          []string{"\x03ab"},
+			framing:        LengthPrefix(1, binary.BigEndian),
+			maxFrameLength: 10,
+			expectedErr:    ErrTruncatedFrame,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := make([][]byte, len(tt.input))
+			for i, s := range tt.input {
+				input[i] = []byte(s)
+			}
+			stream := compose.SourceThroughFlowToSink2(
+				sources.Slice(input),
+				Frame(tt.framing, tt.maxFrameLength),
+				Map(func(ctx context.Context, b []byte) string { return string(b) }),
+				sinks.Slice[string](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// ErrTooManySubstreams is emitted by GroupBy when an element has a new key while the
+// maximum number of substreams is already open.
+var ErrTooManySubstreams = errors.New("too many substreams")
+
+// substream is a running substream of GroupBy.
+type substream[I, O any] struct {
+	in     chan core.Item[I]
+	done   chan struct{}
+	stream *core.Stream[struct{}]
+	res    <-chan core.Item[struct{}]
+}
+
+// GroupBy creates a Flow that demultiplexes items into substreams by key. Each substream is
+// processed by its own Flow, created by calling newFlow with the key of the substream, and
+// the outputs of all substreams are merged back into a single stream.
+//
+// Items with the same key are processed in order by the same substream, while substreams
+// for different keys run concurrently. This allows preserving ordering per key, such as per
+// user, while parallelizing across keys. Substreams stay open until the upstream completes.
+// Each substream buffers as many items as the buffer size of the GroupBy flow, so a slow
+// substream only blocks the flow once its buffer is full.
+//
+// If an item has a new key while maxSubstreams substreams are open, the flow emits
+// ErrTooManySubstreams and stops. Errors emitted by a substream are passed downstream.
+// If a substream stops early, for example because its flow failed, further items with
+// its key are dropped and reported with DropReasonSubstreamTerminated.
+//
+// Type Parameters:
+//   - I: The type of input items
+//   - K: The type of the keys
+//   - O: The type of output items
+//
+// Parameters:
+//   - maxSubstreams: Maximum number of substreams that can be open at the same time
+//   - keyFn: Function that returns the key of an item
+//   - newFlow: Function that creates the Flow processing the substream of a key
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that processes items in per-key substreams
+func GroupBy[I any, K comparable, O any](
+	maxSubstreams int,
+	keyFn func(I) K,
+	newFlow func(key K) *core.Flow[I, O],
+	opts ...core.FlowOption,
+) *core.Flow[I, O] {
+	substreams := make(map[K]*substream[I, O])
+
+	open := func(ctx context.Context, key K, out chan<- core.Item[O]) *substream[I, O] {
+		bufSize, _ := core.GetAttribute(core.AttributesFromContext(ctx), core.BufSizeKey)
+		in := make(chan core.Item[I], bufSize)
+
+		source := core.NewSource(
+			func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan core.Item[I] {
+				return in
+			},
+		)
+
+		done := make(chan struct{})
+		stream := core.ConnectSourceToSink(core.AppendFlowToSource(source, newFlow(key)), forwardSink(out))
+		stream.OnTermination(func(error) {
+			close(done)
+		})
+		return &substream[I, O]{
+			in:     in,
+			done:   done,
+			stream: stream,
+			res:    stream.Run(ctx),
+		}
+	}
+
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[O]) core.StreamAction {
+			key := keyFn(elem)
+			sub, ok := substreams[key]
+			if !ok {
+				if len(substreams) >= maxSubstreams {
+					util.Send(ctx, core.Item[O]{
+						Err: fmt.Errorf("%w: limit of %d reached", ErrTooManySubstreams, maxSubstreams),
+					}, out)
+					return core.ActionStop
+				}
+				sub = open(ctx, key, out)
+				substreams[key] = sub
+			}
+
+			select {
+			case <-ctx.Done():
+			case <-sub.done:
+				// The substream stopped early, for example because its flow failed
+				core.ReportDrop(ctx, "GroupBy", core.DropReasonSubstreamTerminated, elem)
+			case sub.in <- core.Item[I]{Value: elem}:
+			}
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[O]) {
+			// Complete all substreams and wait for them to emit their remaining items
+			for _, sub := range substreams {
+				close(sub.in)
+			}
+			for _, sub := range substreams {
+				<-sub.res
+				sub.stream.AwaitDone()
+			}
+			substreams = make(map[K]*substream[I, O])
+		},
+		opts...,
+	)
+}
@@ -0,0 +1,132 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestGroupBy(t *testing.T) {
+	testErr := errors.New("test error")
+
+	tests := []struct {
+		name          string
+		input         []int
+		maxSubstreams int
+		newFlow       func(key int) *core.Flow[int, string]
+		want          map[string][]string
+		wantErr       error
+	}{
+		{
+			name:          "processes each key in its own substream",
+			input:         []int{1, 2, 3, 4, 5, 6, 7},
+			maxSubstreams: 2,
+			newFlow: func(key int) *core.Flow[int, string] {
+				return Map(func(ctx context.Context, i int) string {
+					return fmt.Sprintf("%d:%d", key, i)
+				})
+			},
+			want: map[string][]string{
+				"0": {"0:2", "0:4", "0:6"},
+				"1": {"1:1", "1:3", "1:5", "1:7"},
+			},
+		},
+		{
+			name:          "creates a new flow per substream",
+			input:         []int{1, 2, 3, 4, 5, 6, 7},
+			maxSubstreams: 2,
+			newFlow: func(key int) *core.Flow[int, string] {
+				return compose.MergeFlows(
+					Batch[int](2),
+					Map(func(ctx context.Context, batch []int) string {
+						return fmt.Sprintf("%d:%v", key, batch)
+					}),
+				)
+			},
+			want: map[string][]string{
+				"0": {"0:[2 4]", "0:[6]"},
+				"1": {"1:[1 3]", "1:[5 7]"},
+			},
+		},
+		{
+			name:          "fails when exceeding the maximum number of substreams",
+			input:         []int{1, 2, 3},
+			maxSubstreams: 1,
+			newFlow: func(key int) *core.Flow[int, string] {
+				return Map(func(ctx context.Context, i int) string {
+					return fmt.Sprintf("%d:%d", key, i)
+				})
+			},
+			wantErr: ErrTooManySubstreams,
+		},
+		{
+			name:          "propagates substream errors",
+			input:         []int{1, 2, 3},
+			maxSubstreams: 2,
+			newFlow: func(key int) *core.Flow[int, string] {
+				return TryMap(func(ctx context.Context, i int) (string, error) {
+					if i == 2 {
+						return "", testErr
+					}
+					return fmt.Sprintf("%d:%d", key, i), nil
+				})
+			},
+			wantErr: testErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(tt.input),
+				GroupBy(tt.maxSubstreams, func(i int) int { return i % 2 }, tt.newFlow),
+				sinks.Slice[string](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, res.Err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, res.Err)
+
+			// Substreams are interleaved nondeterministically, so only compare per key
+			got := make(map[string][]string)
+			for _, v := range res.Value {
+				key, _, _ := strings.Cut(v, ":")
+				got[key] = append(got[key], v)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGroupByReportsDropsForTerminatedSubstreams(t *testing.T) {
+	counter := core.NewDropCounter()
+	ctx := core.WithDropHandler(context.Background(), counter.Handle)
+
+	stream := compose.SourceThroughFlowToSink(
+		sources.Slice([]int{1, 2, 3, 4, 5}),
+		GroupBy(1, func(i int) string { return "all" }, func(string) *core.Flow[int, int] {
+			return TakeWhile(func(i int) bool { return i < 2 })
+		}),
+		sinks.Slice[int](),
+	)
+
+	res := <-stream.Run(ctx)
+	stream.AwaitDone()
+
+	assert.NoError(t, res.Err)
+	assert.Equal(t, []int{1}, res.Value)
+	assert.Positive(t, counter.Count("GroupBy", core.DropReasonSubstreamTerminated))
+}
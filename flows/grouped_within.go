@@ -0,0 +1,121 @@
+package flows
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// GroupedWithin creates a Flow that groups items into batches, emitting a batch once it holds
+// maxItems items or maxDuration has passed since its first item was received, whichever comes
+// first. This is the shape of most batch APIs, such as SQS SendMessageBatch or DynamoDB
+// BatchWriteItem, which limit the size of a batch while items should not wait for long.
+//
+// Empty batches are never emitted. When upstream completes or the stream is drained, the
+// current batch is emitted before the flow completes.
+//
+// Type Parameters:
+//   - I: The type of items to batch
+//
+// Parameters:
+//   - maxItems: The maximum number of items in a batch
+//   - maxDuration: The maximum time the first item of a batch waits for the batch to be emitted
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that transforms individual items into slices of items
+func GroupedWithin[I any](
+	maxItems int,
+	maxDuration time.Duration,
+	opts ...core.FlowOption,
+) *core.Flow[I, []I] {
+	maxItems = max(maxItems, 1)
+
+	// mu guards the batch and serializes emitting batches, so they keep their order
+	var mu sync.Mutex
+	var batch []I
+	// gen identifies the current batch, so a timer does not emit a later batch
+	var gen int
+	// starts passes the generation of every started batch to the goroutine emitting batches
+	// on time, which is started with the first item of a run
+	var starts chan int
+	var stop, stopped chan struct{}
+
+	// emit sends the current batch downstream. It must be called with mu held.
+	emit := func(ctx context.Context, out chan<- core.Item[[]I]) {
+		if len(batch) > 0 {
+			util.Send(ctx, core.Item[[]I]{Value: batch}, out)
+			batch = nil
+		}
+	}
+
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[[]I]) core.StreamAction {
+			if stop == nil {
+				starts, stop, stopped = make(chan int), make(chan struct{}), make(chan struct{})
+				go func() {
+					defer close(stopped)
+					timer := time.NewTimer(maxDuration)
+					timer.Stop()
+					defer timer.Stop()
+					var expiring <-chan time.Time
+					current := 0
+					for {
+						select {
+						case <-ctx.Done():
+							return
+						case <-stop:
+							return
+						case current = <-starts:
+							timer.Reset(maxDuration)
+							expiring = timer.C
+						case <-expiring:
+							expiring = nil
+							mu.Lock()
+							if gen == current {
+								emit(ctx, out)
+							}
+							mu.Unlock()
+						}
+					}
+				}()
+			}
+
+			mu.Lock()
+			batch = append(batch, elem)
+			started := len(batch) == 1
+			if started {
+				gen++
+			}
+			current := gen
+			if len(batch) >= maxItems {
+				emit(ctx, out)
+				started = false
+			}
+			mu.Unlock()
+
+			if started {
+				select {
+				case <-ctx.Done():
+				case starts <- current:
+				}
+			}
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[[]I]) {
+			if stop != nil {
+				close(stop)
+				<-stopped
+				starts, stop, stopped = nil, nil, nil
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			emit(ctx, out)
+			gen = 0
+		},
+		opts...)
+}
@@ -0,0 +1,98 @@
+package flows
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestGroupedWithin(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxItems int
+		bursts   [][]int
+		expected [][]int
+	}{
+		{
+			name:     "emits full batches immediately",
+			maxItems: 2,
+			bursts:   [][]int{{1, 2, 3, 4, 5}},
+			expected: [][]int{{1, 2}, {3, 4}, {5}},
+		},
+		{
+			name:     "emits partial batches after max duration",
+			maxItems: 10,
+			bursts:   [][]int{{1, 2}, {3}, {4, 5}},
+			expected: [][]int{{1, 2}, {3}, {4, 5}},
+		},
+		{
+			name:     "emits by size and by time",
+			maxItems: 2,
+			bursts:   [][]int{{1, 2, 3}, {4, 5, 6}},
+			expected: [][]int{{1, 2}, {3}, {4, 5}, {6}},
+		},
+		{
+			name:     "emits nothing without items",
+			maxItems: 2,
+			bursts:   [][]int{{}},
+			expected: [][]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan int)
+			stream := compose.SourceThroughFlowToSink(
+				sources.Chan(ch),
+				GroupedWithin[int](tt.maxItems, 40*time.Millisecond),
+				sinks.Slice[[]int](),
+			)
+			res := stream.Run(context.Background())
+
+			for i, burst := range tt.bursts {
+				for _, elem := range burst {
+					ch <- elem
+				}
+				if i < len(tt.bursts)-1 {
+					time.Sleep(80 * time.Millisecond)
+				}
+			}
+			close(ch)
+
+			result := <-res
+			stream.AwaitDone()
+
+			require.NoError(t, result.Err)
+			assert.Equal(t, tt.expected, result.Value)
+		})
+	}
+}
+
+func TestGroupedWithinBoundsWait(t *testing.T) {
+	ch := make(chan int)
+	var emitted []time.Time
+	stream := compose.SourceThroughFlowToSink(
+		sources.Chan(ch),
+		GroupedWithin[int](100, 30*time.Millisecond),
+		sinks.ForEach(func(ctx context.Context, batch []int) {
+			emitted = append(emitted, time.Now())
+		}),
+	)
+	res := stream.Run(context.Background())
+
+	start := time.Now()
+	ch <- 1
+	time.Sleep(100 * time.Millisecond)
+	close(ch)
+
+	require.NoError(t, (<-res).Err)
+	stream.AwaitDone()
+	require.Len(t, emitted, 1)
+	assert.InDelta(t, 30*time.Millisecond, emitted[0].Sub(start), float64(20*time.Millisecond))
+}
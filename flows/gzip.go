@@ -0,0 +1,158 @@
+package flows
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// gzipChunkSize is the maximum size of the chunks emitted by GzipDecompress.
+const gzipChunkSize = 32 * 1024
+
+// GzipCompress creates a Flow that compresses a stream of byte chunks into a single gzip
+// stream. Chunks are compressed as they arrive and compressed bytes are emitted as soon as
+// the compressor produces them, so the stream is never held in memory as a whole. The end
+// of the gzip stream is emitted once upstream completes. The emitted chunks do not correspond
+// to the incoming chunks, and only their concatenation is a valid gzip stream.
+//
+// If level is invalid, an error is emitted and the flow stops.
+//
+// Parameters:
+//   - level: The compression level, such as gzip.DefaultCompression or gzip.BestSpeed
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that compresses bytes with gzip
+func GzipCompress(
+	level int,
+	opts ...core.FlowOption,
+) *core.Flow[[]byte, []byte] {
+	var buf bytes.Buffer
+	var w *gzip.Writer
+
+	// emitCompressed emits the compressed bytes produced so far
+	emitCompressed := func(ctx context.Context, out chan<- core.Item[[]byte]) {
+		if buf.Len() > 0 {
+			util.Send(ctx, core.Item[[]byte]{Value: bytes.Clone(buf.Bytes())}, out)
+			buf.Reset()
+		}
+	}
+
+	return core.NewFlow(
+		func(ctx context.Context, elem []byte, out chan<- core.Item[[]byte]) core.StreamAction {
+			if w == nil {
+				var err error
+				if w, err = gzip.NewWriterLevel(&buf, level); err != nil {
+					util.Send(ctx, core.Item[[]byte]{Err: fmt.Errorf("compressing gzip: %w", err)}, out)
+					return core.ActionStop
+				}
+			}
+			// Writing to a bytes.Buffer cannot fail
+			_, _ = w.Write(elem)
+			emitCompressed(ctx, out)
+			return core.ActionProceed
+		},
+		nil,
+		func(ctx context.Context, out chan<- core.Item[[]byte]) core.StreamAction {
+			if w == nil {
+				// An empty stream still compresses into a valid gzip stream
+				w, _ = gzip.NewWriterLevel(&buf, level)
+			}
+			if w != nil {
+				_ = w.Close()
+				emitCompressed(ctx, out)
+			}
+			return core.ActionStop
+		},
+		func(ctx context.Context, out chan<- core.Item[[]byte]) {
+			w = nil
+			buf.Reset()
+		},
+		opts...,
+	)
+}
+
+// GzipDecompress creates a Flow that decompresses a gzip stream split across byte chunks, such
+// as read from a file. Chunks are decompressed as they arrive and decompressed bytes are
+// emitted in chunks of up to 32 KiB as soon as they are available, so the stream is never held
+// in memory as a whole. Concatenated gzip streams are decompressed as one.
+//
+// If the bytes are not a valid gzip stream, or upstream completes before the end of the gzip
+// stream, an error is emitted and the flow stops.
+//
+// Parameters:
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that decompresses gzip compressed bytes
+func GzipDecompress(
+	opts ...core.FlowOption,
+) *core.Flow[[]byte, []byte] {
+	var pw *io.PipeWriter
+	var done chan struct{}
+
+	// decompress reads the gzip stream written to pw, emitting the decompressed bytes
+	decompress := func(ctx context.Context, pr *io.PipeReader, out chan<- core.Item[[]byte]) {
+		defer close(done)
+		err := func() error {
+			r, err := gzip.NewReader(pr)
+			if err != nil {
+				return err
+			}
+			chunk := make([]byte, gzipChunkSize)
+			for {
+				n, err := r.Read(chunk)
+				if n > 0 {
+					util.Send(ctx, core.Item[[]byte]{Value: bytes.Clone(chunk[:n])}, out)
+				}
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}()
+		if err == nil {
+			_ = pr.Close()
+			return
+		}
+		if errors.Is(err, io.EOF) {
+			// Upstream completed before the gzip header
+			err = io.ErrUnexpectedEOF
+		}
+		util.Send(ctx, core.Item[[]byte]{Err: fmt.Errorf("decompressing gzip: %w", err)}, out)
+		// Writes fail from now on, making the flow stop
+		_ = pr.CloseWithError(err)
+	}
+
+	return core.NewFlow(
+		func(ctx context.Context, elem []byte, out chan<- core.Item[[]byte]) core.StreamAction {
+			if pw == nil {
+				var pr *io.PipeReader
+				pr, pw = io.Pipe()
+				done = make(chan struct{})
+				go decompress(ctx, pr, out)
+			}
+			if _, err := pw.Write(elem); err != nil {
+				// The error was emitted by decompress
+				return core.ActionStop
+			}
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[[]byte]) {
+			if pw != nil {
+				_ = pw.Close()
+				<-done
+				pw = nil
+			}
+		},
+		opts...,
+	)
+}
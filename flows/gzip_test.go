@@ -0,0 +1,186 @@
+package flows
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+// testGzip compresses data with gzip.
+func testGzip(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// testSplit splits data into chunks of the given size.
+func testSplit(data []byte, size int) [][]byte {
+	var chunks [][]byte
+	for len(data) > size {
+		chunks = append(chunks, data[:size])
+		data = data[size:]
+	}
+	return append(chunks, data)
+}
+
+func TestGzipCompress(t *testing.T) {
+	large := strings.Repeat("linea streams bytes ", 10000)
+
+	tests := []struct {
+		name        string
+		input       []string
+		level       int
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "compresses chunks into a single stream",
+			input:    []string{"hello ", "gzip ", "world"},
+			level:    gzip.DefaultCompression,
+			expected: "hello gzip world",
+		},
+		{
+			name:     "compresses large streams",
+			input:    []string{large, large},
+			level:    gzip.BestSpeed,
+			expected: large + large,
+		},
+		{
+			name:     "compresses empty streams",
+			input:    []string{},
+			level:    gzip.DefaultCompression,
+			expected: "",
+		},
+		{
+			name:        "fails on invalid levels",
+			input:       []string{"hello"},
+			level:       42,
+			expectedErr: "compressing gzip: gzip: invalid compression level: 42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := make([][]byte, len(tt.input))
+			for i, s := range tt.input {
+				input[i] = []byte(s)
+			}
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(input),
+				GzipCompress(tt.level),
+				sinks.Slice[[]byte](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, res.Err)
+
+			r, err := gzip.NewReader(bytes.NewReader(bytes.Join(res.Value, nil)))
+			require.NoError(t, err)
+			decompressed, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(decompressed))
+		})
+	}
+}
+
+func TestGzipDecompress(t *testing.T) {
+	large := strings.Repeat("linea streams bytes ", 10000)
+
+	tests := []struct {
+		name        string
+		input       func(t *testing.T) [][]byte
+		expected    string
+		expectedErr string
+	}{
+		{
+			name: "decompresses streams split across chunks",
+			input: func(t *testing.T) [][]byte {
+				return testSplit(testGzip(t, "hello gzip world"), 3)
+			},
+			expected: "hello gzip world",
+		},
+		{
+			name: "decompresses large streams",
+			input: func(t *testing.T) [][]byte {
+				return testSplit(testGzip(t, large), 1000)
+			},
+			expected: large,
+		},
+		{
+			name: "decompresses concatenated streams",
+			input: func(t *testing.T) [][]byte {
+				return [][]byte{testGzip(t, "hello "), testGzip(t, "world")}
+			},
+			expected: "hello world",
+		},
+		{
+			name: "fails on invalid data",
+			input: func(t *testing.T) [][]byte {
+				return [][]byte{[]byte("not gzip at all")}
+			},
+			expectedErr: "decompressing gzip: gzip: invalid header",
+		},
+		{
+			name: "fails on truncated streams",
+			input: func(t *testing.T) [][]byte {
+				data := testGzip(t, "hello gzip world")
+				return [][]byte{data[:len(data)-4]}
+			},
+			expectedErr: "decompressing gzip: unexpected EOF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(tt.input(t)),
+				GzipDecompress(),
+				sinks.Slice[[]byte](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, string(bytes.Join(res.Value, nil)))
+		})
+	}
+}
+
+func TestGzipRoundTrip(t *testing.T) {
+	input := testSplit([]byte(strings.Repeat("round trip ", 5000)), 777)
+	stream := compose.SourceThroughFlowToSink2(
+		sources.Slice(input),
+		GzipCompress(gzip.BestCompression),
+		GzipDecompress(),
+		sinks.Slice[[]byte](),
+	)
+
+	res := <-stream.Run(context.Background())
+	stream.AwaitDone()
+
+	require.NoError(t, res.Err)
+	assert.Equal(t, bytes.Join(input, nil), bytes.Join(res.Value, nil))
+}
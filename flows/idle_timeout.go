@@ -0,0 +1,98 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// ErrIdleTimeout is emitted by IdleTimeout when no item passed through it for its timeout.
+var ErrIdleTimeout = errors.New("idle timeout")
+
+// IdleTimeout creates a Flow that fails the stream if no item passes through it for the given
+// duration, measured from the start of the run and from every item after that. This detects
+// upstreams that got stuck silently, such as consumers of a queue that stopped receiving
+// messages. Items pass through unchanged.
+//
+// Once the timeout elapses, err is emitted, or an error wrapping ErrIdleTimeout if err is nil,
+// and the flow stops. Following the standard error path, this fails the stream unless the
+// error is handled downstream.
+//
+// Type Parameters:
+//   - I: The type of items
+//
+// Parameters:
+//   - timeout: The maximum time between two items
+//   - err: The error to emit once the timeout elapses, or nil for ErrIdleTimeout
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that fails if no item passes through it in time
+func IdleTimeout[I any](
+	timeout time.Duration,
+	err error,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	if err == nil {
+		err = fmt.Errorf("%w: no item for %s", ErrIdleTimeout, timeout)
+	}
+
+	var timedOut atomic.Bool
+	// seen signals an item to the goroutine watching the timeout, which is controlled by stop
+	// and stopped
+	var seen, stop, stopped chan struct{}
+
+	watch := func(ctx context.Context, out chan<- core.Item[I]) {
+		seen = make(chan struct{}, 1)
+		stop, stopped = make(chan struct{}), make(chan struct{})
+		go func() {
+			defer close(stopped)
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-stop:
+					return
+				case <-seen:
+					timer.Reset(timeout)
+				case <-timer.C:
+					timedOut.Store(true)
+					select {
+					case <-stop:
+					case <-ctx.Done():
+					case out <- core.Item[I]{Err: err}:
+					}
+					return
+				}
+			}
+		}()
+	}
+
+	return core.NewFlowWithStart(
+		watch,
+		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			if timedOut.Load() {
+				return core.ActionStop
+			}
+			select {
+			case seen <- struct{}{}:
+			default: // the goroutine has yet to take the previous signal
+			}
+			util.Send(ctx, core.Item[I]{Value: elem}, out)
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[I]) {
+			close(stop)
+			<-stopped
+			timedOut.Store(false)
+		},
+		opts...)
+}
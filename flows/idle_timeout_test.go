@@ -0,0 +1,87 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestIdleTimeout(t *testing.T) {
+	errStuck := errors.New("stuck")
+
+	tests := []struct {
+		name        string
+		delays      []time.Duration
+		err         error
+		expected    []int
+		expectedErr error
+	}{
+		{
+			name:     "passes items arriving in time",
+			delays:   []time.Duration{0, 10 * time.Millisecond, 10 * time.Millisecond},
+			expected: []int{0, 1, 2},
+		},
+		{
+			name:        "fails if no item arrives after starting",
+			delays:      []time.Duration{200 * time.Millisecond},
+			expectedErr: ErrIdleTimeout,
+		},
+		{
+			name:        "fails if items stop arriving",
+			delays:      []time.Duration{0, 0, 200 * time.Millisecond},
+			expectedErr: ErrIdleTimeout,
+		},
+		{
+			name:        "emits the given error",
+			delays:      []time.Duration{200 * time.Millisecond},
+			err:         errStuck,
+			expectedErr: errStuck,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan int)
+			done := make(chan struct{})
+			go func() {
+				defer close(ch)
+				for i, delay := range tt.delays {
+					select {
+					case <-done:
+						return
+					case <-time.After(delay):
+					}
+					select {
+					case <-done:
+						return
+					case ch <- i:
+					}
+				}
+			}()
+
+			stream := compose.SourceThroughFlowToSink(
+				sources.Chan(ch),
+				IdleTimeout[int](50*time.Millisecond, tt.err),
+				sinks.Slice[int](),
+			)
+
+			res := <-stream.Run(context.Background())
+			close(done)
+			stream.AwaitDone()
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+		})
+	}
+}
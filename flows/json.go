@@ -0,0 +1,100 @@
+package flows
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// JSONDecodeConfig configures how JSONDecode decodes items.
+type JSONDecodeConfig struct {
+	// DisallowUnknownFields makes decoding fail if an object has a field that does not
+	// match a field of the target type
+	// If not specified, unknown fields are ignored
+	DisallowUnknownFields bool
+}
+
+// JSONDecode creates a Flow that decodes each item from JSON into a value of type T. If an
+// item cannot be decoded, the error is emitted in place of the value, following the standard
+// error path like TryMap. Items of type json.RawMessage can be decoded after converting them
+// to []byte.
+//
+// Type Parameters:
+//   - T: The type items are decoded into
+//
+// Parameters:
+//   - config: Configuration of the decoding
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that decodes JSON items
+func JSONDecode[T any](
+	config JSONDecodeConfig,
+	opts ...core.FlowOption,
+) *core.Flow[[]byte, T] {
+	return TryMap(func(ctx context.Context, data []byte) (T, error) {
+		var value T
+		if err := decodeJSON(data, &value, config); err != nil {
+			return value, fmt.Errorf("decoding JSON: %w", err)
+		}
+		return value, nil
+	}, opts...)
+}
+
+// decodeJSON decodes data into value according to config.
+func decodeJSON(data []byte, value any, config JSONDecodeConfig) error {
+	if !config.DisallowUnknownFields {
+		return json.Unmarshal(data, value)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(value); err != nil {
+		return err
+	}
+	// Like json.Unmarshal, reject data following the value
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("invalid data after top-level value")
+	}
+	return nil
+}
+
+// JSONEncodeConfig configures how JSONEncode encodes items.
+type JSONEncodeConfig struct {
+	// Newline appends a newline to every encoded item, producing JSON Lines when the
+	// items are written one after another, for example to a file
+	// If not specified, items are encoded without a trailing newline
+	Newline bool
+}
+
+// JSONEncode creates a Flow that encodes each item as JSON. If an item cannot be encoded,
+// the error is emitted in place of the encoded item, following the standard error path like
+// TryMap.
+//
+// Type Parameters:
+//   - T: The type of the items to encode
+//
+// Parameters:
+//   - config: Configuration of the encoding
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that encodes items as JSON
+func JSONEncode[T any](
+	config JSONEncodeConfig,
+	opts ...core.FlowOption,
+) *core.Flow[T, []byte] {
+	return TryMap(func(ctx context.Context, value T) ([]byte, error) {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("encoding JSON: %w", err)
+		}
+		if config.Newline {
+			data = append(data, '\n')
+		}
+		return data, nil
+	}, opts...)
+}
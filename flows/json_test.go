@@ -0,0 +1,128 @@
+package flows
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+// testEvent is the type JSON items are decoded into in tests.
+type testEvent struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestJSONDecode(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       []string
+		config      JSONDecodeConfig
+		expected    []testEvent
+		expectedErr string
+	}{
+		{
+			name:     "decodes items",
+			input:    []string{`{"id":1,"name":"a"}`, `{"id":2}`},
+			expected: []testEvent{{ID: 1, Name: "a"}, {ID: 2}},
+		},
+		{
+			name:     "ignores unknown fields",
+			input:    []string{`{"id":1,"extra":true}`},
+			expected: []testEvent{{ID: 1}},
+		},
+		{
+			name:        "fails on unknown fields if disallowed",
+			input:       []string{`{"id":1,"extra":true}`},
+			config:      JSONDecodeConfig{DisallowUnknownFields: true},
+			expectedErr: `decoding JSON: json: unknown field "extra"`,
+		},
+		{
+			name:        "fails on trailing data if unknown fields are disallowed",
+			input:       []string{`{"id":1} {"id":2}`},
+			config:      JSONDecodeConfig{DisallowUnknownFields: true},
+			expectedErr: "decoding JSON: invalid data after top-level value",
+		},
+		{
+			name:        "fails on invalid JSON",
+			input:       []string{`{"id":`},
+			expectedErr: "decoding JSON: unexpected end of JSON input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := make([][]byte, len(tt.input))
+			for i, s := range tt.input {
+				input[i] = []byte(s)
+			}
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(input),
+				JSONDecode[testEvent](tt.config),
+				sinks.Slice[testEvent](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+		})
+	}
+}
+
+func TestJSONEncode(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       []any
+		config      JSONEncodeConfig
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name:     "encodes items",
+			input:    []any{testEvent{ID: 1, Name: "a"}, testEvent{ID: 2}},
+			expected: []string{`{"id":1,"name":"a"}`, `{"id":2,"name":""}`},
+		},
+		{
+			name:     "appends newlines",
+			input:    []any{testEvent{ID: 1}, 2},
+			config:   JSONEncodeConfig{Newline: true},
+			expected: []string{"{\"id\":1,\"name\":\"\"}\n", "2\n"},
+		},
+		{
+			name:        "fails on unsupported values",
+			input:       []any{make(chan int)},
+			expectedErr: "encoding JSON: json: unsupported type: chan int",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink2(
+				sources.Slice(tt.input),
+				JSONEncode[any](tt.config),
+				Map(func(ctx context.Context, b []byte) string { return string(b) }),
+				sinks.Slice[string](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package flows
+
+import (
+	"context"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// KeepAlive creates a Flow that injects a heartbeat item, created by calling makeHeartbeat,
+// whenever no item passed through it for the given interval, measured from the start of the
+// run and from every item after that. This keeps downstream stages that time out when idle,
+// such as websocket connections or visibility timeout extenders, alive while upstream is quiet.
+// Items pass through unchanged.
+//
+// Type Parameters:
+//   - I: The type of items
+//
+// Parameters:
+//   - interval: The maximum time between two items before a heartbeat is injected
+//   - makeHeartbeat: Function creating the heartbeat item
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that injects heartbeats while upstream is idle
+func KeepAlive[I any](
+	interval time.Duration,
+	makeHeartbeat func() I,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	// seen signals an item to the goroutine injecting heartbeats, which is controlled by
+	// stop and stopped
+	var seen, stop, stopped chan struct{}
+
+	watch := func(ctx context.Context, out chan<- core.Item[I]) {
+		seen = make(chan struct{}, 1)
+		stop, stopped = make(chan struct{}), make(chan struct{})
+		go func() {
+			defer close(stopped)
+			timer := time.NewTimer(interval)
+			defer timer.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-stop:
+					return
+				case <-seen:
+				case <-timer.C:
+					select {
+					case <-ctx.Done():
+						return
+					case <-stop:
+						return
+					case out <- core.Item[I]{Value: makeHeartbeat()}:
+					}
+				}
+				timer.Reset(interval)
+			}
+		}()
+	}
+
+	return core.NewFlowWithStart(
+		watch,
+		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			select {
+			case seen <- struct{}{}:
+			default: // the goroutine has yet to take the previous signal
+			}
+			util.Send(ctx, core.Item[I]{Value: elem}, out)
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[I]) {
+			close(stop)
+			<-stopped
+		},
+		opts...)
+}
@@ -0,0 +1,62 @@
+package flows
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestKeepAlive(t *testing.T) {
+	tests := []struct {
+		name     string
+		delays   []time.Duration
+		expected []int
+	}{
+		{
+			name:     "passes items arriving in time",
+			delays:   []time.Duration{0, 10 * time.Millisecond, 10 * time.Millisecond},
+			expected: []int{1, 2, 3},
+		},
+		{
+			name:     "injects heartbeats while upstream is idle",
+			delays:   []time.Duration{0, 125 * time.Millisecond},
+			expected: []int{1, -1, -1, 2},
+		},
+		{
+			name:     "injects heartbeats before the first item",
+			delays:   []time.Duration{75 * time.Millisecond},
+			expected: []int{-1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan int)
+			go func() {
+				defer close(ch)
+				for i, delay := range tt.delays {
+					time.Sleep(delay)
+					ch <- i + 1
+				}
+			}()
+
+			stream := compose.SourceThroughFlowToSink(
+				sources.Chan(ch),
+				KeepAlive(50*time.Millisecond, func() int { return -1 }),
+				sinks.Slice[int](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+		})
+	}
+}
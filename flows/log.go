@@ -0,0 +1,101 @@
+package flows
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// Logger is the interface through which Log writes its records. It is implemented by
+// *slog.Logger, and can be implemented by adapters for other logging libraries.
+type Logger interface {
+	Log(ctx context.Context, level slog.Level, msg string, args ...any)
+}
+
+// LogConfig configures the logger and levels used by Log.
+type LogConfig struct {
+	// Logger receives the records
+	// If not specified, slog.Default() is used
+	Logger Logger
+
+	// ElementLevel is the level at which items are logged
+	// If not specified, items are logged at slog.LevelDebug
+	ElementLevel slog.Leveler
+
+	// ErrorLevel is the level at which errors are logged
+	// If not specified, errors are logged at slog.LevelError
+	ErrorLevel slog.Leveler
+
+	// CompletionLevel is the level at which the completion of upstream is logged
+	// If not specified, completion is logged at slog.LevelInfo
+	CompletionLevel slog.Leveler
+}
+
+// levelOr returns the level of leveler, or def if it is nil.
+func levelOr(leveler slog.Leveler, def slog.Level) slog.Level {
+	if leveler == nil {
+		return def
+	}
+	return leveler.Level()
+}
+
+// Log creates a Flow that logs every item and error it receives, and the completion of
+// upstream, and passes them on unchanged. Records include the given name as "stage" and
+// the identifier of the run, see core.RunID, as "run", so the records of concurrent
+// streams can be told apart. The completion record includes the number of items and
+// errors received.
+//
+// Errors are passed on unchanged, leaving their handling to the stages downstream, so a Log
+// can be added anywhere in a pipeline without changing its behavior.
+//
+// Type Parameters:
+//   - I: The type of items
+//
+// Parameters:
+//   - name: Name identifying the records of this flow
+//   - config: Configuration of the logger and levels
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that logs items, errors and completion
+func Log[I any](
+	name string,
+	config LogConfig,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	elementLevel := levelOr(config.ElementLevel, slog.LevelDebug)
+	errorLevel := levelOr(config.ErrorLevel, slog.LevelError)
+	completionLevel := levelOr(config.CompletionLevel, slog.LevelInfo)
+
+	logger := func() Logger {
+		if config.Logger == nil {
+			return slog.Default()
+		}
+		return config.Logger
+	}
+
+	var items, errs int64
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			items++
+			logger().Log(ctx, elementLevel, "element", "stage", name, "run", core.RunID(ctx), "element", elem)
+			util.Send(ctx, core.Item[I]{Value: elem}, out)
+			return core.ActionProceed
+		},
+		func(ctx context.Context, err error, out chan<- core.Item[I]) core.StreamAction {
+			errs++
+			logger().Log(ctx, errorLevel, "error", "stage", name, "run", core.RunID(ctx), "error", err)
+			util.Send(ctx, core.Item[I]{Err: err}, out)
+			return core.ActionProceed
+		},
+		func(ctx context.Context, out chan<- core.Item[I]) core.StreamAction {
+			logger().Log(ctx, completionLevel, "upstream completed",
+				"stage", name, "run", core.RunID(ctx), "elements", items, "errors", errs)
+			return core.ActionStop
+		},
+		func(ctx context.Context, out chan<- core.Item[I]) {
+			items, errs = 0, 0
+		},
+		opts...)
+}
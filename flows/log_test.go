@@ -0,0 +1,107 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+// testRecord is a record written to a testLogger.
+type testRecord struct {
+	level slog.Level
+	msg   string
+	attrs map[string]any
+}
+
+// testLogger is a Logger recording its records.
+type testLogger struct {
+	mu      sync.Mutex
+	records []testRecord
+}
+
+func (l *testLogger) Log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	attrs := map[string]any{}
+	for i := 0; i+1 < len(args); i += 2 {
+		attrs[args[i].(string)] = args[i+1]
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, testRecord{level: level, msg: msg, attrs: attrs})
+}
+
+func TestLog(t *testing.T) {
+	errOdd := errors.New("odd")
+
+	tests := []struct {
+		name     string
+		input    []int
+		config   func(logger Logger) LogConfig
+		expected []testRecord
+	}{
+		{
+			name:   "logs items and completion at default levels",
+			input:  []int{2, 4},
+			config: func(logger Logger) LogConfig { return LogConfig{Logger: logger} },
+			expected: []testRecord{
+				{level: slog.LevelDebug, msg: "element", attrs: map[string]any{"stage": "numbers", "element": 2}},
+				{level: slog.LevelDebug, msg: "element", attrs: map[string]any{"stage": "numbers", "element": 4}},
+				{level: slog.LevelInfo, msg: "upstream completed", attrs: map[string]any{"stage": "numbers", "elements": int64(2), "errors": int64(0)}},
+			},
+		},
+		{
+			name:  "logs errors at configured levels",
+			input: []int{1, 2},
+			config: func(logger Logger) LogConfig {
+				return LogConfig{
+					Logger:          logger,
+					ElementLevel:    slog.LevelInfo,
+					ErrorLevel:      slog.LevelWarn,
+					CompletionLevel: slog.LevelDebug,
+				}
+			},
+			expected: []testRecord{
+				{level: slog.LevelWarn, msg: "error", attrs: map[string]any{"stage": "numbers", "error": errOdd}},
+				{level: slog.LevelInfo, msg: "element", attrs: map[string]any{"stage": "numbers", "element": 2}},
+				{level: slog.LevelDebug, msg: "upstream completed", attrs: map[string]any{"stage": "numbers", "elements": int64(1), "errors": int64(1)}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &testLogger{}
+			stream := compose.SourceThroughFlowToSink3(
+				sources.Slice(tt.input),
+				TryMap(func(ctx context.Context, i int) (int, error) {
+					if i%2 == 1 {
+						return 0, errOdd
+					}
+					return i, nil
+				}),
+				Log[int]("numbers", tt.config(logger)),
+				Map(func(ctx context.Context, i int) int { return i }, core.WithSupervision(core.ResumingDecider)),
+				sinks.Slice[int](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+			require.NoError(t, res.Err)
+
+			require.Len(t, logger.records, len(tt.expected))
+			for i, record := range logger.records {
+				assert.NotZero(t, record.attrs["run"])
+				delete(record.attrs, "run")
+				assert.Equal(t, tt.expected[i], record)
+			}
+		})
+	}
+}
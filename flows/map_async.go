@@ -0,0 +1,117 @@
+package flows
+
+import (
+	"context"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// MapAsync creates a Flow that transforms items in parallel using the provided mapping
+// function, like MapPar, but emits the results in the order the items were received.
+// Up to 'parallelism' items are processed or waiting to be emitted at once: results that
+// complete before the results of earlier items are buffered until those are emitted, and
+// upstream is backpressured while the buffer is full.
+//
+// A panic in fn is emitted as a core.PanicError in place of its result, unless the Decider
+// of core.WithSupervision decides otherwise. If the flow is configured with core.WithSandbox,
+// fn runs in its sandbox, which further bounds the concurrency. Use WithPriorityLane to
+// reserve workers for high-priority items.
+//
+// Type Parameters:
+//   - I: The type of input items
+//   - O: The type of output items
+//
+// Parameters:
+//   - fn: Function that transforms an input item into an output item
+//   - parallelism: Maximum number of items processed or waiting to be emitted at once
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that transforms items in parallel while preserving their order
+func MapAsync[I, O any](
+	fn func(context.Context, I) O,
+	parallelism int,
+	opts ...core.FlowOption,
+) *core.Flow[I, O] {
+	parallelism = max(parallelism, 1)
+
+	// job is an item to transform, together with the channel receiving its result
+	type job struct {
+		elem I
+		res  chan core.Item[O]
+	}
+
+	// Jobs are run by reused workers, as in MapPar. The results are emitted by a single
+	// goroutine in the order the jobs were created.
+	var workers *parWorkers[job]
+	var pending chan chan core.Item[O]
+	var emitted chan struct{}
+	decisions := &workerDecisions{}
+
+	emit := func(ctx context.Context, pending <-chan chan core.Item[O], out chan<- core.Item[O], emitted chan<- struct{}) {
+		defer close(emitted)
+		for res := range pending {
+			select {
+			case <-ctx.Done():
+				return
+			case item := <-res:
+				if item.Err != nil {
+					decisions.handle(ctx, item.Err, func() { util.Send(ctx, item, out) })
+					continue
+				}
+				util.Send(ctx, item, out)
+			}
+		}
+	}
+
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[O]) core.StreamAction {
+			if decisions.stopped() {
+				return core.ActionStop
+			}
+			if workers == nil {
+				var isJobPriority func(job) bool
+				isPriority, reserved := priorityLaneOf[I](ctx, parallelism)
+				if isPriority != nil {
+					isJobPriority = func(j job) bool { return isPriority(j.elem) }
+				}
+				workers = newParWorkers(parallelism, reserved, isJobPriority, func() func(job) {
+					return func(j job) {
+						var res O
+						if err := core.RunSandboxed(ctx, func() { res = fn(ctx, j.elem) }); err != nil {
+							j.res <- core.Item[O]{Err: err}
+							return
+						}
+						j.res <- core.Item[O]{Value: res}
+					}
+				})
+				pending = make(chan chan core.Item[O], parallelism)
+				emitted = make(chan struct{})
+				go emit(ctx, pending, out, emitted)
+			}
+
+			j := job{elem: elem, res: make(chan core.Item[O], 1)}
+			select {
+			case <-ctx.Done():
+				return core.ActionStop
+			case pending <- j.res: // reserve the position of the result
+			}
+
+			if !workers.dispatch(ctx, j) {
+				return core.ActionStop
+			}
+			return decisions.action()
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[O]) {
+			if workers != nil {
+				workers.close() // wait for all workers to finish
+				close(pending)
+				<-emitted
+				workers, pending = nil, nil
+			}
+			decisions.reset()
+		},
+		append([]core.FlowOption{core.WithFlowAttributes(core.Parallelism(parallelism))}, opts...)...)
+}
@@ -0,0 +1,104 @@
+package flows
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+	"github.com/svenvdam/linea/test"
+)
+
+func TestMapAsync(t *testing.T) {
+	input := make([]int, 20)
+	want := make([]string, 20)
+	for i := range input {
+		input[i] = i + 1
+		want[i] = strconv.Itoa(i + 1)
+	}
+
+	tests := []struct {
+		name        string
+		parallelism int
+	}{
+		{name: "preserves order with parallelism", parallelism: 4},
+		{name: "runs sequentially without parallelism", parallelism: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := test.NewParallelTracker()
+			var mu sync.Mutex
+			var peak int
+
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(input),
+				MapAsync(func(ctx context.Context, i int) string {
+					current, done := tracker.Track()
+					defer done()
+					mu.Lock()
+					peak = max(peak, current)
+					mu.Unlock()
+					// Later items complete first, so results arrive out of order
+					time.Sleep(time.Duration(20-i) * time.Millisecond / 4)
+					return strconv.Itoa(i)
+				}, tt.parallelism),
+				sinks.Slice[string](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			require.NoError(t, res.Err)
+			assert.Equal(t, want, res.Value)
+			assert.LessOrEqual(t, peak, tt.parallelism)
+			if tt.parallelism > 1 {
+				assert.Greater(t, peak, 1)
+			}
+		})
+	}
+}
+
+func TestMapAsyncPanic(t *testing.T) {
+	stream := compose.SourceThroughFlowToSink(
+		sources.Slice([]int{1, 2, 3}),
+		MapAsync(func(ctx context.Context, i int) int {
+			if i == 2 {
+				panic("boom")
+			}
+			return i
+		}, 2),
+		sinks.Slice[int](),
+	)
+
+	res := <-stream.Run(context.Background())
+	stream.AwaitDone()
+
+	var pe *core.PanicError
+	require.ErrorAs(t, res.Err, &pe)
+}
+
+func TestMapAsyncCancel(t *testing.T) {
+	stream := compose.SourceThroughFlowToSink(
+		sources.Repeat(1),
+		MapAsync(func(ctx context.Context, i int) int {
+			time.Sleep(time.Millisecond)
+			return i
+		}, 4),
+		sinks.Noop[int](),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	res := <-stream.Run(ctx)
+	stream.AwaitDone()
+	assert.ErrorIs(t, res.Err, context.DeadlineExceeded)
+}
@@ -3,6 +3,7 @@ package flows
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 
 	"github.com/svenvdam/linea/core"
 	"github.com/svenvdam/linea/util"
@@ -12,6 +13,11 @@ import (
 // function. Up to 'parallelism' items will be processed concurrently. The order of
 // output items is not guaranteed to match the input order.
 //
+// A panic in fn is emitted as a core.PanicError, unless the Decider of core.WithSupervision
+// decides otherwise. If the flow is configured with core.WithSandbox, fn runs in its sandbox,
+// which further bounds the concurrency. Use WithPriorityLane to reserve workers for
+// high-priority items.
+//
 // Type Parameters:
 //   - I: The type of input items
 //   - O: The type of output items
@@ -27,25 +33,269 @@ func MapPar[I, O any](
 	parallelism int,
 	opts ...core.FlowOption,
 ) *core.Flow[I, O] {
-	sem := make(chan struct{}, parallelism)
-	wg := sync.WaitGroup{}
+	return mapPar(func(ctx context.Context, elem I) (O, error) {
+		return fn(ctx, elem), nil
+	}, parallelism, opts...)
+}
+
+// MapAsyncUnordered creates a Flow that transforms items in parallel using the provided
+// mapping function, which can fail. Up to 'parallelism' items will be processed concurrently,
+// and results are emitted as soon as they are available, so their order is not guaranteed to
+// match the input order.
+//
+// Errors returned by fn are emitted downstream in place of the result, like TryMap does, so
+// they follow the standard error path: by default the stream fails, and an error mode such as
+// core.ContinueOnError decides otherwise. If the flow is configured with core.WithSupervision,
+// its Decider decides whether an error is emitted, discarded or restarts upstream. A panic in
+// fn is emitted as a core.PanicError. If the flow is configured with core.WithSandbox, fn runs
+// in its sandbox, which further bounds the concurrency.
+//
+// Type Parameters:
+//   - I: The type of input items
+//   - O: The type of output items
+//
+// Parameters:
+//   - fn: Function that transforms an input item into an output item or returns an error
+//   - parallelism: Maximum number of items to process concurrently
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that transforms items in parallel
+func MapAsyncUnordered[I, O any](
+	fn func(context.Context, I) (O, error),
+	parallelism int,
+	opts ...core.FlowOption,
+) *core.Flow[I, O] {
+	return mapPar(fn, parallelism, opts...)
+}
+
+// mapPar creates a Flow transforming items in parallel with fn, emitting results in the order
+// they complete and errors in place of their results.
+func mapPar[I, O any](
+	fn func(context.Context, I) (O, error),
+	parallelism int,
+	opts ...core.FlowOption,
+) *core.Flow[I, O] {
+	var workers *parWorkers[I]
+	decisions := &workerDecisions{}
 	return core.NewFlow(
 		func(ctx context.Context, elem I, out chan<- core.Item[O]) core.StreamAction {
-			sem <- struct{}{} // wait for a slot
-			wg.Add(1)
-			go func() {
-				defer func() {
-					wg.Done()
-					<-sem // release the slot
-				}()
-				util.Send(ctx, core.Item[O]{Value: fn(ctx, elem)}, out)
-			}()
-			return core.ActionProceed
+			if decisions.stopped() {
+				return core.ActionStop
+			}
+			if workers == nil {
+				isPriority, reserved := priorityLaneOf[I](ctx, parallelism)
+				workers = newParWorkers(parallelism, reserved, isPriority, func() func(I) {
+					// Every worker reuses the function it passes to RunSandboxed, which escapes
+					// to the heap, so that processing an item does not allocate
+					var elem I
+					var res O
+					var err error
+					call := func() { res, err = fn(ctx, elem) }
+					return func(j I) {
+						if ctx.Err() != nil {
+							// A call abandoned by the sandbox may still use the state of the worker
+							return
+						}
+						elem = j
+						failure := core.RunSandboxed(ctx, call)
+						if failure == nil {
+							failure = err
+						}
+						if failure != nil {
+							decisions.handle(ctx, failure, func() { util.Send(ctx, core.Item[O]{Err: failure}, out) })
+							return
+						}
+						util.Send(ctx, core.Item[O]{Value: res}, out)
+					}
+				})
+			}
+			if !workers.dispatch(ctx, elem) {
+				return core.ActionStop
+			}
+			return decisions.action()
 		},
 		nil,
 		nil,
 		func(ctx context.Context, out chan<- core.Item[O]) {
-			wg.Wait() // wait for all goroutines to finish
+			if workers != nil {
+				workers.close() // wait for all workers to finish
+				workers = nil
+			}
+			decisions.reset()
 		},
-		opts...)
+		append([]core.FlowOption{core.WithFlowAttributes(core.Parallelism(parallelism))}, opts...)...)
+}
+
+// parWorkers hands jobs to workers that are started as needed and reused for subsequent jobs,
+// so that processing a job does not start a goroutine. If the flow has a priority lane, some
+// of the workers are reserved for priority jobs, and other jobs wait in a queue so that
+// priority jobs behind them can pass.
+type parWorkers[J any] struct {
+	newWorker  func() func(J)
+	isPriority func(J) bool
+	unreserved int
+	reserved   int
+	shared     int
+	dedicated  int
+	wg         sync.WaitGroup
+
+	// jobs holds the jobs for the unreserved workers, buffered if the flow has a priority lane
+	jobs chan J
+
+	// priority hands priority jobs to any idle worker
+	priority chan J
+}
+
+// newParWorkers creates the workers of a single run of a parallel flow, running at most
+// parallelism jobs at once, of which reserved workers only run jobs for which isPriority
+// returns true. isPriority is nil if the flow has no priority lane. newWorker is called for
+// every worker started, returning the function running its jobs, so that a worker can reuse
+// state between jobs.
+func newParWorkers[J any](parallelism, reserved int, isPriority func(J) bool, newWorker func() func(J)) *parWorkers[J] {
+	queue := 0
+	if isPriority != nil {
+		queue = parallelism
+	}
+	return &parWorkers[J]{
+		newWorker:  newWorker,
+		isPriority: isPriority,
+		unreserved: parallelism - reserved,
+		reserved:   reserved,
+		jobs:       make(chan J, queue),
+		priority:   make(chan J),
+	}
+}
+
+// dispatch hands j to a worker, waiting while all workers it can use are busy. It returns
+// false if ctx is cancelled first.
+func (p *parWorkers[J]) dispatch(ctx context.Context, j J) bool {
+	if p.isPriority != nil && p.isPriority(j) {
+		select {
+		case p.priority <- j: // handed to an idle worker
+			return true
+		default:
+		}
+		if p.dedicated < p.reserved {
+			p.dedicated++
+			p.start(p.priority, nil)
+		} else if p.shared < p.unreserved {
+			p.shared++
+			p.start(p.priority, p.jobs)
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case p.priority <- j:
+			return true
+		}
+	}
+
+	select {
+	case p.jobs <- j: // handed to an idle worker or queued
+		if len(p.jobs) > 0 && p.shared < p.unreserved {
+			p.shared++
+			p.start(p.priority, p.jobs)
+		}
+		return true
+	default:
+	}
+	if p.shared < p.unreserved {
+		p.shared++
+		p.start(p.priority, p.jobs)
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case p.jobs <- j: // wait for a worker
+		return true
+	}
+}
+
+// start starts a worker running priority jobs, and other jobs if jobs is not nil. Workers
+// running both take priority jobs first.
+func (p *parWorkers[J]) start(priority, jobs <-chan J) {
+	work := p.newWorker()
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for priority != nil || jobs != nil {
+			select {
+			case j, ok := <-priority:
+				if !ok {
+					priority = nil
+					continue
+				}
+				work(j)
+				continue
+			default:
+			}
+
+			select {
+			case j, ok := <-priority:
+				if !ok {
+					priority = nil
+					continue
+				}
+				work(j)
+			case j, ok := <-jobs:
+				if !ok {
+					jobs = nil
+					continue
+				}
+				work(j)
+			}
+		}
+	}()
+}
+
+// close lets the workers finish the queued jobs and waits for them to stop.
+func (p *parWorkers[J]) close() {
+	close(p.jobs)
+	close(p.priority)
+	p.wg.Wait()
+}
+
+// workerDecisions applies the Decider of a supervised flow to the errors produced by its worker
+// goroutines, which cannot return a StreamAction themselves. The decisions are applied by the
+// flow when it receives the next element.
+type workerDecisions struct {
+	stop    atomic.Bool
+	restart atomic.Bool
+}
+
+// handle supervises err, calling emit if the error is to be emitted downstream.
+func (d *workerDecisions) handle(ctx context.Context, err error, emit func()) {
+	decision, ok := core.Supervise(ctx, err)
+	switch {
+	case !ok:
+		emit()
+	case decision == core.DecisionResume:
+	case decision == core.DecisionRestart:
+		d.restart.Store(true)
+	default:
+		emit()
+		d.stop.Store(true)
+	}
+}
+
+// stopped reports whether an error stopped the flow.
+func (d *workerDecisions) stopped() bool {
+	return d.stop.Load()
+}
+
+// action returns the StreamAction resulting from the errors handled since the last call.
+func (d *workerDecisions) action() core.StreamAction {
+	if d.stop.Load() {
+		return core.ActionStop
+	}
+	if d.restart.Swap(false) {
+		return core.ActionRestartUpstream
+	}
+	return core.ActionProceed
+}
+
+// reset clears the decisions, so they do not carry over to the next run of the flow.
+func (d *workerDecisions) reset() {
+	d.stop.Store(false)
+	d.restart.Store(false)
 }
@@ -0,0 +1,63 @@
+package flows
+
+import (
+	"context"
+	"math"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// priorityLane configures the priority lane of a parallel flow, see WithPriorityLane.
+type priorityLane struct {
+	isPriority    any
+	reservedShare float64
+}
+
+// priorityLaneKey is the attribute holding the priority lane of parallel flows.
+var priorityLaneKey = core.NewAttributeKey[priorityLane]("priorityLane")
+
+// WithPriorityLane creates a FlowOption that reserves a share of the workers of MapPar,
+// MapAsyncUnordered or MapAsync for high-priority items. Items for which isPriority returns
+// true can use any worker, while other items can only use the unreserved workers. This keeps
+// latency-sensitive items from getting stuck behind a backlog of bulk items in the same
+// pipeline.
+//
+// Items that are not prioritized and are waiting for a worker are queued, up to
+// 'parallelism' items, so that high-priority items behind them can still pass. At least
+// one worker is always left for items that are not prioritized. MapAsync still emits the
+// results in the order the items were received, so the priority lane only speeds up their
+// processing.
+//
+// The priority lane applies to parallel flows whose input items are of type I. When it is
+// attached to several stages at once, for example with core.FlowWithAttributes, stages with
+// other input types are not affected.
+//
+// Type Parameters:
+//   - I: The type of input items of the flow
+//
+// Parameters:
+//   - isPriority: Function that returns whether an item is high-priority
+//   - reservedShare: Share of the workers reserved for high-priority items, between 0 and 1
+//
+// Returns:
+//   - A FlowOption that can be passed to MapPar, MapAsyncUnordered and MapAsync
+func WithPriorityLane[I any](isPriority func(I) bool, reservedShare float64) core.FlowOption {
+	lane := priorityLane{isPriority: isPriority, reservedShare: reservedShare}
+	return core.WithFlowAttributes(core.SetAttribute(core.Attributes{}, priorityLaneKey, lane))
+}
+
+// priorityLaneOf returns the priority function of the priority lane configured for the stage
+// ctx was passed to, together with the number of workers reserved for it. The function is nil
+// if the stage has no priority lane for items of type I.
+func priorityLaneOf[I any](ctx context.Context, parallelism int) (func(I) bool, int) {
+	lane, ok := core.GetAttribute(core.AttributesFromContext(ctx), priorityLaneKey)
+	if !ok {
+		return nil, 0
+	}
+	isPriority, ok := lane.isPriority.(func(I) bool)
+	if !ok || isPriority == nil {
+		return nil, 0
+	}
+	reserved := int(math.Ceil(float64(parallelism) * lane.reservedShare))
+	return isPriority, min(max(reserved, 0), parallelism-1)
+}
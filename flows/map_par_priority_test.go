@@ -0,0 +1,149 @@
+package flows
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+	"github.com/svenvdam/linea/test"
+)
+
+func TestWithPriorityLane(t *testing.T) {
+	isPriority := func(s string) bool { return s[0] == 'p' }
+
+	tests := []struct {
+		name          string
+		input         []string
+		parallelism   int
+		reservedShare float64
+		setup         func(t *testing.T) func(context.Context, string) string
+		want          []string
+		wantFirst     string
+	}{
+		{
+			name:          "processes priority items while bulk items are blocked",
+			input:         []string{"b1", "b2", "b3", "p1"},
+			parallelism:   2,
+			reservedShare: 0.5,
+			setup: func(t *testing.T) func(context.Context, string) string {
+				release := make(chan struct{})
+				return func(ctx context.Context, s string) string {
+					if isPriority(s) {
+						// bulk items can only finish once the priority item was processed
+						close(release)
+						return s
+					}
+					select {
+					case <-ctx.Done():
+					case <-release:
+					}
+					return s
+				}
+			},
+			want:      []string{"p1", "b1", "b2", "b3"},
+			wantFirst: "p1",
+		},
+		{
+			name:          "limits bulk items to unreserved workers",
+			input:         []string{"b1", "b2", "b3", "b4", "b5", "b6"},
+			parallelism:   3,
+			reservedShare: 0.5,
+			setup: func(t *testing.T) func(context.Context, string) string {
+				parTracker := test.NewParallelTracker()
+				return func(ctx context.Context, s string) string {
+					parallelism, cleanup := parTracker.Track()
+					defer cleanup()
+
+					assert.LessOrEqual(t, parallelism, 1)
+
+					time.Sleep(10 * time.Millisecond) // simulate work
+					return s
+				}
+			},
+			want: []string{"b1", "b2", "b3", "b4", "b5", "b6"},
+		},
+		{
+			name:          "keeps one worker for bulk items",
+			input:         []string{"b1", "p1", "b2", "p2"},
+			parallelism:   1,
+			reservedShare: 1,
+			setup: func(t *testing.T) func(context.Context, string) string {
+				return func(ctx context.Context, s string) string {
+					return s
+				}
+			},
+			want: []string{"b1", "p1", "b2", "p2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(tt.input),
+				MapPar(tt.setup(t), tt.parallelism, WithPriorityLane(isPriority, tt.reservedShare)),
+				sinks.Slice[string](),
+			)
+
+			res := <-stream.Run(ctx)
+			stream.AwaitDone()
+
+			assert.NoError(t, res.Err)
+			assert.ElementsMatch(t, tt.want, res.Value)
+			if tt.wantFirst != "" {
+				assert.Equal(t, tt.wantFirst, res.Value[0])
+			}
+		})
+	}
+}
+
+func TestWithPriorityLaneMapAsync(t *testing.T) {
+	isPriority := func(s string) bool { return s[0] == 'p' }
+	input := []string{"b1", "b2", "p1", "b3", "p2", "b4"}
+
+	parTracker := test.NewParallelTracker()
+	fn := func(ctx context.Context, s string) string {
+		if !isPriority(s) {
+			parallelism, cleanup := parTracker.Track()
+			defer cleanup()
+
+			assert.LessOrEqual(t, parallelism, 2)
+		}
+		time.Sleep(10 * time.Millisecond) // simulate work
+		return s
+	}
+
+	stream := compose.SourceThroughFlowToSink(
+		sources.Slice(input),
+		MapAsync(fn, 4, WithPriorityLane(isPriority, 0.5)),
+		sinks.Slice[string](),
+	)
+
+	res := <-stream.Run(context.Background())
+	stream.AwaitDone()
+
+	assert.NoError(t, res.Err)
+	assert.Equal(t, input, res.Value)
+}
+
+func TestWithPriorityLaneOtherType(t *testing.T) {
+	// A priority lane for other items does not affect the flow
+	stream := compose.SourceThroughFlowToSink(
+		sources.Slice([]int{1, 2, 3}),
+		MapPar(func(ctx context.Context, i int) int { return i * 2 }, 2,
+			WithPriorityLane(func(s string) bool { return true }, 0.5)),
+		sinks.Slice[int](),
+	)
+
+	res := <-stream.Run(context.Background())
+	stream.AwaitDone()
+
+	assert.NoError(t, res.Err)
+	assert.ElementsMatch(t, []int{2, 4, 6}, res.Value)
+}
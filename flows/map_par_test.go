@@ -2,12 +2,15 @@ package flows
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
 	"github.com/svenvdam/linea/sinks"
 	"github.com/svenvdam/linea/sources"
 	"github.com/svenvdam/linea/test"
@@ -21,7 +24,9 @@ func TestMapPar(t *testing.T) {
 		input       []int
 		setup       func() func(context.Context, int) string
 		parallelism int
+		opts        []core.FlowOption
 		want        []string
+		wantErr     bool
 	}{
 		{
 			name: "maps integers to strings in parallel",
@@ -74,6 +79,37 @@ func TestMapPar(t *testing.T) {
 			parallelism: maxParallelism,
 			want:        []string{"1", "2", "3"},
 		},
+		{
+			name:  "sandbox bounds parallelism",
+			input: []int{1, 2, 3, 4},
+			setup: func() func(ctx context.Context, i int) string {
+				parTracker := test.NewParallelTracker()
+				return func(ctx context.Context, i int) string {
+					parallelism, cleanup := parTracker.Track()
+					defer cleanup()
+
+					assert.LessOrEqual(t, parallelism, 1)
+
+					time.Sleep(20 * time.Millisecond) // simulate work
+					return strconv.Itoa(i)
+				}
+			},
+			parallelism: 4,
+			opts:        []core.FlowOption{core.WithSandbox(1)},
+			want:        []string{"1", "2", "3", "4"},
+		},
+		{
+			name:  "emits panics as errors",
+			input: []int{1},
+			setup: func() func(ctx context.Context, i int) string {
+				return func(ctx context.Context, i int) string {
+					panic("boom")
+				}
+			},
+			parallelism: maxParallelism,
+			want:        []string{},
+			wantErr:     true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -86,7 +122,7 @@ func TestMapPar(t *testing.T) {
 				test.CheckItems(t, func(t *testing.T, seen []int) {
 					assert.Equal(t, tt.input, seen)
 				}),
-				MapPar(mapper, tt.parallelism),
+				MapPar(mapper, tt.parallelism, tt.opts...),
 				test.CheckItems(t, func(t *testing.T, seen []string) {
 					assert.ElementsMatch(t, tt.want, seen)
 				}),
@@ -94,7 +130,81 @@ func TestMapPar(t *testing.T) {
 			)
 
 			res := <-stream.Run(ctx)
-			assert.NoError(t, res.Err)
+			if tt.wantErr {
+				var pe *core.PanicError
+				assert.ErrorAs(t, res.Err, &pe)
+			} else {
+				assert.NoError(t, res.Err)
+			}
+		})
+	}
+}
+
+func TestMapAsyncUnordered(t *testing.T) {
+	errOdd := errors.New("odd")
+	parse := func(ctx context.Context, i int) (string, error) {
+		if i%2 == 1 {
+			return "", errOdd
+		}
+		return strconv.Itoa(i), nil
+	}
+
+	tests := []struct {
+		name    string
+		flow    func() *core.Flow[int, string]
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "fails stream on error",
+			flow: func() *core.Flow[int, string] {
+				return MapAsyncUnordered(parse, 3)
+			},
+			wantErr: errOdd,
+		},
+		{
+			name: "resumes errors with supervision of next flow",
+			flow: func() *core.Flow[int, string] {
+				return compose.MergeFlows(
+					MapAsyncUnordered(parse, 3),
+					Map(func(ctx context.Context, s string) string { return s }, core.WithSupervision(core.ResumingDecider)),
+				)
+			},
+			want: []string{"2", "4", "6"},
+		},
+		{
+			name: "resumes errors with supervision of the flow itself",
+			flow: func() *core.Flow[int, string] {
+				return MapAsyncUnordered(parse, 3, core.WithSupervision(core.ResumingDecider))
+			},
+			want: []string{"2", "4", "6"},
+		},
+		{
+			name: "fails stream with stopping supervision of the flow itself",
+			flow: func() *core.Flow[int, string] {
+				return MapAsyncUnordered(parse, 3, core.WithSupervision(core.StoppingDecider))
+			},
+			wantErr: errOdd,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice([]int{1, 2, 3, 4, 5, 6}),
+				tt.flow(),
+				sinks.Slice[string](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, res.Err, tt.wantErr)
+				return
+			}
+			require.NoError(t, res.Err)
+			assert.ElementsMatch(t, tt.want, res.Value)
 		})
 	}
 }
@@ -0,0 +1,54 @@
+package flows
+
+import (
+	"context"
+	"errors"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// MapWithFallback creates a Flow that transforms each input item using a primary function,
+// and falls back to a second function for items the primary fails on, such as reading from
+// the source of truth on a cache miss or serving a degraded result.
+//
+// The fallback receives the item together with the error of the primary. If the fallback
+// succeeds, its result is emitted and the error of the primary is reported to the listeners
+// registered with Stream.OnError with core.ErrorHandlingRecovered. If the fallback fails as
+// well, both errors are joined with errors.Join and emitted downstream in place of the result,
+// like TryMap does.
+//
+// Type Parameters:
+//   - I: The type of input items
+//   - O: The type of output items
+//
+// Parameters:
+//   - primary: Function that transforms an input item into an output item or returns an error
+//   - fallback: Function invoked with the item and the error when primary fails
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that transforms items, falling back for failed items
+func MapWithFallback[I, O any](
+	primary func(context.Context, I) (O, error),
+	fallback func(context.Context, I, error) (O, error),
+	opts ...core.FlowOption,
+) *core.Flow[I, O] {
+	return core.NewSyncFlow(
+		func(ctx context.Context, elem I, emit func(core.Item[O])) core.StreamAction {
+			result, err := primary(ctx, elem)
+			if err == nil {
+				emit(core.Item[O]{Value: result})
+				return core.ActionProceed
+			}
+
+			result, fallbackErr := fallback(ctx, elem, err)
+			if fallbackErr != nil {
+				emit(core.Item[O]{Err: errors.Join(err, fallbackErr)})
+				return core.ActionProceed
+			}
+			core.ReportError(ctx, "MapWithFallback", core.ErrorHandlingRecovered, err)
+			emit(core.Item[O]{Value: result})
+			return core.ActionProceed
+		},
+		nil,
+		opts...)
+}
@@ -0,0 +1,88 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestMapWithFallback(t *testing.T) {
+	errMiss := errors.New("cache miss")
+	errUnavailable := errors.New("unavailable")
+	cache := map[int]string{1: "one", 3: "three"}
+	primary := func(ctx context.Context, i int) (string, error) {
+		if v, ok := cache[i]; ok {
+			return v, nil
+		}
+		return "", errMiss
+	}
+
+	tests := []struct {
+		name           string
+		fallback       func(context.Context, int, error) (string, error)
+		expected       []string
+		expectedEvents int
+		expectedErrs   []error
+	}{
+		{
+			name: "falls back for failed items",
+			fallback: func(ctx context.Context, i int, err error) (string, error) {
+				return "fallback", nil
+			},
+			expected:       []string{"one", "fallback", "three"},
+			expectedEvents: 1,
+		},
+		{
+			name: "passes the primary error to the fallback",
+			fallback: func(ctx context.Context, i int, err error) (string, error) {
+				return err.Error(), nil
+			},
+			expected:       []string{"one", "cache miss", "three"},
+			expectedEvents: 1,
+		},
+		{
+			name: "emits both errors when the fallback fails",
+			fallback: func(ctx context.Context, i int, err error) (string, error) {
+				return "", errUnavailable
+			},
+			expectedErrs: []error{errMiss, errUnavailable},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice([]int{1, 2, 3}),
+				MapWithFallback(primary, tt.fallback),
+				sinks.Slice[string](),
+			)
+
+			var events []core.ErrorEvent
+			stream.OnError(func(ctx context.Context, event core.ErrorEvent) {
+				events = append(events, event)
+			})
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			for _, err := range tt.expectedErrs {
+				assert.ErrorIs(t, res.Err, err)
+			}
+			if tt.expectedErrs != nil {
+				return
+			}
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+			require.Len(t, events, tt.expectedEvents)
+			assert.Equal(t, core.ErrorHandlingRecovered, events[0].Handling)
+			assert.ErrorIs(t, events[0].Err, errMiss)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package flows
+
+import (
+	"context"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// MetricsRecorder receives the measurements of Meter, for example to export them to a metrics
+// system. Implementations are called from the goroutine of the flow, so they should not block.
+type MetricsRecorder interface {
+	// RecordElement is called for every item, with the time elapsed since the previous
+	// item of the run, or zero for the first item
+	RecordElement(ctx context.Context, stage string, interval time.Duration)
+
+	// RecordError is called for every error
+	RecordError(ctx context.Context, stage string, err error)
+
+	// RecordProcessing is called for every item and error, with the time taken until it
+	// was accepted downstream
+	RecordProcessing(ctx context.Context, stage string, duration time.Duration)
+}
+
+// Meter creates a Flow that reports every item and error it receives to recorder under the
+// given name, and passes them on unchanged. For every item it reports the time elapsed since
+// the previous item, showing the rate of upstream, and for every item and error the time taken
+// until it was accepted downstream, showing how much downstream backpressures.
+//
+// Errors are passed on unchanged, leaving their handling to the stages downstream, so a Meter
+// can be added anywhere in a pipeline without changing its behavior.
+//
+// Type Parameters:
+//   - I: The type of items
+//
+// Parameters:
+//   - name: Name of the stage passed to recorder
+//   - recorder: Recorder receiving the measurements
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that reports metrics of the items passing through it
+func Meter[I any](
+	name string,
+	recorder MetricsRecorder,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	var last time.Time
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			now := time.Now()
+			var interval time.Duration
+			if !last.IsZero() {
+				interval = now.Sub(last)
+			}
+			last = now
+			recorder.RecordElement(ctx, name, interval)
+
+			util.Send(ctx, core.Item[I]{Value: elem}, out)
+			recorder.RecordProcessing(ctx, name, time.Since(now))
+			return core.ActionProceed
+		},
+		func(ctx context.Context, err error, out chan<- core.Item[I]) core.StreamAction {
+			start := time.Now()
+			recorder.RecordError(ctx, name, err)
+
+			util.Send(ctx, core.Item[I]{Err: err}, out)
+			recorder.RecordProcessing(ctx, name, time.Since(start))
+			return core.ActionProceed
+		},
+		nil,
+		func(ctx context.Context, out chan<- core.Item[I]) {
+			last = time.Time{}
+		},
+		opts...)
+}
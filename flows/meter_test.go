@@ -0,0 +1,113 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+// testRecorder is a MetricsRecorder recording its measurements.
+type testRecorder struct {
+	stages      []string
+	intervals   []time.Duration
+	errs        []error
+	processings []time.Duration
+}
+
+func (r *testRecorder) RecordElement(ctx context.Context, stage string, interval time.Duration) {
+	r.stages = append(r.stages, stage)
+	r.intervals = append(r.intervals, interval)
+}
+
+func (r *testRecorder) RecordError(ctx context.Context, stage string, err error) {
+	r.stages = append(r.stages, stage)
+	r.errs = append(r.errs, err)
+}
+
+func (r *testRecorder) RecordProcessing(ctx context.Context, stage string, duration time.Duration) {
+	r.processings = append(r.processings, duration)
+}
+
+func TestMeter(t *testing.T) {
+	errOdd := errors.New("odd")
+
+	tests := []struct {
+		name              string
+		input             []int
+		delay             time.Duration
+		expected          []int
+		expectedElements  int
+		expectedErrors    int
+		minInterval       time.Duration
+		minProcessingTime time.Duration
+	}{
+		{
+			name:             "records items",
+			input:            []int{2, 4, 6},
+			expected:         []int{2, 4, 6},
+			expectedElements: 3,
+		},
+		{
+			name:             "records errors",
+			input:            []int{1, 2, 3},
+			expected:         []int{2},
+			expectedElements: 1,
+			expectedErrors:   2,
+		},
+		{
+			name:              "measures intervals and processing time",
+			input:             []int{2, 4, 6, 8},
+			delay:             20 * time.Millisecond,
+			expected:          []int{2, 4, 6, 8},
+			expectedElements:  4,
+			minInterval:       20 * time.Millisecond,
+			minProcessingTime: 20 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &testRecorder{}
+			stream := compose.SourceThroughFlowToSink3(
+				sources.Slice(tt.input),
+				TryMap(func(ctx context.Context, i int) (int, error) {
+					if i%2 == 1 {
+						return 0, errOdd
+					}
+					return i, nil
+				}),
+				Meter[int]("numbers", recorder, core.WithFlowAttributes(core.BufSize(0))),
+				Map(func(ctx context.Context, i int) int {
+					time.Sleep(tt.delay)
+					return i
+				}, core.WithSupervision(core.ResumingDecider), core.WithFlowAttributes(core.BufSize(0))),
+				sinks.Slice[int](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+
+			require.Len(t, recorder.intervals, tt.expectedElements)
+			assert.Len(t, recorder.errs, tt.expectedErrors)
+			assert.Len(t, recorder.processings, tt.expectedElements+tt.expectedErrors)
+			for _, stage := range recorder.stages {
+				assert.Equal(t, "numbers", stage)
+			}
+			assert.Zero(t, recorder.intervals[0])
+			// Once the buffers are full, items are accepted as downstream processes the previous ones
+			assert.GreaterOrEqual(t, slices.Max(recorder.intervals), tt.minInterval)
+			assert.GreaterOrEqual(t, slices.Max(recorder.processings), tt.minProcessingTime)
+		})
+	}
+}
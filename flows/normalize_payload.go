@@ -0,0 +1,124 @@
+package flows
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// Detection controls whether NormalizePayload applies a decoding step.
+type Detection int
+
+const (
+	// DetectAuto applies the decoding step if the payload appears to be encoded.
+	DetectAuto Detection = iota
+
+	// DetectAlways applies the decoding step to every payload, failing if it is not encoded.
+	DetectAlways
+
+	// DetectNever never applies the decoding step.
+	DetectNever
+)
+
+// PayloadConfig overrides the detection of encodings by NormalizePayload.
+type PayloadConfig struct {
+	// Base64 controls decoding of standard base64 encoded payloads
+	// If not specified, payloads are decoded if they appear to be base64 encoded
+	Base64 Detection
+
+	// Gzip controls decompression of gzip compressed payloads, after base64 decoding
+	// If not specified, payloads are decompressed if they start with the gzip header
+	Gzip Detection
+}
+
+// gzipMagic are the first bytes of every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// NormalizePayload creates a Flow that decodes string payloads that are base64 encoded, gzip
+// compressed, or both, into raw bytes. Payloads such as CloudWatch Logs subscription data and
+// Kinesis records are delivered in these encodings, and this flow removes the glue code for
+// decoding them. Payloads that are not encoded are passed through as bytes.
+//
+// A payload is detected as base64 if it only contains characters of the standard base64
+// alphabet, and decodes to gzip compressed data or valid UTF-8 text. Detection is a heuristic,
+// so set config.Base64 to DetectAlways or DetectNever when the encoding of the payloads is
+// known. If a payload cannot be decoded, an error is emitted.
+//
+// Parameters:
+//   - config: Overrides for the detection of encodings
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that transforms encoded payloads into raw bytes
+func NormalizePayload(
+	config PayloadConfig,
+	opts ...core.FlowOption,
+) *core.Flow[string, []byte] {
+	return TryMap(func(ctx context.Context, payload string) ([]byte, error) {
+		return normalizePayload(payload, config)
+	}, opts...)
+}
+
+// normalizePayload decodes a single payload according to config.
+func normalizePayload(payload string, config PayloadConfig) ([]byte, error) {
+	data := []byte(payload)
+
+	switch config.Base64 {
+	case DetectAlways:
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(payload))
+		if err != nil {
+			return nil, fmt.Errorf("decoding base64 payload: %w", err)
+		}
+		data = decoded
+	case DetectAuto:
+		if decoded, ok := detectBase64(payload); ok {
+			data = decoded
+		}
+	}
+
+	switch config.Gzip {
+	case DetectAlways:
+		return gunzip(data)
+	case DetectAuto:
+		if bytes.HasPrefix(data, gzipMagic) {
+			return gunzip(data)
+		}
+	}
+	return data, nil
+}
+
+// detectBase64 decodes payload if it appears to be base64 encoded.
+func detectBase64(payload string) ([]byte, bool) {
+	payload = strings.TrimSpace(payload)
+	if payload == "" || len(payload)%4 != 0 {
+		return nil, false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, false
+	}
+	if bytes.HasPrefix(decoded, gzipMagic) || utf8.Valid(decoded) {
+		return decoded, true
+	}
+	return nil, false
+}
+
+// gunzip decompresses gzip compressed data.
+func gunzip(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing gzip payload: %w", err)
+	}
+	defer r.Close()
+	res, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing gzip payload: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,111 @@
+package flows
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+// gzipString compresses s with gzip.
+func gzipString(t *testing.T, s string) string {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(s))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return buf.String()
+}
+
+func TestNormalizePayload(t *testing.T) {
+	payload := `{"logEvents":[{"message":"hello"}]}`
+	b64 := base64.StdEncoding.EncodeToString([]byte(payload))
+	gz := gzipString(t, payload)
+	b64gz := base64.StdEncoding.EncodeToString([]byte(gz))
+
+	tests := []struct {
+		name        string
+		config      PayloadConfig
+		input       []string
+		expected    []string
+		expectedErr bool
+	}{
+		{
+			name:     "passes through raw payloads",
+			input:    []string{payload, "test"},
+			expected: []string{payload, "test"},
+		},
+		{
+			name:     "detects base64",
+			input:    []string{b64},
+			expected: []string{payload},
+		},
+		{
+			name:     "detects gzip",
+			input:    []string{gz},
+			expected: []string{payload},
+		},
+		{
+			name:     "detects base64 encoded gzip",
+			input:    []string{b64gz},
+			expected: []string{payload},
+		},
+		{
+			name:     "does not decode base64 when disabled",
+			config:   PayloadConfig{Base64: DetectNever},
+			input:    []string{b64},
+			expected: []string{b64},
+		},
+		{
+			name:     "does not decompress gzip when disabled",
+			config:   PayloadConfig{Gzip: DetectNever},
+			input:    []string{b64gz},
+			expected: []string{gz},
+		},
+		{
+			name:     "always decodes base64 when forced",
+			config:   PayloadConfig{Base64: DetectAlways},
+			input:    []string{base64.StdEncoding.EncodeToString([]byte{0xff, 0xfe})},
+			expected: []string{string([]byte{0xff, 0xfe})},
+		},
+		{
+			name:        "fails on invalid forced base64",
+			config:      PayloadConfig{Base64: DetectAlways},
+			input:       []string{payload},
+			expected:    []string{},
+			expectedErr: true,
+		},
+		{
+			name:        "fails on invalid forced gzip",
+			config:      PayloadConfig{Gzip: DetectAlways},
+			input:       []string{payload},
+			expected:    []string{},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(tt.input),
+				NormalizePayload(tt.config),
+				sinks.Slice[[]byte](),
+			)
+
+			res := <-stream.Run(context.Background())
+
+			got := make([]string, len(res.Value))
+			for i, b := range res.Value {
+				got[i] = string(b)
+			}
+			assert.Equal(t, tt.expected, got)
+			assert.Equal(t, tt.expectedErr, res.Err != nil)
+		})
+	}
+}
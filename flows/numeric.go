@@ -0,0 +1,113 @@
+package flows
+
+import (
+	"context"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// Number is the constraint satisfied by the integer and floating-point types that numeric
+// flows such as MovingAverage and EWMA operate on.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// movingAverage holds the state of MovingAverage.
+type movingAverage struct {
+	window []float64 // ring buffer of the items in the window
+	next   int       // index in window the next item is written to
+	sum    float64
+}
+
+// MovingAverage creates a Flow that emits, for every item, the simple moving average of the
+// last windowSize items including it. Until windowSize items have been received, the average
+// of all items received so far is emitted.
+//
+// Type Parameters:
+//   - N: The numeric type of input items
+//
+// Parameters:
+//   - windowSize: Number of most recent items to average, must be positive
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that emits the moving average of the items
+func MovingAverage[N Number](
+	windowSize int,
+	opts ...core.FlowOption,
+) *core.Flow[N, float64] {
+	return StatefulMap(
+		func() *movingAverage {
+			return &movingAverage{window: make([]float64, 0, windowSize)}
+		},
+		func(ctx context.Context, state *movingAverage, elem N) (*movingAverage, []float64) {
+			v := float64(elem)
+			if len(state.window) < windowSize {
+				state.window = append(state.window, v)
+			} else {
+				state.sum -= state.window[state.next]
+				state.window[state.next] = v
+			}
+			state.next = (state.next + 1) % windowSize
+			state.sum += v
+			return state, []float64{state.sum / float64(len(state.window))}
+		},
+		nil,
+		opts...)
+}
+
+// EWMA creates a Flow that emits, for every item, the exponentially weighted moving average
+// of the items received so far. Each item x updates the average to alpha*x + (1-alpha)*avg,
+// so a larger alpha discounts older items faster. The first item initializes the average.
+//
+// Type Parameters:
+//   - N: The numeric type of input items
+//
+// Parameters:
+//   - alpha: Smoothing factor in the range (0, 1]
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that emits the exponentially weighted moving average of the items
+func EWMA[N Number](
+	alpha float64,
+	opts ...core.FlowOption,
+) *core.Flow[N, float64] {
+	return StatefulMap(
+		func() *float64 { return nil },
+		func(ctx context.Context, avg *float64, elem N) (*float64, []float64) {
+			v := float64(elem)
+			if avg != nil {
+				v = alpha*v + (1-alpha)**avg
+			}
+			return &v, []float64{v}
+		},
+		nil,
+		opts...)
+}
+
+// RateOfChange creates a Flow that emits, for every item, the difference between it and the
+// item before it, such as the increase of a counter between two polls. Nothing is emitted for
+// the first item, as it has no predecessor. Use Pairwise to compute other deltas.
+//
+// Type Parameters:
+//   - N: The numeric type of input items
+//
+// Parameters:
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that emits the differences between consecutive items
+func RateOfChange[N Number](
+	opts ...core.FlowOption,
+) *core.Flow[N, float64] {
+	return StatefulMap(
+		func() *N { return nil },
+		func(ctx context.Context, prev *N, elem N) (*N, []float64) {
+			if prev == nil {
+				return &elem, nil
+			}
+			return &elem, []float64{float64(elem) - float64(*prev)}
+		},
+		nil,
+		opts...)
+}
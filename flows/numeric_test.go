@@ -0,0 +1,84 @@
+package flows
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestNumeric(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []int
+		flow     *core.Flow[int, float64]
+		expected []float64
+	}{
+		{
+			name:     "moving average fills the window",
+			input:    []int{2, 4, 6, 8, 10},
+			flow:     MovingAverage[int](3),
+			expected: []float64{2, 3, 4, 6, 8},
+		},
+		{
+			name:     "moving average of a single item window",
+			input:    []int{1, 5, 3},
+			flow:     MovingAverage[int](1),
+			expected: []float64{1, 5, 3},
+		},
+		{
+			name:     "moving average of empty input",
+			input:    []int{},
+			flow:     MovingAverage[int](3),
+			expected: []float64{},
+		},
+		{
+			name:     "ewma starts at the first item",
+			input:    []int{10, 20, 20},
+			flow:     EWMA[int](0.5),
+			expected: []float64{10, 15, 17.5},
+		},
+		{
+			name:     "ewma with alpha 1 follows the items",
+			input:    []int{1, 7, 3},
+			flow:     EWMA[int](1),
+			expected: []float64{1, 7, 3},
+		},
+		{
+			name:     "rate of change between consecutive items",
+			input:    []int{10, 15, 12, 12},
+			flow:     RateOfChange[int](),
+			expected: []float64{5, -3, 0},
+		},
+		{
+			name:     "rate of change of a single item",
+			input:    []int{10},
+			flow:     RateOfChange[int](),
+			expected: []float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(tt.input),
+				tt.flow,
+				sinks.Slice[float64](),
+			)
+
+			// runs do not share state
+			for range 2 {
+				res := <-stream.Run(context.Background())
+				stream.AwaitDone()
+
+				require.NoError(t, res.Err)
+				assert.InDeltaSlice(t, tt.expected, res.Value, 1e-9)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package flows
+
+import (
+	"context"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// Consecutive holds two consecutive items, as emitted by Pairwise.
+type Consecutive[T any] struct {
+	// Previous is the item received before Current
+	Previous T
+
+	// Current is the item just received
+	Current T
+}
+
+// Pairwise creates a Flow that emits every item together with the item before it, so
+// downstream stages can compute deltas between consecutive items, such as the rate of
+// change of a polled metric. Nothing is emitted for the first item, as it has no predecessor.
+//
+// Type Parameters:
+//   - I: The type of items to pair
+//
+// Parameters:
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that emits pairs of consecutive items
+func Pairwise[I any](
+	opts ...core.FlowOption,
+) *core.Flow[I, Consecutive[I]] {
+	var prev I
+	seen := false
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[Consecutive[I]]) core.StreamAction {
+			if seen {
+				util.Send(ctx, core.Item[Consecutive[I]]{Value: Consecutive[I]{Previous: prev, Current: elem}}, out)
+			}
+			prev, seen = elem, true
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[Consecutive[I]]) {
+			var zero I
+			prev, seen = zero, false
+		},
+		opts...)
+}
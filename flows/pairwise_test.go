@@ -0,0 +1,52 @@
+package flows
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestPairwise(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []int
+		expected []Consecutive[int]
+	}{
+		{
+			name:     "pairs consecutive items",
+			input:    []int{1, 3, 6, 10},
+			expected: []Consecutive[int]{{Previous: 1, Current: 3}, {Previous: 3, Current: 6}, {Previous: 6, Current: 10}},
+		},
+		{
+			name:     "emits nothing for single item",
+			input:    []int{1},
+			expected: []Consecutive[int]{},
+		},
+		{
+			name:     "handles empty input",
+			input:    []int{},
+			expected: []Consecutive[int]{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(tt.input),
+				Pairwise[int](),
+				sinks.Slice[Consecutive[int]](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+	"golang.org/x/time/rate"
+)
+
+// ErrRateLimitExceeded is emitted by RateLimit and RateLimitByKey when a limiter can never
+// allow an item, because its burst is zero.
+var ErrRateLimitExceeded = errors.New("rate limit can never be satisfied")
+
+// RateLimit creates a Flow that waits on limiter before passing on each item. Unlike
+// Throttle, the limiter can be shared with code outside the stream, such as other streams
+// or request handlers calling the same API, so that they are limited together. Waiting is
+// interrupted when the stream is cancelled.
+//
+// If the limiter can never allow an item, because its burst is zero, ErrRateLimitExceeded is
+// emitted and the flow stops.
+//
+// Type Parameters:
+//   - I: The type of items
+//
+// Parameters:
+//   - limiter: The limiter to wait on for each item
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that limits the rate of items with limiter
+func RateLimit[I any](
+	limiter *rate.Limiter,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			return waitAndSend(ctx, limiter, elem, out)
+		},
+		nil,
+		nil,
+		nil,
+		opts...)
+}
+
+// RateLimitByKey creates a Flow that waits on a limiter per key before passing on each item,
+// for example to limit the requests per tenant. The limiter of a key is created by calling
+// newLimiter when the key is first seen. newLimiter may return a limiter shared with code
+// outside the stream.
+//
+// A limiter is kept while it is limiting its key, across runs of the flow. Once it has
+// refilled to its full burst it no longer holds back any item, so it is released when the
+// flow holds many limiters, and newLimiter is called again when its key is seen again. This
+// bounds the memory of long-lived flows with many distinct keys, such as tenant or user IDs,
+// by the number of keys that were active recently.
+//
+// Items are passed on in order, so an item waiting on the limiter of its key holds back the
+// items of other keys behind it. Waiting is interrupted when the stream is cancelled. If a
+// limiter can never allow an item, ErrRateLimitExceeded is emitted and the flow stops.
+//
+// Type Parameters:
+//   - I: The type of items
+//   - K: The type of the keys
+//
+// Parameters:
+//   - keyFn: Function that returns the key of an item
+//   - newLimiter: Function that creates the limiter of a key
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that limits the rate of items per key
+func RateLimitByKey[I any, K comparable](
+	keyFn func(I) K,
+	newLimiter func(key K) *rate.Limiter,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	// minLimiters is the number of limiters kept before rested ones are released
+	const minLimiters = 64
+
+	limiters := make(map[K]*rate.Limiter)
+	releaseAt := minLimiters
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			key := keyFn(elem)
+			limiter, ok := limiters[key]
+			if !ok {
+				if len(limiters) >= releaseAt {
+					releaseRested(limiters)
+					// Releasing again only once the limiters doubled keeps the cost per item constant
+					releaseAt = max(2*len(limiters), minLimiters)
+				}
+				limiter = newLimiter(key)
+				limiters[key] = limiter
+			}
+			return waitAndSend(ctx, limiter, elem, out)
+		},
+		nil,
+		nil,
+		nil,
+		opts...)
+}
+
+// releaseRested removes the limiters that have refilled to their full burst, as they behave
+// like newly created limiters.
+func releaseRested[K comparable](limiters map[K]*rate.Limiter) {
+	now := time.Now()
+	for key, limiter := range limiters {
+		if limiter.TokensAt(now) >= float64(limiter.Burst()) {
+			delete(limiters, key)
+		}
+	}
+}
+
+// waitAndSend waits on limiter and sends elem to out, emitting ErrRateLimitExceeded if the
+// limiter cannot allow it.
+func waitAndSend[I any](ctx context.Context, limiter *rate.Limiter, elem I, out chan<- core.Item[I]) core.StreamAction {
+	// Unlike limiter.Wait, a reservation waits until the stream is cancelled rather than
+	// failing early when the delay exceeds the deadline of ctx
+	r := limiter.Reserve()
+	if !r.OK() {
+		util.Send(ctx, core.Item[I]{Err: fmt.Errorf("%w: burst of %d", ErrRateLimitExceeded, limiter.Burst())}, out)
+		return core.ActionStop
+	}
+	if delay := r.Delay(); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			// Return the reservation, so it does not delay others sharing the limiter
+			r.Cancel()
+			return core.ActionStop
+		case <-timer.C:
+		}
+	}
+	util.Send(ctx, core.Item[I]{Value: elem}, out)
+	return core.ActionProceed
+}
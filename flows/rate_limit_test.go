@@ -0,0 +1,133 @@
+package flows
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       []int
+		newFlow     func() *core.Flow[int, int]
+		minDuration time.Duration
+		maxDuration time.Duration
+		expectedErr error
+	}{
+		{
+			name:  "limits the rate of items",
+			input: []int{1, 2, 3, 4},
+			newFlow: func() *core.Flow[int, int] {
+				return RateLimit[int](rate.NewLimiter(rate.Every(20*time.Millisecond), 1))
+			},
+			minDuration: 60 * time.Millisecond,
+		},
+		{
+			name:  "passes bursts without waiting",
+			input: []int{1, 2, 3, 4},
+			newFlow: func() *core.Flow[int, int] {
+				return RateLimit[int](rate.NewLimiter(rate.Every(time.Second), 4))
+			},
+			maxDuration: 500 * time.Millisecond,
+		},
+		{
+			name:  "fails if the limiter cannot allow items",
+			input: []int{1},
+			newFlow: func() *core.Flow[int, int] {
+				return RateLimit[int](rate.NewLimiter(rate.Every(time.Second), 0))
+			},
+			expectedErr: ErrRateLimitExceeded,
+		},
+		{
+			name:  "limits the rate per key",
+			input: []int{1, 2, 3, 4},
+			newFlow: func() *core.Flow[int, int] {
+				return RateLimitByKey(
+					func(i int) bool { return i%2 == 0 },
+					func(key bool) *rate.Limiter { return rate.NewLimiter(rate.Every(50*time.Millisecond), 1) },
+				)
+			},
+			minDuration: 50 * time.Millisecond,
+			maxDuration: 90 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(tt.input),
+				tt.newFlow(),
+				sinks.Slice[int](),
+			)
+
+			start := time.Now()
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+			elapsed := time.Since(start)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.input, res.Value)
+			assert.GreaterOrEqual(t, elapsed, tt.minDuration)
+			if tt.maxDuration > 0 {
+				assert.Less(t, elapsed, tt.maxDuration)
+			}
+		})
+	}
+}
+
+func TestRateLimitCancel(t *testing.T) {
+	stream := compose.SourceThroughFlowToSink(
+		sources.Slice([]int{1, 2}),
+		RateLimit[int](rate.NewLimiter(rate.Every(time.Hour), 1)),
+		sinks.Slice[int](),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	res := <-stream.Run(ctx)
+	stream.AwaitDone()
+
+	assert.ErrorIs(t, res.Err, context.DeadlineExceeded)
+}
+
+func TestRateLimitByKeyReleasesRestedLimiters(t *testing.T) {
+	keys := make([]int, 200)
+	for i := range keys {
+		keys[i] = i
+	}
+
+	var created atomic.Int64
+	flow := RateLimitByKey(
+		func(i int) int { return i },
+		func(key int) *rate.Limiter {
+			created.Add(1)
+			// refills right after every item
+			return rate.NewLimiter(rate.Every(time.Microsecond), 1)
+		},
+	)
+	stream := compose.SourceThroughFlowToSink(sources.Slice(keys), flow, sinks.Slice[int]())
+
+	for range 2 {
+		res := <-stream.Run(context.Background())
+		stream.AwaitDone()
+		require.NoError(t, res.Err)
+		assert.Equal(t, keys, res.Value)
+	}
+
+	// Without releasing, every key would have kept its limiter from the first run
+	assert.Greater(t, created.Load(), int64(len(keys)))
+}
@@ -0,0 +1,40 @@
+package flows
+
+import (
+	"context"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// Recover creates a Flow that converts errors received from upstream into fallback items
+// and continues processing, instead of stopping the stream. For every error, fn returns the
+// item to emit in its place and true, or false to drop the error. Items pass through
+// unchanged. Recovered errors are reported to the listeners registered with Stream.OnError
+// with core.ErrorHandlingRecovered.
+//
+// Type Parameters:
+//   - I: The type of items
+//
+// Parameters:
+//   - fn: Function returning the fallback item for an error, and whether to emit it
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that replaces errors with fallback items
+func Recover[I any](
+	fn func(err error) (I, bool),
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	return core.NewSyncFlow(
+		func(ctx context.Context, elem I, emit func(core.Item[I])) core.StreamAction {
+			emit(core.Item[I]{Value: elem})
+			return core.ActionProceed
+		},
+		func(ctx context.Context, err error, emit func(core.Item[I])) core.StreamAction {
+			core.ReportError(ctx, "Recover", core.ErrorHandlingRecovered, err)
+			if fallback, ok := fn(err); ok {
+				emit(core.Item[I]{Value: fallback})
+			}
+			return core.ActionProceed
+		},
+		opts...)
+}
@@ -0,0 +1,77 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestRecover(t *testing.T) {
+	errNotFound := errors.New("not found")
+	errFatal := errors.New("fatal")
+	parse := func(ctx context.Context, i int) (int, error) {
+		switch i {
+		case 2:
+			return 0, errNotFound
+		case 4:
+			return 0, errFatal
+		}
+		return i, nil
+	}
+
+	tests := []struct {
+		name     string
+		fn       func(err error) (int, bool)
+		expected []int
+	}{
+		{
+			name:     "replaces errors with fallback items",
+			fn:       func(err error) (int, bool) { return -1, true },
+			expected: []int{1, -1, 3, -1, 5},
+		},
+		{
+			name:     "drops errors",
+			fn:       func(err error) (int, bool) { return 0, false },
+			expected: []int{1, 3, 5},
+		},
+		{
+			name: "decides per error",
+			fn: func(err error) (int, bool) {
+				return 0, errors.Is(err, errNotFound)
+			},
+			expected: []int{1, 0, 3, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink2(
+				sources.Slice([]int{1, 2, 3, 4, 5}),
+				TryMap(parse),
+				Recover(tt.fn),
+				sinks.Slice[int](),
+			)
+
+			var events []core.ErrorEvent
+			stream.OnError(func(ctx context.Context, event core.ErrorEvent) {
+				events = append(events, event)
+			})
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+			require.Len(t, events, 2)
+			assert.Equal(t, core.ErrorHandlingRecovered, events[0].Handling)
+			assert.ErrorIs(t, events[0].Err, errNotFound)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package flows
+
+import (
+	"context"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// RecoverWithSource creates a Flow that switches to a fallback Source when upstream fails,
+// for example to fall back from a cache to the origin, or from a primary to a secondary queue.
+// Items pass through unchanged until an error is received from upstream, after which upstream
+// is no longer read and the items of the Source returned by fallback are emitted instead. The
+// flow completes once the fallback Source completes.
+//
+// If the fallback Source fails as well, fallback is called again with its error, up to
+// maxSwitches switches in total. A negative maxSwitches allows unlimited switches. Once the
+// switches are exhausted, the error is passed downstream and the flow stops. Every switch is
+// reported to the listeners registered with Stream.OnError with core.ErrorHandlingRecovered.
+//
+// Type Parameters:
+//   - I: The type of items
+//
+// Parameters:
+//   - fallback: Function returning the Source to switch to for an error
+//   - maxSwitches: Maximum number of switches to a fallback Source, or negative for no limit
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that switches to a fallback Source on failure
+func RecoverWithSource[I any](
+	fallback func(err error) *core.Source[I],
+	maxSwitches int,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	switches := 0
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			util.Send(ctx, core.Item[I]{Value: elem}, out)
+			return core.ActionProceed
+		},
+		func(ctx context.Context, err error, out chan<- core.Item[I]) core.StreamAction {
+			for maxSwitches < 0 || switches < maxSwitches {
+				switches++
+				core.ReportError(ctx, "RecoverWithSource", core.ErrorHandlingRecovered, err)
+				if err = runFallback(ctx, fallback(err), out); err == nil {
+					return core.ActionComplete
+				}
+				if ctx.Err() != nil {
+					return core.ActionStop
+				}
+			}
+			util.Send(ctx, core.Item[I]{Err: err}, out)
+			return core.ActionStop
+		},
+		nil,
+		func(ctx context.Context, out chan<- core.Item[I]) {
+			switches = 0
+		},
+		opts...)
+}
+
+// runFallback runs source until it completes or fails, forwarding its items to out and
+// returning the error it failed with.
+func runFallback[O any](ctx context.Context, source *core.Source[O], out chan<- core.Item[O]) error {
+	stream := core.ConnectSourceToSink(source, core.NewSink(
+		struct{}{},
+		func(ctx context.Context, elem O, acc core.Item[struct{}]) (core.Item[struct{}], core.StreamAction) {
+			util.Send(ctx, core.Item[O]{Value: elem}, out)
+			return acc, core.ActionProceed
+		},
+		func(ctx context.Context, err error, acc core.Item[struct{}]) (core.Item[struct{}], core.StreamAction) {
+			return core.Item[struct{}]{Err: err}, core.ActionStop
+		},
+		nil,
+	))
+	res := <-stream.Run(ctx)
+	stream.AwaitDone()
+	return res.Err
+}
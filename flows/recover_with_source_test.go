@@ -0,0 +1,151 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestRecoverWithSource(t *testing.T) {
+	errPrimary := errors.New("primary failed")
+	errFallback := errors.New("fallback failed")
+
+	// failing creates a Source emitting the given items followed by err
+	failing := func(err error, items ...int) *core.Source[int] {
+		return compose.SourceThroughFlow(
+			sources.Slice(append(items, 0)),
+			TryMap(func(ctx context.Context, i int) (int, error) {
+				if i == 0 {
+					return 0, err
+				}
+				return i, nil
+			}),
+		)
+	}
+
+	tests := []struct {
+		name          string
+		upstream      *core.Source[int]
+		fallback      func(calls int, err error) *core.Source[int]
+		maxSwitches   int
+		expected      []int
+		expectedErr   error
+		expectedCalls int
+	}{
+		{
+			name:     "passes items through without errors",
+			upstream: sources.Slice([]int{1, 2, 3}),
+			fallback: func(calls int, err error) *core.Source[int] {
+				return sources.Slice([]int{10})
+			},
+			maxSwitches: 1,
+			expected:    []int{1, 2, 3},
+		},
+		{
+			name:     "switches to the fallback on failure",
+			upstream: failing(errPrimary, 1, 2),
+			fallback: func(calls int, err error) *core.Source[int] {
+				return sources.Slice([]int{10, 11})
+			},
+			maxSwitches:   1,
+			expected:      []int{1, 2, 10, 11},
+			expectedCalls: 1,
+		},
+		{
+			name:     "switches again when the fallback fails",
+			upstream: failing(errPrimary, 1),
+			fallback: func(calls int, err error) *core.Source[int] {
+				if calls == 1 {
+					return failing(errFallback, 10)
+				}
+				return sources.Slice([]int{20})
+			},
+			maxSwitches:   2,
+			expected:      []int{1, 10, 20},
+			expectedCalls: 2,
+		},
+		{
+			name:     "fails once switches are exhausted",
+			upstream: failing(errPrimary, 1),
+			fallback: func(calls int, err error) *core.Source[int] {
+				return failing(errFallback, 10)
+			},
+			maxSwitches:   2,
+			expectedErr:   errFallback,
+			expectedCalls: 2,
+		},
+		{
+			name:     "fails without switches",
+			upstream: failing(errPrimary, 1),
+			fallback: func(calls int, err error) *core.Source[int] {
+				return sources.Slice([]int{10})
+			},
+			maxSwitches: 0,
+			expectedErr: errPrimary,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			var errs []error
+			stream := compose.SourceThroughFlowToSink(
+				tt.upstream,
+				RecoverWithSource(func(err error) *core.Source[int] {
+					calls++
+					errs = append(errs, err)
+					return tt.fallback(calls, err)
+				}, tt.maxSwitches),
+				sinks.Slice[int](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			assert.Equal(t, tt.expectedCalls, calls)
+			if calls > 0 {
+				assert.ErrorIs(t, errs[0], errPrimary)
+			}
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+		})
+	}
+}
+
+func TestRecoverWithSourceUnlimited(t *testing.T) {
+	errFailed := errors.New("failed")
+	calls := 0
+	stream := compose.SourceThroughFlowToSink2(
+		sources.Slice([]int{1}),
+		TryMap(func(ctx context.Context, i int) (int, error) { return 0, errFailed }),
+		RecoverWithSource(func(err error) *core.Source[int] {
+			calls++
+			if calls < 5 {
+				return compose.SourceThroughFlow(
+					sources.Slice([]int{calls}),
+					TryMap(func(ctx context.Context, i int) (int, error) { return 0, errFailed }),
+				)
+			}
+			return sources.Slice([]int{calls})
+		}, -1),
+		sinks.Slice[int](),
+	)
+
+	res := <-stream.Run(context.Background())
+	stream.AwaitDone()
+
+	require.NoError(t, res.Err)
+	assert.Equal(t, []int{5}, res.Value)
+	assert.Equal(t, 5, calls)
+}
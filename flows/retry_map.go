@@ -0,0 +1,88 @@
+package flows
+
+import (
+	"context"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/retry"
+	"github.com/svenvdam/linea/util"
+)
+
+// RetryMap creates a Flow that transforms each input item using a function that can fail,
+// retrying the function for that item with backoff until it succeeds. Unlike Retry, which
+// restarts the whole upstream, only the failed item is retried, which suits transient
+// per-item failures such as throttled requests.
+//
+// The backoff between attempts and the maximum number of retries are taken from config.
+// Every failed attempt that is retried is reported to the listeners registered with
+// Stream.OnError with core.ErrorHandlingRetried. Once the retries are exhausted, the last
+// error is emitted downstream in place of the result, like TryMap does, wrapped in an
+// ElementError recording the item and the number of attempts, so that it can be routed to a
+// dead letter queue using DivertTo. If the flow is configured with core.WithSupervision, its
+// Decider decides about that error instead. Items are processed one at a time, so a retried
+// item backpressures upstream during its backoff. As the flow waits for the backoff, it runs in
+// its own goroutine and is not fused with adjacent synchronous flows.
+//
+// Type Parameters:
+//   - I: The type of input items
+//   - O: The type of output items
+//
+// Parameters:
+//   - config: The retry configuration controlling backoff and max retries
+//   - fn: Function that transforms an input item into an output item or returns an error
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that transforms items, retrying failed items
+func RetryMap[I, O any](
+	config *retry.Config,
+	fn func(context.Context, I) (O, error),
+	opts ...core.FlowOption,
+) *core.Flow[I, O] {
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[O]) core.StreamAction {
+			for attempts := uint(0); ; attempts++ {
+				result, err := fn(ctx, elem)
+				if err == nil {
+					util.Send(ctx, core.Item[O]{Value: result}, out)
+					return core.ActionProceed
+				}
+
+				backoff, canRetry := config.NextBackoff(attempts)
+				if !canRetry {
+					return emitExhausted(ctx, &ElementError[I]{Elem: elem, Err: err, Attempts: int(attempts) + 1}, out)
+				}
+				core.ReportError(ctx, "RetryMap", core.ErrorHandlingRetried, err)
+
+				timer := time.NewTimer(backoff)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return core.ActionStop
+				case <-timer.C:
+				}
+			}
+		},
+		nil,
+		nil,
+		nil,
+		opts...)
+}
+
+// emitExhausted emits the error of an item whose retries are exhausted, unless the Decider of a
+// supervised flow decides otherwise, and returns the resulting StreamAction.
+func emitExhausted[O any](ctx context.Context, err error, out chan<- core.Item[O]) core.StreamAction {
+	decision, ok := core.Supervise(ctx, err)
+	switch {
+	case !ok:
+		util.Send(ctx, core.Item[O]{Err: err}, out)
+		return core.ActionProceed
+	case decision == core.DecisionResume:
+		return core.ActionProceed
+	case decision == core.DecisionRestart:
+		return core.ActionRestartUpstream
+	default:
+		util.Send(ctx, core.Item[O]{Err: err}, out)
+		return core.ActionStop
+	}
+}
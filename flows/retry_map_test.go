@@ -0,0 +1,163 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/retry"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestRetryMap(t *testing.T) {
+	errThrottled := errors.New("throttled")
+
+	tests := []struct {
+		name          string
+		maxRetries    uint
+		failures      map[int]int // number of times each item fails before succeeding
+		expected      []int
+		expectedErr   error
+		expectedCalls map[int]int
+		expectedRetry int
+	}{
+		{
+			name:          "maps items without failures",
+			maxRetries:    3,
+			failures:      map[int]int{},
+			expected:      []int{2, 4, 6},
+			expectedCalls: map[int]int{1: 1, 2: 1, 3: 1},
+		},
+		{
+			name:          "retries only the failed item",
+			maxRetries:    3,
+			failures:      map[int]int{2: 2},
+			expected:      []int{2, 4, 6},
+			expectedCalls: map[int]int{1: 1, 2: 3, 3: 1},
+			expectedRetry: 2,
+		},
+		{
+			name:          "emits the last error once retries are exhausted",
+			maxRetries:    1,
+			failures:      map[int]int{3: 5},
+			expectedErr:   errThrottled,
+			expectedCalls: map[int]int{1: 1, 2: 1, 3: 2},
+			expectedRetry: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := map[int]int{}
+			config := retry.NewConfig(time.Millisecond, 5*time.Millisecond, 0, retry.WithMaxRetries(tt.maxRetries))
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice([]int{1, 2, 3}),
+				RetryMap(config, func(ctx context.Context, i int) (int, error) {
+					calls[i]++
+					if calls[i] <= tt.failures[i] {
+						return 0, errThrottled
+					}
+					return i * 2, nil
+				}),
+				sinks.Slice[int](),
+			)
+
+			var mu sync.Mutex
+			var events []core.ErrorEvent
+			stream.OnError(func(ctx context.Context, event core.ErrorEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				events = append(events, event)
+			})
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, res.Err, tt.expectedErr)
+			} else {
+				assert.NoError(t, res.Err)
+				assert.Equal(t, tt.expected, res.Value)
+			}
+			assert.Equal(t, tt.expectedCalls, calls)
+			assert.Len(t, events, tt.expectedRetry)
+			for _, event := range events {
+				assert.Equal(t, core.ErrorHandlingRetried, event.Handling)
+				assert.ErrorIs(t, event.Err, errThrottled)
+			}
+		})
+	}
+
+	t.Run("stops backing off when cancelled", func(t *testing.T) {
+		config := retry.NewConfig(time.Hour, time.Hour, 0)
+		stream := compose.SourceThroughFlowToSink(
+			sources.Slice([]int{1}),
+			RetryMap(config, func(ctx context.Context, i int) (int, error) {
+				return 0, errThrottled
+			}),
+			sinks.Slice[int](),
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		res := <-stream.Run(ctx)
+		stream.AwaitDone()
+
+		assert.Error(t, res.Err)
+	})
+
+	t.Run("does not stall adjacent flows during backoff", func(t *testing.T) {
+		seen := make(chan struct{})
+		config := retry.NewConfig(time.Millisecond, time.Millisecond, 0, retry.WithMaxRetries(1000))
+		flow := core.ConnectFlows(
+			Map(func(ctx context.Context, i int) int {
+				if i == 2 {
+					close(seen)
+				}
+				return i
+			}),
+			RetryMap(config, func(ctx context.Context, i int) (int, error) {
+				// The first item is retried until the flow in front of RetryMap took the second one
+				select {
+				case <-seen:
+					return i, nil
+				default:
+					return 0, errThrottled
+				}
+			}),
+		)
+		stream := compose.SourceThroughFlowToSink(sources.Slice([]int{1, 2}), flow, sinks.Slice[int]())
+
+		res := <-stream.Run(context.Background())
+		stream.AwaitDone()
+
+		assert.NoError(t, res.Err)
+		assert.Equal(t, []int{1, 2}, res.Value)
+	})
+
+	t.Run("applies supervision to exhausted items", func(t *testing.T) {
+		config := retry.NewConfig(time.Millisecond, time.Millisecond, 0, retry.WithMaxRetries(1))
+		stream := compose.SourceThroughFlowToSink(
+			sources.Slice([]int{1, 2, 3}),
+			RetryMap(config, func(ctx context.Context, i int) (int, error) {
+				if i == 2 {
+					return 0, errThrottled
+				}
+				return i, nil
+			}, core.WithSupervision(core.ResumingDecider)),
+			sinks.Slice[int](),
+		)
+
+		res := <-stream.Run(context.Background())
+		stream.AwaitDone()
+
+		assert.NoError(t, res.Err)
+		assert.Equal(t, []int{1, 3}, res.Value)
+	})
+}
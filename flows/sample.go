@@ -0,0 +1,95 @@
+package flows
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// Sample creates a Flow that emits at most one item per interval, also known as
+// ThrottleLatest. It keeps the most recent item received during each interval and emits it
+// when the interval ends, discarding the items it replaced. Intervals in which no item was
+// received emit nothing. This suits dashboards and progress reporting from high-frequency
+// sources, where only the latest state matters.
+//
+// Upstream is never backpressured. Discarded items are reported to the stream's DropHandler,
+// if any, with reason core.DropReasonSampled. When upstream completes, the item kept for the
+// current interval is emitted before the flow completes.
+//
+// Type Parameters:
+//   - I: The type of items to sample
+//
+// Parameters:
+//   - interval: Duration of each interval
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that emits the latest item of every interval
+func Sample[I any](
+	interval time.Duration,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	var mu sync.Mutex
+	var latest I
+	var pending bool
+	// stop and stopped control the goroutine emitting the latest item, which is started
+	// with the first item of a run
+	var stop, stopped chan struct{}
+
+	// take returns the kept item, if any, and clears it
+	take := func() (I, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		elem, ok := latest, pending
+		var zero I
+		latest, pending = zero, false
+		return elem, ok
+	}
+
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			if stop == nil {
+				stop, stopped = make(chan struct{}), make(chan struct{})
+				go func() {
+					defer close(stopped)
+					ticker := time.NewTicker(interval)
+					defer ticker.Stop()
+					for {
+						select {
+						case <-ctx.Done():
+							return
+						case <-stop:
+							return
+						case <-ticker.C:
+							if elem, ok := take(); ok {
+								util.Send(ctx, core.Item[I]{Value: elem}, out)
+							}
+						}
+					}
+				}()
+			}
+
+			mu.Lock()
+			if pending {
+				core.ReportDrop(ctx, "Sample", core.DropReasonSampled, latest)
+			}
+			latest, pending = elem, true
+			mu.Unlock()
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[I]) {
+			if stop != nil {
+				close(stop)
+				<-stopped
+				stop, stopped = nil, nil
+			}
+			if elem, ok := take(); ok {
+				util.Send(ctx, core.Item[I]{Value: elem}, out)
+			}
+		},
+		opts...)
+}
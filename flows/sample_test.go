@@ -0,0 +1,87 @@
+package flows
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestSample(t *testing.T) {
+	tests := []struct {
+		name            string
+		bursts          [][]int
+		expected        []int
+		expectedDropped int64
+	}{
+		{
+			name:            "emits latest item of every interval",
+			bursts:          [][]int{{1, 2, 3}, {4, 5}, {6}},
+			expected:        []int{3, 5, 6},
+			expectedDropped: 3,
+		},
+		{
+			name:     "emits single items unchanged",
+			bursts:   [][]int{{1}, {2}, {3}},
+			expected: []int{1, 2, 3},
+		},
+		{
+			name:     "emits nothing without items",
+			bursts:   [][]int{{}, {}},
+			expected: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan int)
+			counter := core.NewDropCounter()
+			ctx := core.WithDropHandler(context.Background(), counter.Handle)
+
+			stream := compose.SourceThroughFlowToSink(
+				sources.Chan(ch),
+				Sample[int](50*time.Millisecond),
+				sinks.Slice[int](),
+			)
+			res := stream.Run(ctx)
+
+			for i, burst := range tt.bursts {
+				for _, elem := range burst {
+					ch <- elem
+				}
+				if i < len(tt.bursts)-1 {
+					time.Sleep(80 * time.Millisecond)
+				}
+			}
+			close(ch)
+
+			result := <-res
+			stream.AwaitDone()
+
+			require.NoError(t, result.Err)
+			assert.Equal(t, tt.expected, result.Value)
+			assert.Equal(t, tt.expectedDropped, counter.Count("Sample", core.DropReasonSampled))
+		})
+	}
+}
+
+func TestSampleCancel(t *testing.T) {
+	stream := compose.SourceThroughFlowToSink(
+		sources.Repeat(1),
+		Sample[int](10*time.Millisecond),
+		sinks.Slice[int](),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	res := <-stream.Run(ctx)
+	stream.AwaitDone()
+	assert.ErrorIs(t, res.Err, context.DeadlineExceeded)
+}
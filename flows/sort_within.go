@@ -0,0 +1,161 @@
+package flows
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// sortEntry is an item buffered by SortWithin.
+type sortEntry[I any] struct {
+	elem    I
+	arrived time.Time
+	emitted bool
+}
+
+// sortHeap is a min-heap of the items buffered by SortWithin, implementing heap.Interface.
+type sortHeap[I any] struct {
+	entries []*sortEntry[I]
+	less    func(a, b I) bool
+}
+
+func (h *sortHeap[I]) Len() int           { return len(h.entries) }
+func (h *sortHeap[I]) Less(i, j int) bool { return h.less(h.entries[i].elem, h.entries[j].elem) }
+func (h *sortHeap[I]) Swap(i, j int)      { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+func (h *sortHeap[I]) Push(x any)         { h.entries = append(h.entries, x.(*sortEntry[I])) }
+
+func (h *sortHeap[I]) Pop() any {
+	last := h.entries[len(h.entries)-1]
+	h.entries[len(h.entries)-1] = nil
+	h.entries = h.entries[:len(h.entries)-1]
+	return last
+}
+
+// SortWithin creates a Flow that sorts mildly out-of-order items, such as items merged from
+// several shards, within a bounded window. Items are buffered until windowSize items are held,
+// after which every new item makes the smallest buffered item, according to less, be emitted.
+// An item arriving more than windowSize items later than a smaller one is emitted out of order.
+//
+// If maxDelay is positive, items are additionally emitted at the latest maxDelay after they
+// arrived, together with all smaller buffered items, so that items are not held back while
+// upstream is quiet. The buffered items are emitted in order when upstream completes, or when
+// the stream is drained.
+//
+// Type Parameters:
+//   - I: The type of items
+//
+// Parameters:
+//   - windowSize: The maximum number of items buffered
+//   - maxDelay: The maximum time an item is buffered, or zero to only bound the window by size
+//   - less: Function reporting whether a sorts before b
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that sorts items within a bounded window
+func SortWithin[I any](
+	windowSize int,
+	maxDelay time.Duration,
+	less func(a, b I) bool,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	windowSize = max(windowSize, 1)
+
+	// mu guards the buffered items and serializes emitting them, so they keep their order
+	var mu sync.Mutex
+	h := &sortHeap[I]{less: less}
+	// arrivals holds the buffered items in order of arrival, to find the oldest one
+	var arrivals []*sortEntry[I]
+	// wake signals the goroutine emitting items on time that the oldest item changed. It is
+	// started with the first item of a run if maxDelay is positive.
+	var wake chan struct{}
+	var stop, stopped chan struct{}
+
+	// emitMin emits the smallest buffered item. It must be called with mu held.
+	emitMin := func(ctx context.Context, out chan<- core.Item[I]) {
+		e := heap.Pop(h).(*sortEntry[I])
+		e.emitted = true
+		for len(arrivals) > 0 && arrivals[0].emitted {
+			arrivals[0] = nil
+			arrivals = arrivals[1:]
+		}
+		util.Send(ctx, core.Item[I]{Value: e.elem}, out)
+	}
+
+	// emitExpired emits the smallest items until no buffered item is older than maxDelay,
+	// returning the time the oldest remaining item expires. It must be called with mu held.
+	emitExpired := func(ctx context.Context, out chan<- core.Item[I]) (time.Time, bool) {
+		for len(arrivals) > 0 {
+			expires := arrivals[0].arrived.Add(maxDelay)
+			if time.Now().Before(expires) {
+				return expires, true
+			}
+			emitMin(ctx, out)
+		}
+		return time.Time{}, false
+	}
+
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			if maxDelay > 0 && stop == nil {
+				wake, stop, stopped = make(chan struct{}, 1), make(chan struct{}), make(chan struct{})
+				go func() {
+					defer close(stopped)
+					timer := time.NewTimer(maxDelay)
+					timer.Stop()
+					defer timer.Stop()
+					for {
+						select {
+						case <-ctx.Done():
+							return
+						case <-stop:
+							return
+						case <-wake:
+						case <-timer.C:
+						}
+						mu.Lock()
+						expires, ok := emitExpired(ctx, out)
+						mu.Unlock()
+						if ok {
+							timer.Reset(time.Until(expires))
+						}
+					}
+				}()
+			}
+
+			mu.Lock()
+			e := &sortEntry[I]{elem: elem, arrived: time.Now()}
+			heap.Push(h, e)
+			arrivals = append(arrivals, e)
+			if h.Len() > windowSize {
+				emitMin(ctx, out)
+			}
+			first := len(arrivals) == 1 && arrivals[0] == e
+			mu.Unlock()
+
+			if first && wake != nil {
+				select {
+				case wake <- struct{}{}:
+				default: // the goroutine has yet to take the previous signal
+				}
+			}
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[I]) {
+			if stop != nil {
+				close(stop)
+				<-stopped
+				wake, stop, stopped = nil, nil, nil
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for h.Len() > 0 {
+				emitMin(ctx, out)
+			}
+		},
+		opts...)
+}
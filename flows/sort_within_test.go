@@ -0,0 +1,99 @@
+package flows
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestSortWithin(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+
+	tests := []struct {
+		name       string
+		input      []int
+		windowSize int
+		expected   []int
+	}{
+		{
+			name:       "sorts items within the window",
+			input:      []int{2, 1, 4, 3, 6, 5},
+			windowSize: 2,
+			expected:   []int{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:       "emits items beyond the window out of order",
+			input:      []int{5, 6, 7, 1},
+			windowSize: 2,
+			expected:   []int{5, 1, 6, 7},
+		},
+		{
+			name:       "sorts all items if the window holds them",
+			input:      []int{3, 1, 2},
+			windowSize: 10,
+			expected:   []int{1, 2, 3},
+		},
+		{
+			name:       "handles empty input",
+			input:      []int{},
+			windowSize: 2,
+			expected:   []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink(
+				sources.Slice(tt.input),
+				SortWithin(tt.windowSize, 0, less),
+				sinks.Slice[int](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+		})
+	}
+}
+
+func TestSortWithinMaxDelay(t *testing.T) {
+	ch := make(chan int)
+	var mu sync.Mutex
+	var emitted []int
+
+	stream := compose.SourceThroughFlowToSink(
+		sources.Chan(ch),
+		SortWithin(10, 30*time.Millisecond, func(a, b int) bool { return a < b }),
+		sinks.ForEach(func(ctx context.Context, i int) {
+			mu.Lock()
+			defer mu.Unlock()
+			emitted = append(emitted, i)
+		}),
+	)
+	res := stream.Run(context.Background())
+
+	ch <- 2
+	ch <- 1
+	// Both items are emitted in order while upstream is quiet
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(emitted) == 2
+	}, time.Second, 5*time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, []int{1, 2}, emitted)
+	mu.Unlock()
+
+	close(ch)
+	require.NoError(t, (<-res).Err)
+	stream.AwaitDone()
+}
@@ -0,0 +1,66 @@
+package flows
+
+import (
+	"context"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// StatefulMap creates a Flow that transforms items using state carried from one item to the
+// next, such as a counter, a set of seen keys or the current run of a run-length encoding.
+// For every item, fn receives the current state and returns the new state together with the
+// items to emit, which may be none, so that stateful filters such as deduplication fit as well.
+// When upstream completes or the stream is drained, onComplete receives the final state and
+// returns the items to emit before the flow completes, so no state is lost.
+//
+// The state is created by initial whenever the stream is run, so runs do not share state.
+//
+// Type Parameters:
+//   - S: The type of the state
+//   - I: The type of input items
+//   - O: The type of output items
+//
+// Parameters:
+//   - initial: Function creating the state at the start of a run
+//   - fn: Function transforming an item using the state, returning the new state and the output items
+//   - onComplete: Function returning the items to emit for the final state, or nil to emit nothing
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that transforms items using state
+func StatefulMap[S, I, O any](
+	initial func() S,
+	fn func(ctx context.Context, state S, elem I) (S, []O),
+	onComplete func(ctx context.Context, state S) []O,
+	opts ...core.FlowOption,
+) *core.Flow[I, O] {
+	var state S
+	started := false
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[O]) core.StreamAction {
+			if !started {
+				state, started = initial(), true
+			}
+			var res []O
+			state, res = fn(ctx, state, elem)
+			for _, r := range res {
+				util.Send(ctx, core.Item[O]{Value: r}, out)
+			}
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[O]) {
+			if !started {
+				state = initial()
+			}
+			if onComplete != nil {
+				for _, res := range onComplete(ctx, state) {
+					util.Send(ctx, core.Item[O]{Value: res}, out)
+				}
+			}
+			var zero S
+			state, started = zero, false
+		},
+		opts...)
+}
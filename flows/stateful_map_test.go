@@ -0,0 +1,116 @@
+package flows
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+// runLength is the state of a run-length encoding.
+type runLength struct {
+	value string
+	count int
+}
+
+// encode returns the encoded form of a run, or nothing for an empty run.
+func (r runLength) encode() []string {
+	if r.count == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("%d%s", r.count, r.value)}
+}
+
+func TestStatefulMap(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []string
+		flow  func() *core.Flow[string, string]
+		want  []string
+	}{
+		{
+			name:  "numbers items with counter",
+			input: []string{"a", "b", "c"},
+			flow: func() *core.Flow[string, string] {
+				return StatefulMap(
+					func() int { return 0 },
+					func(ctx context.Context, n int, elem string) (int, []string) {
+						return n + 1, []string{fmt.Sprintf("%d:%s", n+1, elem)}
+					},
+					nil,
+				)
+			},
+			want: []string{"1:a", "2:b", "3:c"},
+		},
+		{
+			name:  "deduplicates items",
+			input: []string{"a", "b", "a", "c", "b"},
+			flow: func() *core.Flow[string, string] {
+				return StatefulMap(
+					func() map[string]bool { return map[string]bool{} },
+					func(ctx context.Context, seen map[string]bool, elem string) (map[string]bool, []string) {
+						if seen[elem] {
+							return seen, nil
+						}
+						seen[elem] = true
+						return seen, []string{elem}
+					},
+					nil,
+				)
+			},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name:  "flushes final state of run-length encoding",
+			input: []string{"a", "a", "b", "c", "c", "c"},
+			flow: func() *core.Flow[string, string] {
+				return StatefulMap(
+					func() runLength { return runLength{} },
+					func(ctx context.Context, r runLength, elem string) (runLength, []string) {
+						if r.count > 0 && r.value == elem {
+							return runLength{value: elem, count: r.count + 1}, nil
+						}
+						return runLength{value: elem, count: 1}, r.encode()
+					},
+					func(ctx context.Context, r runLength) []string {
+						return r.encode()
+					},
+				)
+			},
+			want: []string{"2a", "1b", "3c"},
+		},
+		{
+			name:  "flushes initial state without items",
+			input: []string{},
+			flow: func() *core.Flow[string, string] {
+				return StatefulMap(
+					func() int { return 0 },
+					func(ctx context.Context, n int, elem string) (int, []string) { return n + 1, nil },
+					func(ctx context.Context, n int) []string { return []string{fmt.Sprintf("total %d", n)} },
+				)
+			},
+			want: []string{"total 0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink(sources.Slice(tt.input), tt.flow(), sinks.Slice[string]())
+
+			// Every run starts with a fresh state
+			for range 2 {
+				res := <-stream.Run(context.Background())
+				stream.AwaitDone()
+
+				require.NoError(t, res.Err)
+				assert.Equal(t, tt.want, res.Value)
+			}
+		})
+	}
+}
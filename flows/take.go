@@ -0,0 +1,56 @@
+package flows
+
+import (
+	"context"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// Take creates a Flow that emits the first n items and then completes upstream, so that
+// infinite sources stop cleanly. Items and errors upstream had already produced when it was
+// completed are discarded, and the flow completes once upstream has finished.
+//
+// Type Parameters:
+//   - I: The type of items to take
+//
+// Parameters:
+//   - n: The number of items to emit
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that emits at most n items
+func Take[I any](
+	n int,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	taken := 0
+	done := false
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			if done {
+				// Items upstream produced before it completed are discarded
+				return core.ActionProceed
+			}
+			if taken < n {
+				util.Send(ctx, core.Item[I]{Value: elem}, out)
+				taken++
+			}
+			if taken >= n {
+				done = true
+				return core.ActionComplete
+			}
+			return core.ActionProceed
+		},
+		func(ctx context.Context, err error, out chan<- core.Item[I]) core.StreamAction {
+			if done {
+				// Errors upstream produced before it completed do not fail the finished take
+				return core.ActionProceed
+			}
+			return core.DefaultFlowErrorHandler(ctx, err, out)
+		},
+		nil,
+		func(ctx context.Context, out chan<- core.Item[I]) {
+			taken, done = 0, false
+		},
+		opts...)
+}
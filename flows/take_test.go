@@ -0,0 +1,100 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+	"github.com/svenvdam/linea/util"
+)
+
+func TestTake(t *testing.T) {
+	tests := []struct {
+		name   string
+		source func() *core.Source[int]
+		n      int
+		want   []int
+	}{
+		{
+			name:   "takes first n items",
+			source: func() *core.Source[int] { return sources.Slice([]int{1, 2, 3, 4, 5}) },
+			n:      3,
+			want:   []int{1, 2, 3},
+		},
+		{
+			name:   "takes all items when fewer than n",
+			source: func() *core.Source[int] { return sources.Slice([]int{1, 2}) },
+			n:      3,
+			want:   []int{1, 2},
+		},
+		{
+			name:   "takes no items when n is zero",
+			source: func() *core.Source[int] { return sources.Slice([]int{1, 2}) },
+			n:      0,
+			want:   []int{},
+		},
+		{
+			name:   "completes infinite source",
+			source: func() *core.Source[int] { return sources.Repeat(7) },
+			n:      4,
+			want:   []int{7, 7, 7, 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceThroughFlowToSink(tt.source(), Take[int](tt.n), sinks.Slice[int]())
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.want, res.Value)
+		})
+	}
+}
+
+func TestTakeRerun(t *testing.T) {
+	stream := compose.SourceThroughFlowToSink(sources.Repeat(1), Take[int](2), sinks.Slice[int]())
+
+	for range 2 {
+		res := <-stream.Run(context.Background())
+		stream.AwaitDone()
+		require.NoError(t, res.Err)
+		assert.Equal(t, []int{1, 1}, res.Value)
+	}
+}
+
+func TestTakeIgnoresErrorsAfterCutoff(t *testing.T) {
+	errLate := errors.New("late")
+	stream := compose.SourceThroughFlowToSink2(
+		sources.Slice([]int{1, 2, 3}),
+		// emits an error right behind the last item taken
+		core.NewFlow(
+			func(ctx context.Context, elem int, out chan<- core.Item[int]) core.StreamAction {
+				util.Send(ctx, core.Item[int]{Value: elem}, out)
+				if elem == 2 {
+					util.Send(ctx, core.Item[int]{Err: errLate}, out)
+				}
+				return core.ActionProceed
+			},
+			nil,
+			nil,
+			nil,
+		),
+		Take[int](2),
+		sinks.Slice[int](),
+	)
+
+	res := <-stream.Run(context.Background())
+	stream.AwaitDone()
+
+	assert.NoError(t, res.Err)
+	assert.Equal(t, []int{1, 2}, res.Value)
+}
@@ -8,7 +8,10 @@ import (
 )
 
 // TakeWhile creates a Flow that emits items as long as the predicate returns true.
-// Once the predicate returns false for an item, the flow stops emitting items.
+// Once the predicate returns false for an item, the flow stops emitting items and completes
+// upstream, so that infinite sources stop cleanly. Items and errors upstream had already
+// produced when it was completed are discarded, and the flow completes once upstream has
+// finished.
 //
 // Type Parameters:
 //   - I: The type of items to check
@@ -22,16 +25,30 @@ func TakeWhile[I any](
 	pred func(I) bool,
 	opts ...core.FlowOption,
 ) *core.Flow[I, I] {
+	done := false
 	return core.NewFlow(
 		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			if done {
+				// Items upstream produced before it completed are discarded
+				return core.ActionProceed
+			}
 			if !pred(elem) {
-				return core.ActionStop
+				done = true
+				return core.ActionComplete
 			}
 			util.Send(ctx, core.Item[I]{Value: elem}, out)
 			return core.ActionProceed
 		},
+		func(ctx context.Context, err error, out chan<- core.Item[I]) core.StreamAction {
+			if done {
+				// Errors upstream produced before it completed do not fail the finished take
+				return core.ActionProceed
+			}
+			return core.DefaultFlowErrorHandler(ctx, err, out)
+		},
 		nil,
-		nil,
-		nil,
+		func(ctx context.Context, out chan<- core.Item[I]) {
+			done = false
+		},
 		opts...)
 }
@@ -2,13 +2,16 @@ package flows
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
 	"github.com/svenvdam/linea/sinks"
 	"github.com/svenvdam/linea/sources"
 	"github.com/svenvdam/linea/test"
+	"github.com/svenvdam/linea/util"
 )
 
 func TestTakeWhile(t *testing.T) {
@@ -62,3 +65,50 @@ func TestTakeWhile(t *testing.T) {
 		})
 	}
 }
+
+func TestTakeWhileCompletesInfiniteSource(t *testing.T) {
+	count := 0
+	stream := compose.SourceThroughFlowToSink2(
+		sources.Repeat(1),
+		Map(func(ctx context.Context, i int) int {
+			count++
+			return count
+		}),
+		TakeWhile(func(i int) bool { return i <= 3 }),
+		sinks.Slice[int](),
+	)
+
+	res := <-stream.Run(context.Background())
+	stream.AwaitDone()
+
+	assert.NoError(t, res.Err)
+	assert.Equal(t, []int{1, 2, 3}, res.Value)
+}
+
+func TestTakeWhileIgnoresErrorsAfterCutoff(t *testing.T) {
+	errLate := errors.New("late")
+	stream := compose.SourceThroughFlowToSink2(
+		sources.Slice([]int{1, 2, 3}),
+		// emits an error right behind the item ending the take
+		core.NewFlow(
+			func(ctx context.Context, elem int, out chan<- core.Item[int]) core.StreamAction {
+				util.Send(ctx, core.Item[int]{Value: elem}, out)
+				if elem == 3 {
+					util.Send(ctx, core.Item[int]{Err: errLate}, out)
+				}
+				return core.ActionProceed
+			},
+			nil,
+			nil,
+			nil,
+		),
+		TakeWhile(func(i int) bool { return i < 3 }),
+		sinks.Slice[int](),
+	)
+
+	res := <-stream.Run(context.Background())
+	stream.AwaitDone()
+
+	assert.NoError(t, res.Err)
+	assert.Equal(t, []int{1, 2}, res.Value)
+}
@@ -0,0 +1,68 @@
+package flows
+
+import (
+	"context"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// Tap creates a Flow that calls fn for each item as a side effect, such as logging or
+// updating metrics, and passes the item on unchanged. Errors are passed on unchanged as well,
+// leaving their handling to the stages downstream, so a Tap can be added anywhere in a
+// pipeline without changing its behavior.
+//
+// Type Parameters:
+//   - I: The type of items
+//
+// Parameters:
+//   - fn: Function called with each item
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that calls fn for each item
+func Tap[I any](
+	fn func(context.Context, I),
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	return core.NewSyncFlow(
+		func(ctx context.Context, elem I, emit func(core.Item[I])) core.StreamAction {
+			fn(ctx, elem)
+			emit(core.Item[I]{Value: elem})
+			return core.ActionProceed
+		},
+		passError[I],
+		opts...)
+}
+
+// TapError creates a Flow that calls fn for each error received from upstream as a side
+// effect, and passes the error on unchanged, leaving its handling to the stages downstream.
+// Items are passed on unchanged.
+//
+// Type Parameters:
+//   - I: The type of items
+//
+// Parameters:
+//   - fn: Function called with each error
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that calls fn for each error
+func TapError[I any](
+	fn func(context.Context, error),
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	return core.NewSyncFlow(
+		func(ctx context.Context, elem I, emit func(core.Item[I])) core.StreamAction {
+			emit(core.Item[I]{Value: elem})
+			return core.ActionProceed
+		},
+		func(ctx context.Context, err error, emit func(core.Item[I])) core.StreamAction {
+			fn(ctx, err)
+			return passError(ctx, err, emit)
+		},
+		opts...)
+}
+
+// passError passes an error received from upstream on unchanged and continues processing.
+func passError[I any](ctx context.Context, err error, emit func(core.Item[I])) core.StreamAction {
+	emit(core.Item[I]{Err: err})
+	return core.ActionProceed
+}
@@ -0,0 +1,85 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestTap(t *testing.T) {
+	errOdd := errors.New("odd")
+	failOdd := func(ctx context.Context, i int) (int, error) {
+		if i%2 == 1 {
+			return 0, errOdd
+		}
+		return i, nil
+	}
+
+	tests := []struct {
+		name           string
+		input          []int
+		newFlow        func(items *[]int, errs *[]error) *core.Flow[int, int]
+		expected       []int
+		expectedItems  []int
+		expectedErrors int
+	}{
+		{
+			name:  "Tap calls fn for each item",
+			input: []int{2, 4, 6},
+			newFlow: func(items *[]int, errs *[]error) *core.Flow[int, int] {
+				return Tap(func(ctx context.Context, i int) { *items = append(*items, i) })
+			},
+			expected:      []int{2, 4, 6},
+			expectedItems: []int{2, 4, 6},
+		},
+		{
+			name:  "Tap passes errors on",
+			input: []int{2, 3, 4, 5},
+			newFlow: func(items *[]int, errs *[]error) *core.Flow[int, int] {
+				return Tap(func(ctx context.Context, i int) { *items = append(*items, i) })
+			},
+			expected:      []int{2, 4},
+			expectedItems: []int{2, 4},
+		},
+		{
+			name:  "TapError calls fn for each error",
+			input: []int{1, 2, 3, 4},
+			newFlow: func(items *[]int, errs *[]error) *core.Flow[int, int] {
+				return TapError[int](func(ctx context.Context, err error) { *errs = append(*errs, err) })
+			},
+			expected:       []int{2, 4},
+			expectedErrors: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var items []int
+			var errs []error
+			stream := compose.SourceThroughFlowToSink3(
+				sources.Slice(tt.input),
+				TryMap(failOdd),
+				tt.newFlow(&items, &errs),
+				Map(func(ctx context.Context, i int) int { return i }, core.WithSupervision(core.ResumingDecider)),
+				sinks.Slice[int](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			assert.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+			assert.Equal(t, tt.expectedItems, items)
+			assert.Len(t, errs, tt.expectedErrors)
+			for _, err := range errs {
+				assert.ErrorIs(t, err, errOdd)
+			}
+		})
+	}
+}
@@ -8,44 +8,74 @@ import (
 	"github.com/svenvdam/linea/util"
 )
 
-// Throttle creates a Flow that limits the rate at which items pass through.
-// It allows n items to pass through per interval, holding back additional items
-// until the next interval begins.
+// Throttle creates a Flow that limits the rate at which items pass through using a token
+// bucket. The bucket holds up to burst tokens and is refilled with elements tokens per
+// duration. Every item takes a token, and while the bucket is empty the flow waits for the
+// next token, backpressuring upstream. This makes it suitable for calling rate-limited APIs,
+// for example by placing it before a MapPar stage.
+//
+// The bucket starts full, so up to burst items pass through immediately after the stream
+// starts or after a pause in which the bucket refilled.
 //
 // Type Parameters:
 //   - I: The type of items to throttle
 //
 // Parameters:
-//   - n: Maximum number of items allowed per interval
-//   - interval: Duration of each interval
+//   - elements: Number of tokens added to the bucket per duration
+//   - per: Duration over which elements tokens are added
+//   - burst: Maximum number of tokens in the bucket, at least 1
 //   - opts: Optional FlowOption functions to configure the flow
 //
 // Returns a Flow that throttles the rate of items
 func Throttle[I any](
-	n int,
-	interval time.Duration,
+	elements int,
+	per time.Duration,
+	burst int,
 	opts ...core.FlowOption,
 ) *core.Flow[I, I] {
-	remaining := n
-	ticker := time.NewTicker(interval)
+	burst = max(burst, 1)
+	interval := max(per/time.Duration(max(elements, 1)), 1)
+
+	tokens := burst
+	// refilled is the time the last token was added, or zero before the first item
+	var refilled time.Time
+
 	return core.NewFlow(
 		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
-			for remaining <= 0 {
+			now := time.Now()
+			if refilled.IsZero() {
+				refilled = now
+			}
+			if earned := int(now.Sub(refilled) / interval); earned > 0 {
+				tokens = min(burst, tokens+earned)
+				refilled = refilled.Add(time.Duration(earned) * interval)
+			}
+			if tokens == burst {
+				// A full bucket does not accumulate tokens
+				refilled = now
+			}
+
+			if tokens == 0 {
+				timer := time.NewTimer(time.Until(refilled.Add(interval)))
 				select {
 				case <-ctx.Done():
+					timer.Stop()
 					return core.ActionStop
-				case <-ticker.C:
-					remaining = n
+				case <-timer.C:
 				}
+				tokens = 1
+				refilled = refilled.Add(interval)
 			}
+
 			util.Send(ctx, core.Item[I]{Value: elem}, out)
-			remaining--
+			tokens--
 			return core.ActionProceed
 		},
 		nil,
 		nil,
 		func(ctx context.Context, out chan<- core.Item[I]) {
-			ticker.Stop()
+			tokens = burst
+			refilled = time.Time{}
 		},
 		opts...)
 }
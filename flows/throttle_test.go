@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/svenvdam/linea/compose"
 	"github.com/svenvdam/linea/sinks"
 	"github.com/svenvdam/linea/sources"
@@ -13,84 +14,90 @@ import (
 )
 
 func TestThrottle(t *testing.T) {
-	const allowedDeltaNanos = 10_000_000 // 10ms tolerance
-	var last *time.Time
+	const tolerance = 20 * time.Millisecond
 	tests := []struct {
 		name     string
-		n        int
-		interval time.Duration
+		elements int
+		per      time.Duration
+		burst    int
 		items    []int
-		check    func(t *testing.T, elem int)
+		// expected holds the time each item is expected to be emitted, relative to the first
+		expected []time.Duration
 	}{
 		{
 			name:     "throttles single item per interval",
-			n:        1,
-			interval: 100 * time.Millisecond,
-			items:    []int{1, 2, 3, 4, 5},
-			check: func(t *testing.T, elem int) {
-				now := time.Now()
-				if last == nil {
-					last = &now
-					return
-				}
-
-				expectedTime := last.Add(100 * time.Millisecond)
-
-				// Verify throttle interval is respected
-				assert.InDelta(t,
-					expectedTime.UnixNano(),
-					now.UnixNano(),
-					allowedDeltaNanos,
-				)
-
-				last = &now
-			},
+			elements: 1,
+			per:      50 * time.Millisecond,
+			burst:    1,
+			items:    []int{1, 2, 3, 4},
+			expected: []time.Duration{0, 50 * time.Millisecond, 100 * time.Millisecond, 150 * time.Millisecond},
 		},
 		{
-			name:     "allows multiple items per interval",
-			n:        3,
-			interval: 200 * time.Millisecond,
-			items:    []int{1, 2, 3, 4, 5, 6, 7, 8, 9},
-			check: func(t *testing.T, elem int) {
-				now := time.Now()
-
-				if last != nil {
-					if (elem-1)%3 == 0 { // first item of each batch
-						expectedTime := last.Add(200 * time.Millisecond)
-
-						assert.InDelta(t,
-							expectedTime.UnixNano(),
-							now.UnixNano(),
-							allowedDeltaNanos,
-						)
-					} else {
-						assert.InDelta(t,
-							last.UnixNano(),
-							now.UnixNano(),
-							10_000_000,
-						)
-					}
-				}
-
-				last = &now
-			},
+			name:     "emits burst immediately",
+			elements: 1,
+			per:      time.Second,
+			burst:    4,
+			items:    []int{1, 2, 3, 4},
+			expected: []time.Duration{0, 0, 0, 0},
+		},
+		{
+			name:     "spreads items at rate after burst",
+			elements: 20,
+			per:      time.Second,
+			burst:    2,
+			items:    []int{1, 2, 3, 4, 5},
+			expected: []time.Duration{0, 0, 50 * time.Millisecond, 100 * time.Millisecond, 150 * time.Millisecond},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			last = nil
-			ctx := context.Background()
-
+			var times []time.Time
 			stream := compose.SourceThroughFlowToSink2(
 				sources.Slice(tt.items),
-				Throttle[int](tt.n, tt.interval),
-				test.AssertEachItem(t, tt.check),
-				sinks.Noop[int](),
+				Throttle[int](tt.elements, tt.per, tt.burst),
+				test.AssertEachItem(t, func(t *testing.T, elem int) {
+					times = append(times, time.Now())
+				}),
+				sinks.Slice[int](),
 			)
 
-			res := <-stream.Run(ctx)
-			assert.NoError(t, res.Err)
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.items, res.Value)
+			require.Len(t, times, len(tt.expected))
+			for i, expected := range tt.expected {
+				assert.InDelta(t, expected, times[i].Sub(times[0]), float64(tolerance), "item %d", i)
+			}
 		})
 	}
 }
+
+func TestThrottleRefillsAfterPause(t *testing.T) {
+	ch := make(chan int)
+	var times []time.Time
+	stream := compose.SourceThroughFlowToSink2(
+		sources.Chan(ch),
+		Throttle[int](10, time.Second, 2),
+		test.AssertEachItem(t, func(t *testing.T, elem int) {
+			times = append(times, time.Now())
+		}),
+		sinks.Noop[int](),
+	)
+
+	res := stream.Run(context.Background())
+	ch <- 1
+	ch <- 2
+	// The bucket refills completely while no items arrive
+	time.Sleep(300 * time.Millisecond)
+	ch <- 3
+	ch <- 4
+	close(ch)
+
+	require.NoError(t, (<-res).Err)
+	stream.AwaitDone()
+	require.Len(t, times, 4)
+	assert.Less(t, times[3].Sub(times[2]), 50*time.Millisecond)
+}
@@ -0,0 +1,141 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+var (
+	// ErrCompletionTimeout is emitted by CompletionTimeout when upstream did not complete in time.
+	ErrCompletionTimeout = errors.New("completion timeout")
+
+	// ErrInitialTimeout is emitted by InitialTimeout when the first item did not arrive in time.
+	ErrInitialTimeout = errors.New("initial timeout")
+)
+
+// CompletionTimeout creates a Flow that fails the stream if upstream does not complete within
+// the given duration from the start of the run, enforcing a deadline on batch pipelines without
+// passing a context with a timeout to Stream.Run. Items pass through unchanged.
+//
+// Once the timeout elapses, an error wrapping ErrCompletionTimeout is emitted and the flow
+// stops. Following the standard error path, this fails the stream unless the error is handled
+// downstream.
+//
+// Type Parameters:
+//   - I: The type of items
+//
+// Parameters:
+//   - timeout: The maximum time until upstream completes
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that fails if upstream does not complete in time
+func CompletionTimeout[I any](
+	timeout time.Duration,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	return deadlineFlow[I](
+		timeout,
+		fmt.Errorf("%w: upstream did not complete within %s", ErrCompletionTimeout, timeout),
+		false,
+		opts...)
+}
+
+// InitialTimeout creates a Flow that fails the stream if its first item does not arrive within
+// the given duration from the start of the run, detecting upstreams that fail to start, such as
+// sources waiting on an unreachable service. Items pass through unchanged, and the timeout no
+// longer applies once the first item arrived.
+//
+// Once the timeout elapses, an error wrapping ErrInitialTimeout is emitted and the flow stops.
+// Following the standard error path, this fails the stream unless the error is handled
+// downstream.
+//
+// Type Parameters:
+//   - I: The type of items
+//
+// Parameters:
+//   - timeout: The maximum time until the first item arrives
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that fails if the first item does not arrive in time
+func InitialTimeout[I any](
+	timeout time.Duration,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	return deadlineFlow[I](
+		timeout,
+		fmt.Errorf("%w: no item within %s", ErrInitialTimeout, timeout),
+		true,
+		opts...)
+}
+
+// deadlineFlow creates a Flow emitting err and stopping once timeout has elapsed since the
+// start of the run. If untilFirst is set, the deadline is lifted once the first item arrives.
+func deadlineFlow[I any](
+	timeout time.Duration,
+	err error,
+	untilFirst bool,
+	opts ...core.FlowOption,
+) *core.Flow[I, I] {
+	var timedOut atomic.Bool
+	// stop and stopped control the goroutine watching the deadline
+	var stop, stopped chan struct{}
+
+	// lift stops the goroutine watching the deadline, if it is still running
+	lift := func() {
+		if stop != nil {
+			close(stop)
+			<-stopped
+			stop = nil
+		}
+	}
+
+	watch := func(ctx context.Context, out chan<- core.Item[I]) {
+		stop, stopped = make(chan struct{}), make(chan struct{})
+		go func(stop <-chan struct{}) {
+			defer close(stopped)
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+			select {
+			case <-ctx.Done():
+			case <-stop:
+			case <-timer.C:
+				timedOut.Store(true)
+				select {
+				case <-stop:
+				case <-ctx.Done():
+				case out <- core.Item[I]{Err: err}:
+				}
+			}
+		}(stop)
+	}
+
+	return core.NewFlowWithStart(
+		watch,
+		func(ctx context.Context, elem I, out chan<- core.Item[I]) core.StreamAction {
+			if timedOut.Load() {
+				return core.ActionStop
+			}
+			if untilFirst {
+				lift()
+				if timedOut.Load() {
+					// The deadline elapsed while lifting it
+					return core.ActionStop
+				}
+			}
+			util.Send(ctx, core.Item[I]{Value: elem}, out)
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[I]) {
+			lift()
+			timedOut.Store(false)
+		},
+		opts...)
+}
@@ -0,0 +1,88 @@
+package flows
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestTimeouts(t *testing.T) {
+	tests := []struct {
+		name        string
+		delays      []time.Duration
+		flow        *core.Flow[int, int]
+		expected    []int
+		expectedErr error
+	}{
+		{
+			name:     "CompletionTimeout passes streams completing in time",
+			delays:   []time.Duration{0, 10 * time.Millisecond},
+			flow:     CompletionTimeout[int](100 * time.Millisecond),
+			expected: []int{0, 1},
+		},
+		{
+			name:        "CompletionTimeout fails streams not completing in time",
+			delays:      []time.Duration{0, 40 * time.Millisecond, 40 * time.Millisecond, 40 * time.Millisecond},
+			flow:        CompletionTimeout[int](100 * time.Millisecond),
+			expectedErr: ErrCompletionTimeout,
+		},
+		{
+			name:     "InitialTimeout passes streams starting in time",
+			delays:   []time.Duration{10 * time.Millisecond, 100 * time.Millisecond},
+			flow:     InitialTimeout[int](50 * time.Millisecond),
+			expected: []int{0, 1},
+		},
+		{
+			name:        "InitialTimeout fails streams not starting in time",
+			delays:      []time.Duration{200 * time.Millisecond},
+			flow:        InitialTimeout[int](50 * time.Millisecond),
+			expectedErr: ErrInitialTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan int)
+			done := make(chan struct{})
+			go func() {
+				defer close(ch)
+				for i, delay := range tt.delays {
+					select {
+					case <-done:
+						return
+					case <-time.After(delay):
+					}
+					select {
+					case <-done:
+						return
+					case ch <- i:
+					}
+				}
+			}()
+
+			stream := compose.SourceThroughFlowToSink(
+				sources.Chan(ch),
+				tt.flow,
+				sinks.Slice[int](),
+			)
+
+			res := <-stream.Run(context.Background())
+			close(done)
+			stream.AwaitDone()
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+		})
+	}
+}
@@ -4,12 +4,12 @@ import (
 	"context"
 
 	"github.com/svenvdam/linea/core"
-	"github.com/svenvdam/linea/util"
 )
 
 // TryMap creates a Flow that transforms each input item into an output item
 // using the provided mapping function that can return errors.
-// If the mapping function returns an error for any item, the stream is cancelled.
+// If the mapping function returns an error for any item, the stream is cancelled, unless
+// the flow is configured with core.WithSupervision and its Decider decides otherwise.
 // Each item is processed independently.
 //
 // Type Parameters:
@@ -25,18 +25,16 @@ func TryMap[I, O any](
 	fn func(context.Context, I) (O, error),
 	opts ...core.FlowOption,
 ) *core.Flow[I, O] {
-	return core.NewFlow(
-		func(ctx context.Context, elem I, out chan<- core.Item[O]) core.StreamAction {
+	return core.NewSyncFlow(
+		func(ctx context.Context, elem I, emit func(core.Item[O])) core.StreamAction {
 			result, err := fn(ctx, elem)
 			if err != nil {
-				util.Send(ctx, core.Item[O]{Err: err}, out)
+				emit(core.Item[O]{Err: err})
 			} else {
-				util.Send(ctx, core.Item[O]{Value: result}, out)
+				emit(core.Item[O]{Value: result})
 			}
 			return core.ActionProceed
 		},
 		nil,
-		nil,
-		nil,
 		opts...)
 }
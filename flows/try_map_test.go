@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
 	"github.com/svenvdam/linea/sinks"
 	"github.com/svenvdam/linea/sources"
 )
@@ -17,6 +18,7 @@ func TestTryMap(t *testing.T) {
 		name        string
 		input       []int
 		mapFn       func(context.Context, int) (string, error)
+		opts        []core.FlowOption
 		expected    []string
 		expectedErr error
 	}{
@@ -62,6 +64,31 @@ func TestTryMap(t *testing.T) {
 			expected:    []string{},
 			expectedErr: errors.New("error on first item"),
 		},
+		{
+			name:  "supervision resumes its own errors",
+			input: []int{1, 2, 3, 4},
+			mapFn: func(ctx context.Context, i int) (string, error) {
+				if i%2 == 0 {
+					return "", errors.New("even")
+				}
+				return strconv.Itoa(i), nil
+			},
+			opts:     []core.FlowOption{core.WithSupervision(core.ResumingDecider)},
+			expected: []string{"1", "3"},
+		},
+		{
+			name:  "supervision stops on its own errors",
+			input: []int{1, 2, 3},
+			mapFn: func(ctx context.Context, i int) (string, error) {
+				if i == 2 {
+					return "", errors.New("error on 2")
+				}
+				return strconv.Itoa(i), nil
+			},
+			opts:        []core.FlowOption{core.WithSupervision(core.StoppingDecider)},
+			expected:    []string{"1"},
+			expectedErr: errors.New("error on 2"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -72,7 +99,7 @@ func TestTryMap(t *testing.T) {
 
 			mapErrFlow := TryMap(func(ctx context.Context, i int) (string, error) {
 				return mapFunc(ctx, i)
-			})
+			}, tt.opts...)
 
 			stream := compose.SourceThroughFlowToSink(
 				sources.Slice(tt.input),
@@ -0,0 +1,90 @@
+package flows
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// Window creates a Flow that groups items into non-overlapping windows of the given
+// duration, emitting the items received during each window as a single slice when the
+// window ends. Windows in which no item was received emit nothing. When upstream completes
+// or the stream is drained, the items of the current window are emitted before the flow
+// completes.
+//
+// Windows are based on processing time: the first window starts when the first item is
+// received, and every following window starts when the previous one ends. Use Batch to
+// group items by count instead.
+//
+// Type Parameters:
+//   - I: The type of items to group
+//
+// Parameters:
+//   - duration: The duration of each window
+//   - opts: Optional FlowOption functions to configure the flow
+//
+// Returns a Flow that transforms individual items into slices of the items of each window
+func Window[I any](
+	duration time.Duration,
+	opts ...core.FlowOption,
+) *core.Flow[I, []I] {
+	var mu sync.Mutex
+	var window []I
+	// stop and stopped control the goroutine emitting the windows, which is started with
+	// the first item of a run
+	var stop, stopped chan struct{}
+
+	// take returns the items of the current window and starts a new one
+	take := func() []I {
+		mu.Lock()
+		defer mu.Unlock()
+		items := window
+		window = nil
+		return items
+	}
+
+	return core.NewFlow(
+		func(ctx context.Context, elem I, out chan<- core.Item[[]I]) core.StreamAction {
+			if stop == nil {
+				stop, stopped = make(chan struct{}), make(chan struct{})
+				go func() {
+					defer close(stopped)
+					ticker := time.NewTicker(duration)
+					defer ticker.Stop()
+					for {
+						select {
+						case <-ctx.Done():
+							return
+						case <-stop:
+							return
+						case <-ticker.C:
+							if items := take(); len(items) > 0 {
+								util.Send(ctx, core.Item[[]I]{Value: items}, out)
+							}
+						}
+					}
+				}()
+			}
+
+			mu.Lock()
+			window = append(window, elem)
+			mu.Unlock()
+			return core.ActionProceed
+		},
+		nil,
+		nil,
+		func(ctx context.Context, out chan<- core.Item[[]I]) {
+			if stop != nil {
+				close(stop)
+				<-stopped
+				stop, stopped = nil, nil
+			}
+			if items := take(); len(items) > 0 {
+				util.Send(ctx, core.Item[[]I]{Value: items}, out)
+			}
+		},
+		opts...)
+}
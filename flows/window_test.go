@@ -0,0 +1,85 @@
+package flows
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		bursts   [][]int
+		expected [][]int
+	}{
+		{
+			name:     "groups items per window",
+			bursts:   [][]int{{1, 2, 3}, {4, 5}, {6}},
+			expected: [][]int{{1, 2, 3}, {4, 5}, {6}},
+		},
+		{
+			name:     "skips empty windows",
+			bursts:   [][]int{{1}, {}, {2, 3}},
+			expected: [][]int{{1}, {2, 3}},
+		},
+		{
+			name:     "emits nothing without items",
+			bursts:   [][]int{{}},
+			expected: [][]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan int)
+			stream := compose.SourceThroughFlowToSink(
+				sources.Chan(ch),
+				Window[int](50*time.Millisecond),
+				sinks.Slice[[]int](),
+			)
+			res := stream.Run(context.Background())
+
+			for i, burst := range tt.bursts {
+				for _, elem := range burst {
+					ch <- elem
+				}
+				if i < len(tt.bursts)-1 {
+					time.Sleep(70 * time.Millisecond)
+				}
+			}
+			close(ch)
+
+			result := <-res
+			stream.AwaitDone()
+
+			require.NoError(t, result.Err)
+			assert.Equal(t, tt.expected, result.Value)
+		})
+	}
+}
+
+func TestWindowDrain(t *testing.T) {
+	stream := compose.SourceThroughFlowToSink(
+		sources.Repeat(1),
+		Window[int](time.Hour),
+		sinks.Slice[[]int](),
+	)
+
+	res := stream.Run(context.Background())
+	time.Sleep(20 * time.Millisecond)
+	stream.Drain()
+
+	result := <-res
+	stream.AwaitDone()
+
+	require.NoError(t, result.Err)
+	// The current window is emitted when the stream is drained
+	require.Len(t, result.Value, 1)
+	assert.NotEmpty(t, result.Value[0])
+}
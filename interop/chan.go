@@ -0,0 +1,122 @@
+package interop
+
+import (
+	"context"
+	"sync"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// chanSource creates a Source emitting the items received on in until it is closed.
+// Unlike sources.Chan, it stops waiting for items as soon as the stream is cancelled or drained.
+func chanSource[I any](in <-chan I) *core.Source[I] {
+	return core.NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan core.Item[I] {
+			out := make(chan core.Item[I])
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer close(out)
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-complete:
+						return
+					case elem, ok := <-in:
+						if !ok {
+							return
+						}
+						select {
+						case <-ctx.Done():
+							return
+						case <-complete:
+							return
+						case out <- core.Item[I]{Value: elem}:
+						}
+					}
+				}
+			}()
+			return out
+		},
+	)
+}
+
+// chanSink creates a Sink sending all items and the first error it receives to out.
+func chanSink[O any](out chan<- core.Item[O]) *core.Sink[O, struct{}] {
+	send := func(ctx context.Context, item core.Item[O]) {
+		select {
+		case <-ctx.Done():
+		case out <- item:
+		}
+	}
+
+	return core.NewSink(
+		struct{}{},
+		func(ctx context.Context, elem O, acc core.Item[struct{}]) (core.Item[struct{}], core.StreamAction) {
+			send(ctx, core.Item[O]{Value: elem})
+			return acc, core.ActionProceed
+		},
+		func(ctx context.Context, err error, acc core.Item[struct{}]) (core.Item[struct{}], core.StreamAction) {
+			send(ctx, core.Item[O]{Err: err})
+			return core.Item[struct{}]{Err: err}, core.ActionStop
+		},
+		nil,
+	)
+}
+
+// FlowChannels runs flow in a new stream and exposes it as a pair of channels.
+//
+// Ownership of the channels is split between the caller and the stream:
+//   - The input channel is owned by the caller, which sends items to be processed and must
+//     close it once done. Closing it completes the stream after all items are processed.
+//   - The output channel is owned by the stream, which closes it once the stream has
+//     terminated. The caller must keep receiving until it is closed, as the flow is
+//     backpressured otherwise.
+//   - If the flow fails, its error is sent on the output channel as an Item with Err set,
+//     after which the output channel is closed.
+//
+// The returned Stream can be used to cancel processing or wait for it to finish. Its result
+// must not be read through Run, as it is already consumed to close the output channel.
+//
+// Type Parameters:
+//   - I: The type of input items
+//   - O: The type of output items
+//
+// Parameters:
+//   - ctx: Context controlling the lifetime of the stream
+//   - flow: The flow processing the items
+//   - bufSize: Buffer size of both channels
+//
+// Returns the input channel, the output channel and the running Stream
+func FlowChannels[I, O any](
+	ctx context.Context,
+	flow *core.Flow[I, O],
+	bufSize int,
+) (chan<- I, <-chan core.Item[O], *core.Stream[struct{}]) {
+	in, out, stream := newFlowChannels(flow, bufSize)
+	runFlowChannels(ctx, stream, out)
+	return in, out, stream
+}
+
+// newFlowChannels creates the channels and the stream used by FlowChannels, without starting it.
+func newFlowChannels[I, O any](
+	flow *core.Flow[I, O],
+	bufSize int,
+) (chan I, chan core.Item[O], *core.Stream[struct{}]) {
+	in := make(chan I, bufSize)
+	out := make(chan core.Item[O], bufSize)
+	stream := core.ConnectSourceToSink(core.AppendFlowToSource(chanSource(in), flow), chanSink(out))
+	return in, out, stream
+}
+
+// runFlowChannels starts a stream created by newFlowChannels and closes out once it has terminated.
+func runFlowChannels[O any](ctx context.Context, stream *core.Stream[struct{}], out chan core.Item[O]) {
+	res := stream.Run(ctx)
+	go func() {
+		<-res
+		// Only close the output channel once the sink can no longer send to it
+		stream.AwaitDone()
+		close(out)
+	}()
+}
@@ -0,0 +1,97 @@
+package interop
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/flows"
+)
+
+func TestFlowChannels(t *testing.T) {
+	testErr := errors.New("test error")
+
+	tests := []struct {
+		name    string
+		input   []int
+		flow    *core.Flow[int, int]
+		want    []int
+		wantErr error
+	}{
+		{
+			name:  "processes items sent on the input channel",
+			input: []int{1, 2, 3},
+			flow: flows.Map(func(ctx context.Context, i int) int {
+				return i * 2
+			}),
+			want: []int{2, 4, 6},
+		},
+		{
+			name:  "completes without items",
+			input: []int{},
+			flow: flows.Map(func(ctx context.Context, i int) int {
+				return i
+			}),
+		},
+		{
+			name:  "delivers errors on the output channel",
+			input: []int{1, 2, 3},
+			flow: flows.TryMap(func(ctx context.Context, i int) (int, error) {
+				if i == 2 {
+					return 0, testErr
+				}
+				return i, nil
+			}),
+			want:    []int{1},
+			wantErr: testErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, out, stream := FlowChannels(context.Background(), tt.flow, 1)
+
+			go func() {
+				defer close(in)
+				for _, i := range tt.input {
+					select {
+					case <-t.Context().Done():
+						return
+					case in <- i:
+					}
+				}
+			}()
+
+			var got []int
+			var gotErr error
+			for item := range out {
+				if item.Err != nil {
+					gotErr = item.Err
+					continue
+				}
+				got = append(got, item.Value)
+			}
+			stream.AwaitDone()
+
+			assert.Equal(t, tt.want, got)
+			assert.ErrorIs(t, gotErr, tt.wantErr)
+		})
+	}
+}
+
+func TestFlowChannelsCancel(t *testing.T) {
+	in, out, stream := FlowChannels(context.Background(), flows.Map(func(ctx context.Context, i int) int {
+		return i
+	}), 0)
+
+	in <- 1
+	assert.Equal(t, 1, (<-out).Value)
+
+	// Cancelling closes the output channel even though the input channel is still open
+	stream.Cancel()
+	for range out {
+	}
+	stream.AwaitDone()
+}
@@ -0,0 +1,22 @@
+// Package interop provides adapters for embedding linea pipelines into code built on other
+// streaming abstractions.
+//
+// The adapters run a pipeline segment in its own stream and expose it through standard Go
+// types, so linea can be introduced incrementally:
+//   - FlowChannels exposes a Flow as an input and an output channel
+//   - FlowPipe exposes a Flow of byte slices as an io.WriteCloser and an io.ReadCloser
+//
+// Example:
+//
+//	in, out, stream := interop.FlowChannels(ctx, flows.Map(double), 0)
+//	go func() {
+//	    defer close(in)
+//	    for _, i := range items {
+//	        in <- i
+//	    }
+//	}()
+//	for item := range out {
+//	    // handle item.Value or item.Err
+//	}
+//	stream.AwaitDone()
+package interop
@@ -0,0 +1,115 @@
+package interop
+
+import (
+	"context"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// pipeWriter feeds written bytes into a running stream.
+type pipeWriter struct {
+	mu     sync.Mutex
+	in     chan<- []byte
+	done   <-chan struct{}
+	closed bool
+}
+
+// Write sends a copy of p into the stream. It blocks while the stream is backpressured.
+func (w *pipeWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	select {
+	case <-w.done:
+		return 0, io.ErrClosedPipe
+	case w.in <- slices.Clone(p):
+		return len(p), nil
+	}
+}
+
+// Close completes the input of the stream. Bytes already written are still processed.
+func (w *pipeWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.closed = true
+		close(w.in)
+	}
+	return nil
+}
+
+// pipeReader reads the output of a running stream.
+type pipeReader struct {
+	out    <-chan core.Item[[]byte]
+	stream *core.Stream[struct{}]
+	buf    []byte
+	err    error
+}
+
+// Read reads the output of the stream. It returns io.EOF once the stream has completed,
+// or the error of the stream if it failed.
+func (r *pipeReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		item, ok := <-r.out
+		switch {
+		case !ok:
+			r.err = io.EOF
+		case item.Err != nil:
+			r.err = item.Err
+		default:
+			r.buf = item.Value
+		}
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Close cancels the stream and discards its remaining output.
+func (r *pipeReader) Close() error {
+	r.stream.Cancel()
+	for range r.out {
+	}
+	return nil
+}
+
+// FlowPipe runs flow in a new stream and exposes it as an io.WriteCloser and io.ReadCloser
+// pair, similar to io.Pipe with a flow in between. Every call to Write sends a copy of the
+// written bytes into the flow as a single item, and the items emitted by the flow can be
+// read from the reader as one continuous byte stream.
+//
+// Ownership of both ends is split between the writing and the reading side:
+//   - The writing side must close the writer once done, which completes the stream after
+//     all written bytes are processed. Writes after that fail with io.ErrClosedPipe.
+//   - The reading side must keep reading until io.EOF, or close the reader to cancel the
+//     stream, as the flow is backpressured otherwise. If the flow fails, Read returns its error.
+//
+// Parameters:
+//   - ctx: Context controlling the lifetime of the stream
+//   - flow: The flow processing the written bytes
+//
+// Returns the writer, the reader and the running Stream
+func FlowPipe(
+	ctx context.Context,
+	flow *core.Flow[[]byte, []byte],
+) (io.WriteCloser, io.ReadCloser, *core.Stream[struct{}]) {
+	in, out, stream := newFlowChannels(flow, 0)
+
+	done := make(chan struct{})
+	stream.OnTermination(func(error) {
+		close(done)
+	})
+	runFlowChannels(ctx, stream, out)
+
+	return &pipeWriter{in: in, done: done}, &pipeReader{out: out, stream: stream}, stream
+}
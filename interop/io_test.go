@@ -0,0 +1,86 @@
+package interop
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/flows"
+)
+
+func TestFlowPipe(t *testing.T) {
+	testErr := errors.New("test error")
+
+	tests := []struct {
+		name    string
+		writes  []string
+		flow    *core.Flow[[]byte, []byte]
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "reads the output of the flow as a continuous byte stream",
+			writes: []string{"hello ", "world"},
+			flow: flows.Map(func(ctx context.Context, b []byte) []byte {
+				return bytes.ToUpper(b)
+			}),
+			want: "HELLO WORLD",
+		},
+		{
+			name:   "returns the error of the flow",
+			writes: []string{"ok", "fail"},
+			flow: flows.TryMap(func(ctx context.Context, b []byte) ([]byte, error) {
+				if string(b) == "fail" {
+					return nil, testErr
+				}
+				return b, nil
+			}),
+			want:    "ok",
+			wantErr: testErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, r, stream := FlowPipe(context.Background(), tt.flow)
+
+			go func() {
+				defer w.Close()
+				for _, s := range tt.writes {
+					if _, err := w.Write([]byte(s)); err != nil {
+						return
+					}
+				}
+			}()
+
+			var got bytes.Buffer
+			_, err := io.Copy(&got, r)
+			assert.NoError(t, r.Close())
+			stream.AwaitDone()
+
+			assert.Equal(t, tt.want, got.String())
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFlowPipeWriteAfterClose(t *testing.T) {
+	w, r, stream := FlowPipe(context.Background(), flows.Map(func(ctx context.Context, b []byte) []byte {
+		return b
+	}))
+
+	assert.NoError(t, w.Close())
+	_, err := w.Write([]byte("late"))
+	assert.ErrorIs(t, err, io.ErrClosedPipe)
+
+	assert.NoError(t, r.Close())
+	stream.AwaitDone()
+}
@@ -0,0 +1,123 @@
+package linea
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// DefaultDrainTimeout is the time RunUntilSignal gives a stream to drain after a signal,
+// unless configured otherwise.
+const DefaultDrainTimeout = 30 * time.Second
+
+// SignalOption is a function that configures RunUntilSignal.
+type SignalOption func(*signalConfig)
+
+// signalConfig holds configuration options for RunUntilSignal.
+type signalConfig struct {
+	// signals are the signals triggering the shutdown
+	signals []os.Signal
+
+	// drainTimeout is the time the stream is given to drain before it is cancelled
+	drainTimeout time.Duration
+
+	// onSignal is called with the signal that triggered the shutdown
+	onSignal func(sig os.Signal)
+}
+
+// WithSignals returns a SignalOption that sets the signals triggering the shutdown,
+// replacing the default SIGTERM and SIGINT.
+//
+// Parameters:
+//   - signals: The signals to listen for
+func WithSignals(signals ...os.Signal) SignalOption {
+	return func(c *signalConfig) {
+		c.signals = signals
+	}
+}
+
+// WithDrainTimeout returns a SignalOption that sets the time the stream is given to drain
+// after a signal before it is cancelled.
+//
+// Parameters:
+//   - timeout: The time allowed for draining
+func WithDrainTimeout(timeout time.Duration) SignalOption {
+	return func(c *signalConfig) {
+		c.drainTimeout = timeout
+	}
+}
+
+// OnSignal returns a SignalOption that registers a function called when a signal triggers
+// the shutdown, for example to log it.
+//
+// Parameters:
+//   - fn: Function called with the received signal
+func OnSignal(fn func(sig os.Signal)) SignalOption {
+	return func(c *signalConfig) {
+		c.onSignal = fn
+	}
+}
+
+// RunUntilSignal runs the stream until it terminates or the process receives SIGTERM or
+// SIGINT, and returns the final result. This is the shutdown sequence expected of
+// long-running pipelines, such as those consuming an SQS queue, in containers and process
+// managers.
+//
+// On the first signal, the stream is drained so that items already in flight are processed.
+// If it has not finished within the drain timeout, or a second signal is received, it is
+// cancelled and the result reports context.Canceled. RunUntilSignal only returns once all
+// goroutines of the stream have completed.
+//
+// Type Parameters:
+//   - R: The type of the result produced by the stream
+//
+// Parameters:
+//   - ctx: Context controlling the lifecycle of the stream
+//   - stream: The stream to run
+//   - opts: Optional SignalOption functions to configure the signals and drain timeout
+//
+// Returns the result of the stream and its terminal error
+func RunUntilSignal[R any](ctx context.Context, stream *core.Stream[R], opts ...SignalOption) (R, error) {
+	cfg := &signalConfig{
+		signals:      []os.Signal{syscall.SIGTERM, os.Interrupt},
+		drainTimeout: DefaultDrainTimeout,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, cfg.signals...)
+	defer signal.Stop(signals)
+
+	res := stream.Run(ctx)
+	defer stream.AwaitDone()
+
+	select {
+	case r := <-res:
+		return r.Value, r.Err
+	case sig := <-signals:
+		if cfg.onSignal != nil {
+			cfg.onSignal(sig)
+		}
+	}
+
+	stream.Drain()
+	timer := time.NewTimer(cfg.drainTimeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-res:
+		return r.Value, r.Err
+	case <-timer.C:
+	case <-signals:
+	}
+
+	stream.Cancel()
+	r := <-res
+	return r.Value, r.Err
+}
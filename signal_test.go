@@ -0,0 +1,100 @@
+//go:build unix
+
+package linea
+
+import (
+	"context"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sinks"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestRunUntilSignal(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      []int
+		blocking    bool
+		timeout     time.Duration
+		signals     int
+		expectedErr error
+	}{
+		{
+			name:    "returns result of completed stream",
+			source:  []int{1, 2, 3},
+			timeout: time.Second,
+		},
+		{
+			name:    "drains stream on signal",
+			timeout: time.Second,
+			signals: 1,
+		},
+		{
+			name:        "cancels stream after drain timeout",
+			blocking:    true,
+			timeout:     50 * time.Millisecond,
+			signals:     1,
+			expectedErr: context.Canceled,
+		},
+		{
+			name:        "cancels stream on second signal",
+			blocking:    true,
+			timeout:     time.Minute,
+			signals:     2,
+			expectedErr: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := sources.Repeat(1)
+			if tt.source != nil {
+				source = sources.Slice(tt.source)
+			}
+
+			started := make(chan struct{})
+			once := sync.Once{}
+			stream := compose.SourceToSink(source, sinks.ForEach(func(ctx context.Context, i int) {
+				once.Do(func() { close(started) })
+				if tt.blocking {
+					<-ctx.Done()
+				}
+			}))
+
+			var received []os.Signal
+			done := make(chan struct{})
+			var err error
+			go func() {
+				defer close(done)
+				_, err = RunUntilSignal(context.Background(), stream,
+					WithSignals(syscall.SIGUSR1),
+					WithDrainTimeout(tt.timeout),
+					OnSignal(func(sig os.Signal) { received = append(received, sig) }),
+				)
+			}()
+
+			<-started
+			for i := 0; i < tt.signals; i++ {
+				// Give the previous signal time to be handled
+				time.Sleep(20 * time.Millisecond)
+				assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+			}
+			<-done
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.signals > 0 {
+				assert.Equal(t, []os.Signal{syscall.SIGUSR1}, received)
+			}
+		})
+	}
+}
@@ -15,10 +15,12 @@ import (
 //
 // Parameters:
 //   - pred: Function that returns true if processing should be cancelled
+//   - opts: Optional SinkOption functions to configure the sink
 //
 // Returns a Sink that may cancel processing based on item values
 func CancelIf[I any](
 	pred func(I) bool,
+	opts ...core.SinkOption,
 ) *core.Sink[I, struct{}] {
 	return core.NewSink(
 		struct{}{},
@@ -30,5 +32,6 @@ func CancelIf[I any](
 		},
 		nil,
 		nil,
+		opts...,
 	)
 }
@@ -17,10 +17,12 @@ import (
 //
 // Parameters:
 //   - pred: Function that returns true if processing should be completed
+//   - opts: Optional SinkOption functions to configure the sink
 //
 // Returns a Sink that may gracefully complete processing based on item values
 func CompleteIf[I any](
 	pred func(I) bool,
+	opts ...core.SinkOption,
 ) *core.Sink[I, struct{}] {
 	return core.NewSink(
 		struct{}{},
@@ -32,5 +34,6 @@ func CompleteIf[I any](
 		},
 		nil,
 		nil,
+		opts...,
 	)
 }
@@ -15,10 +15,12 @@ import (
 //
 // Parameters:
 //   - fn: Function to execute for each item
+//   - opts: Optional SinkOption functions to configure the sink
 //
 // Returns a Sink that applies the side-effect to each item
 func ForEach[I any](
 	fn func(context.Context, I),
+	opts ...core.SinkOption,
 ) *core.Sink[I, struct{}] {
 	return core.NewSink(
 		struct{}{},
@@ -28,5 +30,6 @@ func ForEach[I any](
 		},
 		nil,
 		nil,
+		opts...,
 	)
 }
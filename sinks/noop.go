@@ -13,7 +13,10 @@ import (
 // Type Parameters:
 //   - I: The type of items to consume
 //
+// Parameters:
+//   - opts: Optional SinkOption functions to configure the sink
+//
 // Returns a Sink that discards all items
-func Noop[I any]() *core.Sink[I, struct{}] {
-	return ForEach(func(_ context.Context, _ I) {})
+func Noop[I any](opts ...core.SinkOption) *core.Sink[I, struct{}] {
+	return ForEach(func(_ context.Context, _ I) {}, opts...)
 }
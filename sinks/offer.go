@@ -0,0 +1,99 @@
+package sinks
+
+import (
+	"context"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// OfferDecision determines how an OfferWithTimeout sink handles a rejected offer.
+type OfferDecision int
+
+const (
+	// OfferFail stops the stream with the rejection error.
+	OfferFail OfferDecision = iota
+
+	// OfferRetry offers the same element again.
+	OfferRetry
+
+	// OfferDivert gives up on the element and continues with the next one.
+	// The rejection handler is responsible for diverting the element elsewhere if needed.
+	OfferDivert
+)
+
+// OfferResult summarizes the outcome of an OfferWithTimeout sink.
+type OfferResult struct {
+	// Accepted is the number of elements accepted by the external system
+	Accepted int
+
+	// Diverted is the number of elements given up on after being rejected
+	Diverted int
+}
+
+// OfferWithTimeout creates a Sink that pushes each item into a bounded external system that
+// can reject items, such as a full queue. Each offer is given at most timeout to complete; an
+// offer returning an error or exceeding the timeout counts as a rejection.
+//
+// On rejection, onRejected is called with the element, the error and the 1-based attempt number,
+// and decides whether to retry the offer, divert the element or fail the stream. onRejected may
+// block, for example to back off before retrying. If onRejected is nil, rejections fail the stream.
+//
+// Type Parameters:
+//   - I: The type of items to offer
+//
+// Parameters:
+//   - offer: Function that pushes an item into the external system
+//   - timeout: Maximum duration of a single offer attempt
+//   - onRejected: Function that decides how to handle a rejected offer
+//   - opts: Optional SinkOption functions to configure the sink
+//
+// Returns a Sink that offers items and reports how many were accepted and diverted
+func OfferWithTimeout[I any](
+	offer func(context.Context, I) error,
+	timeout time.Duration,
+	onRejected func(ctx context.Context, elem I, err error, attempt int) OfferDecision,
+	opts ...core.SinkOption,
+) *core.Sink[I, OfferResult] {
+	if onRejected == nil {
+		onRejected = func(context.Context, I, error, int) OfferDecision {
+			return OfferFail
+		}
+	}
+
+	tryOffer := func(ctx context.Context, elem I) error {
+		offerCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return offer(offerCtx, elem)
+	}
+
+	return core.NewSink(
+		OfferResult{},
+		func(ctx context.Context, in I, acc core.Item[OfferResult]) (core.Item[OfferResult], core.StreamAction) {
+			for attempt := 1; ; attempt++ {
+				err := tryOffer(ctx, in)
+				if err == nil {
+					acc.Value.Accepted++
+					return acc, core.ActionProceed
+				}
+
+				if ctx.Err() != nil {
+					return core.Item[OfferResult]{Value: acc.Value, Err: ctx.Err()}, core.ActionStop
+				}
+
+				switch onRejected(ctx, in, err, attempt) {
+				case OfferRetry:
+					continue
+				case OfferDivert:
+					acc.Value.Diverted++
+					return acc, core.ActionProceed
+				default:
+					return core.Item[OfferResult]{Value: acc.Value, Err: err}, core.ActionStop
+				}
+			}
+		},
+		nil,
+		nil,
+		opts...,
+	)
+}
@@ -0,0 +1,112 @@
+package sinks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sources"
+)
+
+func TestOfferWithTimeout(t *testing.T) {
+	errFull := errors.New("queue full")
+
+	tests := []struct {
+		name        string
+		elements    []int
+		offer       func(rejections map[int]int) func(context.Context, int) error
+		onRejected  func(ctx context.Context, elem int, err error, attempt int) OfferDecision
+		want        OfferResult
+		expectedErr error
+	}{
+		{
+			name:     "accepts all elements",
+			elements: []int{1, 2, 3},
+			offer: func(map[int]int) func(context.Context, int) error {
+				return func(context.Context, int) error { return nil }
+			},
+			want: OfferResult{Accepted: 3},
+		},
+		{
+			name:     "fails on rejection by default",
+			elements: []int{1, 2, 3},
+			offer: func(map[int]int) func(context.Context, int) error {
+				return func(_ context.Context, i int) error {
+					if i == 2 {
+						return errFull
+					}
+					return nil
+				}
+			},
+			want:        OfferResult{Accepted: 1},
+			expectedErr: errFull,
+		},
+		{
+			name:     "retries rejected elements",
+			elements: []int{1, 2, 3},
+			offer: func(rejections map[int]int) func(context.Context, int) error {
+				return func(_ context.Context, i int) error {
+					if rejections[i] < 2 {
+						rejections[i]++
+						return errFull
+					}
+					return nil
+				}
+			},
+			onRejected: func(context.Context, int, error, int) OfferDecision {
+				return OfferRetry
+			},
+			want: OfferResult{Accepted: 3},
+		},
+		{
+			name:     "diverts rejected elements",
+			elements: []int{1, 2, 3, 4},
+			offer: func(map[int]int) func(context.Context, int) error {
+				return func(_ context.Context, i int) error {
+					if i%2 == 0 {
+						return errFull
+					}
+					return nil
+				}
+			},
+			onRejected: func(context.Context, int, error, int) OfferDecision {
+				return OfferDivert
+			},
+			want: OfferResult{Accepted: 2, Diverted: 2},
+		},
+		{
+			name:     "treats timeouts as rejections",
+			elements: []int{1},
+			offer: func(map[int]int) func(context.Context, int) error {
+				return func(ctx context.Context, _ int) error {
+					<-ctx.Done()
+					return ctx.Err()
+				}
+			},
+			onRejected: func(_ context.Context, _ int, err error, attempt int) OfferDecision {
+				if attempt < 2 {
+					return OfferRetry
+				}
+				return OfferFail
+			},
+			want:        OfferResult{},
+			expectedErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceToSink(
+				sources.Slice(tt.elements),
+				OfferWithTimeout(tt.offer(map[int]int{}), 10*time.Millisecond, tt.onRejected),
+			)
+
+			res := <-stream.Run(context.Background())
+			assert.Equal(t, tt.want, res.Value)
+			assert.ErrorIs(t, res.Err, tt.expectedErr)
+		})
+	}
+}
@@ -17,11 +17,13 @@ import (
 // Parameters:
 //   - initial: The initial value for the reduction
 //   - fn: Function that combines the current result with a new item
+//   - opts: Optional SinkOption functions to configure the sink
 //
 // Returns a Sink that reduces items to a single result
 func Reduce[I, R any](
 	initial R,
 	fn func(context.Context, R, I) R,
+	opts ...core.SinkOption,
 ) *core.Sink[I, R] {
 	return core.NewSink(
 		initial,
@@ -30,5 +32,6 @@ func Reduce[I, R any](
 		},
 		nil,
 		nil,
+		opts...,
 	)
 }
@@ -12,8 +12,11 @@ import (
 // Type Parameters:
 //   - I: The type of items to collect
 //
+// Parameters:
+//   - opts: Optional SinkOption functions to configure the sink
+//
 // Returns a Sink that accumulates items into a slice
-func Slice[I any]() *core.Sink[I, []I] {
+func Slice[I any](opts ...core.SinkOption) *core.Sink[I, []I] {
 	return core.NewSink(
 		make([]I, 0),
 		func(ctx context.Context, in I, acc core.Item[[]I]) (core.Item[[]I], core.StreamAction) {
@@ -21,5 +24,6 @@ func Slice[I any]() *core.Sink[I, []I] {
 		},
 		nil,
 		nil,
+		opts...,
 	)
 }
@@ -0,0 +1,233 @@
+package sources
+
+import (
+	"context"
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// CSVConfig configures how CSV and CSVFile read records.
+type CSVConfig struct {
+	// Comma is the character separating the fields of a record
+	// If not specified, ',' is used
+	Comma rune
+
+	// Comment is the character starting lines that are skipped, such as '#'
+	// If not specified, no lines are skipped
+	Comment rune
+
+	// SkipHeader makes the first record be skipped when records are emitted as []string
+	// Records emitted as structs always take their header from the first record
+	SkipHeader bool
+}
+
+// CSV creates a Source that reads CSV records from r one at a time and emits them, so files
+// larger than memory can be processed. Records are emitted as []string if T is []string.
+// Otherwise, T must be a struct, and the first record is read as a header naming the columns,
+// which are mapped to the fields of the struct named by their csv tag, such as `csv:"id"`, or
+// matching their name case-insensitively. Fields tagged `csv:"-"`, and columns without a
+// field, are ignored. Fields may be strings, booleans, integers, floating-point numbers or
+// implement encoding.TextUnmarshaler; empty fields leave them at their zero value.
+//
+// Errors reading or mapping a record are emitted with the line it is on, after which the
+// source stops. The source stops reading when the context is cancelled or the stream is
+// drained. r is read only once, so the stream cannot be run again; use CSVFile to read a file
+// on every run.
+//
+// Type Parameters:
+//   - T: The type of records produced by this source, []string or a struct
+//
+// Parameters:
+//   - r: The reader to read CSV data from
+//   - config: Configuration of the reading
+//   - opts: Optional configuration options for the source
+//
+// Returns a Source that produces the records read from r
+func CSV[T any](
+	r io.Reader,
+	config CSVConfig,
+	opts ...core.SourceOption,
+) *core.Source[T] {
+	return csvSource[T](func() (io.Reader, io.Closer, error) {
+		return r, nil, nil
+	}, config, opts...)
+}
+
+// CSVFile creates a Source that reads the CSV records of a file, like CSV. The file is
+// opened whenever the stream is run, and closed whenever the source stops, whether it
+// completed, the stream was drained or cancelled, or reading failed.
+//
+// Type Parameters:
+//   - T: The type of records produced by this source, []string or a struct
+//
+// Parameters:
+//   - path: The path of the file to read
+//   - config: Configuration of the reading
+//   - opts: Optional configuration options for the source
+//
+// Returns a Source that produces the records of the file
+func CSVFile[T any](
+	path string,
+	config CSVConfig,
+	opts ...core.SourceOption,
+) *core.Source[T] {
+	return csvSource[T](func() (io.Reader, io.Closer, error) {
+		f, err := os.Open(path)
+		return f, f, err
+	}, config, opts...)
+}
+
+// csvReader holds the state of a source reading CSV records.
+type csvReader[T any] struct {
+	closer  io.Closer
+	reader  *csv.Reader
+	decoder func(record []string) (T, error) // set once the header was read
+}
+
+// csvSource creates a Source reading CSV records from the reader returned by open.
+func csvSource[T any](
+	open func() (io.Reader, io.Closer, error),
+	config CSVConfig,
+	opts ...core.SourceOption,
+) *core.Source[T] {
+	return UnfoldResource(
+		func(ctx context.Context) (*csvReader[T], error) {
+			r, closer, err := open()
+			if err != nil {
+				return nil, err
+			}
+			reader := csv.NewReader(r)
+			if config.Comma != 0 {
+				reader.Comma = config.Comma
+			}
+			reader.Comment = config.Comment
+			return &csvReader[T]{closer: closer, reader: reader}, nil
+		},
+		func(ctx context.Context, c *csvReader[T]) (T, bool, error) {
+			var zero T
+			for {
+				record, err := c.reader.Read()
+				if errors.Is(err, io.EOF) {
+					return zero, false, nil
+				}
+				if err != nil {
+					return zero, false, fmt.Errorf("reading CSV: %w", err)
+				}
+				if c.decoder != nil {
+					line, _ := c.reader.FieldPos(0)
+					elem, err := c.decoder(record)
+					if err != nil {
+						return zero, false, fmt.Errorf("reading CSV: line %d: %w", line, err)
+					}
+					return elem, true, nil
+				}
+
+				// The first record is the header, unless records are emitted as they are
+				if rec, ok := any(record).(T); ok {
+					c.decoder = func(record []string) (T, error) { return any(record).(T), nil }
+					if !config.SkipHeader {
+						return rec, true, nil
+					}
+					continue
+				}
+				if c.decoder, err = newCSVDecoder[T](record); err != nil {
+					return zero, false, fmt.Errorf("reading CSV: %w", err)
+				}
+			}
+		},
+		func(c *csvReader[T]) error {
+			if c.closer == nil {
+				return nil
+			}
+			return c.closer.Close()
+		},
+		opts...)
+}
+
+// newCSVDecoder creates a function mapping records to the struct T using the names of the
+// columns in header.
+func newCSVDecoder[T any](header []string) (func(record []string) (T, error), error) {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot map records to %v: not a struct or []string", typ)
+	}
+
+	// fields holds the index of the field every column is mapped to, or nil if it is ignored
+	fields := make([][]int, len(header))
+	for i, column := range header {
+		for _, field := range reflect.VisibleFields(typ) {
+			if !field.IsExported() || field.Anonymous {
+				continue
+			}
+			name, tagged := field.Tag.Lookup("csv")
+			if name == "-" {
+				continue
+			}
+			if (tagged && name == column) || (!tagged && strings.EqualFold(field.Name, column)) {
+				fields[i] = field.Index
+				break
+			}
+		}
+	}
+
+	return func(record []string) (T, error) {
+		var elem T
+		v := reflect.ValueOf(&elem).Elem()
+		for i, value := range record {
+			if i >= len(fields) || fields[i] == nil || value == "" {
+				continue
+			}
+			if err := setCSVField(v.FieldByIndex(fields[i]), value); err != nil {
+				return elem, fmt.Errorf("column %q: %w", header[i], err)
+			}
+		}
+		return elem, nil
+	}, nil
+}
+
+// setCSVField parses value into the field f.
+func setCSVField(f reflect.Value, value string) error {
+	if u, ok := f.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(value, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %v", f.Type())
+	}
+	return nil
+}
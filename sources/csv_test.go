@@ -0,0 +1,159 @@
+package sources
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sinks"
+)
+
+// testOrder is a record mapped from CSV columns.
+type testOrder struct {
+	ID       int    `csv:"id"`
+	Customer string // matched case-insensitively
+	Amount   float64
+	Paid     bool
+	Placed   time.Time `csv:"placed_at"`
+	Note     string    `csv:"-"`
+}
+
+func TestCSV(t *testing.T) {
+	placed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("emits records as strings", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			data     string
+			config   CSVConfig
+			expected [][]string
+		}{
+			{
+				name:     "emits all records",
+				data:     "a,b\n1,\"x,y\"\n",
+				expected: [][]string{{"a", "b"}, {"1", "x,y"}},
+			},
+			{
+				name:     "skips the header",
+				data:     "a,b\n1,2\n",
+				config:   CSVConfig{SkipHeader: true},
+				expected: [][]string{{"1", "2"}},
+			},
+			{
+				name:     "uses the configured separator and comments",
+				data:     "# comment\na;b\n",
+				config:   CSVConfig{Comma: ';', Comment: '#'},
+				expected: [][]string{{"a", "b"}},
+			},
+			{
+				name:     "handles empty input",
+				data:     "",
+				expected: [][]string{},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				stream := compose.SourceToSink(
+					CSV[[]string](strings.NewReader(tt.data), tt.config),
+					sinks.Slice[[]string](),
+				)
+
+				res := <-stream.Run(context.Background())
+				stream.AwaitDone()
+
+				require.NoError(t, res.Err)
+				assert.Equal(t, tt.expected, res.Value)
+			})
+		}
+	})
+
+	t.Run("maps records to structs", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			data        string
+			expected    []testOrder
+			expectedErr error
+			errContains string
+		}{
+			{
+				name: "maps columns by tag and name",
+				data: "id,customer,AMOUNT,paid,placed_at,note,unknown\n" +
+					"1,alice,9.5,true,2024-05-01T12:00:00Z,ignored,x\n" +
+					"2,bob,,false,,,\n",
+				expected: []testOrder{
+					{ID: 1, Customer: "alice", Amount: 9.5, Paid: true, Placed: placed},
+					{ID: 2, Customer: "bob"},
+				},
+			},
+			{
+				name:     "handles a header without records",
+				data:     "id,customer\n",
+				expected: []testOrder{},
+			},
+			{
+				name:        "fails on invalid fields",
+				data:        "id,customer\n1,alice\nx,bob\n",
+				expectedErr: strconv.ErrSyntax,
+				errContains: `line 3: column "id"`,
+			},
+			{
+				name:        "fails on malformed records",
+				data:        "id,customer\n1,alice,extra\n",
+				expectedErr: csv.ErrFieldCount,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				stream := compose.SourceToSink(
+					CSV[testOrder](strings.NewReader(tt.data), CSVConfig{}),
+					sinks.Slice[testOrder](),
+				)
+
+				res := <-stream.Run(context.Background())
+				stream.AwaitDone()
+
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, res.Err, tt.expectedErr)
+					assert.ErrorContains(t, res.Err, tt.errContains)
+					return
+				}
+				require.NoError(t, res.Err)
+				assert.Equal(t, tt.expected, res.Value)
+			})
+		}
+	})
+
+	t.Run("fails on unsupported types", func(t *testing.T) {
+		stream := compose.SourceToSink(CSV[int](strings.NewReader("a\n1\n"), CSVConfig{}), sinks.Slice[int]())
+
+		res := <-stream.Run(context.Background())
+		stream.AwaitDone()
+
+		assert.ErrorContains(t, res.Err, "not a struct")
+	})
+}
+
+func TestCSVFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.csv")
+	require.NoError(t, os.WriteFile(path, []byte("id,customer\n1,alice\n2,bob\n"), 0o600))
+	stream := compose.SourceToSink(CSVFile[testOrder](path, CSVConfig{}), sinks.Slice[testOrder]())
+
+	// The file is read again on every run
+	for range 2 {
+		res := <-stream.Run(context.Background())
+		stream.AwaitDone()
+
+		require.NoError(t, res.Err)
+		assert.Equal(t, []testOrder{{ID: 1, Customer: "alice"}, {ID: 2, Customer: "bob"}}, res.Value)
+	}
+}
@@ -0,0 +1,83 @@
+package sources
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// FileLinesConfig configures how FileLines reads a file.
+type FileLinesConfig struct {
+	// MaxLineLength is the maximum length of a line in bytes, excluding the line ending
+	// Reading fails with bufio.ErrTooLong on longer lines
+	// If not specified, bufio.MaxScanTokenSize is used
+	MaxLineLength int
+}
+
+// FileLines creates a Source that emits the lines of a file, without their line endings, and
+// completes at the end of the file. Lines ending in "\r\n" are stripped of both characters, and
+// a final line without a line ending is emitted as well. The file is read with buffering, one
+// line at a time, so files larger than memory can be processed.
+//
+// The file is opened whenever the stream is run, and closed whenever the source stops, whether
+// it completed, the stream was drained or cancelled, or reading failed. Errors opening or
+// reading the file are emitted, after which the source stops.
+//
+// Parameters:
+//   - path: The path of the file to read
+//   - config: Configuration of the reading
+//   - opts: Optional configuration options for the source
+//
+// Returns a Source that produces the lines of the file
+func FileLines(
+	path string,
+	config FileLinesConfig,
+	opts ...core.SourceOption,
+) *core.Source[string] {
+	maxLineLength := config.MaxLineLength
+	if maxLineLength <= 0 {
+		maxLineLength = bufio.MaxScanTokenSize
+	}
+	type lineFile struct {
+		file    *os.File
+		scanner *bufio.Scanner
+	}
+
+	return UnfoldResource(
+		func(ctx context.Context) (*lineFile, error) {
+			file, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			return &lineFile{file: file, scanner: newLineScanner(file, maxLineLength)}, nil
+		},
+		func(ctx context.Context, f *lineFile) (string, bool, error) {
+			if f.scanner.Scan() {
+				if len(f.scanner.Bytes()) > maxLineLength {
+					return "", false, fmt.Errorf("reading %s: %w", path, bufio.ErrTooLong)
+				}
+				return f.scanner.Text(), true, nil
+			}
+			if err := f.scanner.Err(); err != nil {
+				return "", false, fmt.Errorf("reading %s: %w", path, err)
+			}
+			return "", false, nil
+		},
+		func(f *lineFile) error {
+			return f.file.Close()
+		},
+		opts...)
+}
+
+// newLineScanner creates a Scanner splitting the contents of r into lines, failing with
+// bufio.ErrTooLong on lines that do not fit into a buffer of maxLineLength bytes together with
+// their line ending.
+func newLineScanner(r io.Reader, maxLineLength int) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, min(maxLineLength+2, 64*1024)), maxLineLength+2)
+	return scanner
+}
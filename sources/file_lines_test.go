@@ -0,0 +1,105 @@
+package sources
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/flows"
+	"github.com/svenvdam/linea/sinks"
+)
+
+func TestFileLines(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		config      FileLinesConfig
+		expected    []string
+		expectedErr error
+	}{
+		{
+			name:     "emits lines without line endings",
+			content:  "a\nbb\r\n\nccc\n",
+			expected: []string{"a", "bb", "", "ccc"},
+		},
+		{
+			name:     "emits a final line without line ending",
+			content:  "a\nb",
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "handles empty files",
+			content:  "",
+			expected: []string{},
+		},
+		{
+			name:     "accepts lines of the maximum length",
+			content:  "abc\r\nde\n",
+			config:   FileLinesConfig{MaxLineLength: 3},
+			expected: []string{"abc", "de"},
+		},
+		{
+			name:        "fails on lines exceeding the maximum length",
+			content:     "abc\nabcd\n",
+			config:      FileLinesConfig{MaxLineLength: 3},
+			expectedErr: bufio.ErrTooLong,
+		},
+		{
+			name:        "fails on long lines by default",
+			content:     strings.Repeat("x", bufio.MaxScanTokenSize+1),
+			expectedErr: bufio.ErrTooLong,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "lines.txt")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+			stream := compose.SourceToSink(FileLines(path, tt.config), sinks.Slice[string]())
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+		})
+	}
+
+	t.Run("fails on missing files", func(t *testing.T) {
+		stream := compose.SourceToSink(
+			FileLines(filepath.Join(t.TempDir(), "missing.txt"), FileLinesConfig{}),
+			sinks.Slice[string](),
+		)
+
+		res := <-stream.Run(context.Background())
+		stream.AwaitDone()
+
+		assert.ErrorIs(t, res.Err, os.ErrNotExist)
+	})
+
+	t.Run("stops reading when downstream completes", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "lines.txt")
+		require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("line\n", 1000)), 0o600))
+		stream := compose.SourceThroughFlowToSink(
+			FileLines(path, FileLinesConfig{}),
+			flows.Take[string](2),
+			sinks.Slice[string](),
+		)
+
+		res := <-stream.Run(context.Background())
+		stream.AwaitDone()
+
+		require.NoError(t, res.Err)
+		assert.Equal(t, []string{"line", "line"}, res.Value)
+	})
+}
@@ -0,0 +1,76 @@
+package sources
+
+import (
+	"context"
+	"sync"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// FromChan creates a Source that emits items received from a channel, and completes gracefully
+// once the channel is closed. Unlike Chan, it also stops while waiting for the next item when
+// the context is cancelled or the stream is drained, so a channel that is never closed does not
+// keep the stream running. Items not yet received from the channel are left in it, while an item
+// already received when the stream is drained is discarded and reported through core.ReportDrop.
+//
+// Errors received from errs are emitted as errors, interleaved with the items in the order they
+// are received, leaving it to downstream stages to decide whether to stop. errs may be nil, and
+// closing it does not complete the source.
+//
+// Type Parameters:
+//   - O: The type of items produced by this source
+//
+// Parameters:
+//   - ch: The channel from which items are received
+//   - errs: An optional channel from which errors are received, or nil
+//   - opts: Optional configuration options for the source
+//
+// Returns a Source that produces items from the channel
+func FromChan[O any](
+	ch <-chan O,
+	errs <-chan error,
+	opts ...core.SourceOption,
+) *core.Source[O] {
+	return core.NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan core.Item[O] {
+			out := make(chan core.Item[O])
+			wg.Add(1)
+			go func() {
+				defer close(out)
+				defer wg.Done()
+				errs := errs // set to nil once closed, so that it is no longer selected
+				for {
+					var item core.Item[O]
+					select {
+					case <-ctx.Done():
+						return
+					case <-complete:
+						return
+					case elem, ok := <-ch:
+						if !ok {
+							return
+						}
+						item = core.Item[O]{Value: elem}
+					case err, ok := <-errs:
+						if !ok {
+							errs = nil
+							continue
+						}
+						item = core.Item[O]{Err: err}
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case <-complete:
+						if item.Err == nil {
+							core.ReportDrop(ctx, "FromChan", core.DropReasonDrained, item.Value)
+						}
+						return
+					case out <- item:
+					}
+				}
+			}()
+			return out
+		},
+		opts...)
+}
@@ -0,0 +1,134 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/flows"
+	"github.com/svenvdam/linea/sinks"
+)
+
+func TestFromChan(t *testing.T) {
+	errRead := errors.New("read failed")
+
+	tests := []struct {
+		name   string
+		action func(ch chan int, errs chan error, stream *core.Stream[[]int])
+		ctx    func() context.Context
+		want   core.Item[[]int]
+	}{
+		{
+			name: "completes when channel is closed",
+			action: func(ch chan int, errs chan error, stream *core.Stream[[]int]) {
+				ch <- 1
+				ch <- 2
+				close(ch)
+			},
+			want: core.Item[[]int]{Value: []int{1, 2}},
+		},
+		{
+			name: "emits errors from the error channel",
+			action: func(ch chan int, errs chan error, stream *core.Stream[[]int]) {
+				ch <- 1
+				errs <- errRead
+			},
+			want: core.Item[[]int]{Err: errRead},
+		},
+		{
+			name: "continues after errors when configured",
+			action: func(ch chan int, errs chan error, stream *core.Stream[[]int]) {
+				ch <- 1
+				errs <- errRead
+				close(errs)
+				ch <- 2
+				close(ch)
+			},
+			ctx: func() context.Context {
+				return core.ContextWithAttributes(context.Background(), core.ContinueOnError())
+			},
+			want: core.Item[[]int]{Value: []int{1, 2}},
+		},
+		{
+			name: "stops waiting when stream is drained",
+			action: func(ch chan int, errs chan error, stream *core.Stream[[]int]) {
+				ch <- 1
+				time.Sleep(20 * time.Millisecond) // give time for processing
+				stream.Drain()
+			},
+			want: core.Item[[]int]{Value: []int{1}},
+		},
+		{
+			name: "stops waiting when context is cancelled",
+			action: func(ch chan int, errs chan error, stream *core.Stream[[]int]) {
+				stream.Cancel()
+			},
+			want: core.Item[[]int]{Err: context.Canceled},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx()
+			}
+			// The channels are never closed unless the test case does so
+			ch := make(chan int)
+			errs := make(chan error)
+
+			stream := compose.SourceToSink(
+				FromChan(ch, errs),
+				sinks.Slice[int](),
+			)
+
+			resChan := stream.Run(ctx)
+			tt.action(ch, errs, stream)
+			res := <-resChan
+			stream.AwaitDone()
+
+			if tt.want.Err != nil {
+				assert.ErrorIs(t, res.Err, tt.want.Err)
+				return
+			}
+			assert.NoError(t, res.Err)
+			assert.Equal(t, tt.want.Value, res.Value)
+		})
+	}
+}
+
+func TestFromChanDrainWithItemInFlight(t *testing.T) {
+	counter := core.NewDropCounter()
+	ctx := core.WithDropHandler(context.Background(), counter.Handle)
+	ch := make(chan int)
+	release := make(chan struct{})
+	stream := compose.SourceThroughFlowToSink(
+		FromChan[int](ch, nil),
+		flows.Map(func(ctx context.Context, i int) int {
+			<-release // hold the first item, so that the source holds the next ones
+			return i
+		}),
+		sinks.Slice[int](),
+	)
+
+	resChan := stream.Run(ctx)
+	ch <- 1
+	ch <- 2
+	ch <- 3 // received from the channel, but not emitted yet
+
+	stream.Drain()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	res := <-resChan
+	stream.AwaitDone()
+
+	// Every item received from the channel is either emitted or reported as drained
+	assert.NoError(t, res.Err)
+	assert.Equal(t, []int{1, 2, 3}[:len(res.Value)], res.Value)
+	assert.Equal(t, int64(3-len(res.Value)), counter.Total())
+}
@@ -13,12 +13,19 @@ import (
 // The source will continue polling and emitting values until the context is cancelled, the stream is drained,
 // or the poll function returns an error.
 //
+// The context passed to the poll function is cancelled when the stream is drained, so that
+// long-running polls (such as long polling an external queue) stop fetching new items as soon
+// as possible. Errors returned by a poll that was interrupted this way are not emitted.
+//
 // The poll function returns three values:
 //   - val: Pointer to the value to emit (or nil if no value should be emitted)
 //   - more: Whether there are more items available to poll immediately. If true, the next polling attempt
 //     will occur immediately (nanosecond interval) rather than waiting for the specified interval.
 //   - err: Error that occurred during polling (if non-nil, the error will be sent to the stream and polling continues)
 //
+// A panic in the poll function is sent to the stream as a core.PanicError, unless the source
+// is configured with core.PropagatePanics.
+//
 // Type Parameters:
 //   - O: The type of items produced by this source
 //
@@ -46,13 +53,31 @@ func Poll[O any](
 				ticker := time.NewTicker(interval)
 				defer ticker.Stop()
 
+				// Cancel in-flight polls when the stream is drained
+				pollCtx, cancelPoll := context.WithCancel(ctx)
+				defer cancelPoll()
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					select {
+					case <-complete:
+						cancelPoll()
+					case <-pollCtx.Done():
+					}
+				}()
+
 				shouldPoll := true
 
 				for {
 					if shouldPoll {
-						val, more, err := poll(ctx)
+						var val *O
+						var more bool
+						var err error
+						if panicErr := core.CatchPanic(ctx, func() { val, more, err = poll(pollCtx) }); panicErr != nil {
+							err = panicErr
+						}
 
-						if err != nil {
+						if err != nil && pollCtx.Err() == nil {
 							util.Send(ctx, core.Item[O]{Err: err}, out)
 						}
 
@@ -187,6 +187,25 @@ func TestPoll(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "fails on panic",
+			poll: func() func(context.Context) (*int, bool, error) {
+				counter := atomic.Int32{}
+				return func(ctx context.Context) (*int, bool, error) {
+					val := int(counter.Add(1))
+					if val > 2 {
+						panic("poll failed")
+					}
+					return &val, false, nil
+				}
+			},
+			interval: 50 * time.Millisecond,
+			duration: 250 * time.Millisecond,
+			check: func(t *testing.T, res []int) {
+				assert.Equal(t, []int{1, 2}, res)
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -214,3 +233,28 @@ func TestPoll(t *testing.T) {
 		})
 	}
 }
+
+func TestPollInterruptedOnDrain(t *testing.T) {
+	started := make(chan struct{})
+	stream := compose.SourceToSink(
+		Poll(func(ctx context.Context) (*int, bool, error) {
+			close(started)
+			// Simulate a long poll that only returns when its context is cancelled
+			<-ctx.Done()
+			return nil, false, ctx.Err()
+		}, time.Second),
+		sinks.Slice[int](),
+	)
+
+	resChan := stream.Run(context.Background())
+	<-started
+	stream.Drain()
+
+	select {
+	case res := <-resChan:
+		assert.NoError(t, res.Err)
+		assert.Empty(t, res.Value)
+	case <-time.After(time.Second):
+		t.Fatal("drain did not interrupt the in-flight poll")
+	}
+}
@@ -0,0 +1,154 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// ErrQueueClosed is returned by SourceQueue.Offer once the queue no longer accepts items,
+// because it was completed or failed, or because its stream stopped.
+var ErrQueueClosed = errors.New("queue closed")
+
+// SourceQueue is the handle of a Source created by Queue, through which imperative code such
+// as HTTP handlers or callbacks pushes items into a running stream. Its methods are safe for
+// concurrent use.
+//
+// Type Parameters:
+//   - T: The type of items offered to the queue
+type SourceQueue[T any] struct {
+	in      chan T
+	closed  chan struct{} // closed by Complete or Fail
+	stopped chan struct{} // closed once the source stopped
+	err     error         // the error passed to Fail, written before closed is closed
+
+	closeOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// Offer pushes an item into the stream. It waits until the source accepts the item, which
+// backpressures the caller while the buffer of the queue is full, unless the queue was created
+// with an overflow strategy that drops items instead.
+//
+// Parameters:
+//   - ctx: Context bounding how long to wait for the item to be accepted
+//   - v: The item to push into the stream
+//
+// Returns:
+//   - nil once the item was accepted
+//   - ErrQueueClosed if the queue no longer accepts items
+//   - The error of ctx if it is done before the item was accepted
+func (q *SourceQueue[T]) Offer(ctx context.Context, v T) error {
+	select {
+	case <-q.closed:
+		return ErrQueueClosed
+	case <-q.stopped:
+		return ErrQueueClosed
+	default:
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-q.closed:
+		return ErrQueueClosed
+	case <-q.stopped:
+		return ErrQueueClosed
+	case q.in <- v:
+		return nil
+	}
+}
+
+// Complete stops the queue from accepting items, after which the source completes once the
+// items already accepted have been emitted. Calls after the first Complete or Fail have no effect.
+func (q *SourceQueue[T]) Complete() {
+	q.closeOnce.Do(func() {
+		close(q.closed)
+	})
+}
+
+// Fail stops the queue from accepting items and emits err into the stream, after the items
+// already accepted. Calls after the first Complete or Fail have no effect.
+//
+// Parameters:
+//   - err: The error to emit into the stream
+func (q *SourceQueue[T]) Fail(err error) {
+	q.closeOnce.Do(func() {
+		q.err = err
+		close(q.closed)
+	})
+}
+
+// Queue creates a MatSource whose materialized SourceQueue pushes items into the stream. The
+// source buffers up to bufSize items offered to it, applying strategy when the buffer is full:
+// with core.OverflowBackpressure, SourceQueue.Offer waits for room in the buffer, while the
+// other strategies, such as core.OverflowDropHead, accept items immediately and drop items
+// while downstream is slow.
+//
+// The source completes once SourceQueue.Complete or SourceQueue.Fail is called and the items
+// already accepted have been emitted, or when the stream is drained or cancelled, in which case
+// accepted items not yet emitted are discarded, like with other sources. A SourceQueue feeds a
+// single run of its stream; materialize the stream again for a new queue.
+//
+// Type Parameters:
+//   - O: The type of items produced by this source
+//
+// Parameters:
+//   - bufSize: The number of offered items to buffer
+//   - strategy: The OverflowStrategy applied when the buffer is full
+//   - opts: Optional configuration options for the source
+//
+// Returns a MatSource materializing the SourceQueue of the source
+func Queue[O any](
+	bufSize int,
+	strategy core.OverflowStrategy,
+	opts ...core.SourceOption,
+) core.MatSource[O, *SourceQueue[O]] {
+	return func() (*core.Source[O], *SourceQueue[O]) {
+		q := &SourceQueue[O]{
+			in:      make(chan O),
+			closed:  make(chan struct{}),
+			stopped: make(chan struct{}),
+		}
+		source := core.NewSource(
+			func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan core.Item[O] {
+				out := make(chan core.Item[O])
+				wg.Add(1)
+				go func() {
+					defer close(out)
+					defer wg.Done()
+					defer q.stopOnce.Do(func() { close(q.stopped) })
+					for {
+						var item core.Item[O]
+						select {
+						case <-ctx.Done():
+							return
+						case <-complete:
+							return
+						case <-q.closed:
+							if q.err == nil {
+								return
+							}
+							item = core.Item[O]{Err: q.err}
+						case v := <-q.in:
+							item = core.Item[O]{Value: v}
+						}
+						select {
+						case <-ctx.Done():
+							return
+						case <-complete:
+							return
+						case out <- item:
+						}
+						if item.Err != nil {
+							return
+						}
+					}
+				}()
+				return out
+			},
+			append([]core.SourceOption{core.WithSourceBuffer(bufSize, strategy)}, opts...)...)
+		return source, q
+	}
+}
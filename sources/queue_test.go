@@ -0,0 +1,125 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+)
+
+func TestQueue(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name   string
+		action func(t *testing.T, q *SourceQueue[int], stream *core.Stream[[]int])
+		want   core.Item[[]int]
+	}{
+		{
+			name: "emits offered items until completed",
+			action: func(t *testing.T, q *SourceQueue[int], stream *core.Stream[[]int]) {
+				for i := 1; i <= 3; i++ {
+					require.NoError(t, q.Offer(context.Background(), i))
+				}
+				q.Complete()
+				assert.ErrorIs(t, q.Offer(context.Background(), 4), ErrQueueClosed)
+			},
+			want: core.Item[[]int]{Value: []int{1, 2, 3}},
+		},
+		{
+			name: "fails the stream",
+			action: func(t *testing.T, q *SourceQueue[int], stream *core.Stream[[]int]) {
+				require.NoError(t, q.Offer(context.Background(), 1))
+				q.Fail(errFailed)
+				q.Complete() // has no effect after Fail
+				assert.ErrorIs(t, q.Offer(context.Background(), 2), ErrQueueClosed)
+			},
+			want: core.Item[[]int]{Err: errFailed},
+		},
+		{
+			name: "closes when stream is drained",
+			action: func(t *testing.T, q *SourceQueue[int], stream *core.Stream[[]int]) {
+				stream.Drain()
+				stream.AwaitDone()
+				assert.ErrorIs(t, q.Offer(context.Background(), 2), ErrQueueClosed)
+			},
+			want: core.Item[[]int]{Value: []int{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blueprint := core.ToMat(Queue[int](4, core.OverflowBackpressure), core.MatSinkOf(sinks.Slice[int]()), core.KeepLeft)
+			stream, q := blueprint.Materialize()
+
+			resChan := stream.Run(context.Background())
+			tt.action(t, q, stream)
+			res := <-resChan
+			stream.AwaitDone()
+
+			if tt.want.Err != nil {
+				assert.ErrorIs(t, res.Err, tt.want.Err)
+				return
+			}
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.want.Value, res.Value)
+		})
+	}
+}
+
+func TestQueueOverflow(t *testing.T) {
+	tests := []struct {
+		name      string
+		strategy  core.OverflowStrategy
+		expectErr error
+	}{
+		{
+			name:      "backpressures offers while the buffer is full",
+			strategy:  core.OverflowBackpressure,
+			expectErr: context.DeadlineExceeded,
+		},
+		{
+			name:     "accepts offers while dropping items",
+			strategy: core.OverflowDropHead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			blocked := sinks.ForEach(func(ctx context.Context, i int) {
+				<-release
+			})
+			blueprint := core.ToMat(Queue[int](2, tt.strategy), core.MatSinkOf(blocked), core.KeepLeft)
+			stream, q := blueprint.Materialize()
+			resChan := stream.Run(context.Background())
+
+			// The sink holds the first item, so offers cannot be accepted once the buffer is full
+			var err error
+			for i := range 20 {
+				ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+				err = q.Offer(ctx, i)
+				cancel()
+				if err != nil {
+					break
+				}
+			}
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			close(release)
+			q.Complete()
+			res := <-resChan
+			stream.AwaitDone()
+			assert.NoError(t, res.Err)
+		})
+	}
+}
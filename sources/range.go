@@ -0,0 +1,100 @@
+package sources
+
+import (
+	"context"
+	"sync"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// Integer is the constraint satisfied by the integer types that numeric sources such as
+// Range and Iota generate.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Range creates a Source that emits the integers from start up to, but not including, end,
+// incrementing by step, and then completes. A negative step counts down from start to end
+// instead. If step is zero, or does not lead from start towards end, nothing is emitted.
+//
+// Type Parameters:
+//   - N: The integer type of items produced by this source
+//
+// Parameters:
+//   - start: The first item to emit
+//   - end: The bound at which the source completes, which is not emitted
+//   - step: The difference between consecutive items
+//   - opts: Optional configuration options for the source
+//
+// Returns a Source that produces the integers in the range
+func Range[N Integer](
+	start, end, step N,
+	opts ...core.SourceOption,
+) *core.Source[N] {
+	var zero N
+	descending := step < zero
+	return count(start, step, func(v N) bool {
+		if step == zero {
+			return false
+		}
+		if descending {
+			return v > end
+		}
+		return v < end
+	}, opts...)
+}
+
+// Iota creates a Source that emits the integers start, start+1, start+2 and so on, until the
+// context is cancelled or the stream is drained. It completes rather than wrapping around once
+// the largest value of the integer type was emitted. It suits generating sequence numbers or
+// driving pagination, combined with flows such as Take or TakeWhile to bound it.
+//
+// Type Parameters:
+//   - N: The integer type of items produced by this source
+//
+// Parameters:
+//   - start: The first item to emit
+//   - opts: Optional configuration options for the source
+//
+// Returns a Source that produces increasing integers indefinitely
+func Iota[N Integer](
+	start N,
+	opts ...core.SourceOption,
+) *core.Source[N] {
+	return count(start, 1, func(N) bool { return true }, opts...)
+}
+
+// count creates a Source that emits start and every following integer step apart, as long as
+// more returns true for it and the integer type does not overflow.
+func count[N Integer](
+	start, step N,
+	more func(v N) bool,
+	opts ...core.SourceOption,
+) *core.Source[N] {
+	return core.NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan core.Item[N] {
+			out := make(chan core.Item[N])
+			wg.Add(1)
+			go func() {
+				defer close(out)
+				defer wg.Done()
+				for v := start; more(v); v += step {
+					select {
+					case <-ctx.Done():
+						return
+					case <-complete:
+						return
+					case out <- core.Item[N]{Value: v}:
+					}
+					// Stop rather than wrap around once the integer type overflows
+					if (v+step > v) != (step > 0) {
+						return
+					}
+				}
+			}()
+			return out
+		},
+		opts...,
+	)
+}
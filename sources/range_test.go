@@ -0,0 +1,85 @@
+package sources
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/flows"
+	"github.com/svenvdam/linea/sinks"
+)
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   *core.Source[int]
+		expected []int
+	}{
+		{
+			name:     "counts up to the exclusive end",
+			source:   Range(0, 5, 1),
+			expected: []int{0, 1, 2, 3, 4},
+		},
+		{
+			name:     "counts with a step",
+			source:   Range(1, 10, 3),
+			expected: []int{1, 4, 7},
+		},
+		{
+			name:     "counts down with a negative step",
+			source:   Range(5, 0, -2),
+			expected: []int{5, 3, 1},
+		},
+		{
+			name:     "emits nothing for an empty range",
+			source:   Range(5, 5, 1),
+			expected: []int{},
+		},
+		{
+			name:     "emits nothing when step leads away from end",
+			source:   Range(0, 5, -1),
+			expected: []int{},
+		},
+		{
+			name:     "emits nothing for a zero step",
+			source:   Range(0, 5, 0),
+			expected: []int{},
+		},
+		{
+			name:     "stops before overflowing",
+			source:   Range(math.MaxInt-2, math.MaxInt, 5),
+			expected: []int{math.MaxInt - 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceToSink(tt.source, sinks.Slice[int]())
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+		})
+	}
+}
+
+func TestIota(t *testing.T) {
+	stream := compose.SourceThroughFlowToSink(
+		Iota[uint8](250),
+		flows.Take[uint8](10),
+		sinks.Slice[uint8](),
+	)
+
+	res := <-stream.Run(context.Background())
+	stream.AwaitDone()
+
+	// The source completes rather than wrapping around once the type overflows
+	require.NoError(t, res.Err)
+	assert.Equal(t, []uint8{250, 251, 252, 253, 254, 255}, res.Value)
+}
@@ -0,0 +1,80 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// defaultReaderChunkSize is the chunk size used by Reader when chunkSize is not positive.
+const defaultReaderChunkSize = 32 * 1024
+
+// Reader creates a Source that emits the bytes read from r in chunks of at most chunkSize
+// bytes, and completes once r returns io.EOF. Every chunk is a fresh slice, so downstream
+// stages may retain it. Any other error returned by r is emitted after the bytes read with it,
+// after which the source stops. Combine it with flows such as flows.Frame or flows.Chunk to
+// re-slice the bytes. If chunkSize is not positive, chunks of at most 32 KiB are read.
+//
+// The source stops reading when the context is cancelled or the stream is drained. A read that
+// is blocked, such as on a network connection without a deadline, cannot be interrupted, so the
+// source stops once it returns. The source does not close r.
+//
+// Parameters:
+//   - r: The reader to read bytes from
+//   - chunkSize: The maximum number of bytes per emitted chunk
+//   - opts: Optional configuration options for the source
+//
+// Returns a Source that produces the bytes read from r
+func Reader(
+	r io.Reader,
+	chunkSize int,
+	opts ...core.SourceOption,
+) *core.Source[[]byte] {
+	if chunkSize <= 0 {
+		chunkSize = defaultReaderChunkSize
+	}
+	return core.NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan core.Item[[]byte] {
+			out := make(chan core.Item[[]byte])
+			wg.Add(1)
+			go func() {
+				defer close(out)
+				defer wg.Done()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-complete:
+						return
+					default:
+					}
+
+					buf := make([]byte, chunkSize)
+					n, err := r.Read(buf)
+					if n > 0 {
+						select {
+						case <-ctx.Done():
+							return
+						case <-complete:
+							return
+						case out <- core.Item[[]byte]{Value: buf[:n]}:
+						}
+					}
+					if errors.Is(err, io.EOF) {
+						return
+					}
+					if err != nil {
+						util.Send(ctx, core.Item[[]byte]{Err: err}, out)
+						return
+					}
+				}
+			}()
+			return out
+		},
+		opts...,
+	)
+}
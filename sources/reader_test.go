@@ -0,0 +1,98 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/flows"
+	"github.com/svenvdam/linea/sinks"
+)
+
+func TestReader(t *testing.T) {
+	errRead := errors.New("read failed")
+
+	tests := []struct {
+		name        string
+		r           io.Reader
+		chunkSize   int
+		expected    [][]byte
+		expectedErr error
+	}{
+		{
+			name:      "emits chunks until EOF",
+			r:         strings.NewReader("hello world"),
+			chunkSize: 4,
+			expected:  [][]byte{[]byte("hell"), []byte("o wo"), []byte("rld")},
+		},
+		{
+			name:      "handles empty readers",
+			r:         strings.NewReader(""),
+			chunkSize: 4,
+			expected:  [][]byte{},
+		},
+		{
+			name:      "emits data returned together with EOF",
+			r:         iotest.DataErrReader(strings.NewReader("abc")),
+			chunkSize: 8,
+			expected:  [][]byte{[]byte("abc")},
+		},
+		{
+			name:      "uses the default chunk size if chunk size is zero",
+			r:         strings.NewReader("hello world"),
+			chunkSize: 0,
+			expected:  [][]byte{[]byte("hello world")},
+		},
+		{
+			name:      "uses the default chunk size if chunk size is negative",
+			r:         strings.NewReader("hello world"),
+			chunkSize: -1,
+			expected:  [][]byte{[]byte("hello world")},
+		},
+		{
+			name:        "emits read errors",
+			r:           io.MultiReader(strings.NewReader("abc"), iotest.ErrReader(errRead)),
+			chunkSize:   8,
+			expectedErr: errRead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := compose.SourceToSink(Reader(tt.r, tt.chunkSize), sinks.Slice[[]byte]())
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+		})
+	}
+
+	t.Run("stops reading when downstream completes", func(t *testing.T) {
+		r := bytes.NewReader(bytes.Repeat([]byte("x"), 1024))
+		stream := compose.SourceThroughFlowToSink(
+			Reader(r, 1),
+			flows.Take[[]byte](2),
+			sinks.Slice[[]byte](),
+		)
+
+		res := <-stream.Run(context.Background())
+		stream.AwaitDone()
+
+		require.NoError(t, res.Err)
+		assert.Len(t, res.Value, 2)
+		assert.Greater(t, r.Len(), 0, "reader should not be consumed entirely")
+	})
+}
@@ -41,5 +41,6 @@ func Repeat[O any](
 			}()
 			return out
 		},
+		opts...,
 	)
 }
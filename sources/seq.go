@@ -0,0 +1,90 @@
+package sources
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// FromSeq creates a Source that emits the values of an iterator, such as those returned by
+// slices.Values or maps.Keys, and completes once the iterator is exhausted. The iterator is
+// consumed lazily, so it may be infinite, and it is stopped when the context is cancelled or
+// the stream is drained. Every run of the stream iterates seq again.
+//
+// A panic in seq is sent to the stream as a core.PanicError, unless the source is configured
+// with core.PropagatePanics.
+//
+// Type Parameters:
+//   - O: The type of items produced by this source
+//
+// Parameters:
+//   - seq: The iterator producing the items to emit
+//   - opts: Optional configuration options for the source
+//
+// Returns a Source that produces the values of the iterator
+func FromSeq[O any](
+	seq iter.Seq[O],
+	opts ...core.SourceOption,
+) *core.Source[O] {
+	return FromSeq2(
+		func(yield func(O, error) bool) {
+			for v := range seq {
+				if !yield(v, nil) {
+					return
+				}
+			}
+		},
+		opts...)
+}
+
+// FromSeq2 creates a Source that emits the values of an iterator yielding values together
+// with errors, as is common for iterators reading from fallible resources. Pairs with a
+// non-nil error are emitted as errors, and iteration continues, leaving it to downstream
+// stages to decide whether to stop. Otherwise, it behaves like FromSeq.
+//
+// Type Parameters:
+//   - O: The type of items produced by this source
+//
+// Parameters:
+//   - seq: The iterator producing the items and errors to emit
+//   - opts: Optional configuration options for the source
+//
+// Returns a Source that produces the values and errors of the iterator
+func FromSeq2[O any](
+	seq iter.Seq2[O, error],
+	opts ...core.SourceOption,
+) *core.Source[O] {
+	return core.NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan core.Item[O] {
+			out := make(chan core.Item[O])
+			wg.Add(1)
+			go func() {
+				defer close(out)
+				defer wg.Done()
+				panicErr := core.CatchPanic(ctx, func() {
+					for v, err := range seq {
+						item := core.Item[O]{Value: v}
+						if err != nil {
+							item = core.Item[O]{Err: err}
+						}
+						select {
+						case <-ctx.Done():
+							return
+						case <-complete:
+							return
+						case out <- item:
+						}
+					}
+				})
+				if panicErr != nil {
+					util.Send(ctx, core.Item[O]{Err: panicErr}, out)
+				}
+			}()
+			return out
+		},
+		opts...,
+	)
+}
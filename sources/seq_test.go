@@ -0,0 +1,110 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/flows"
+	"github.com/svenvdam/linea/sinks"
+)
+
+func TestFromSeq(t *testing.T) {
+	// naturals yields the natural numbers, recording whether iteration was stopped
+	stopped := false
+	naturals := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if !yield(i) {
+				stopped = true
+				return
+			}
+		}
+	}
+
+	tests := []struct {
+		name        string
+		seq         iter.Seq[int]
+		take        int
+		expected    []int
+		expectedErr bool
+	}{
+		{
+			name:     "emits the values of a sequence",
+			seq:      slices.Values([]int{1, 2, 3}),
+			expected: []int{1, 2, 3},
+		},
+		{
+			name:     "handles empty sequences",
+			seq:      slices.Values([]int{}),
+			expected: []int{},
+		},
+		{
+			name:     "stops infinite sequences when drained",
+			seq:      naturals,
+			take:     3,
+			expected: []int{0, 1, 2},
+		},
+		{
+			name:        "emits panics as errors",
+			seq:         func(yield func(int) bool) { panic("boom") },
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stopped = false
+			flow := flows.Map(func(ctx context.Context, i int) int { return i })
+			if tt.take > 0 {
+				flow = flows.Take[int](tt.take)
+			}
+			stream := compose.SourceThroughFlowToSink(FromSeq(tt.seq), flow, sinks.Slice[int]())
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if tt.expectedErr {
+				var panicErr *core.PanicError
+				assert.ErrorAs(t, res.Err, &panicErr)
+				return
+			}
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+			if tt.take > 0 {
+				assert.True(t, stopped, "iteration should have been stopped")
+			}
+		})
+	}
+}
+
+func TestFromSeq2(t *testing.T) {
+	errOdd := errors.New("odd")
+	seq := func(yield func(int, error) bool) {
+		for i := 1; i <= 4; i++ {
+			var err error
+			if i%2 == 1 {
+				err = errOdd
+			}
+			if !yield(i, err) {
+				return
+			}
+		}
+	}
+
+	stream := compose.SourceToSink(FromSeq2(seq), sinks.Slice[int]())
+	res := <-stream.Run(context.Background())
+	stream.AwaitDone()
+	assert.ErrorIs(t, res.Err, errOdd)
+
+	// Iteration continues after errors, so they can be skipped downstream
+	res = <-stream.Run(core.ContextWithAttributes(context.Background(), core.ContinueOnError()))
+	stream.AwaitDone()
+	require.NoError(t, res.Err)
+	assert.Equal(t, []int{2, 4}, res.Value)
+}
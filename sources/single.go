@@ -0,0 +1,122 @@
+package sources
+
+import (
+	"context"
+	"sync"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// Single creates a Source that emits a single item and then completes.
+//
+// Type Parameters:
+//   - O: The type of the item
+//
+// Parameters:
+//   - elem: The item to emit
+//   - opts: Optional configuration options for the source
+//
+// Returns a Source that produces exactly one item
+func Single[O any](
+	elem O,
+	opts ...core.SourceOption,
+) *core.Source[O] {
+	return Lazy(func(context.Context) (O, error) { return elem, nil }, opts...)
+}
+
+// Lazy creates a Source that emits the single item returned by fn and then completes. fn is
+// called whenever the stream is run, not when the source is created, so the item can be
+// computed from the state at the time of the run, such as a fresh database query. If fn
+// returns an error, it is emitted instead of the item.
+//
+// A panic in fn is sent to the stream as a core.PanicError, unless the source is configured
+// with core.PropagatePanics.
+//
+// Type Parameters:
+//   - O: The type of the item
+//
+// Parameters:
+//   - fn: Function returning the item to emit, or an error
+//   - opts: Optional configuration options for the source
+//
+// Returns a Source that produces exactly one item or error
+func Lazy[O any](
+	fn func(ctx context.Context) (O, error),
+	opts ...core.SourceOption,
+) *core.Source[O] {
+	return core.NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan core.Item[O] {
+			out := make(chan core.Item[O])
+			wg.Add(1)
+			go func() {
+				defer close(out)
+				defer wg.Done()
+				var elem O
+				var err error
+				if panicErr := core.CatchPanic(ctx, func() { elem, err = fn(ctx) }); panicErr != nil {
+					err = panicErr
+				}
+				if err != nil {
+					util.Send(ctx, core.Item[O]{Err: err}, out)
+					return
+				}
+				select {
+				case <-ctx.Done():
+				case <-complete:
+				case out <- core.Item[O]{Value: elem}:
+				}
+			}()
+			return out
+		},
+		opts...,
+	)
+}
+
+// Future creates a Source that waits for a single item from a channel, such as the result of
+// an asynchronous operation, emits it and then completes. If the channel is closed without an
+// item, the source completes without emitting anything. The source stops waiting when the
+// context is cancelled or the stream is drained.
+//
+// Type Parameters:
+//   - O: The type of the item
+//
+// Parameters:
+//   - ch: The channel delivering the item
+//   - opts: Optional configuration options for the source
+//
+// Returns a Source that produces at most one item
+func Future[O any](
+	ch <-chan O,
+	opts ...core.SourceOption,
+) *core.Source[O] {
+	return core.NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan core.Item[O] {
+			out := make(chan core.Item[O])
+			wg.Add(1)
+			go func() {
+				defer close(out)
+				defer wg.Done()
+				var elem O
+				select {
+				case <-ctx.Done():
+					return
+				case <-complete:
+					return
+				case v, ok := <-ch:
+					if !ok {
+						return
+					}
+					elem = v
+				}
+				select {
+				case <-ctx.Done():
+				case <-complete:
+				case out <- core.Item[O]{Value: elem}:
+				}
+			}()
+			return out
+		},
+		opts...,
+	)
+}
@@ -0,0 +1,117 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/sinks"
+)
+
+func TestSingle(t *testing.T) {
+	stream := compose.SourceToSink(Single(42), sinks.Slice[int]())
+
+	res := <-stream.Run(context.Background())
+	stream.AwaitDone()
+
+	require.NoError(t, res.Err)
+	assert.Equal(t, []int{42}, res.Value)
+}
+
+func TestLazy(t *testing.T) {
+	errQuery := errors.New("query failed")
+
+	tests := []struct {
+		name        string
+		fn          func(calls int64) (int, error)
+		expected    []int
+		expectedErr error
+	}{
+		{
+			name:     "emits the computed item",
+			fn:       func(calls int64) (int, error) { return int(calls), nil },
+			expected: []int{1},
+		},
+		{
+			name:        "emits the error",
+			fn:          func(calls int64) (int, error) { return 0, errQuery },
+			expectedErr: errQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int64
+			source := Lazy(func(ctx context.Context) (int, error) {
+				return tt.fn(calls.Add(1))
+			})
+			stream := compose.SourceToSink(source, sinks.Slice[int]())
+
+			// fn is not called before the stream is run
+			assert.Equal(t, int64(0), calls.Load())
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			assert.Equal(t, int64(1), calls.Load())
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, res.Err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+		})
+	}
+}
+
+func TestFuture(t *testing.T) {
+	tests := []struct {
+		name     string
+		action   func(ch chan int, stream *core.Stream[[]int])
+		expected []int
+	}{
+		{
+			name: "emits the delivered item",
+			action: func(ch chan int, stream *core.Stream[[]int]) {
+				time.Sleep(10 * time.Millisecond)
+				ch <- 42
+			},
+			expected: []int{42},
+		},
+		{
+			name: "completes when closed without an item",
+			action: func(ch chan int, stream *core.Stream[[]int]) {
+				close(ch)
+			},
+			expected: []int{},
+		},
+		{
+			name: "stops waiting when stream is drained",
+			action: func(ch chan int, stream *core.Stream[[]int]) {
+				stream.Drain()
+			},
+			expected: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan int)
+			stream := compose.SourceToSink(Future(ch), sinks.Slice[int]())
+
+			resChan := stream.Run(context.Background())
+			tt.action(ch, stream)
+			res := <-resChan
+			stream.AwaitDone()
+
+			require.NoError(t, res.Err)
+			assert.Equal(t, tt.expected, res.Value)
+		})
+	}
+}
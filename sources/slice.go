@@ -41,5 +41,6 @@ func Slice[O any](
 			}()
 			return out
 		},
+		opts...,
 	)
 }
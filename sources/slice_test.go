@@ -71,7 +71,7 @@ func TestSlice(t *testing.T) {
 
 			stream := compose.SourceThroughFlowToSink(
 				Slice(tt.elements),
-				flows.Throttle[int](1, tt.interval),
+				flows.Throttle[int](1, tt.interval, 1),
 				sinks.Slice[int](),
 			)
 
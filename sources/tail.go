@@ -0,0 +1,223 @@
+package sources
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// TailConfig configures how Tail follows a file.
+type TailConfig struct {
+	// PollInterval is the time between checks for data appended to the file
+	// If not specified, 250ms is used
+	PollInterval time.Duration
+
+	// FromStart makes Tail emit the lines already in the file before following it
+	// If not specified, only lines appended after the source started are emitted
+	FromStart bool
+
+	// MaxLineLength is the maximum length of a line in bytes, excluding the line ending
+	// Reading fails with bufio.ErrTooLong on longer lines
+	// If not specified, bufio.MaxScanTokenSize is used
+	MaxLineLength int
+}
+
+// Tail creates a Source that follows a growing file like tail -F, emitting the lines appended
+// to it without their line endings, as is needed to ship logs. A line is only emitted once its
+// line ending was written, so lines are never split. The source keeps following the file until
+// the context is cancelled or the stream is drained.
+//
+// The file is checked for new data every poll interval. If the file is truncated, it is read
+// again from its start. If the file is rotated, that is, replaced by a new file with the same
+// name, the rest of the old file is emitted and the new file is followed from its start, once
+// it has been created. The file must exist when the source starts; errors opening or reading
+// it are emitted, after which the source stops. The file is closed whenever the source stops.
+//
+// Parameters:
+//   - path: The path of the file to follow
+//   - config: Configuration of the following
+//   - opts: Optional configuration options for the source
+//
+// Returns a Source that produces the lines appended to the file
+func Tail(
+	path string,
+	config TailConfig,
+	opts ...core.SourceOption,
+) *core.Source[string] {
+	pollInterval := config.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = 250 * time.Millisecond
+	}
+	maxLineLength := config.MaxLineLength
+	if maxLineLength <= 0 {
+		maxLineLength = bufio.MaxScanTokenSize
+	}
+
+	return core.NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan core.Item[string] {
+			out := make(chan core.Item[string])
+			wg.Add(1)
+			go func() {
+				defer close(out)
+				defer wg.Done()
+
+				t, err := openTail(path, !config.FromStart)
+				if err != nil {
+					util.Send(ctx, core.Item[string]{Err: err}, out)
+					return
+				}
+				defer func() { t.file.Close() }()
+
+				ticker := time.NewTicker(pollInterval)
+				defer ticker.Stop()
+
+				for {
+					// Emit all complete lines available
+					for {
+						line, ok, err := t.readLine(maxLineLength)
+						if err != nil {
+							util.Send(ctx, core.Item[string]{Err: fmt.Errorf("reading %s: %w", path, err)}, out)
+							return
+						}
+						if !ok {
+							break
+						}
+						select {
+						case <-ctx.Done():
+							return
+						case <-complete:
+							return
+						case out <- core.Item[string]{Value: line}:
+						}
+					}
+
+					select {
+					case <-ctx.Done():
+						return
+					case <-complete:
+						return
+					case <-ticker.C:
+					}
+
+					if err := t.follow(path); err != nil {
+						util.Send(ctx, core.Item[string]{Err: err}, out)
+						return
+					}
+				}
+			}()
+			return out
+		},
+		opts...,
+	)
+}
+
+// tailFile is the file followed by Tail.
+type tailFile struct {
+	file    *os.File
+	info    os.FileInfo
+	reader  *bufio.Reader
+	offset  int64  // number of bytes read from the file
+	pending []byte // the start of a line whose line ending was not yet written
+	rotated bool   // whether the file was replaced, so the rest of it is the last data to read
+}
+
+// openTail opens the file at path for Tail, positioned at its end if atEnd is set.
+func openTail(path string, atEnd bool) (*tailFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	var offset int64
+	if atEnd {
+		if offset, err = file.Seek(0, io.SeekEnd); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return &tailFile{file: file, info: info, reader: bufio.NewReader(file), offset: offset}, nil
+}
+
+// readLine returns the next complete line of the file, or false if no complete line is
+// available yet. Once the file was rotated, its last line is returned even without a line ending.
+func (t *tailFile) readLine(maxLineLength int) (string, bool, error) {
+	for {
+		chunk, err := t.reader.ReadSlice('\n')
+		t.offset += int64(len(chunk))
+		t.pending = append(t.pending, chunk...)
+		if len(bytes.TrimRight(t.pending, "\r\n")) > maxLineLength {
+			return "", false, bufio.ErrTooLong
+		}
+		switch {
+		case err == nil:
+			line := string(bytes.TrimSuffix(bytes.TrimSuffix(t.pending, []byte("\n")), []byte("\r")))
+			t.pending = t.pending[:0]
+			return line, true, nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF):
+			if t.rotated && len(t.pending) > 0 {
+				line := string(t.pending)
+				t.pending = t.pending[:0]
+				return line, true, nil
+			}
+			return "", false, nil
+		default:
+			return "", false, err
+		}
+	}
+}
+
+// follow checks whether the file at path was truncated or rotated since it was last read, and
+// prepares reading the data to follow accordingly.
+func (t *tailFile) follow(path string) error {
+	if t.rotated {
+		// The rest of the old file was read, so switch to the new file
+		next, err := openTail(path, false)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil // wait for the new file to be created
+		}
+		if err != nil {
+			return err
+		}
+		t.file.Close()
+		*t = *next
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		t.rotated = true // the file was moved away, and is replaced later
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !os.SameFile(info, t.info) {
+		t.rotated = true
+		return nil
+	}
+	if info.Size() < t.offset {
+		// The file was truncated, so read it again from its start
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		t.reader.Reset(t.file)
+		t.offset = 0
+		t.pending = t.pending[:0]
+	}
+	return nil
+}
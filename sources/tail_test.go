@@ -0,0 +1,117 @@
+package sources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/sinks"
+)
+
+func TestTail(t *testing.T) {
+	// appendFile appends data to the file at path
+	appendFile := func(t *testing.T, path string, data string) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
+		require.NoError(t, err)
+		_, err = f.WriteString(data)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+	}
+
+	tests := []struct {
+		name      string
+		fromStart bool
+		steps     []func(t *testing.T, path string)
+		expected  []string
+	}{
+		{
+			name: "emits appended lines once they are complete",
+			steps: []func(t *testing.T, path string){
+				func(t *testing.T, path string) { appendFile(t, path, "a\nb") },
+				func(t *testing.T, path string) { appendFile(t, path, "c\r\n") },
+			},
+			expected: []string{"a", "bc"},
+		},
+		{
+			name:      "emits existing lines from the start",
+			fromStart: true,
+			steps: []func(t *testing.T, path string){
+				func(t *testing.T, path string) { appendFile(t, path, "a\n") },
+			},
+			expected: []string{"existing", "a"},
+		},
+		{
+			name: "reads truncated files from the start",
+			steps: []func(t *testing.T, path string){
+				func(t *testing.T, path string) { appendFile(t, path, "a\n") },
+				func(t *testing.T, path string) {
+					require.NoError(t, os.WriteFile(path, []byte("b\n"), 0o600))
+				},
+			},
+			expected: []string{"a", "b"},
+		},
+		{
+			name: "follows rotated files",
+			steps: []func(t *testing.T, path string){
+				func(t *testing.T, path string) {
+					appendFile(t, path, "a\nb\nc")
+					require.NoError(t, os.Rename(path, path+".1"))
+				},
+				func(t *testing.T, path string) { appendFile(t, path, "d\n") },
+			},
+			expected: []string{"a", "b", "c", "d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			require.NoError(t, os.WriteFile(path, []byte("existing\n"), 0o600))
+
+			lines := make(chan string, 10)
+			stream := compose.SourceToSink(
+				Tail(path, TailConfig{PollInterval: 5 * time.Millisecond, FromStart: tt.fromStart}),
+				sinks.ForEach(func(ctx context.Context, line string) { lines <- line }),
+			)
+			resChan := stream.Run(context.Background())
+
+			for _, step := range tt.steps {
+				time.Sleep(20 * time.Millisecond) // let the source catch up
+				step(t, path)
+			}
+
+			var got []string
+			for range tt.expected {
+				select {
+				case line := <-lines:
+					got = append(got, line)
+				case <-time.After(2 * time.Second):
+					t.Fatalf("timed out waiting for lines, got %v", got)
+				}
+			}
+			assert.Equal(t, tt.expected, got)
+
+			stream.Drain()
+			res := <-resChan
+			stream.AwaitDone()
+			assert.NoError(t, res.Err)
+		})
+	}
+
+	t.Run("fails on missing files", func(t *testing.T) {
+		stream := compose.SourceToSink(
+			Tail(filepath.Join(t.TempDir(), "missing.log"), TailConfig{}),
+			sinks.Slice[string](),
+		)
+
+		res := <-stream.Run(context.Background())
+		stream.AwaitDone()
+
+		assert.ErrorIs(t, res.Err, os.ErrNotExist)
+	})
+}
@@ -0,0 +1,78 @@
+package sources
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/svenvdam/linea/core"
+)
+
+// Tick creates a Source that emits an item on a fixed schedule, created by makeElem from the
+// time of the tick. The source will keep emitting items until the context is cancelled or the
+// stream is drained. Unlike Poll, no external call is involved, which suits heartbeats and
+// triggering periodic work downstream.
+//
+// If downstream is slower than the interval, ticks are dropped rather than queued, like with
+// a time.Ticker, so items never pile up.
+//
+// Type Parameters:
+//   - O: The type of items produced by this source
+//
+// Parameters:
+//   - interval: Duration between ticks
+//   - makeElem: Function creating the item to emit from the time of the tick
+//   - immediate: Whether to emit an item when the source starts rather than after the first interval
+//   - opts: Optional configuration options for the source
+//
+// Returns a Source that produces an item every interval
+func Tick[O any](
+	interval time.Duration,
+	makeElem func(t time.Time) O,
+	immediate bool,
+	opts ...core.SourceOption,
+) *core.Source[O] {
+	return core.NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan core.Item[O] {
+			out := make(chan core.Item[O])
+			wg.Add(1)
+			go func() {
+				defer close(out)
+				defer wg.Done()
+
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				// send emits the item for a tick, returning false once the source should stop
+				send := func(t time.Time) bool {
+					select {
+					case <-ctx.Done():
+						return false
+					case <-complete:
+						return false
+					case out <- core.Item[O]{Value: makeElem(t)}:
+						return true
+					}
+				}
+
+				if immediate && !send(time.Now()) {
+					return
+				}
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-complete:
+						return
+					case t := <-ticker.C:
+						if !send(t) {
+							return
+						}
+					}
+				}
+			}()
+			return out
+		},
+		opts...,
+	)
+}
@@ -0,0 +1,78 @@
+package sources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/flows"
+	"github.com/svenvdam/linea/sinks"
+)
+
+func TestTick(t *testing.T) {
+	tests := []struct {
+		name      string
+		interval  time.Duration
+		immediate bool
+		take      int
+		minDelay  time.Duration
+		maxDelay  time.Duration
+	}{
+		{
+			name:     "emits after the first interval",
+			interval: 20 * time.Millisecond,
+			take:     3,
+			minDelay: 20 * time.Millisecond,
+		},
+		{
+			name:      "emits immediately on start",
+			interval:  time.Hour,
+			immediate: true,
+			take:      1,
+			maxDelay:  time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			stream := compose.SourceThroughFlowToSink(
+				Tick(tt.interval, func(t time.Time) time.Time { return t }, tt.immediate),
+				flows.Take[time.Time](tt.take),
+				sinks.Slice[time.Time](),
+			)
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			require.NoError(t, res.Err)
+			require.Len(t, res.Value, tt.take)
+			assert.GreaterOrEqual(t, res.Value[0].Sub(start), tt.minDelay)
+			if tt.maxDelay > 0 {
+				assert.Less(t, time.Since(start), tt.maxDelay)
+			}
+			for i := 1; i < len(res.Value); i++ {
+				assert.True(t, res.Value[i].After(res.Value[i-1]), "ticks should be increasing")
+			}
+		})
+	}
+
+	t.Run("stops when stream is drained", func(t *testing.T) {
+		stream := compose.SourceToSink(
+			Tick(5*time.Millisecond, func(t time.Time) int { return 1 }, true),
+			sinks.Slice[int](),
+		)
+
+		resChan := stream.Run(context.Background())
+		time.Sleep(30 * time.Millisecond)
+		stream.Drain()
+		res := <-resChan
+		stream.AwaitDone()
+
+		assert.NoError(t, res.Err)
+		assert.NotEmpty(t, res.Value)
+	})
+}
@@ -0,0 +1,66 @@
+package sources
+
+import (
+	"context"
+	"sync"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// Unfold creates a Source that generates items from state carried from one item to the next,
+// such as a pagination cursor or the frontier of a recursive expansion. Starting from initial,
+// fn returns the next state together with the item to emit, or false to complete the source.
+// Every run of the stream starts from initial again. The source stops early when the context
+// is cancelled or the stream is drained.
+//
+// A panic in fn is sent to the stream as a core.PanicError, after which the source completes,
+// unless the source is configured with core.PropagatePanics.
+//
+// Type Parameters:
+//   - S: The type of the state
+//   - O: The type of items produced by this source
+//
+// Parameters:
+//   - initial: The state the first item is generated from
+//   - fn: Function returning the next state and the item to emit, or false to complete
+//   - opts: Optional configuration options for the source
+//
+// Returns a Source that produces the items generated from the state
+func Unfold[S, O any](
+	initial S,
+	fn func(ctx context.Context, state S) (next S, elem O, ok bool),
+	opts ...core.SourceOption,
+) *core.Source[O] {
+	return core.NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan core.Item[O] {
+			out := make(chan core.Item[O])
+			wg.Add(1)
+			go func() {
+				defer close(out)
+				defer wg.Done()
+				state := initial
+				for {
+					var elem O
+					var ok bool
+					if panicErr := core.CatchPanic(ctx, func() { state, elem, ok = fn(ctx, state) }); panicErr != nil {
+						util.Send(ctx, core.Item[O]{Err: panicErr}, out)
+						return
+					}
+					if !ok {
+						return
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case <-complete:
+						return
+					case out <- core.Item[O]{Value: elem}:
+					}
+				}
+			}()
+			return out
+		},
+		opts...,
+	)
+}
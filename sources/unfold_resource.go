@@ -0,0 +1,96 @@
+package sources
+
+import (
+	"context"
+	"sync"
+
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/util"
+)
+
+// UnfoldResource creates a Source that reads items from a resource, such as a database cursor
+// or a network connection, which is opened whenever the stream is run and closed whenever the
+// source stops. For every item, read returns the item to emit, or false once the resource is
+// exhausted, after which the source completes.
+//
+// The resource is closed exactly once per successful open, whether the source completes, the
+// stream is drained or cancelled, or reading fails. Errors returned by open, read or close, as
+// well as panics in them, are sent to the stream, after which the source stops. Because the
+// resource is opened again whenever the source is set up, a flow restarting upstream, such as
+// Retry or a Decider returning core.DecisionRestart, reopens it after a failure.
+//
+// Type Parameters:
+//   - R: The type of the resource
+//   - O: The type of items produced by this source
+//
+// Parameters:
+//   - open: Function opening the resource
+//   - read: Function reading the next item from the resource, returning false once it is exhausted
+//   - closeResource: Function closing the resource
+//   - opts: Optional configuration options for the source
+//
+// Returns a Source that produces the items read from the resource
+func UnfoldResource[R, O any](
+	open func(ctx context.Context) (R, error),
+	read func(ctx context.Context, resource R) (elem O, ok bool, err error),
+	closeResource func(resource R) error,
+	opts ...core.SourceOption,
+) *core.Source[O] {
+	return core.NewSource(
+		func(ctx context.Context, complete <-chan struct{}, cancel context.CancelFunc, wg *sync.WaitGroup) <-chan core.Item[O] {
+			out := make(chan core.Item[O])
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				var resource R
+				var err error
+				if panicErr := core.CatchPanic(ctx, func() { resource, err = open(ctx) }); panicErr != nil {
+					err = panicErr
+				}
+				if err != nil {
+					util.Send(ctx, core.Item[O]{Err: err}, out)
+					close(out)
+					return
+				}
+
+				// The resource is closed before out, so its error can still be sent
+				defer close(out)
+				defer func() {
+					var closeErr error
+					if panicErr := core.CatchPanic(ctx, func() { closeErr = closeResource(resource) }); panicErr != nil {
+						closeErr = panicErr
+					}
+					if closeErr != nil {
+						util.Send(ctx, core.Item[O]{Err: closeErr}, out)
+					}
+				}()
+
+				for {
+					var elem O
+					var ok bool
+					var readErr error
+					if panicErr := core.CatchPanic(ctx, func() { elem, ok, readErr = read(ctx, resource) }); panicErr != nil {
+						readErr = panicErr
+					}
+					if readErr != nil {
+						util.Send(ctx, core.Item[O]{Err: readErr}, out)
+						return
+					}
+					if !ok {
+						return
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case <-complete:
+						return
+					case out <- core.Item[O]{Value: elem}:
+					}
+				}
+			}()
+			return out
+		},
+		opts...,
+	)
+}
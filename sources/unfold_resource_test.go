@@ -0,0 +1,144 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/flows"
+	"github.com/svenvdam/linea/retry"
+	"github.com/svenvdam/linea/sinks"
+)
+
+// testCursor is a resource reading items, failing once it reaches failAt if set.
+type testCursor struct {
+	items  []int
+	pos    int
+	failAt int
+}
+
+func TestUnfoldResource(t *testing.T) {
+	errOpen := errors.New("open failed")
+	errRead := errors.New("read failed")
+
+	tests := []struct {
+		name           string
+		openErr        error
+		failAt         func(opens int64) int // position reading fails at for the given open, or -1
+		flow           *core.Flow[int, int]
+		expected       []int
+		expectedErr    error
+		expectedOpens  int64
+		expectedCloses int64
+	}{
+		{
+			name:           "reads until exhausted and closes",
+			failAt:         func(int64) int { return -1 },
+			flow:           flows.Map(func(ctx context.Context, i int) int { return i }),
+			expected:       []int{1, 2, 3},
+			expectedOpens:  1,
+			expectedCloses: 1,
+		},
+		{
+			name:           "closes when read fails",
+			failAt:         func(int64) int { return 1 },
+			flow:           flows.Map(func(ctx context.Context, i int) int { return i }),
+			expectedErr:    errRead,
+			expectedOpens:  1,
+			expectedCloses: 1,
+		},
+		{
+			name:           "does not close when open fails",
+			openErr:        errOpen,
+			failAt:         func(int64) int { return -1 },
+			flow:           flows.Map(func(ctx context.Context, i int) int { return i }),
+			expectedErr:    errOpen,
+			expectedOpens:  1,
+			expectedCloses: 0,
+		},
+		{
+			name:           "closes when downstream completes",
+			failAt:         func(int64) int { return -1 },
+			flow:           flows.Take[int](1),
+			expected:       []int{1},
+			expectedOpens:  1,
+			expectedCloses: 1,
+		},
+		{
+			name: "reopens when restarted",
+			failAt: func(opens int64) int {
+				if opens == 1 {
+					return 1
+				}
+				return -1
+			},
+			flow:           flows.Retry[int](retry.NewConfig(time.Millisecond, time.Millisecond, 0, retry.WithMaxRetries(1))),
+			expected:       []int{1, 1, 2, 3},
+			expectedOpens:  2,
+			expectedCloses: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opens, closes atomic.Int64
+			source := UnfoldResource(
+				func(ctx context.Context) (*testCursor, error) {
+					n := opens.Add(1)
+					if tt.openErr != nil {
+						return nil, tt.openErr
+					}
+					return &testCursor{items: []int{1, 2, 3}, failAt: tt.failAt(n)}, nil
+				},
+				func(ctx context.Context, c *testCursor) (int, bool, error) {
+					if c.pos == c.failAt {
+						return 0, false, errRead
+					}
+					if c.pos == len(c.items) {
+						return 0, false, nil
+					}
+					c.pos++
+					return c.items[c.pos-1], true, nil
+				},
+				func(c *testCursor) error {
+					closes.Add(1)
+					return nil
+				},
+			)
+			stream := compose.SourceThroughFlowToSink(source, tt.flow, sinks.Slice[int]())
+
+			res := <-stream.Run(context.Background())
+			stream.AwaitDone()
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, res.Err, tt.expectedErr)
+			} else {
+				require.NoError(t, res.Err)
+				assert.Equal(t, tt.expected, res.Value)
+			}
+			assert.Equal(t, tt.expectedOpens, opens.Load())
+			assert.Equal(t, tt.expectedCloses, closes.Load())
+		})
+	}
+
+	t.Run("emits close errors", func(t *testing.T) {
+		errClose := errors.New("close failed")
+		source := UnfoldResource(
+			func(ctx context.Context) (int, error) { return 0, nil },
+			func(ctx context.Context, r int) (int, bool, error) { return 0, false, nil },
+			func(r int) error { return errClose },
+		)
+		stream := compose.SourceToSink(source, sinks.Slice[int]())
+
+		res := <-stream.Run(context.Background())
+		stream.AwaitDone()
+
+		assert.ErrorIs(t, res.Err, errClose)
+	})
+}
@@ -0,0 +1,94 @@
+package sources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svenvdam/linea/compose"
+	"github.com/svenvdam/linea/core"
+	"github.com/svenvdam/linea/flows"
+	"github.com/svenvdam/linea/sinks"
+)
+
+func TestUnfold(t *testing.T) {
+	// pages simulates a paginated API returning the items of a page and the next cursor
+	pages := map[string]struct {
+		items []int
+		next  string
+	}{
+		"":  {items: []int{1, 2}, next: "a"},
+		"a": {items: []int{3}, next: "b"},
+		"b": {items: []int{4, 5}},
+	}
+	type cursor struct {
+		token string
+		done  bool
+	}
+
+	tests := []struct {
+		name        string
+		source      func() *core.Source[[]int]
+		take        int
+		expected    [][]int
+		expectedErr bool
+	}{
+		{
+			name: "follows cursors until done",
+			source: func() *core.Source[[]int] {
+				return Unfold(cursor{}, func(ctx context.Context, c cursor) (cursor, []int, bool) {
+					if c.done {
+						return c, nil, false
+					}
+					page := pages[c.token]
+					return cursor{token: page.next, done: page.next == ""}, page.items, true
+				})
+			},
+			expected: [][]int{{1, 2}, {3}, {4, 5}},
+		},
+		{
+			name: "generates lazily from an unbounded state",
+			source: func() *core.Source[[]int] {
+				return Unfold([]int{0, 1}, func(ctx context.Context, fib []int) ([]int, []int, bool) {
+					return []int{fib[1], fib[0] + fib[1]}, fib, true
+				})
+			},
+			take:     4,
+			expected: [][]int{{0, 1}, {1, 1}, {1, 2}, {2, 3}},
+		},
+		{
+			name: "emits panics as errors",
+			source: func() *core.Source[[]int] {
+				return Unfold(0, func(ctx context.Context, i int) (int, []int, bool) {
+					panic("boom")
+				})
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow := flows.Map(func(ctx context.Context, page []int) []int { return page })
+			if tt.take > 0 {
+				flow = flows.Take[[]int](tt.take)
+			}
+			stream := compose.SourceThroughFlowToSink(tt.source(), flow, sinks.Slice[[]int]())
+
+			// Every run starts from the initial state
+			for range 2 {
+				res := <-stream.Run(context.Background())
+				stream.AwaitDone()
+
+				if tt.expectedErr {
+					var panicErr *core.PanicError
+					assert.ErrorAs(t, res.Err, &panicErr)
+					continue
+				}
+				require.NoError(t, res.Err)
+				assert.Equal(t, tt.expected, res.Value)
+			}
+		})
+	}
+}